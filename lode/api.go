@@ -74,7 +74,8 @@ type Manifest struct {
 	// Metadata contains user-provided key-value pairs.
 	Metadata Metadata `json:"metadata"`
 
-	// Files lists all data files comprising this snapshot.
+	// Files lists all data files comprising this snapshot. Lode writes
+	// them sorted by path; readers must not rely on any order.
 	Files []FileRef `json:"files"`
 
 	// ParentSnapshotID optionally references the previous snapshot.
@@ -91,6 +92,11 @@ type Manifest struct {
 	// Omitted when not applicable.
 	MaxTimestamp *time.Time `json:"max_timestamp,omitempty"`
 
+	// TimestampsSkipped counts records whose timestamp field was missing or
+	// unparseable (see WithTimestampField). Such records do not contribute to
+	// MinTimestamp/MaxTimestamp. Omitted when zero.
+	TimestampsSkipped int64 `json:"timestamps_skipped,omitempty"`
+
 	// Codec records the codec used to serialize structured data (e.g., "jsonl").
 	// Omitted when no codec is configured.
 	Codec string `json:"codec,omitempty"`
@@ -104,11 +110,46 @@ type Manifest struct {
 	// ChecksumAlgorithm records the checksum algorithm used (e.g., "md5").
 	// Omitted when no checksum is configured.
 	ChecksumAlgorithm string `json:"checksum_algorithm,omitempty"`
+
+	// RecordSchemaName and RecordSchemaVersion identify the Schema records
+	// were validated against (see WithSchema).
+	// Omitted when no schema is configured.
+	RecordSchemaName    string `json:"record_schema_name,omitempty"`
+	RecordSchemaVersion string `json:"record_schema_version,omitempty"`
+
+	// Partitions summarizes each partition written by this snapshot, sorted
+	// by path, so readers can prune without scanning Files. Omitted for
+	// unpartitioned layouts and for manifests written before it existed.
+	Partitions []PartitionSummary `json:"partitions,omitempty"`
+
+	// Ordered declares that the snapshot's records have a defined order,
+	// served by Dataset.ReadOrdered (see WithOrdering). Omitted when false.
+	Ordered bool `json:"ordered,omitempty"`
+
+	// OrderKey names the record field ReadOrdered sorts the snapshot by.
+	// Empty with Ordered means partition order: files by path, records as
+	// written.
+	OrderKey string `json:"order_key,omitempty"`
+}
+
+// PartitionSummary aggregates the data files of one partition of a snapshot.
+type PartitionSummary struct {
+	// Path is the partition path (e.g., "day=2024-01-01/region=us").
+	Path string `json:"path"`
+
+	// RowCount is the number of data units in the partition.
+	RowCount int64 `json:"row_count"`
+
+	// SizeBytes is the total stored size of the partition's files.
+	SizeBytes int64 `json:"size_bytes"`
 }
 
 // FileRef describes a single data file within a snapshot.
 type FileRef struct {
-	// Path is the relative path to the file within the dataset.
+	// Path is the file's full store key, e.g.
+	// "datasets/<dataset>/snapshots/<segment>/data/data.jsonl". It is used
+	// as-is on read, with no layout or segment prefix applied, so it may
+	// name a file in another segment (see WithDedup).
 	Path string `json:"path"`
 
 	// SizeBytes is the file size in bytes.
@@ -120,6 +161,17 @@ type FileRef struct {
 	// Stats contains per-file column statistics reported by the codec.
 	// Omitted when the codec does not report statistics.
 	Stats *FileStats `json:"stats,omitempty"`
+
+	// ContentType is the media type of the stored bytes, derived from the
+	// codec (e.g. "application/x-ndjson", "text/csv"), or from the
+	// compressor when HTTP has no content coding for it.
+	// Omitted for manifests written before it existed.
+	ContentType string `json:"content_type,omitempty"`
+
+	// ContentEncoding is the HTTP content coding of the compressor (e.g.
+	// "gzip", "zstd", "br"). Omitted for uncompressed files and compressors
+	// without a content coding.
+	ContentEncoding string `json:"content_encoding,omitempty"`
 }
 
 // FileStats holds per-file statistics reported by a codec after encoding.
@@ -163,6 +215,54 @@ type DatasetSnapshot struct {
 
 	// Manifest describes the snapshot's contents.
 	Manifest *Manifest
+
+	// WriteStats describes the write that created the snapshot. It is set
+	// only on snapshots returned by Write or Append on a dataset created
+	// with WithWriteStats(true), and is nil otherwise.
+	WriteStats *WriteStats
+
+	// layout is the layout of the dataset that returned the snapshot,
+	// used to attribute files to partitions.
+	layout layout
+}
+
+// FileCount returns the number of data files in the snapshot.
+func (s *DatasetSnapshot) FileCount() int {
+	if s.Manifest == nil {
+		return 0
+	}
+	return len(s.Manifest.Files)
+}
+
+// TotalBytes returns the sum of the stored sizes of the snapshot's data files.
+func (s *DatasetSnapshot) TotalBytes() int64 {
+	if s.Manifest == nil {
+		return 0
+	}
+	var total int64
+	for _, f := range s.Manifest.Files {
+		total += f.SizeBytes
+	}
+	return total
+}
+
+// PartitionCounts returns the number of data files per partition path
+// (e.g. "day=2024-01-01"), as extracted by the dataset's layout. Files
+// outside any partition count under "". Snapshots not returned by a Dataset
+// have no layout, so all their files count under "".
+func (s *DatasetSnapshot) PartitionCounts() map[string]int {
+	counts := make(map[string]int)
+	if s.Manifest == nil {
+		return counts
+	}
+	for _, f := range s.Manifest.Files {
+		partition := ""
+		if s.layout != nil {
+			partition = s.layout.extractPartitionPath(f.Path)
+		}
+		counts[partition]++
+	}
+	return counts
 }
 
 // -----------------------------------------------------------------------------
@@ -191,6 +291,9 @@ type Store interface {
 
 	// ReadRange reads a byte range from the given path.
 	// Returns ErrNotFound if the path does not exist.
+	// Returns ErrInvalidRange for a negative offset or length, or on overflow.
+	// An offset at or beyond EOF returns an empty slice; a range extending
+	// beyond EOF is clamped to the available bytes.
 	// Returns ErrRangeReadNotSupported if the store does not support range reads.
 	ReadRange(ctx context.Context, path string, offset, length int64) ([]byte, error)
 
@@ -200,6 +303,86 @@ type Store interface {
 	ReaderAt(ctx context.Context, path string) (io.ReaderAt, error)
 }
 
+// ConditionalWriter is an optional Store capability for optimistic
+// concurrency on latest pointers.
+//
+// CompareAndSwap atomically replaces the content at path with replacement
+// if the current content equals expected. An expected value of "" matches
+// a missing or empty object. On mismatch it returns ErrSnapshotConflict.
+//
+// When the store implements ConditionalWriter, dataset commits swap the
+// latest pointer with CompareAndSwap instead of Delete+Put.
+type ConditionalWriter interface {
+	CompareAndSwap(ctx context.Context, path, expected, replacement string) error
+}
+
+// BatchExistsStore is an optional Store capability for checking the
+// existence of many paths in one call.
+//
+// ExistsMany returns a map with an entry for every requested path.
+// Adapters with high per-request latency (such as S3) implement it to
+// avoid N serial Exists round-trips. Use ExistsMany to dispatch with a
+// per-path fallback for stores that do not implement it.
+type BatchExistsStore interface {
+	ExistsMany(ctx context.Context, paths []string) (map[string]bool, error)
+}
+
+// StatStore is an optional Store capability for reading object metadata
+// without fetching content.
+//
+// Stat returns ErrNotFound if the path does not exist. Use StatObject to
+// dispatch with a Get-based fallback for stores that do not implement it.
+type StatStore interface {
+	Stat(ctx context.Context, path string) (ObjectInfo, error)
+}
+
+// CopyStore is an optional Store capability for copying an object without
+// transferring its bytes through the client.
+//
+// Copy writes the content of src to dst. As with Put, dst must not exist
+// (ErrPathExists); a missing src returns ErrNotFound. Use CopyObject to
+// dispatch with a Get+Put fallback for stores that do not implement it.
+type CopyStore interface {
+	Copy(ctx context.Context, src, dst string) error
+}
+
+// DelimitedLister is an optional Store capability for listing one level of
+// a key hierarchy.
+//
+// ListPrefixes returns the keys under prefix that contain no delimiter
+// after prefix, and the distinct common prefixes, each ending with the
+// first delimiter after prefix, that group all other keys. Adapters with
+// native delimiter listing (such as S3 CommonPrefixes) implement it so a
+// level can be enumerated without listing every key below it. Use
+// ListPrefixes to dispatch with a List-based fallback.
+type DelimitedLister interface {
+	ListPrefixes(ctx context.Context, prefix, delimiter string) (keys, commonPrefixes []string, err error)
+}
+
+// SizedLister is an optional Store capability for listing keys together
+// with their sizes and modification times in one pass.
+//
+// ListSized returns the same keys as List, with ObjectInfo.Path set.
+// Adapters whose list API already reports sizes (S3, the filesystem)
+// implement it so sizing a prefix does not cost a Stat per key. Use
+// ListSized to dispatch with a List+Stat fallback.
+type SizedLister interface {
+	ListSized(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	// Path is the object key. Set by ListSized; empty from Stat.
+	Path string
+
+	// SizeBytes is the object size in bytes.
+	SizeBytes int64
+
+	// ModTime is when the object was last written, as reported by the
+	// store. Zero when the store does not track modification times.
+	ModTime time.Time
+}
+
 // StoreFactory creates a Store. Used for deferred store construction.
 type StoreFactory func() (Store, error)
 
@@ -214,6 +397,11 @@ type Codec interface {
 	// Name returns the codec identifier (for example, "jsonl" or "parquet").
 	Name() string
 
+	// Extension returns the file extension (for example, ".jsonl", ".csv",
+	// ".parquet"). Data file names end with the codec extension followed by
+	// the compressor extension. Readers rely on manifests, not extensions.
+	Extension() string
+
 	// Encode writes records to the given writer.
 	Encode(w io.Writer, records []any) error
 
@@ -245,6 +433,19 @@ type RecordStreamEncoder interface {
 	Close() error
 }
 
+// StreamingDecodeCodec is implemented by codecs that can decode records one
+// at a time.
+//
+// ReadFileRecords uses it to iterate a data file without materializing it.
+// Records of other codecs are decoded with Decode and iterated from memory.
+type StreamingDecodeCodec interface {
+	Codec
+
+	// NewStreamDecoder creates a decoder that reads records from r.
+	// The returned iterator reports decode failures through Err.
+	NewStreamDecoder(r io.Reader) (RecordIterator, error)
+}
+
 // -----------------------------------------------------------------------------
 // Statistical codec interfaces
 // -----------------------------------------------------------------------------
@@ -276,6 +477,21 @@ type StatisticalStreamEncoder interface {
 	FileStats() *FileStats
 }
 
+// RandomAccessCodec is implemented by codecs whose encoded files support
+// decoding a range of records by index without scanning preceding records.
+// This is an optional extension to the Codec interface.
+//
+// Random access requires the stored bytes to be the codec output, so it is
+// only meaningful with the no-op compressor.
+type RandomAccessCodec interface {
+	Codec
+
+	// DecodeRange decodes count records starting at record index start from
+	// an encoded file of the given size. Returns an error if the range
+	// exceeds the number of records in the file.
+	DecodeRange(r io.ReaderAt, size int64, start, count int) ([]any, error)
+}
+
 // -----------------------------------------------------------------------------
 // Compressor interface
 // -----------------------------------------------------------------------------
@@ -319,7 +535,8 @@ type Checksum interface {
 type HashWriter interface {
 	io.Writer
 
-	// Sum returns the computed checksum as a hex-encoded string.
+	// Sum returns the computed checksum as a string. The encoding is
+	// defined by the algorithm: hex for MD5, base64 for CRC32C.
 	Sum() string
 }
 
@@ -338,6 +555,17 @@ type Dataset interface {
 	// Write commits new data and metadata as an immutable snapshot.
 	Write(ctx context.Context, data []any, metadata Metadata) (*DatasetSnapshot, error)
 
+	// Append commits new data as a snapshot whose parent is the current
+	// latest snapshot in storage, re-resolved on every call.
+	// Returns ErrSnapshotConflict if the store implements ConditionalWriter
+	// and another writer committed concurrently.
+	Append(ctx context.Context, data []any, metadata Metadata) (*DatasetSnapshot, error)
+
+	// PlanWrite computes the snapshot Write would commit without writing
+	// anything to storage. Data is encoded, compressed, and partitioned in
+	// memory; the plan's manifest and object keys are returned.
+	PlanWrite(ctx context.Context, data []any, metadata Metadata) (*WritePlan, error)
+
 	// Snapshot retrieves a specific snapshot by ID.
 	Snapshot(ctx context.Context, id DatasetSnapshotID) (*DatasetSnapshot, error)
 
@@ -347,9 +575,88 @@ type Dataset interface {
 	// Read retrieves all data units from a specific snapshot.
 	Read(ctx context.Context, id DatasetSnapshotID) ([]any, error)
 
+	// ReadN retrieves the first n records of a snapshot in manifest file
+	// order. Files are opened one at a time and reading stops once n records
+	// are decoded, so later files are never fetched. Returns every record
+	// when the snapshot has fewer than n.
+	ReadN(ctx context.Context, id DatasetSnapshotID, n int) ([]any, error)
+
+	// Sample returns a uniform random sample of k records of a snapshot, in
+	// no particular order. Every file is read, but only the sample is held
+	// in memory. Returns every record when the snapshot has fewer than k.
+	Sample(ctx context.Context, id DatasetSnapshotID, k int, opts SampleOptions) ([]any, error)
+
+	// ReadChan streams the records of a snapshot onto a channel in manifest
+	// file order. The record channel is closed when reading ends; the error
+	// channel then delivers the terminal error, if any, and is closed.
+	// Cancelling ctx stops reading, releases open objects, and closes both
+	// channels with ctx.Err().
+	ReadChan(ctx context.Context, id DatasetSnapshotID, opts ReadChanOptions) (<-chan any, <-chan error)
+
+	// OpenStream returns the decompressed bytes of every data file of a
+	// snapshot as one stream, in manifest file order, without decoding
+	// records. Files are opened one at a time as the stream reaches them.
+	// The caller must close the stream.
+	OpenStream(ctx context.Context, id DatasetSnapshotID) (io.ReadCloser, error)
+
+	// WriteJSONTo streams the records of a snapshot to w as a JSON array or
+	// NDJSON, in manifest file order, without materializing the snapshot.
+	// Cancelling ctx stops the output between records.
+	WriteJSONTo(ctx context.Context, id DatasetSnapshotID, w io.Writer, opts WriteJSONOptions) error
+
+	// ReadOrdered retrieves the records of a snapshot in the order its
+	// manifest declares (see WithOrdering). Returns ErrNotOrdered if the
+	// manifest does not declare ordering.
+	ReadOrdered(ctx context.Context, id DatasetSnapshotID) ([]any, error)
+
+	// ReadPartitionsWhere retrieves the records of a snapshot whose partition
+	// values satisfy pred. Excluded files are pruned by path without being fetched.
+	ReadPartitionsWhere(ctx context.Context, id DatasetSnapshotID, pred func(partition map[string]string) bool) ([]any, error)
+
+	// ReadPartition retrieves the records of the data files of a snapshot in
+	// one partition, given as its path (e.g. "region=us/day=2024-01-01").
+	// Other files are pruned by path without being fetched.
+	ReadPartition(ctx context.Context, id DatasetSnapshotID, partition string, opts ReadPartitionOptions) ([]any, error)
+
+	// ReadFile retrieves the records of a single data file of a snapshot.
+	// Returns ErrFileNotInManifest if filePath is not in the manifest's Files.
+	ReadFile(ctx context.Context, id DatasetSnapshotID, filePath string) ([]any, error)
+
+	// ReadFileRecords returns an iterator over the records of a single data
+	// file of a snapshot. The caller must close the iterator.
+	// Returns ErrFileNotInManifest if filePath is not in the manifest's Files.
+	ReadFileRecords(ctx context.Context, id DatasetSnapshotID, filePath string) (FileRecordIterator, error)
+
+	// ReadSince returns an iterator over the records of every snapshot that
+	// descends from since, oldest first. The caller must close the iterator.
+	// Returns ErrNotFound if since is not a committed snapshot.
+	ReadSince(ctx context.Context, since DatasetSnapshotID) (FileRecordIterator, error)
+
+	// ReadFrom returns an iterator over the records of cp.SnapshotID that
+	// skips the data files cp.Completed lists, resuming an interrupted
+	// read. A checkpoint with no completed files reads the whole snapshot.
+	// The caller must close the iterator.
+	// Returns ErrFileNotInManifest if a completed path is not in the manifest.
+	ReadFrom(ctx context.Context, cp ReadCheckpoint) (CheckpointIterator, error)
+
 	// Latest returns the most recently committed snapshot.
 	Latest(ctx context.Context) (*DatasetSnapshot, error)
 
+	// LatestBy returns the latest snapshot as chosen by strategy.
+	// LatestByPointer is equivalent to Latest.
+	LatestBy(ctx context.Context, strategy LatestStrategy) (*DatasetSnapshot, error)
+
+	// Tag points alias at a committed snapshot, creating or moving the tag.
+	// Tags are mutable names over immutable snapshots; resolve them with
+	// DatasetReader.ResolveTag. Returns ErrNotFound if the snapshot does not
+	// exist and ErrSnapshotConflict if the store implements
+	// ConditionalWriter and the tag moved concurrently.
+	Tag(ctx context.Context, alias string, id DatasetSnapshotID) error
+
+	// Compact rewrites the records of the given snapshots into one new snapshot.
+	// Input snapshots are left untouched.
+	Compact(ctx context.Context, ids []DatasetSnapshotID, opts CompactOptions) (*DatasetSnapshot, error)
+
 	// StreamWrite returns a StreamWriter for single-pass streaming of a binary payload.
 	// Returns an error if metadata is nil or if a codec is configured.
 	StreamWrite(ctx context.Context, metadata Metadata) (StreamWriter, error)
@@ -360,6 +667,83 @@ type Dataset interface {
 	StreamWriteRecords(ctx context.Context, records RecordIterator, metadata Metadata) (*DatasetSnapshot, error)
 }
 
+// WritePlan describes a snapshot computed by Dataset.PlanWrite.
+//
+// The snapshot ID and CreatedAt are assigned at planning time; a subsequent
+// Write of the same data produces a new ID and timestamp but the same file
+// layout.
+type WritePlan struct {
+	// Manifest is the manifest Write would commit.
+	Manifest *Manifest
+
+	// DataPaths are the object keys of the data files, in manifest order.
+	// With WithDedup, keys of shared parent files are included even though
+	// Write would not upload them.
+	DataPaths []string
+
+	// ManifestPaths are the object keys the manifest would be written to.
+	// The canonical manifest path is always first.
+	ManifestPaths []string
+}
+
+// CompactOptions controls Dataset.Compact.
+type CompactOptions struct {
+	// TargetFileBytes is the approximate maximum stored size of each output
+	// file. Partitions whose encoded size exceeds it are split into multiple
+	// files with evenly sized record batches.
+	// Zero means one file per partition.
+	TargetFileBytes int64
+
+	// CoalesceSmallFiles splits oversized partitions by encoded size rather
+	// than by equal record counts: batches are halved until each encodes
+	// within TargetFileBytes, then adjacent batches of the same partition
+	// are merged while the merged file still fits. Every file stays within
+	// the target (unless a single record exceeds it) and skewed record sizes
+	// do not leave many small files. Costs extra encoding passes.
+	// Requires TargetFileBytes.
+	CoalesceSmallFiles bool
+
+	// Metadata is recorded on the compacted snapshot.
+	// Nil is coalesced to empty.
+	Metadata Metadata
+}
+
+// SampleOptions configures Dataset.Sample.
+type SampleOptions struct {
+	// Seed makes the sample reproducible: the same seed over the same
+	// snapshot selects the same records. Zero means a random seed.
+	Seed uint64
+}
+
+// ReadChanOptions configures Dataset.ReadChan.
+type ReadChanOptions struct {
+	// Buffer is the capacity of the record channel, bounding how far reading
+	// runs ahead of the consumer. Zero means unbuffered; negative is invalid.
+	Buffer int
+}
+
+// ReadPartitionOptions configures Dataset.ReadPartition.
+type ReadPartitionOptions struct {
+	// RequirePresent returns ErrPartitionNotFound when no data file of the
+	// snapshot is in the partition. By default a missing partition reads as
+	// no records.
+	RequirePresent bool
+}
+
+// LatestStrategy selects how Dataset.LatestBy chooses the latest snapshot.
+type LatestStrategy int
+
+const (
+	// LatestByPointer follows the latest pointer, falling back to the
+	// largest snapshot ID, exactly like Latest.
+	LatestByPointer LatestStrategy = iota
+	// LatestByStorageModTime picks the snapshot whose manifest the store
+	// reports as most recently written, for datasets whose CreatedAt and
+	// snapshot IDs come from untrusted clocks. Ties go to the larger
+	// snapshot ID. Requires a store whose Stat reports ModTime.
+	LatestByStorageModTime
+)
+
 // -----------------------------------------------------------------------------
 // StreamWriter interface
 // -----------------------------------------------------------------------------
@@ -404,6 +788,38 @@ type RecordIterator interface {
 	Err() error  // Returns any error encountered during iteration.
 }
 
+// FileRecordIterator is a RecordIterator over the records of stored data
+// files. It holds the current object open until Close, which is idempotent.
+type FileRecordIterator interface {
+	RecordIterator
+	Close() error
+
+	// SkippedCount returns the number of records dropped so far under
+	// WithOnDecodeError. It is always 0 with DecodeErrorFail.
+	SkippedCount() int
+}
+
+// CheckpointIterator is a FileRecordIterator that reports its progress as
+// a ReadCheckpoint. See Dataset.ReadFrom.
+type CheckpointIterator interface {
+	FileRecordIterator
+
+	// Checkpoint returns the files whose records have all been yielded.
+	// Records of the file being read when Checkpoint is called are not
+	// covered: resuming from the checkpoint reads that file again.
+	Checkpoint() ReadCheckpoint
+}
+
+// ReadCheckpoint records the progress of a snapshot read at data file
+// granularity. It marshals to JSON for persistence between runs.
+type ReadCheckpoint struct {
+	// SnapshotID is the snapshot being read.
+	SnapshotID DatasetSnapshotID `json:"snapshot_id"`
+
+	// Completed lists the paths of data files whose records were all read.
+	Completed []string `json:"completed,omitempty"`
+}
+
 // -----------------------------------------------------------------------------
 // Errors
 // -----------------------------------------------------------------------------
@@ -425,6 +841,11 @@ var (
 	// ErrRangeReadNotSupported indicates the store does not support range reads.
 	ErrRangeReadNotSupported = errRangeReadNotSupported{}
 
+	// ErrInvalidRange indicates a ReadRange offset or length is negative,
+	// too large for the platform, or overflows when summed. It also matches
+	// ErrInvalidPath, which stores returned for these cases previously.
+	ErrInvalidRange = errInvalidRange{}
+
 	// ErrCodecConfigured indicates StreamWrite was called with a codec configured.
 	ErrCodecConfigured = errCodecConfigured{}
 
@@ -444,6 +865,66 @@ var (
 
 	// ErrInvalidFormat indicates the Parquet file is malformed or corrupted.
 	ErrInvalidFormat = errInvalidFormat{}
+
+	// ErrUnknownCodec indicates a manifest references a codec that is not
+	// registered in the dataset's CodecRegistry.
+	ErrUnknownCodec = errUnknownCodec{}
+
+	// ErrUnknownComponent indicates a strict reader found a manifest codec,
+	// compressor, or partitioner name it cannot resolve.
+	// See WithStrictComponents and UnknownComponentError.
+	ErrUnknownComponent = errUnknownComponent{}
+
+	// ErrAppendOnly indicates an append-only dataset refused to replace or
+	// delete a committed manifest. See WithAppendOnly.
+	ErrAppendOnly = errAppendOnly{}
+
+	// ErrSnapshotConflict indicates another writer committed since the
+	// parent snapshot was resolved. Only returned by ConditionalWriter stores.
+	ErrSnapshotConflict = errSnapshotConflict{}
+
+	// ErrNotOrdered indicates Dataset.ReadOrdered was called on a snapshot
+	// whose manifest does not declare ordering.
+	ErrNotOrdered = errNotOrdered{}
+
+	// ErrRowCountMismatch indicates a manifest's RowCount differs from the
+	// number of records decoded from its files. See RowCountMismatchError.
+	ErrRowCountMismatch = errRowCountMismatch{}
+
+	// ErrChecksumMismatch indicates stored data files whose checksums differ
+	// from their manifest entries. See ChecksumMismatchError.
+	ErrChecksumMismatch = errChecksumMismatch{}
+
+	// ErrNotManifestPath indicates a path the layout does not recognize as a
+	// manifest. See NotManifestPathError.
+	ErrNotManifestPath = errNotManifestPath{}
+
+	// ErrTooManyPartitions indicates a write would produce more partitions
+	// than WithMaxPartitions allows. See TooManyPartitionsError.
+	ErrTooManyPartitions = errTooManyPartitions{}
+
+	// ErrFileNotInManifest indicates a requested data file is not listed in
+	// the snapshot manifest's Files.
+	ErrFileNotInManifest = errFileNotInManifest{}
+
+	// ErrPartitionNotFound indicates a snapshot has no data files in the
+	// requested partition. Returned by Dataset.ReadPartition only with
+	// ReadPartitionOptions.RequirePresent.
+	ErrPartitionNotFound = errPartitionNotFound{}
+
+	// ErrUnsupportedFormatVersion indicates a manifest was written in a
+	// format version newer than this library reads. The error names the
+	// version.
+	ErrUnsupportedFormatVersion = errUnsupportedFormatVersion{}
+
+	// ErrCompressionWriteUnsupported indicates a compressor that can only
+	// decompress (such as bzip2) was used to write data.
+	ErrCompressionWriteUnsupported = errCompressionWriteUnsupported{}
+
+	// ErrDecryptionFailed indicates an object read through NewEncryptedStore
+	// failed authentication: it was written under a different key, or was
+	// modified or truncated.
+	ErrDecryptionFailed = errDecryptionFailed{}
 )
 
 type errNotFound struct{}
@@ -466,6 +947,13 @@ type errRangeReadNotSupported struct{}
 
 func (errRangeReadNotSupported) Error() string { return "range read not supported" }
 
+type errInvalidRange struct{}
+
+func (errInvalidRange) Error() string { return "invalid range" }
+
+// Is keeps errors.Is(err, ErrInvalidPath) working for range errors.
+func (errInvalidRange) Is(target error) bool { return target == ErrInvalidPath }
+
 type errCodecConfigured struct{}
 
 func (errCodecConfigured) Error() string {
@@ -494,6 +982,62 @@ type errInvalidFormat struct{}
 
 func (errInvalidFormat) Error() string { return "parquet: invalid format" }
 
+type errUnknownCodec struct{}
+
+func (errUnknownCodec) Error() string { return "unknown codec" }
+
+type errUnknownComponent struct{}
+
+func (errUnknownComponent) Error() string { return "unknown manifest component" }
+
+type errAppendOnly struct{}
+
+func (errAppendOnly) Error() string { return "dataset is append-only" }
+
+type errNotOrdered struct{}
+
+func (errNotOrdered) Error() string { return "snapshot does not declare ordering" }
+
+type errRowCountMismatch struct{}
+
+func (errRowCountMismatch) Error() string { return "row count mismatch" }
+
+type errChecksumMismatch struct{}
+
+func (errChecksumMismatch) Error() string { return "checksum mismatch" }
+
+type errNotManifestPath struct{}
+
+func (errNotManifestPath) Error() string { return "not a manifest path" }
+
+type errTooManyPartitions struct{}
+
+func (errTooManyPartitions) Error() string { return "too many partitions" }
+
+type errSnapshotConflict struct{}
+
+func (errSnapshotConflict) Error() string { return "snapshot conflict: latest pointer changed" }
+
+type errFileNotInManifest struct{}
+
+func (errFileNotInManifest) Error() string { return "file not in manifest" }
+
+type errPartitionNotFound struct{}
+
+func (errPartitionNotFound) Error() string { return "partition not found" }
+
+type errUnsupportedFormatVersion struct{}
+
+func (errUnsupportedFormatVersion) Error() string { return "unsupported manifest format version" }
+
+type errCompressionWriteUnsupported struct{}
+
+func (errCompressionWriteUnsupported) Error() string { return "compressor does not support writing" }
+
+type errDecryptionFailed struct{}
+
+func (errDecryptionFailed) Error() string { return "decryption failed" }
+
 // -----------------------------------------------------------------------------
 // DatasetReader interface
 // -----------------------------------------------------------------------------
@@ -525,13 +1069,49 @@ type ObjectRef struct {
 
 	// Path is the full storage key for the object.
 	Path string
+
+	// IsManifest reports whether the object is a manifest. Set by
+	// ListAllObjects; Manifest is populated only for manifests there.
+	IsManifest bool
+
+	// SizeBytes is the stored size, set by ListAllObjects with
+	// AllObjectListOptions.Stat. Zero otherwise.
+	SizeBytes int64
+}
+
+// ObjectIterator iterates over stored objects.
+//
+// Ordering and pagination are unspecified. Next returns false after
+// exhaustion or Close. Close is idempotent.
+type ObjectIterator interface {
+	// Next advances to the next object and reports whether one is available.
+	Next() bool
+
+	// Ref returns the current object. Valid only after Next returns true.
+	Ref() ObjectRef
+
+	// Err returns the first error encountered during iteration, if any.
+	Err() error
+
+	// Close releases iterator resources.
+	Close() error
 }
 
 // DatasetListOptions controls dataset listing.
 type DatasetListOptions struct {
+	// Prefix restricts results to dataset IDs starting with Prefix. It is
+	// appended to the layout's datasets prefix in the store List call, so
+	// backends that list by key prefix transfer only matching keys.
+	Prefix string
+
 	// Limit is the maximum number of results to return.
 	// Zero means no limit.
 	Limit int
+
+	// PageToken resumes listing after a previous page: pass the last
+	// DatasetID of that page. Results are sorted by ID, and only IDs
+	// greater than PageToken are returned. Empty starts from the beginning.
+	PageToken string
 }
 
 // PartitionListOptions controls partition listing.
@@ -546,6 +1126,151 @@ type ManifestListOptions struct {
 	// Limit is the maximum number of results to return.
 	// Zero means no limit.
 	Limit int
+
+	// MetadataMatch keeps only manifests whose Metadata contains every
+	// key with an equal string value. Nil or empty matches all manifests.
+	//
+	// Listing already loads each manifest for validation, so matching adds
+	// no storage requests. It runs after the partition filter, which prunes
+	// by path (for time-range layouts, by window).
+	MetadataMatch map[string]string
+
+	// Sort returns refs sorted by snapshot ID (then partition) instead of
+	// store listing order. Sorting buffers every matching ref before Limit
+	// is applied, so listing cannot stop early.
+	Sort bool
+}
+
+// SegmentObjectListOptions controls segment object listing.
+type SegmentObjectListOptions struct {
+	// Limit is the maximum number of results to return.
+	// Zero means no limit.
+	Limit int
+}
+
+// AllObjectListOptions controls dataset-wide object listing.
+type AllObjectListOptions struct {
+	// Limit is the maximum number of results to return.
+	// Zero means no limit.
+	Limit int
+
+	// Stat sets ObjectRef.SizeBytes. SizedLister stores report sizes with
+	// the listing; other stores cost one Stat per object as it is iterated.
+	Stat bool
+}
+
+// VerifySegmentOptions controls segment verification.
+type VerifySegmentOptions struct {
+	// CheckSizes stats each present file and reports size mismatches
+	// against FileRef.SizeBytes. Costs one Stat per file.
+	CheckSizes bool
+}
+
+// DriftReport describes differences between a segment's manifest and the
+// objects stored under its data prefix. Paths are sorted.
+type DriftReport struct {
+	// Dataset is the verified dataset.
+	Dataset DatasetID
+
+	// Segment is the verified segment.
+	Segment ManifestRef
+
+	// Missing lists files referenced by the manifest but not in storage.
+	Missing []string
+
+	// Extra lists objects in storage not referenced by the manifest.
+	Extra []string
+
+	// SizeMismatches lists files whose stored size differs from the
+	// manifest. Populated only when VerifySegmentOptions.CheckSizes is set.
+	SizeMismatches []SizeMismatch
+}
+
+// HasDrift reports whether the report contains any differences.
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Missing) > 0 || len(r.Extra) > 0 || len(r.SizeMismatches) > 0
+}
+
+// SizeMismatch records a file whose stored size differs from its manifest entry.
+type SizeMismatch struct {
+	Path     string
+	Expected int64
+	Actual   int64
+}
+
+// ChecksumVerifyOptions controls DatasetReader.VerifyChecksums.
+type ChecksumVerifyOptions struct {
+	// Concurrency is the maximum number of files hashed in parallel.
+	// Zero means a default of 8.
+	Concurrency int
+
+	// Checksum computes digests for a manifest whose ChecksumAlgorithm is not
+	// built in (md5, crc32c). Its Name must match the manifest's algorithm.
+	// Nil resolves built-in algorithms by name.
+	Checksum Checksum
+}
+
+// ChecksumMismatch records a file whose stored bytes hash to a different
+// digest than its manifest entry.
+type ChecksumMismatch struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// DatasetState classifies what storage holds for a dataset.
+type DatasetState string
+
+// Dataset states reported by DatasetReader.DatasetState.
+const (
+	// DatasetAbsent means no objects exist under the dataset's prefix.
+	DatasetAbsent DatasetState = "absent"
+	// DatasetEmpty means objects exist under the dataset's prefix (stray
+	// files, a latest pointer, or uncommitted data) but no committed manifest.
+	DatasetEmpty DatasetState = "empty"
+	// DatasetCommitted means the dataset has at least one committed manifest.
+	DatasetCommitted DatasetState = "committed"
+)
+
+// SnapshotDiff compares the data files of two snapshots by path.
+// Paths are sorted.
+type SnapshotDiff struct {
+	// Dataset is the compared dataset.
+	Dataset DatasetID
+
+	// A and B are the compared snapshots.
+	A, B ManifestRef
+
+	// OnlyInA lists files referenced by A but not B.
+	OnlyInA []string
+
+	// OnlyInB lists files referenced by B but not A.
+	OnlyInB []string
+
+	// InBoth lists files referenced by both snapshots.
+	InBoth []string
+
+	// RowCountDelta is B's RowCount minus A's.
+	RowCountDelta int64
+
+	// BytesDelta is B's total file size minus A's.
+	BytesDelta int64
+}
+
+// ManifestGetOptions controls bulk manifest loading.
+type ManifestGetOptions struct {
+	// Concurrency is the maximum number of manifests fetched in parallel.
+	// Zero means a default of 8.
+	Concurrency int
+
+	// SkipValidation bypasses manifest validation (required fields, format
+	// version, file and block invariants) for trusted manifests on hot
+	// paths. Manifests are still decoded and decode errors are returned.
+	//
+	// Unsafe: an invalid or newer-format manifest is returned as-is and may
+	// violate invariants other APIs rely on. Unvalidated manifests are never
+	// added to a manifest cache; cached (validated) manifests are still served.
+	SkipValidation bool
 }
 
 // DatasetReader provides read operations over stored datasets.
@@ -554,7 +1279,7 @@ type ManifestListOptions struct {
 // Per CONTRACT_READ_API.md: "Lode's read API exposes stored facts, not interpretations.
 // Planning and meaning belong to consumers."
 type DatasetReader interface {
-	// ListDatasets returns all dataset IDs found in storage.
+	// ListDatasets returns dataset IDs found in storage, sorted by ID.
 	// Returns ErrDatasetsNotModeled if the layout doesn't support dataset enumeration.
 	ListDatasets(ctx context.Context, opts DatasetListOptions) ([]DatasetID, error)
 
@@ -566,12 +1291,103 @@ type DatasetReader interface {
 	// Returns ErrNotFound if the dataset does not exist.
 	ListManifests(ctx context.Context, dataset DatasetID, partition string, opts ManifestListOptions) ([]ManifestRef, error)
 
+	// DatasetExists reports whether the dataset has at least one committed
+	// manifest. It stops at the first manifest found, which is validated as
+	// in ListManifests.
+	DatasetExists(ctx context.Context, dataset DatasetID) (bool, error)
+
+	// DatasetState distinguishes a dataset with committed manifests from one
+	// whose prefix holds only other objects (DatasetEmpty) and from one with
+	// no objects at all (DatasetAbsent), where ListManifests returns
+	// ErrNotFound for both of the latter.
+	DatasetState(ctx context.Context, dataset DatasetID) (DatasetState, error)
+
+	// SnapshotExists reports whether the snapshot's canonical manifest
+	// object exists, with a single store Exists call. The manifest is not
+	// read or validated.
+	SnapshotExists(ctx context.Context, dataset DatasetID, snapshot DatasetSnapshotID) (bool, error)
+
 	// GetManifest loads the manifest for a specific snapshot.
 	// Returns ErrNotFound if the dataset or snapshot does not exist.
 	GetManifest(ctx context.Context, dataset DatasetID, ref ManifestRef) (*Manifest, error)
 
-	// OpenObject returns a reader for a data object.
-	// The caller must close the reader when done.
+	// GetManifestByPath loads the manifest stored at a full storage key, such
+	// as a Path yielded by ListAllObjects with IsManifest set. Paths the
+	// reader's layout does not recognize as manifests return a
+	// *NotManifestPathError without touching storage.
+	// Returns ErrNotFound if the manifest does not exist.
+	GetManifestByPath(ctx context.Context, manifestPath string) (*Manifest, error)
+
+	// GetManifests loads the manifests for several snapshots concurrently.
+	// Results are in input order. The first failure is returned and
+	// identifies the offending snapshot.
+	GetManifests(ctx context.Context, dataset DatasetID, refs []ManifestRef, opts ManifestGetOptions) ([]*Manifest, error)
+
+	// ListSegmentPartitions returns the distinct partition paths of one
+	// snapshot's data files, sorted by path, from its manifest alone: the
+	// Partitions summary when present, otherwise the Files paths. A snapshot
+	// written without partitioning returns an empty list.
+	// Returns ErrNotFound if the manifest does not exist.
+	ListSegmentPartitions(ctx context.Context, dataset DatasetID, ref ManifestRef) ([]PartitionRef, error)
+
+	// ListSegmentObjects lists the data objects physically stored under a
+	// segment's data prefix (for ref.Partition in partitioned layouts).
+	// Manifests are excluded. Ordering is unspecified.
+	ListSegmentObjects(ctx context.Context, dataset DatasetID, ref ManifestRef, opts SegmentObjectListOptions) (ObjectIterator, error)
+
+	// ListAllObjects lists every object stored under the dataset's root:
+	// manifests, data files, and the latest pointer. Refs report
+	// IsManifest, and manifests carry their ManifestRef. For mirroring and
+	// backup tools. Ordering is unspecified.
+	ListAllObjects(ctx context.Context, dataset DatasetID, opts AllObjectListOptions) (ObjectIterator, error)
+
+	// DatasetSize returns the total stored bytes under the dataset's root,
+	// including manifests and the latest pointer. Uses one sized listing on
+	// SizedLister stores and a Stat per object otherwise. An absent dataset
+	// has size 0.
+	DatasetSize(ctx context.Context, dataset DatasetID) (int64, error)
+
+	// VerifySegment compares a segment's manifest against the objects stored
+	// under its data prefix and reports missing files, extra objects, and
+	// (optionally) size mismatches. Drift is reported, not returned as an error.
+	// Returns ErrNotFound if the manifest does not exist.
+	VerifySegment(ctx context.Context, dataset DatasetID, ref ManifestRef, opts VerifySegmentOptions) (*DriftReport, error)
+
+	// AuditRowCount decodes every data file of a segment and compares the
+	// record count to the manifest's RowCount, returning a
+	// *RowCountMismatchError when they differ. This is a full scan meant for
+	// CI and verification jobs, not hot paths. Codecs are resolved from the
+	// WithStrictComponents registry, or the built-in registry by default.
+	// Returns ErrNotFound if the manifest does not exist.
+	AuditRowCount(ctx context.Context, dataset DatasetID, ref ManifestRef) error
+
+	// VerifyChecksums hashes every checksummed data file of a segment in
+	// parallel and compares each digest to FileRef.Checksum. All mismatches
+	// are collected into one *ChecksumMismatchError. Store errors and context
+	// cancellation abort outstanding hashing. This is a full scan meant for
+	// integrity sweeps, not hot paths.
+	// Returns ErrNotFound if the manifest does not exist.
+	VerifyChecksums(ctx context.Context, dataset DatasetID, ref ManifestRef, opts ChecksumVerifyOptions) error
+
+	// ResolveTag returns the snapshot a tag written by Dataset.Tag points at.
+	// The snapshot's manifest is not read.
+	// Returns ErrNotFound if the tag does not exist.
+	ResolveTag(ctx context.Context, dataset DatasetID, alias string) (ManifestRef, error)
+
+	// SnapshotsSince returns the snapshots that descend from since through
+	// ParentSnapshotID lineage, oldest first. An empty since returns every
+	// snapshot. Returns ErrNotFound if since is not a committed snapshot.
+	SnapshotsSince(ctx context.Context, dataset DatasetID, since DatasetSnapshotID) ([]ManifestRef, error)
+
+	// DiffSnapshots compares the Files of two snapshots' manifests by path.
+	// Only manifests are read; data files are not fetched.
+	DiffSnapshots(ctx context.Context, dataset DatasetID, a, b ManifestRef) (*SnapshotDiff, error)
+
+	// OpenObject returns a reader for a data object, such as one yielded by
+	// ListSegmentObjects or named by a manifest FileRef. Bytes are returned as
+	// stored (still compressed); no codec is applied.
+	// Returns ErrNotFound if the object is missing and ErrInvalidPath if
+	// obj.Path is empty. The caller must close the reader when done.
 	OpenObject(ctx context.Context, obj ObjectRef) (io.ReadCloser, error)
 
 	// ReaderAt returns an io.ReaderAt for random access reads on a data object.
@@ -2,8 +2,14 @@ package lode
 
 import (
 	"crypto/md5"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"hash"
+	"hash/crc32"
+	"strings"
 )
 
 // -----------------------------------------------------------------------------
@@ -41,3 +47,114 @@ func (hw *hashWriter) Write(p []byte) (n int, err error) {
 func (hw *hashWriter) Sum() string {
 	return hex.EncodeToString(hw.h.Sum(nil))
 }
+
+// -----------------------------------------------------------------------------
+// CRC32C Checksum
+// -----------------------------------------------------------------------------
+
+// castagnoliTable is the CRC32C (Castagnoli polynomial) lookup table.
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// crc32cChecksum implements Checksum using CRC32C.
+type crc32cChecksum struct{}
+
+// NewCRC32CChecksum creates a CRC32C checksum component.
+//
+// The digest is the 4-byte big-endian CRC32C (Castagnoli) value encoded as
+// standard base64, e.g. "yZRlqg==". This is the representation S3 reports
+// for x-amz-checksum-crc32c, so recorded checksums can be compared with the
+// store's own without re-downloading. Note that it is base64, not hex.
+// Use with WithChecksum or WithVolumeChecksum.
+func NewCRC32CChecksum() Checksum {
+	return &crc32cChecksum{}
+}
+
+func (c *crc32cChecksum) Name() string {
+	return "crc32c"
+}
+
+func (c *crc32cChecksum) NewHasher() HashWriter {
+	return &base64HashWriter{h: crc32.New(castagnoliTable)}
+}
+
+// base64HashWriter wraps a hash.Hash to implement HashWriter with a
+// base64-encoded Sum.
+type base64HashWriter struct {
+	h hash.Hash
+}
+
+func (hw *base64HashWriter) Write(p []byte) (n int, err error) {
+	return hw.h.Write(p)
+}
+
+func (hw *base64HashWriter) Sum() string {
+	return base64.StdEncoding.EncodeToString(hw.h.Sum(nil))
+}
+
+// -----------------------------------------------------------------------------
+// Built-in Checksum Lookup
+// -----------------------------------------------------------------------------
+
+// builtinChecksum returns the built-in checksum registered under name.
+func builtinChecksum(name string) (Checksum, error) {
+	switch name {
+	case "md5":
+		return NewMD5Checksum(), nil
+	case "crc32c":
+		return NewCRC32CChecksum(), nil
+	default:
+		return nil, fmt.Errorf("lode: unknown checksum %q", name)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Digest Validation
+// -----------------------------------------------------------------------------
+
+// checkDigestEncoding reports whether digest is well formed for the named
+// built-in algorithm. Digests of other algorithms, such as custom Checksum
+// implementations, are not checked.
+func checkDigestEncoding(algorithm, digest string) error {
+	switch algorithm {
+	case "md5":
+		if len(digest) != 2*md5.Size || strings.ToLower(digest) != digest {
+			return errors.New("is not a lowercase hex md5 digest")
+		}
+		if _, err := hex.DecodeString(digest); err != nil {
+			return errors.New("is not a lowercase hex md5 digest")
+		}
+	case "crc32c":
+		raw, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil || len(raw) != crc32.Size {
+			return errors.New("is not a base64 crc32c digest")
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Manifest Checksum
+// -----------------------------------------------------------------------------
+
+// ManifestChecksum computes a checksum of a manifest over its canonical form:
+// compact JSON with fields in declaration order and map keys sorted.
+//
+// The result is independent of how the manifest was stored, so an indented
+// manifest (WithPrettyManifest) and a compact one produce the same checksum.
+func ManifestChecksum(m *Manifest, c Checksum) (string, error) {
+	if m == nil {
+		return "", errors.New("lode: manifest must not be nil")
+	}
+	if c == nil {
+		return "", errors.New("lode: checksum must not be nil")
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("lode: encode manifest: %w", err)
+	}
+	h := c.NewHasher()
+	if _, err := h.Write(data); err != nil {
+		return "", fmt.Errorf("lode: hash manifest: %w", err)
+	}
+	return h.Sum(), nil
+}
@@ -0,0 +1,86 @@
+package lode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCRC32CChecksum_KnownVectors(t *testing.T) {
+	c := NewCRC32CChecksum()
+	if c.Name() != "crc32c" {
+		t.Errorf("expected name crc32c, got %q", c.Name())
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "AAAAAA=="},
+		// Standard CRC32C check value 0xE3069283, base64 as reported by S3.
+		{"123456789", "4waSgw=="},
+	}
+	for _, tt := range tests {
+		h := c.NewHasher()
+		_, _ = h.Write([]byte(tt.input))
+		if got := h.Sum(); got != tt.want {
+			t.Errorf("crc32c(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCRC32CChecksum_IncrementalWrites(t *testing.T) {
+	whole := NewCRC32CChecksum().NewHasher()
+	_, _ = whole.Write([]byte("123456789"))
+
+	parts := NewCRC32CChecksum().NewHasher()
+	_, _ = parts.Write([]byte("1234"))
+	_, _ = parts.Write([]byte("56789"))
+
+	if whole.Sum() != parts.Sum() {
+		t.Errorf("incremental digest %s differs from whole %s", parts.Sum(), whole.Sum())
+	}
+}
+
+func TestDataset_Write_WithCRC32C_RecordsStoredBytesChecksum(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCompressor(NewGzipCompressor()),
+		WithChecksum(NewCRC32CChecksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), []any{[]byte("payload")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.ChecksumAlgorithm != "crc32c" {
+		t.Errorf("expected checksum algorithm crc32c, got %q", snap.Manifest.ChecksumAlgorithm)
+	}
+
+	f := snap.Manifest.Files[0]
+	stored, err := store.ReadRange(t.Context(), f.Path, 0, f.SizeBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewCRC32CChecksum().NewHasher()
+	_, _ = h.Write(stored)
+	if f.Checksum != h.Sum() {
+		t.Errorf("recorded checksum %s, stored bytes checksum %s", f.Checksum, h.Sum())
+	}
+}
+
+func TestVolume_StageWriteAt_WithCRC32C(t *testing.T) {
+	vol, err := NewVolume("test-vol", NewMemoryFactory(), 100,
+		WithVolumeChecksum(NewCRC32CChecksum()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blk, err := vol.StageWriteAt(t.Context(), 0, bytes.NewReader([]byte("123456789")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blk.Checksum != "4waSgw==" {
+		t.Errorf("expected 4waSgw==, got %q", blk.Checksum)
+	}
+}
@@ -2,37 +2,67 @@ package lode
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"sync"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
 var jsonCodec = jsoniter.ConfigCompatibleWithStandardLibrary
 
-const maxScanTokenSize = 10 * 1024 * 1024 // 10MB
+// defaultJSONLMaxLineBytes is the default limit on a single JSONL line.
+const defaultJSONLMaxLineBytes = 256 * 1024 * 1024 // 256MB
 
 // -----------------------------------------------------------------------------
 // JSONL Codec
 // -----------------------------------------------------------------------------
 
+// JSONLOption configures JSONL codec behavior.
+type JSONLOption func(*jsonlCodec)
+
+// WithJSONLMaxLineBytes sets the largest line, excluding its terminator, the
+// codec decodes. Lines over the limit fail with an error naming the line
+// number and size. Zero or negative removes the limit.
+// Default: 256MB.
+func WithJSONLMaxLineBytes(n int) JSONLOption {
+	return func(c *jsonlCodec) {
+		c.maxLineBytes = n
+	}
+}
+
 // jsonlCodec implements Codec using JSON Lines format.
-type jsonlCodec struct{}
+type jsonlCodec struct {
+	maxLineBytes int
+}
 
 // NewJSONLCodec creates a JSONL (JSON Lines) codec.
 //
 // Each record is serialized as a single line of JSON.
-// Records can be any JSON-serializable value.
+// Records can be any JSON-serializable value. Decoding reads lines of any
+// length up to the WithJSONLMaxLineBytes limit, without a fixed token buffer.
 //
 // JSONL codec implements StreamingRecordCodec and can be used with
-// StreamWriteRecords for streaming record writes.
-func NewJSONLCodec() Codec {
-	return &jsonlCodec{}
+// StreamWriteRecords for streaming record writes. It also implements
+// StreamingDecodeCodec for ReadFileRecords.
+func NewJSONLCodec(opts ...JSONLOption) Codec {
+	c := &jsonlCodec{maxLineBytes: defaultJSONLMaxLineBytes}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (j *jsonlCodec) Name() string {
 	return "jsonl"
 }
 
+func (j *jsonlCodec) Extension() string {
+	return ".jsonl"
+}
+
 func (j *jsonlCodec) Encode(w io.Writer, records []any) error {
 	enc := jsonCodec.NewEncoder(w)
 	for _, record := range records {
@@ -44,24 +74,20 @@ func (j *jsonlCodec) Encode(w io.Writer, records []any) error {
 }
 
 func (j *jsonlCodec) Decode(r io.Reader) ([]any, error) {
-	var records []any
-	scanner := bufio.NewScanner(r)
-	scanner.Buffer(make([]byte, 0, 64*1024), maxScanTokenSize)
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-		var record any
-		if err := jsonCodec.Unmarshal(line, &record); err != nil {
-			return nil, err
-		}
-		records = append(records, record)
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-	return records, nil
+	return decodeAll(j.newStreamDecoder(r))
+}
+
+// NewStreamDecoder implements StreamingDecodeCodec for JSONL.
+func (j *jsonlCodec) NewStreamDecoder(r io.Reader) (RecordIterator, error) {
+	return j.newStreamDecoder(r), nil
+}
+
+func (j *jsonlCodec) newStreamDecoder(r io.Reader) *jsonlStreamDecoder {
+	return &jsonlStreamDecoder{lines: j.newLineReader(r)}
+}
+
+func (j *jsonlCodec) newLineReader(r io.Reader) *jsonlLineReader {
+	return &jsonlLineReader{r: bufio.NewReaderSize(r, 64*1024), max: j.maxLineBytes}
 }
 
 // NewStreamEncoder implements StreamingRecordCodec for JSONL.
@@ -82,3 +108,210 @@ func (e *jsonlStreamEncoder) Close() error {
 	// JSONL has no footer/finalization needed
 	return nil
 }
+
+// jsonlLineReader reads newline-terminated lines of unbounded length,
+// enforcing an optional maximum. Like bufio.ScanLines, it strips "\n" and
+// a preceding "\r" and returns a final line without a terminator.
+type jsonlLineReader struct {
+	r       *bufio.Reader
+	max     int  // <= 0 means unlimited
+	line    int  // 1-based number of the last line returned
+	tooLong bool // the last error was an over-limit line, already consumed
+	buf     []byte
+}
+
+// next returns the next line, valid until the following call.
+// It returns io.EOF when no lines remain.
+func (lr *jsonlLineReader) next() ([]byte, error) {
+	lr.buf, lr.tooLong = lr.buf[:0], false
+	size, over := 0, false
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		size += len(chunk)
+		if !over {
+			lr.buf = append(lr.buf, chunk...)
+			if lr.max > 0 && len(trimLineEnd(lr.buf)) > lr.max {
+				over, lr.buf = true, lr.buf[:0]
+			}
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, io.EOF
+		}
+		lr.line++
+		if over {
+			lr.tooLong = true
+			n := size - (len(chunk) - len(trimLineEnd(chunk)))
+			return nil, fmt.Errorf("%w: jsonl line %d is %d bytes, exceeds limit of %d bytes (see WithJSONLMaxLineBytes)",
+				ErrInvalidFormat, lr.line, n, lr.max)
+		}
+		return trimLineEnd(lr.buf), nil
+	}
+}
+
+// trimLineEnd strips a trailing "\n" and then a trailing "\r".
+func trimLineEnd(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte{'\n'})
+	return bytes.TrimSuffix(b, []byte{'\r'})
+}
+
+// jsonlStreamDecoder implements RecordIterator over JSONL lines.
+// Empty lines are skipped.
+type jsonlStreamDecoder struct {
+	lines  *jsonlLineReader
+	record any
+	err    error
+
+	// onBad, when set, is called with the line number and error of each
+	// undecodable or over-limit line, which is then dropped instead of
+	// failing iteration.
+	onBad   func(line int, err error)
+	skipped int
+}
+
+func (d *jsonlStreamDecoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	for {
+		line, err := d.lines.next()
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+		if err != nil {
+			if d.lines.tooLong && d.onBad != nil {
+				d.skip(err)
+				continue
+			}
+			d.err = err
+			return false
+		}
+		if len(line) == 0 {
+			continue
+		}
+		var record any
+		if err := jsonCodec.Unmarshal(line, &record); err != nil {
+			if d.onBad != nil {
+				d.skip(err)
+				continue
+			}
+			d.err = err
+			return false
+		}
+		d.record = record
+		return true
+	}
+}
+
+func (d *jsonlStreamDecoder) skip(err error) {
+	d.skipped++
+	d.onBad(d.lines.line, err)
+}
+
+// SkippedCount returns the number of lines dropped as undecodable.
+func (d *jsonlStreamDecoder) SkippedCount() int {
+	return d.skipped
+}
+
+func (d *jsonlStreamDecoder) Record() any {
+	return d.record
+}
+
+func (d *jsonlStreamDecoder) Err() error {
+	return d.err
+}
+
+// decodeAll drains a stream decoder into a slice.
+func decodeAll(it RecordIterator) ([]any, error) {
+	var records []any
+	for it.Next() {
+		records = append(records, it.Record())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// -----------------------------------------------------------------------------
+// Codec Registry
+// -----------------------------------------------------------------------------
+
+// CodecFactory constructs a Codec instance.
+type CodecFactory func() (Codec, error)
+
+// CodecRegistry maps codec names, as recorded in manifests, to factories.
+//
+// A registry lets the read path select the codec from the manifest's
+// recorded name instead of requiring the reader to be configured with
+// the exact codec used at write time.
+//
+// NewCodecRegistry seeds the built-in "jsonl", "json-array", and "gob"
+// codecs and the "csv", "tsv", and "psv" delimited codecs (with header). Codecs that require
+// construction parameters (such as Parquet, which needs a schema) must be
+// registered explicitly by the caller.
+type CodecRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]CodecFactory
+}
+
+// NewCodecRegistry creates a registry seeded with the built-in codecs.
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		factories: map[string]CodecFactory{
+			"jsonl":      func() (Codec, error) { return NewJSONLCodec(), nil },
+			"json-array": func() (Codec, error) { return NewJSONArrayCodec(), nil },
+			"gob":        func() (Codec, error) { return NewGobCodec() },
+			"csv":        func() (Codec, error) { return NewDelimitedCodec(',') },
+			"tsv":        func() (Codec, error) { return NewDelimitedCodec('\t') },
+			"psv":        func() (Codec, error) { return NewDelimitedCodec('|') },
+		},
+	}
+}
+
+// Register associates a codec name with a factory.
+// Registering an existing name replaces the previous factory.
+func (r *CodecRegistry) Register(name string, factory CodecFactory) error {
+	if name == "" {
+		return errors.New("lode: codec name must not be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("lode: codec factory for %q must not be nil", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = factory
+	return nil
+}
+
+// has reports whether a codec is registered under name.
+func (r *CodecRegistry) has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.factories[name]
+	return ok
+}
+
+// Get constructs the codec registered under name.
+// Returns an error wrapping ErrUnknownCodec if no codec is registered.
+func (r *CodecRegistry) Get(name string) (Codec, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("lode: %w: %q", ErrUnknownCodec, name)
+	}
+	codec, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("lode: codec %q: %w", name, err)
+	}
+	if codec == nil {
+		return nil, fmt.Errorf("lode: codec factory for %q returned nil", name)
+	}
+	return codec, nil
+}
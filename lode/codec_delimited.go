@@ -0,0 +1,323 @@
+package lode
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// -----------------------------------------------------------------------------
+// Delimited Codec
+// -----------------------------------------------------------------------------
+
+const delimitedNoHeaderSuffix = "-noheader"
+
+// DelimitedOption configures delimited codec behavior.
+type DelimitedOption func(*delimitedCodec)
+
+// WithDelimitedHeader controls whether the first row holds column names.
+// Default: true. Without a header, WithDelimitedColumns is required so
+// records can be decoded.
+func WithDelimitedHeader(enabled bool) DelimitedOption {
+	return func(c *delimitedCodec) {
+		c.header = enabled
+	}
+}
+
+// WithDelimitedColumns fixes the column order.
+// Default: the sorted union of record keys for Encode, or the keys of the
+// first record for streaming writes.
+func WithDelimitedColumns(columns ...string) DelimitedOption {
+	return func(c *delimitedCodec) {
+		c.columns = columns
+	}
+}
+
+// delimitedCodec implements Codec and StreamingRecordCodec for delimited
+// text (CSV, TSV, and other single-rune separators).
+type delimitedCodec struct {
+	sep     rune
+	header  bool
+	columns []string
+}
+
+// NewDelimitedCodec creates a codec for delimited text with the given field
+// separator, e.g. ',' for CSV, '\t' for TSV, or '|'.
+//
+// Records must be map[string]any. Values are written as text: strings as-is,
+// nil as an empty field, time.Time as RFC3339Nano, and other values with
+// fmt. Fields containing the separator, quotes, or newlines are quoted.
+// Decoded records are map[string]any with string values.
+//
+// The codec name encodes the variant so reads select the same separator:
+// "csv", "tsv", and "psv" for ',', '\t', and '|'; "delimited-U+XXXX" for
+// other separators; "-noheader" is appended when the header is disabled.
+// NewCodecRegistry seeds "csv", "tsv", and "psv" with a header.
+//
+// Delimited codec implements StreamingRecordCodec and StreamingDecodeCodec.
+func NewDelimitedCodec(sep rune, opts ...DelimitedOption) (Codec, error) {
+	if sep == 0 || sep == '"' || sep == '\r' || sep == '\n' || !utf8.ValidRune(sep) || sep == utf8.RuneError {
+		return nil, fmt.Errorf("lode: invalid delimiter %q", sep)
+	}
+	c := &delimitedCodec{sep: sep, header: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if !c.header && len(c.columns) == 0 {
+		return nil, errors.New("lode: delimited codec without header requires WithDelimitedColumns")
+	}
+	seen := make(map[string]bool, len(c.columns))
+	for _, col := range c.columns {
+		if col == "" || seen[col] {
+			return nil, fmt.Errorf("lode: invalid delimited column %q", col)
+		}
+		seen[col] = true
+	}
+	return c, nil
+}
+
+func (c *delimitedCodec) Name() string {
+	var name string
+	switch c.sep {
+	case ',':
+		name = "csv"
+	case '\t':
+		name = "tsv"
+	case '|':
+		name = "psv"
+	default:
+		name = fmt.Sprintf("delimited-%U", c.sep)
+	}
+	if !c.header {
+		name += delimitedNoHeaderSuffix
+	}
+	return name
+}
+
+// Extension returns ".csv", ".tsv", or ".psv" for the named separators and
+// ".txt" otherwise. Header configuration does not affect the extension.
+func (c *delimitedCodec) Extension() string {
+	switch c.sep {
+	case ',':
+		return ".csv"
+	case '\t':
+		return ".tsv"
+	case '|':
+		return ".psv"
+	default:
+		return ".txt"
+	}
+}
+
+func (c *delimitedCodec) Encode(w io.Writer, records []any) error {
+	columns := c.columns
+	if len(columns) == 0 {
+		var err error
+		if columns, err = unionColumns(records); err != nil {
+			return err
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	enc, err := c.newEncoder(w, columns)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := enc.WriteRecord(record); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+func (c *delimitedCodec) Decode(r io.Reader) ([]any, error) {
+	return decodeAll(c.newStreamDecoder(r))
+}
+
+// NewStreamDecoder implements StreamingDecodeCodec. With a header, the first
+// row is read on the first call to Next.
+func (c *delimitedCodec) NewStreamDecoder(r io.Reader) (RecordIterator, error) {
+	return c.newStreamDecoder(r), nil
+}
+
+func (c *delimitedCodec) newStreamDecoder(r io.Reader) *delimitedStreamDecoder {
+	cr := csv.NewReader(r)
+	cr.Comma = c.sep
+	cr.ReuseRecord = true
+	return &delimitedStreamDecoder{cr: cr, header: c.header, columns: c.columns}
+}
+
+// NewStreamEncoder implements StreamingRecordCodec. Without configured
+// columns, the first record's sorted keys fix the columns for the stream.
+func (c *delimitedCodec) NewStreamEncoder(w io.Writer) (RecordStreamEncoder, error) {
+	if len(c.columns) > 0 {
+		return c.newEncoder(w, c.columns)
+	}
+	return &delimitedStreamEncoder{codec: c, w: w}, nil
+}
+
+// newEncoder returns an encoder with fixed columns, writing the header row
+// when enabled.
+func (c *delimitedCodec) newEncoder(w io.Writer, columns []string) (*delimitedStreamEncoder, error) {
+	cw := csv.NewWriter(w)
+	cw.Comma = c.sep
+	if c.header {
+		if err := cw.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	return &delimitedStreamEncoder{codec: c, w: w, cw: cw, columns: columns}, nil
+}
+
+// unionColumns returns the sorted union of record keys.
+func unionColumns(records []any) ([]string, error) {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, record := range records {
+		m, ok := record.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("lode: delimited codec: record must be map[string]any, got %T", record)
+		}
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	slices.Sort(columns)
+	return columns, nil
+}
+
+// delimitedStreamEncoder implements RecordStreamEncoder for delimited text.
+// cw is nil until the columns are known.
+type delimitedStreamEncoder struct {
+	codec   *delimitedCodec
+	w       io.Writer
+	cw      *csv.Writer
+	columns []string
+	row     []string
+}
+
+func (e *delimitedStreamEncoder) WriteRecord(record any) error {
+	m, ok := record.(map[string]any)
+	if !ok {
+		return fmt.Errorf("lode: delimited codec: record must be map[string]any, got %T", record)
+	}
+	if e.cw == nil {
+		columns, _ := unionColumns([]any{m})
+		enc, err := e.codec.newEncoder(e.w, columns)
+		if err != nil {
+			return err
+		}
+		*e = *enc
+	}
+
+	for key := range m {
+		if !slices.Contains(e.columns, key) {
+			return fmt.Errorf("lode: delimited codec: field %q is not a column", key)
+		}
+	}
+	e.row = e.row[:0]
+	for _, col := range e.columns {
+		e.row = append(e.row, formatDelimitedValue(m[col]))
+	}
+	return e.cw.Write(e.row)
+}
+
+func (e *delimitedStreamEncoder) Close() error {
+	if e.cw == nil {
+		return nil
+	}
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+// delimitedStreamDecoder implements RecordIterator over delimited rows.
+// Decoded records are map[string]any with string values.
+type delimitedStreamDecoder struct {
+	cr      *csv.Reader
+	header  bool // header row not yet consumed
+	columns []string
+	record  map[string]any
+	err     error
+	done    bool
+}
+
+func (d *delimitedStreamDecoder) Next() bool {
+	if d.done {
+		return false
+	}
+	if d.header {
+		d.header = false
+		row, ok := d.read()
+		if !ok {
+			return false
+		}
+		d.columns = slices.Clone(row)
+	}
+
+	row, ok := d.read()
+	if !ok {
+		return false
+	}
+	if len(row) != len(d.columns) {
+		d.fail(fmt.Errorf("%w: row has %d fields, expected %d", ErrInvalidFormat, len(row), len(d.columns)))
+		return false
+	}
+	d.record = make(map[string]any, len(d.columns))
+	for i, col := range d.columns {
+		d.record[col] = row[i]
+	}
+	return true
+}
+
+// read returns the next row, ending iteration at EOF or on error.
+func (d *delimitedStreamDecoder) read() ([]string, bool) {
+	row, err := d.cr.Read()
+	if errors.Is(err, io.EOF) {
+		d.done = true
+		return nil, false
+	}
+	if err != nil {
+		d.fail(fmt.Errorf("%w: %w", ErrInvalidFormat, err))
+		return nil, false
+	}
+	return row, true
+}
+
+func (d *delimitedStreamDecoder) fail(err error) {
+	d.err = err
+	d.done = true
+}
+
+func (d *delimitedStreamDecoder) Record() any {
+	return d.record
+}
+
+func (d *delimitedStreamDecoder) Err() error {
+	return d.err
+}
+
+// formatDelimitedValue renders a record value as a field.
+func formatDelimitedValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case []byte:
+		return string(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,218 @@
+package lode
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDelimitedCodec_Names(t *testing.T) {
+	tests := []struct {
+		sep  rune
+		opts []DelimitedOption
+		want string
+	}{
+		{',', nil, "csv"},
+		{'\t', nil, "tsv"},
+		{'|', nil, "psv"},
+		{';', nil, "delimited-U+003B"},
+		{'\t', []DelimitedOption{WithDelimitedHeader(false), WithDelimitedColumns("a")}, "tsv-noheader"},
+	}
+	for _, tt := range tests {
+		c, err := NewDelimitedCodec(tt.sep, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Name(); got != tt.want {
+			t.Errorf("Name() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestDelimitedCodec_RoundTrip_QuotingAndNewlines(t *testing.T) {
+	records := R(
+		D{"id": "1", "note": "plain", "tags": "a\tb"},
+		D{"id": "2", "note": "line one\nline two", "tags": `say "hi"`},
+		D{"id": "3", "note": "a|b,c", "tags": ""},
+	)
+
+	for _, sep := range []rune{',', '\t', '|'} {
+		c, err := NewDelimitedCodec(sep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := c.Encode(&buf, records); err != nil {
+			t.Fatalf("%q: Encode failed: %v", sep, err)
+		}
+		got, err := c.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%q: Decode failed: %v", sep, err)
+		}
+		if !reflect.DeepEqual(got, records) {
+			t.Errorf("%q: round trip mismatch:\n got %v\nwant %v", sep, got, records)
+		}
+	}
+}
+
+func TestDelimitedCodec_Encode_HeaderAndValues(t *testing.T) {
+	c, err := NewDelimitedCodec('\t')
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, R(D{"b": 1.5, "a": at}, D{"c": nil, "a": true})); err != nil {
+		t.Fatal(err)
+	}
+	want := "a\tb\tc\n2024-01-02T03:04:05Z\t1.5\t\ntrue\t\t\n"
+	if buf.String() != want {
+		t.Errorf("Encode = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestDelimitedCodec_NoHeader_UsesColumns(t *testing.T) {
+	c, err := NewDelimitedCodec('|', WithDelimitedHeader(false), WithDelimitedColumns("id", "name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, R(D{"name": "x", "id": "1"})); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1|x\n" {
+		t.Errorf("Encode = %q, want %q", buf.String(), "1|x\n")
+	}
+	got, err := c.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, R(D{"id": "1", "name": "x"})) {
+		t.Errorf("Decode = %v", got)
+	}
+
+	if err := c.Encode(&buf, R(D{"id": "1", "extra": "y"})); err == nil {
+		t.Error("expected error for field outside configured columns")
+	}
+}
+
+func TestDelimitedCodec_Decode_RaggedRow_ReturnsErrInvalidFormat(t *testing.T) {
+	c, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Decode(strings.NewReader("a,b\n1,2\n3\n"))
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got: %v", err)
+	}
+}
+
+func TestNewDelimitedCodec_InvalidConfiguration(t *testing.T) {
+	for _, sep := range []rune{0, '"', '\n', '\r', -1} {
+		if _, err := NewDelimitedCodec(sep); err == nil {
+			t.Errorf("expected error for separator %q", sep)
+		}
+	}
+	if _, err := NewDelimitedCodec(',', WithDelimitedHeader(false)); err == nil {
+		t.Error("expected error for headerless codec without columns")
+	}
+	if _, err := NewDelimitedCodec(',', WithDelimitedColumns("a", "a")); err == nil {
+		t.Error("expected error for duplicate column")
+	}
+}
+
+func TestDataset_DelimitedCodec_ReadSelectsSeparatorFromManifest(t *testing.T) {
+	store := NewMemory()
+	tsv, err := NewDelimitedCodec('\t')
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(tsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := R(D{"id": "1", "msg": "a,b|c"}, D{"id": "2", "msg": "tab\there"})
+	snap, err := writer.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.Codec != "tsv" {
+		t.Errorf("manifest codec = %q, want tsv", snap.Manifest.Codec)
+	}
+
+	csv, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(csv),
+		WithCodecRegistry(NewCodecRegistry()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reader.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("Read = %v, want %v", got, records)
+	}
+}
+
+func TestDataset_DelimitedCodec_StreamWriteRecords(t *testing.T) {
+	c, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := R(D{"id": "1", "v": "x"}, D{"id": "2", "v": "y\nz"})
+	snap, err := ds.StreamWriteRecords(t.Context(), &sliceIterator{records: records}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("Read = %v, want %v", got, records)
+	}
+
+	iter := &sliceIterator{records: R(D{"id": "1"}, D{"id": "2", "late": "x"})}
+	if _, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{}); err == nil {
+		t.Error("expected error for key not in first record's columns")
+	}
+}
+
+func TestDelimitedCodec_StreamDecoder_StopsOnRaggedRow(t *testing.T) {
+	c, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err := c.(StreamingDecodeCodec).NewStreamDecoder(strings.NewReader("a,b\n1,2\n3\n4,5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []any
+	for it.Next() {
+		got = append(got, it.Record())
+	}
+	if !reflect.DeepEqual(got, R(D{"a": "1", "b": "2"})) {
+		t.Errorf("records before error = %v", got)
+	}
+	if !errors.Is(it.Err(), ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got: %v", it.Err())
+	}
+	if it.Next() {
+		t.Error("Next must return false after an error")
+	}
+}
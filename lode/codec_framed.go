@@ -0,0 +1,204 @@
+package lode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// -----------------------------------------------------------------------------
+// Framed Codec
+// -----------------------------------------------------------------------------
+//
+// File layout (all integers big-endian):
+//
+//	frame 0 .. frame N-1   [uint32 length][inner-encoded record]
+//	index                  N x uint64 frame offsets
+//	footer                 [uint64 N][4-byte magic "LDFR"]
+//
+// The footer and index are fixed-width so a reader can locate any frame with
+// two small reads from the end of the file.
+
+const (
+	framedMagic       = "LDFR"
+	framedFooterSize  = 8 + len(framedMagic)
+	framedLengthSize  = 4
+	framedOffsetSize  = 8
+	framedCodecPrefix = "framed-"
+)
+
+// framedCodec implements RandomAccessCodec by wrapping a record codec.
+type framedCodec struct {
+	inner Codec
+}
+
+// NewFramedCodec wraps a record codec so each record is stored as a
+// length-prefixed frame followed by a trailing index of frame offsets.
+//
+// The resulting codec implements RandomAccessCodec: DecodeRange reads a
+// record by index via io.ReaderAt without scanning preceding records.
+// Each record is encoded independently with the inner codec.
+//
+// The codec name is "framed-" followed by the inner codec name.
+func NewFramedCodec(inner Codec) (Codec, error) {
+	if inner == nil {
+		return nil, errors.New("lode: framed codec requires an inner codec")
+	}
+	return &framedCodec{inner: inner}, nil
+}
+
+func (c *framedCodec) Name() string {
+	return framedCodecPrefix + c.inner.Name()
+}
+
+// Extension returns ".framed"; framed bytes are not readable as the inner format.
+func (c *framedCodec) Extension() string {
+	return ".framed"
+}
+
+func (c *framedCodec) Encode(w io.Writer, records []any) error {
+	var frame bytes.Buffer
+	var header [framedLengthSize]byte
+	offsets := make([]uint64, 0, len(records))
+	var offset uint64
+
+	for i, record := range records {
+		frame.Reset()
+		if err := c.inner.Encode(&frame, []any{record}); err != nil {
+			return fmt.Errorf("framed: record %d: %w", i, err)
+		}
+		if uint64(frame.Len()) > math.MaxUint32 {
+			return fmt.Errorf("framed: record %d exceeds maximum frame size", i)
+		}
+		binary.BigEndian.PutUint32(header[:], uint32(frame.Len()))
+		if _, err := w.Write(header[:]); err != nil {
+			return err
+		}
+		if _, err := w.Write(frame.Bytes()); err != nil {
+			return err
+		}
+		offsets = append(offsets, offset)
+		offset += uint64(framedLengthSize + frame.Len())
+	}
+
+	trailer := make([]byte, len(offsets)*framedOffsetSize+framedFooterSize)
+	for i, off := range offsets {
+		binary.BigEndian.PutUint64(trailer[i*framedOffsetSize:], off)
+	}
+	footer := trailer[len(offsets)*framedOffsetSize:]
+	binary.BigEndian.PutUint64(footer, uint64(len(offsets)))
+	copy(footer[8:], framedMagic)
+	_, err := w.Write(trailer)
+	return err
+}
+
+func (c *framedCodec) Decode(r io.Reader) ([]any, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	br := bytes.NewReader(data)
+	return c.DecodeRange(br, int64(len(data)), 0, -1)
+}
+
+// DecodeRange decodes count records starting at index start.
+// A negative count decodes through the last record.
+func (c *framedCodec) DecodeRange(r io.ReaderAt, size int64, start, count int) ([]any, error) {
+	total, indexStart, err := readFramedFooter(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		count = total - start
+	}
+	if start < 0 || count < 0 || start > total || count > total-start {
+		return nil, fmt.Errorf("framed: range [%d, %d) out of bounds for %d records", start, start+count, total)
+	}
+	if count == 0 {
+		return []any{}, nil
+	}
+
+	// Offsets for frames [start, start+count), plus the next frame's offset
+	// (or the index start) to bound the last frame.
+	entries := count
+	if start+count < total {
+		entries++
+	}
+	offsets, err := readFramedOffsets(r, indexStart, start, entries)
+	if err != nil {
+		return nil, err
+	}
+	end := indexStart
+	if entries > count {
+		end = int64(offsets[count])
+	}
+	begin := int64(offsets[0])
+	if begin > end {
+		return nil, errors.New("framed: invalid format: frame offsets out of order")
+	}
+
+	frames := make([]byte, end-begin)
+	if _, err := r.ReadAt(frames, begin); err != nil {
+		return nil, fmt.Errorf("framed: read frames: %w", err)
+	}
+
+	records := make([]any, 0, count)
+	for i := range count {
+		pos := int64(offsets[i]) - begin
+		if pos < 0 || pos+framedLengthSize > int64(len(frames)) {
+			return nil, fmt.Errorf("framed: invalid format: frame %d out of bounds", start+i)
+		}
+		n := int64(binary.BigEndian.Uint32(frames[pos:]))
+		body := pos + framedLengthSize
+		if body+n > int64(len(frames)) {
+			return nil, fmt.Errorf("framed: invalid format: frame %d truncated", start+i)
+		}
+		decoded, err := c.inner.Decode(bytes.NewReader(frames[body : body+n]))
+		if err != nil {
+			return nil, fmt.Errorf("framed: record %d: %w", start+i, err)
+		}
+		records = append(records, decoded...)
+	}
+	return records, nil
+}
+
+// readFramedFooter validates the footer and returns the record count and
+// the offset at which the index begins.
+func readFramedFooter(r io.ReaderAt, size int64) (total int, indexStart int64, err error) {
+	if size < int64(framedFooterSize) {
+		return 0, 0, errors.New("framed: invalid format: file too small")
+	}
+	var footer [framedFooterSize]byte
+	if _, err := r.ReadAt(footer[:], size-int64(framedFooterSize)); err != nil {
+		return 0, 0, fmt.Errorf("framed: read footer: %w", err)
+	}
+	if string(footer[8:]) != framedMagic {
+		return 0, 0, errors.New("framed: invalid format: bad magic")
+	}
+	n := binary.BigEndian.Uint64(footer[:8])
+	maxRecords := uint64(size-int64(framedFooterSize)) / framedOffsetSize
+	if n > maxRecords {
+		return 0, 0, fmt.Errorf("framed: invalid format: record count %d exceeds file size", n)
+	}
+	indexStart = size - int64(framedFooterSize) - int64(n)*framedOffsetSize
+	return int(n), indexStart, nil
+}
+
+// readFramedOffsets reads count index entries starting at entry start.
+func readFramedOffsets(r io.ReaderAt, indexStart int64, start, count int) ([]uint64, error) {
+	buf := make([]byte, count*framedOffsetSize)
+	if _, err := r.ReadAt(buf, indexStart+int64(start)*framedOffsetSize); err != nil {
+		return nil, fmt.Errorf("framed: read index: %w", err)
+	}
+	offsets := make([]uint64, count)
+	for i := range offsets {
+		offsets[i] = binary.BigEndian.Uint64(buf[i*framedOffsetSize:])
+		if offsets[i] > uint64(indexStart) {
+			return nil, errors.New("framed: invalid format: frame offset beyond index")
+		}
+	}
+	return offsets, nil
+}
@@ -0,0 +1,177 @@
+package lode
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingReaderAt records the offset of every ReadAt call.
+type recordingReaderAt struct {
+	r io.ReaderAt
+
+	mu      sync.Mutex
+	offsets []int64
+}
+
+func (r *recordingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	r.offsets = append(r.offsets, off)
+	r.mu.Unlock()
+	return r.r.ReadAt(p, off)
+}
+
+func newFramedJSONL(t *testing.T) Codec {
+	t.Helper()
+	codec, err := NewFramedCodec(NewJSONLCodec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codec
+}
+
+func framedTestRecords(n int) []any {
+	records := make([]any, n)
+	for i := range records {
+		records[i] = D{"i": i, "pad": strings.Repeat("x", 256)}
+	}
+	return records
+}
+
+func TestFramedCodec_RoundTrip(t *testing.T) {
+	codec := newFramedJSONL(t)
+	if codec.Name() != "framed-jsonl" {
+		t.Errorf("expected name framed-jsonl, got %q", codec.Name())
+	}
+
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, framedTestRecords(5)); err != nil {
+		t.Fatal(err)
+	}
+	records, err := codec.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(records))
+	}
+	if got := records[3].(map[string]any)["i"]; got != float64(3) {
+		t.Errorf("expected record 3 to have i=3, got %v", got)
+	}
+}
+
+func TestFramedCodec_Empty(t *testing.T) {
+	codec := newFramedJSONL(t)
+
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	records, err := codec.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
+
+func TestFramedCodec_DecodeRange_SeeksWithoutScanning(t *testing.T) {
+	codec := newFramedJSONL(t)
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, framedTestRecords(10)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	size := int64(len(data))
+
+	// Locate frame 7 independently to bound the reads below.
+	_, indexStart, err := readFramedFooter(bytes.NewReader(data), size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets, err := readFramedOffsets(bytes.NewReader(data), indexStart, 7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &recordingReaderAt{r: bytes.NewReader(data)}
+	records, err := codec.(RandomAccessCodec).DecodeRange(rec, size, 7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	if got := records[0].(map[string]any)["i"]; got != float64(7) {
+		t.Errorf("expected i=7, got %v", got)
+	}
+	for _, off := range rec.offsets {
+		if off < int64(offsets[0]) {
+			t.Errorf("read at offset %d precedes frame 7 at %d", off, offsets[0])
+		}
+	}
+}
+
+func TestFramedCodec_DecodeRange_OutOfBounds(t *testing.T) {
+	codec := newFramedJSONL(t)
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, framedTestRecords(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := codec.(RandomAccessCodec).DecodeRange(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 2, 2)
+	if err == nil {
+		t.Fatal("expected error for out-of-bounds range")
+	}
+}
+
+func TestFramedCodec_Decode_InvalidFormat(t *testing.T) {
+	codec := newFramedJSONL(t)
+	_, err := codec.Decode(strings.NewReader("not a framed file"))
+	if err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("expected invalid format error, got: %v", err)
+	}
+}
+
+func TestNewFramedCodec_NilInner(t *testing.T) {
+	if _, err := NewFramedCodec(nil); err == nil {
+		t.Error("expected error for nil inner codec")
+	}
+}
+
+func TestDataset_FramedCodec_RandomAccessViaStore(t *testing.T) {
+	store := NewMemory()
+	codec := newFramedJSONL(t)
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(codec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), framedTestRecords(4), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(all))
+	}
+
+	file := snap.Manifest.Files[0]
+	ra, err := store.ReaderAt(t.Context(), file.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := codec.(RandomAccessCodec).DecodeRange(ra, file.SizeBytes, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].(map[string]any)["i"] != float64(2) {
+		t.Errorf("unexpected records: %v", records)
+	}
+}
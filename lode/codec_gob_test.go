@@ -0,0 +1,192 @@
+package lode
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type gobTestEvent struct {
+	ID    int64
+	Name  string
+	Score float32
+}
+
+func TestGobCodec_RoundTrip_PreservesTypes(t *testing.T) {
+	c, err := NewGobCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name() != "gob" || c.Extension() != ".gob" {
+		t.Errorf("Name/Extension = %q/%q", c.Name(), c.Extension())
+	}
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	records := []any{
+		D{"id": int64(1), "count": 3, "ratio": 0.5, "at": at, "tags": []any{"a", uint8(7)}},
+		"plain string",
+		int32(-4),
+	}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("round trip mismatch:\n got %#v\nwant %#v", got, records)
+	}
+}
+
+func TestGobCodec_WithGobTypes_Structs(t *testing.T) {
+	c, err := NewGobCodec(WithGobTypes(gobTestEvent{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{gobTestEvent{ID: 1, Name: "a"}, gobTestEvent{ID: 2, Score: 1.5}}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("round trip mismatch:\n got %#v\nwant %#v", got, records)
+	}
+}
+
+func TestGobCodec_UnregisteredType_FailsEncode(t *testing.T) {
+	type unregistered struct{ X int }
+	c, err := NewGobCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Encode(&bytes.Buffer{}, []any{unregistered{X: 1}}); err == nil {
+		t.Error("expected error for unregistered type")
+	}
+}
+
+func TestGobCodec_InvalidInput(t *testing.T) {
+	c, err := NewGobCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Decode(strings.NewReader("not a gob stream")); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got: %v", err)
+	}
+	if _, err := NewGobCodec(WithGobTypes(nil)); err == nil {
+		t.Error("expected error for nil type")
+	}
+}
+
+func TestDataset_GobCodec_StreamWriteAndRegistryRead(t *testing.T) {
+	store := NewMemory()
+	c, err := NewGobCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := R(D{"id": int64(1)}, D{"id": int64(2)})
+	snap, err := writer.StreamWriteRecords(t.Context(), &sliceIterator{records: records}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(snap.Manifest.Files[0].Path, "/data.gob") {
+		t.Errorf("unexpected file path %s", snap.Manifest.Files[0].Path)
+	}
+
+	reader, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCodecRegistry(NewCodecRegistry()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reader.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("Read = %#v, want %#v", got, records)
+	}
+}
+
+// benchRecords returns the same event rows as maps and as structs.
+func benchRecords(n int) (maps, structs []any) {
+	maps = make([]any, n)
+	structs = make([]any, n)
+	for i := range n {
+		maps[i] = D{"ID": int64(i), "Name": "event-name", "Score": float64(i) * 0.5}
+		structs[i] = gobTestEvent{ID: int64(i), Name: "event-name", Score: float32(i) * 0.5}
+	}
+	return maps, structs
+}
+
+// benchCodecCases runs JSONL and gob on the same rows as map records and as
+// registered struct records. JSONL always decodes to maps.
+func benchCodecCases(b *testing.B) map[string]struct {
+	codec   Codec
+	records []any
+} {
+	gobCodec, err := NewGobCodec(WithGobTypes(gobTestEvent{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	maps, structs := benchRecords(10_000)
+	return map[string]struct {
+		codec   Codec
+		records []any
+	}{
+		"jsonl/maps":    {NewJSONLCodec(), maps},
+		"jsonl/structs": {NewJSONLCodec(), structs},
+		"gob/maps":      {gobCodec, maps},
+		"gob/structs":   {gobCodec, structs},
+	}
+}
+
+// BenchmarkCodec_Encode and BenchmarkCodec_Decode compare gob with JSONL.
+// MB/s is relative to each codec's own encoded size.
+func BenchmarkCodec_Encode(b *testing.B) {
+	for name, tc := range benchCodecCases(b) {
+		c, records := tc.codec, tc.records
+		b.Run(name, func(b *testing.B) {
+			var buf bytes.Buffer
+			for b.Loop() {
+				buf.Reset()
+				if err := c.Encode(&buf, records); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.SetBytes(int64(buf.Len()))
+		})
+	}
+}
+
+func BenchmarkCodec_Decode(b *testing.B) {
+	for name, tc := range benchCodecCases(b) {
+		c, records := tc.codec, tc.records
+		b.Run(name, func(b *testing.B) {
+			var buf bytes.Buffer
+			if err := c.Encode(&buf, records); err != nil {
+				b.Fatal(err)
+			}
+			data := buf.Bytes()
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				if _, err := c.Decode(bytes.NewReader(data)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
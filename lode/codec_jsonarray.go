@@ -0,0 +1,172 @@
+package lode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// -----------------------------------------------------------------------------
+// JSON Array Codec
+// -----------------------------------------------------------------------------
+
+// jsonArrayCodec implements Codec, StreamingRecordCodec, and
+// StreamingDecodeCodec for a single top-level JSON array.
+type jsonArrayCodec struct{}
+
+// NewJSONArrayCodec creates a codec that stores records as one JSON array
+// document, for consumers that cannot read JSON Lines.
+//
+// Records can be any JSON-serializable value; each is one array element.
+// Decoding streams the array element by element, so large files are not
+// held in memory by ReadFileRecords. Malformed documents return an error
+// matching ErrInvalidFormat that wraps the underlying JSON syntax error.
+//
+// JSON array codec implements StreamingRecordCodec and StreamingDecodeCodec.
+func NewJSONArrayCodec() Codec {
+	return &jsonArrayCodec{}
+}
+
+func (c *jsonArrayCodec) Name() string {
+	return "json-array"
+}
+
+func (c *jsonArrayCodec) Extension() string {
+	return ".json"
+}
+
+func (c *jsonArrayCodec) Encode(w io.Writer, records []any) error {
+	enc := &jsonArrayStreamEncoder{w: w}
+	for _, record := range records {
+		if err := enc.WriteRecord(record); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+func (c *jsonArrayCodec) Decode(r io.Reader) ([]any, error) {
+	return decodeAll(newJSONArrayStreamDecoder(r))
+}
+
+// NewStreamEncoder implements StreamingRecordCodec. The closing bracket is
+// written by Close.
+func (c *jsonArrayCodec) NewStreamEncoder(w io.Writer) (RecordStreamEncoder, error) {
+	return &jsonArrayStreamEncoder{w: w}, nil
+}
+
+// NewStreamDecoder implements StreamingDecodeCodec. The opening bracket is
+// read on the first call to Next.
+func (c *jsonArrayCodec) NewStreamDecoder(r io.Reader) (RecordIterator, error) {
+	return newJSONArrayStreamDecoder(r), nil
+}
+
+// jsonArrayStreamEncoder writes one element per line between brackets.
+type jsonArrayStreamEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonArrayStreamEncoder) WriteRecord(record any) error {
+	data, err := jsonCodec.Marshal(record)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if e.count == 0 {
+		sep = "[\n"
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+	e.count++
+	return nil
+}
+
+func (e *jsonArrayStreamEncoder) Close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+// jsonArrayStreamDecoder implements RecordIterator over the elements of a
+// top-level JSON array using token streaming.
+type jsonArrayStreamDecoder struct {
+	dec     *json.Decoder
+	started bool
+	done    bool
+	record  any
+	err     error
+}
+
+func newJSONArrayStreamDecoder(r io.Reader) *jsonArrayStreamDecoder {
+	return &jsonArrayStreamDecoder{dec: json.NewDecoder(r)}
+}
+
+func (d *jsonArrayStreamDecoder) Next() bool {
+	if d.done {
+		return false
+	}
+	if !d.started {
+		d.started = true
+		tok, err := d.dec.Token()
+		if err != nil {
+			d.fail(err)
+			return false
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			d.fail(fmt.Errorf("expected JSON array, got %v", tok))
+			return false
+		}
+	}
+
+	if !d.dec.More() {
+		d.finish()
+		return false
+	}
+	var record any
+	if err := d.dec.Decode(&record); err != nil {
+		d.fail(err)
+		return false
+	}
+	d.record = record
+	return true
+}
+
+// finish consumes the closing bracket and rejects trailing content.
+func (d *jsonArrayStreamDecoder) finish() {
+	d.done = true
+	if _, err := d.dec.Token(); err != nil {
+		d.fail(err)
+		return
+	}
+	if _, err := d.dec.Token(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("unexpected data after JSON array")
+		}
+		d.fail(err)
+	}
+}
+
+func (d *jsonArrayStreamDecoder) fail(err error) {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	d.err = fmt.Errorf("%w: %w", ErrInvalidFormat, err)
+	d.done = true
+}
+
+func (d *jsonArrayStreamDecoder) Record() any {
+	return d.record
+}
+
+func (d *jsonArrayStreamDecoder) Err() error {
+	return d.err
+}
@@ -0,0 +1,168 @@
+package lode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONArrayCodec_RoundTrip(t *testing.T) {
+	c := NewJSONArrayCodec()
+	if c.Name() != "json-array" || c.Extension() != ".json" {
+		t.Errorf("Name/Extension = %q/%q", c.Name(), c.Extension())
+	}
+
+	records := []any{D{"id": "1", "n": 1.5}, "text", []any{true, nil}}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	var doc []any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not one JSON document: %v\n%s", err, buf.String())
+	}
+
+	got, err := c.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("round trip mismatch:\n got %v\nwant %v", got, records)
+	}
+}
+
+func TestJSONArrayCodec_Empty(t *testing.T) {
+	c := NewJSONArrayCodec()
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("Encode(nil) = %q, want %q", buf.String(), "[]\n")
+	}
+	got, err := c.Decode(&buf)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Decode = %v, %v; want no records", got, err)
+	}
+}
+
+// countingReader counts the bytes handed to the decoder.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestJSONArrayCodec_StreamDecoder_Incremental(t *testing.T) {
+	c := NewJSONArrayCodec()
+	var buf bytes.Buffer
+	enc, err := c.(StreamingRecordCodec).NewStreamEncoder(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const total = 50_000
+	for i := range total {
+		if err := enc.WriteRecord(D{"id": i, "payload": strings.Repeat("x", 32)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	size := buf.Len()
+
+	src := &countingReader{r: &buf}
+	it, err := c.(StreamingDecodeCodec).NewStreamDecoder(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.Next() {
+		t.Fatalf("expected first record, got error: %v", it.Err())
+	}
+	if src.n > size/100 {
+		t.Errorf("first record consumed %d of %d bytes; decoding is not incremental", src.n, size)
+	}
+
+	n := 1
+	for it.Next() {
+		n++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != total {
+		t.Errorf("decoded %d records, want %d", n, total)
+	}
+}
+
+func TestJSONArrayCodec_Malformed_ReturnsDecodeError(t *testing.T) {
+	c := NewJSONArrayCodec()
+	for _, input := range []string{
+		"",
+		`{"id": 1}`,
+		`[{"id": 1}, {"id": `,
+		`[{"id": 1} {"id": 2}]`,
+		`[1, 2] [3]`,
+	} {
+		_, err := c.Decode(strings.NewReader(input))
+		if !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%q: expected ErrInvalidFormat, got: %v", input, err)
+		}
+		// A decode failure is not a validation failure.
+		if errors.Is(err, ErrManifestInvalid) || errors.Is(err, ErrSchemaViolation) {
+			t.Errorf("%q: decode error must not match validation sentinels: %v", input, err)
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	_, err := c.Decode(strings.NewReader(`[{"id": 1}, oops]`))
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("expected wrapped *json.SyntaxError, got: %v", err)
+	}
+}
+
+func TestDataset_JSONArrayCodec_RegistrySelectsOnRead(t *testing.T) {
+	store := NewMemory()
+	writer, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONArrayCodec()),
+		WithCompressor(NewGzipCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []any
+	for i := range 3 {
+		records = append(records, D{"id": fmt.Sprint(i)})
+	}
+	snap, err := writer.StreamWriteRecords(t.Context(), &sliceIterator{records: records}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".json.gz") {
+		t.Errorf("unexpected file path %s", snap.Manifest.Files[0].Path)
+	}
+
+	reader, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithCodecRegistry(NewCodecRegistry()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reader.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("Read = %v, want %v", got, records)
+	}
+}
@@ -148,6 +148,10 @@ func (c *parquetCodec) Name() string {
 	return "parquet"
 }
 
+func (c *parquetCodec) Extension() string {
+	return ".parquet"
+}
+
 // FileStats returns statistics accumulated during the most recent Encode call.
 func (c *parquetCodec) FileStats() *FileStats {
 	return c.lastStats
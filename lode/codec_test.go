@@ -0,0 +1,263 @@
+package lode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+// Codec registry
+// -----------------------------------------------------------------------------
+
+func TestCodecRegistry_SeededWithJSONL(t *testing.T) {
+	codec, err := NewCodecRegistry().Get("jsonl")
+	if err != nil {
+		t.Fatalf("Get(jsonl) failed: %v", err)
+	}
+	if codec.Name() != "jsonl" {
+		t.Errorf("expected jsonl codec, got %q", codec.Name())
+	}
+}
+
+func TestCodecRegistry_Get_Unknown_ReturnsErrUnknownCodec(t *testing.T) {
+	_, err := NewCodecRegistry().Get("avro")
+	if !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("expected ErrUnknownCodec, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), `"avro"`) {
+		t.Errorf("expected error to name the codec, got: %v", err)
+	}
+}
+
+func TestCodecRegistry_Register(t *testing.T) {
+	r := NewCodecRegistry()
+	if err := r.Register("custom", func() (Codec, error) { return NewJSONLCodec(), nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get("custom"); err != nil {
+		t.Errorf("Get(custom) failed: %v", err)
+	}
+
+	if err := r.Register("", func() (Codec, error) { return NewJSONLCodec(), nil }); err == nil {
+		t.Error("expected error for empty codec name")
+	}
+	if err := r.Register("nil", nil); err == nil {
+		t.Error("expected error for nil factory")
+	}
+}
+
+func TestWithCodecRegistry_NotValidForReader(t *testing.T) {
+	_, err := NewDatasetReader(NewMemoryFactory(), WithCodecRegistry(NewCodecRegistry()))
+	if !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_Read_CodecFromRegistry(t *testing.T) {
+	store := NewMemory()
+
+	dsWrite, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := dsWrite.Write(t.Context(), []any{D{"id": "1"}, D{"id": "2"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reader is never told the snapshot is JSONL; the manifest decides.
+	dsRead, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodecRegistry(NewCodecRegistry()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := dsRead.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	rec, ok := records[0].(map[string]any)
+	if !ok || rec["id"] != "1" {
+		t.Errorf("unexpected first record: %v", records[0])
+	}
+}
+
+func TestDataset_Read_UnregisteredCodec_ReturnsErrUnknownCodec(t *testing.T) {
+	store := NewMemory()
+
+	dsWrite, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(&testCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := dsWrite.Write(t.Context(), []any{D{"id": "1"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsRead, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodecRegistry(NewCodecRegistry()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = dsRead.Read(t.Context(), snap.ID)
+	if !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("expected ErrUnknownCodec, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), `"test-codec"`) {
+		t.Errorf("expected error to name the codec, got: %v", err)
+	}
+}
+
+func TestDataset_Read_ComponentsFromManifest(t *testing.T) {
+	csv, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	codecs := []Codec{NewJSONLCodec(), csv, NewJSONArrayCodec()}
+	compressors := []Compressor{NewNoOpCompressor(), NewGzipCompressor(), NewZstdCompressor()}
+	for _, codec := range codecs {
+		for _, compressor := range compressors {
+			t.Run(codec.Name()+"/"+compressor.Name(), func(t *testing.T) {
+				ctx := t.Context()
+				store := NewMemory()
+				dsWrite, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+					WithCodec(codec), WithCompressor(compressor))
+				if err != nil {
+					t.Fatal(err)
+				}
+				snap, err := dsWrite.Write(ctx, R(D{"id": "1"}, D{"id": "2"}), Metadata{})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Only the store and the registry: codec and compressor come
+				// from the manifest.
+				dsRead, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodecRegistry(NewCodecRegistry()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				records, err := dsRead.Read(ctx, snap.ID)
+				if err != nil {
+					t.Fatalf("Read: %v", err)
+				}
+				if len(records) != 2 || records[1].(map[string]any)["id"] != "2" {
+					t.Errorf("Read = %v, want ids 1 and 2", records)
+				}
+
+				it, err := dsRead.ReadFileRecords(ctx, snap.ID, snap.Manifest.Files[0].Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var n int
+				for it.Next() {
+					n++
+				}
+				if err := it.Err(); err != nil || n != 2 {
+					t.Errorf("ReadFileRecords: %d records, err %v; want 2", n, err)
+				}
+				_ = it.Close()
+			})
+		}
+	}
+}
+
+func TestJSONLCodec_StreamDecoder(t *testing.T) {
+	codec := NewJSONLCodec().(StreamingDecodeCodec)
+
+	it, err := codec.NewStreamDecoder(strings.NewReader("{\"a\":1}\n\n{\"a\":2}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for it.Next() {
+		n++
+	}
+	if it.Err() != nil || n != 2 {
+		t.Errorf("expected 2 records and no error, got %d, %v", n, it.Err())
+	}
+
+	it, err = codec.NewStreamDecoder(strings.NewReader("{\"a\":1}\nnot json\n{\"a\":2}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n = 0
+	for it.Next() {
+		n++
+	}
+	if it.Err() == nil || n != 1 {
+		t.Errorf("expected error after 1 record, got %d, %v", n, it.Err())
+	}
+}
+
+func TestJSONLCodec_MultiMegabyteRecord(t *testing.T) {
+	// Larger than the 10MB token limit the decoder once had.
+	big := strings.Repeat("x", 12<<20)
+	input := "{\"id\":1}\r\n{\"blob\":\"" + big + "\"}\n{\"id\":3}"
+
+	records, err := NewJSONLCodec().Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if got := records[1].(map[string]any)["blob"].(string); len(got) != len(big) {
+		t.Errorf("blob length = %d, want %d", len(got), len(big))
+	}
+	if records[2].(map[string]any)["id"] != float64(3) {
+		t.Errorf("final unterminated line not decoded: %v", records[2])
+	}
+}
+
+func TestJSONLCodec_MaxLineBytes(t *testing.T) {
+	long := "{\"blob\":\"" + strings.Repeat("x", 5000) + "\"}"
+	input := "{\"id\":1}\n" + long + "\n{\"id\":3}\n"
+
+	codec := NewJSONLCodec(WithJSONLMaxLineBytes(1024))
+	_, err := codec.Decode(strings.NewReader(input))
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expected ErrInvalidFormat, got: %v", err)
+	}
+	if want := fmt.Sprintf("line 2 is %d bytes", len(long)); !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error to contain %q, got: %v", want, err)
+	}
+
+	// A line exactly at the limit is accepted; zero removes the limit.
+	for _, limit := range []int{len(long), 0} {
+		records, err := NewJSONLCodec(WithJSONLMaxLineBytes(limit)).Decode(strings.NewReader(input))
+		if err != nil || len(records) != 3 {
+			t.Errorf("limit %d: got %d records, %v", limit, len(records), err)
+		}
+	}
+}
+
+func TestCodec_Extensions(t *testing.T) {
+	tsv, err := NewDelimitedCodec('\t', WithDelimitedHeader(false), WithDelimitedColumns("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	semi, err := NewDelimitedCodec(';')
+	if err != nil {
+		t.Fatal(err)
+	}
+	framed, err := NewFramedCodec(NewJSONLCodec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[Codec]string{
+		NewJSONLCodec(): ".jsonl",
+		tsv:             ".tsv",
+		semi:            ".txt",
+		framed:          ".framed",
+	}
+	for codec, want := range tests {
+		if got := codec.Extension(); got != want {
+			t.Errorf("%s: Extension() = %q, want %q", codec.Name(), got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package lode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// -----------------------------------------------------------------------------
+// Manifest Committers
+// -----------------------------------------------------------------------------
+
+// Committer writes a manifest object, the step that commits a snapshot.
+//
+// Manifest presence is the commit signal (CONTRACT_CORE.md), so a committer
+// must never leave a partially written object visible at path. Data objects
+// and the latest pointer are always written before Commit is called; Commit
+// is called once per manifest path of the snapshot, canonical path first.
+//
+// Commit must return ErrPathExists when path already exists.
+type Committer interface {
+	Commit(ctx context.Context, store Store, path string, data []byte) error
+}
+
+// putCommitter implements Committer with a single Put.
+type putCommitter struct{}
+
+// NewPutCommitter returns the default Committer, which stores the manifest
+// with one Put. The manifest is committed atomically on stores whose Put
+// makes an object visible only once fully written (memory, S3). On the
+// filesystem store, a crash mid-Put can leave a truncated manifest; use
+// NewStagedCommitter there.
+func NewPutCommitter() Committer {
+	return putCommitter{}
+}
+
+func (putCommitter) Commit(ctx context.Context, store Store, path string, data []byte) error {
+	return store.Put(ctx, path, bytes.NewReader(data))
+}
+
+// stagedCommitSuffix is appended to a manifest path to form its staging key.
+const stagedCommitSuffix = ".tmp"
+
+// stagedCommitter implements Committer as a two-phase commit.
+type stagedCommitter struct{}
+
+// NewStagedCommitter returns a Committer that writes the manifest to a
+// staging key (path + ".tmp") and then copies it to path with the store's
+// CopyStore capability, deleting the staging key afterwards.
+//
+// The manifest becomes visible in one step on stores whose Copy is atomic:
+// the filesystem store links the staged file into place, S3 uses
+// CopyObject, and the memory store swaps the entry under its lock. A crash
+// before the copy leaves at most a staging object, which readers never
+// treat as a manifest. Stores without CopyStore fail the commit.
+func NewStagedCommitter() Committer {
+	return stagedCommitter{}
+}
+
+func (stagedCommitter) Commit(ctx context.Context, store Store, path string, data []byte) error {
+	copier, ok := store.(CopyStore)
+	if !ok {
+		return errors.New("lode: staged commit requires a store implementing CopyStore")
+	}
+
+	staged := path + stagedCommitSuffix
+	// The staging key is removed whatever the outcome, ignoring
+	// cancellation. A leftover staging object is harmless.
+	defer func() { _ = store.Delete(context.WithoutCancel(ctx), staged) }()
+
+	if err := store.Put(ctx, staged, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("staging manifest: %w", err)
+	}
+	return copier.Copy(ctx, staged, path)
+}
+
+// committerOption implements Option for WithCommitter (dataset-only).
+type committerOption struct {
+	committer Committer
+}
+
+// WithCommitter sets how manifests are written at commit time.
+// Default: NewPutCommitter().
+// This option is only valid for NewDataset.
+//
+// Every write path (Write, Append, StreamWrite, StreamWriteRecords, and
+// Compact) commits through it.
+func WithCommitter(c Committer) Option {
+	return &committerOption{committer: c}
+}
+
+func (o *committerOption) applyDataset(cfg *datasetConfig) error {
+	if o.committer == nil {
+		return errors.New("WithCommitter: committer must not be nil")
+	}
+	cfg.committer = o.committer
+	return nil
+}
+
+func (o *committerOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithCommitter: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// -----------------------------------------------------------------------------
+// Append-Only Datasets
+// -----------------------------------------------------------------------------
+
+// appendOnlyOption implements Option for WithAppendOnly (dataset-only).
+type appendOnlyOption struct {
+	enabled bool
+}
+
+// WithAppendOnly makes committed snapshots immutable for this dataset.
+// Default: false.
+// This option is only valid for NewDataset.
+//
+// The Committer is given a store that refuses to replace or delete
+// manifests: a Put or Copy onto an existing manifest path, or a Delete of
+// one, fails with ErrAppendOnly, so a custom Committer cannot rewrite
+// committed snapshots through it. Each guarded write costs one Exists
+// call. A commit to an existing manifest path returns an error matching
+// both ErrAppendOnly and ErrPathExists. New snapshots are written as usual;
+// the latest pointer, staging keys, and data objects of failed writes stay
+// mutable.
+func WithAppendOnly(enabled bool) Option {
+	return &appendOnlyOption{enabled: enabled}
+}
+
+func (o *appendOnlyOption) applyDataset(cfg *datasetConfig) error {
+	cfg.appendOnly = o.enabled
+	return nil
+}
+
+func (o *appendOnlyOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithAppendOnly: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// appendOnlyStore wraps the store handed to a Committer, rejecting writes
+// over existing manifests and deletion of manifests.
+type appendOnlyStore struct {
+	Store
+	layout layout
+}
+
+// newAppendOnlyStore guards store, keeping its CopyStore capability so
+// the staged committer still works.
+func newAppendOnlyStore(store Store, l layout) Store {
+	guarded := &appendOnlyStore{Store: store, layout: l}
+	if _, ok := store.(CopyStore); ok {
+		return &appendOnlyCopyStore{guarded}
+	}
+	return guarded
+}
+
+// checkAbsent returns ErrAppendOnly when path is an existing manifest.
+func (s *appendOnlyStore) checkAbsent(ctx context.Context, path string) error {
+	if !s.layout.isManifest(path) {
+		return nil
+	}
+	exists, err := s.Store.Exists(ctx, path)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("lode: %w: manifest %s already exists: %w", ErrAppendOnly, path, ErrPathExists)
+	}
+	return nil
+}
+
+func (s *appendOnlyStore) Put(ctx context.Context, path string, r io.Reader) error {
+	if err := s.checkAbsent(ctx, path); err != nil {
+		return err
+	}
+	return s.Store.Put(ctx, path, r)
+}
+
+func (s *appendOnlyStore) Delete(ctx context.Context, path string) error {
+	if s.layout.isManifest(path) {
+		return fmt.Errorf("lode: %w: cannot delete manifest %s", ErrAppendOnly, path)
+	}
+	return s.Store.Delete(ctx, path)
+}
+
+// appendOnlyCopyStore is an appendOnlyStore over a CopyStore.
+type appendOnlyCopyStore struct{ *appendOnlyStore }
+
+func (s *appendOnlyCopyStore) Copy(ctx context.Context, src, dst string) error {
+	if err := s.checkAbsent(ctx, dst); err != nil {
+		return err
+	}
+	return s.Store.(CopyStore).Copy(ctx, src, dst)
+}
+
+// commitManifest commits data at path through the configured Committer,
+// enforcing WithAppendOnly.
+func (d *dataset) commitManifest(ctx context.Context, path string, data []byte) error {
+	if !d.appendOnly {
+		return d.committer.Commit(ctx, d.store, path, data)
+	}
+	err := d.committer.Commit(ctx, newAppendOnlyStore(d.store, d.layout), path, data)
+	if errors.Is(err, ErrPathExists) && !errors.Is(err, ErrAppendOnly) {
+		return fmt.Errorf("lode: %w: manifest %s already exists: %w", ErrAppendOnly, path, err)
+	}
+	return err
+}
@@ -0,0 +1,354 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var errCrashed = errors.New("simulated crash")
+
+// crashStore simulates a process crash: the first Put matching crashOn
+// stores only half of its bytes, and every later call fails, so no
+// cleanup or retry reaches the underlying store.
+type crashStore struct {
+	Store
+	crashOn func(path string) bool
+
+	mu      sync.Mutex
+	crashed bool
+}
+
+func (s *crashStore) alive() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.crashed {
+		return errCrashed
+	}
+	return nil
+}
+
+func (s *crashStore) Put(ctx context.Context, p string, r io.Reader) error {
+	if err := s.alive(); err != nil {
+		return err
+	}
+	if !s.crashOn(p) {
+		return s.Store.Put(ctx, p, r)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_ = s.Store.Put(ctx, p, strings.NewReader(string(data[:len(data)/2])))
+	s.mu.Lock()
+	s.crashed = true
+	s.mu.Unlock()
+	return errCrashed
+}
+
+func (s *crashStore) Delete(ctx context.Context, p string) error {
+	if err := s.alive(); err != nil {
+		return err
+	}
+	return s.Store.Delete(ctx, p)
+}
+
+func (s *crashStore) Exists(ctx context.Context, p string) (bool, error) {
+	if err := s.alive(); err != nil {
+		return false, err
+	}
+	return s.Store.Exists(ctx, p)
+}
+
+func (s *crashStore) Copy(ctx context.Context, src, dst string) error {
+	if err := s.alive(); err != nil {
+		return err
+	}
+	return CopyObject(ctx, s.Store, src, dst)
+}
+
+func TestCommitter_CrashDuringManifestCommit(t *testing.T) {
+	tests := []struct {
+		name      string
+		committer Committer
+		// partialVisible reports whether the crash leaves a truncated
+		// manifest where readers look for one.
+		partialVisible bool
+	}{
+		{"put", NewPutCommitter(), true},
+		{"staged", NewStagedCommitter(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			root := t.TempDir()
+			fsStore, err := NewFS(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			crash := &crashStore{Store: fsStore, crashOn: func(p string) bool {
+				return strings.Contains(path.Base(p), "manifest.json")
+			}}
+			ds, err := NewDataset("events", NewMemoryFactoryFrom(crash),
+				WithCodec(NewJSONLCodec()),
+				WithCommitter(tt.committer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ds.Write(ctx, R(D{"id": 1}, D{"id": 2}), Metadata{}); !errors.Is(err, errCrashed) {
+				t.Fatalf("expected simulated crash, got: %v", err)
+			}
+
+			// Restart: a fresh process sees only what reached the disk.
+			reader, err := NewDatasetReader(NewFSFactory(root))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = reader.ListManifests(ctx, "events", "", ManifestListOptions{})
+			if tt.partialVisible {
+				if err == nil || errors.Is(err, ErrNotFound) {
+					t.Errorf("expected the truncated manifest to break listing, got: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected no committed manifest, got: %v", err)
+			}
+			keys, err := fsStore.List(ctx, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.ContainsFunc(keys, func(k string) bool { return strings.HasSuffix(k, "manifest.json.tmp") }) {
+				t.Errorf("expected the crash to leave only a staging object, got %v", keys)
+			}
+			restarted, err := NewDataset("events", NewFSFactory(root), WithCodec(NewJSONLCodec()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := restarted.Latest(ctx); !errors.Is(err, ErrNoSnapshots) {
+				t.Errorf("expected ErrNoSnapshots after crash, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestStagedCommitter_CommitsAndCleansUp(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"),
+		WithCommitter(NewStagedCommitter()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"id": 1, "day": "a"}, D{"id": 2, "day": "b"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := store.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifests := 0
+	for _, k := range keys {
+		if strings.HasSuffix(k, stagedCommitSuffix) {
+			t.Errorf("staging object left behind: %s", k)
+		}
+		if path.Base(k) == "manifest.json" {
+			manifests++
+		}
+	}
+	if manifests != 3 {
+		t.Errorf("expected canonical and 2 partition manifests, got %d in %v", manifests, keys)
+	}
+	if got, err := ds.Read(ctx, snap.ID); err != nil || len(got) != 2 {
+		t.Errorf("Read = %d records, err %v", len(got), err)
+	}
+}
+
+func TestStagedCommitter_RequiresCopyStore(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithCommitter(NewStagedCommitter()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.Write(t.Context(), R(D{"id": 1}), Metadata{}); err == nil || !strings.Contains(err.Error(), "CopyStore") {
+		t.Errorf("expected CopyStore error, got: %v", err)
+	}
+}
+
+func TestWithCommitter_Validation(t *testing.T) {
+	if _, err := NewDataset("events", NewMemoryFactory(), WithCommitter(nil)); err == nil {
+		t.Error("expected error for nil committer")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithCommitter(NewPutCommitter())); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestWithAppendOnly_AppendsSucceed(t *testing.T) {
+	for name, committer := range map[string]Committer{"put": NewPutCommitter(), "staged": NewStagedCommitter()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := t.Context()
+			ds, err := NewDataset("events", NewMemoryFactory(),
+				WithCodec(NewJSONLCodec()),
+				WithHiveLayout("day"),
+				WithCommitter(committer),
+				WithAppendOnly(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := ds.Write(ctx, R(D{"id": 1, "day": "a"}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := ds.Append(ctx, R(D{"id": 2, "day": "b"}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if second.Manifest.ParentSnapshotID != first.ID {
+				t.Errorf("parent = %s, want %s", second.Manifest.ParentSnapshotID, first.ID)
+			}
+			if snaps, err := ds.Snapshots(ctx); err != nil || len(snaps) != 2 {
+				t.Errorf("Snapshots = %d, %v; want 2", len(snaps), err)
+			}
+		})
+	}
+}
+
+// committerFunc adapts a function to Committer.
+type committerFunc func(ctx context.Context, store Store, path string, data []byte) error
+
+func (f committerFunc) Commit(ctx context.Context, store Store, path string, data []byte) error {
+	return f(ctx, store, path, data)
+}
+
+func TestWithAppendOnly_RejectsOverwriteAndDelete(t *testing.T) {
+	ctx := t.Context()
+
+	// replacing overwrites whatever is at path.
+	replacing := committerFunc(func(ctx context.Context, store Store, path string, data []byte) error {
+		if err := store.Delete(ctx, path); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return store.Put(ctx, path, strings.NewReader(string(data)))
+	})
+	// overwriting replaces the manifest with a plain Put on a store that
+	// allows it.
+	overwriting := committerFunc(func(ctx context.Context, store Store, path string, data []byte) error {
+		return store.Put(ctx, path, strings.NewReader(string(data)))
+	})
+
+	newDataset := func(t *testing.T, store Store, committer Committer, appendOnly bool) *dataset {
+		t.Helper()
+		ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+			WithCodec(NewJSONLCodec()),
+			WithCommitter(committer),
+			WithAppendOnly(appendOnly))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ds.(*dataset)
+	}
+
+	committers := map[string]Committer{
+		"put":         NewPutCommitter(),
+		"staged":      NewStagedCommitter(),
+		"replacing":   replacing,
+		"overwriting": overwriting,
+	}
+	for name, committer := range committers {
+		t.Run("overwrite/"+name, func(t *testing.T) {
+			// overwriteStore lets Put replace objects, so only the
+			// append-only guard stands in the way.
+			store := &overwriteStore{Store: NewMemory()}
+			ds := newDataset(t, store, NewPutCommitter(), true)
+			snap, err := ds.Write(ctx, R(D{"id": 1}), Metadata{"v": "original"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			replaced := *snap.Manifest
+			replaced.Metadata = Metadata{"v": "replaced"}
+
+			err = newDataset(t, store, committer, true).writeManifests(ctx, snap.ID, &replaced, nil)
+			if !errors.Is(err, ErrAppendOnly) {
+				t.Errorf("append-only: expected ErrAppendOnly, got: %v", err)
+			}
+			if name != "replacing" && !errors.Is(err, ErrPathExists) {
+				t.Errorf("append-only: expected ErrPathExists for a write over the manifest, got: %v", err)
+			}
+			got, err := ds.Snapshot(ctx, snap.ID)
+			if err != nil || got.Manifest.Metadata["v"] != "original" {
+				t.Errorf("manifest changed under append-only: %v, %v", got, err)
+			}
+		})
+	}
+
+	t.Run("overwrite without option", func(t *testing.T) {
+		store := &overwriteStore{Store: NewMemory()}
+		snap, err := newDataset(t, store, NewPutCommitter(), false).Write(ctx, R(D{"id": 1}), Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := newDataset(t, store, overwriting, false).writeManifests(ctx, snap.ID, snap.Manifest, nil); err != nil {
+			t.Errorf("without append-only the overwriting committer should succeed, got: %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := NewMemory()
+		first, err := newDataset(t, store, NewPutCommitter(), true).Write(ctx, R(D{"id": 1}), Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		firstManifest := NewDefaultLayout().manifestPath("events", first.ID)
+
+		// pruning keeps only the newest snapshot by deleting the parent's manifest.
+		pruning := committerFunc(func(ctx context.Context, store Store, path string, data []byte) error {
+			if err := store.Put(ctx, path, strings.NewReader(string(data))); err != nil {
+				return err
+			}
+			return store.Delete(ctx, firstManifest)
+		})
+		_, err = newDataset(t, store, pruning, true).Write(ctx, R(D{"id": 2}), Metadata{})
+		if !errors.Is(err, ErrAppendOnly) {
+			t.Errorf("expected ErrAppendOnly, got: %v", err)
+		}
+		if ok, err := store.Exists(ctx, firstManifest); err != nil || !ok {
+			t.Errorf("first manifest deleted under append-only: %v, %v", ok, err)
+		}
+	})
+
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithAppendOnly(true)); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+// overwriteStore is a memory store whose Put replaces existing objects, as
+// stores without write-once semantics do.
+type overwriteStore struct {
+	Store
+}
+
+func (s *overwriteStore) Put(ctx context.Context, path string, r io.Reader) error {
+	if err := s.Store.Delete(ctx, path); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return s.Store.Put(ctx, path, r)
+}
+
+func (s *overwriteStore) Copy(ctx context.Context, src, dst string) error {
+	if err := s.Store.Delete(ctx, dst); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return s.Store.(CopyStore).Copy(ctx, src, dst)
+}
@@ -0,0 +1,356 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Compaction
+// -----------------------------------------------------------------------------
+
+// Compact reads all records from the input snapshots and commits them as a
+// single new snapshot encoded with the dataset's configured codec,
+// compressor, and layout.
+//
+// Each input is decoded with the codec and compressor recorded in its own
+// manifest: the configured codec, a codec from WithCodecRegistry, or a
+// built-in compressor. The output's ParentSnapshotID is the newest input
+// and its RowCount is the sum of the inputs' row counts.
+//
+// Inputs are never deleted; callers verify the result and remove inputs
+// explicitly. Compaction is never triggered automatically. As with Write,
+// output data files are deleted on a best-effort basis if the commit fails.
+func (d *dataset) Compact(ctx context.Context, ids []DatasetSnapshotID, opts CompactOptions) (*DatasetSnapshot, error) {
+	if d.codec == nil {
+		return nil, errors.New("lode: Compact requires a codec")
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("lode: Compact requires at least one input snapshot")
+	}
+	if opts.TargetFileBytes < 0 {
+		return nil, errors.New("lode: TargetFileBytes must be non-negative")
+	}
+	if opts.CoalesceSmallFiles && opts.TargetFileBytes == 0 {
+		return nil, errors.New("lode: CoalesceSmallFiles requires TargetFileBytes")
+	}
+	metadata := opts.Metadata
+	if metadata == nil {
+		metadata = Metadata{}
+	}
+
+	var (
+		records      []any
+		rowCount     int64
+		minTs, maxTs *time.Time
+		newest       *DatasetSnapshot
+	)
+	seen := make(map[DatasetSnapshotID]struct{}, len(ids))
+	for _, id := range ids {
+		if _, dup := seen[id]; dup {
+			return nil, fmt.Errorf("lode: duplicate compaction input %s", id)
+		}
+		seen[id] = struct{}{}
+
+		snap, err := d.Snapshot(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		m := snap.Manifest
+
+		inputRecords, err := d.readCompactionInput(ctx, m)
+		if err != nil {
+			return nil, fmt.Errorf("lode: failed to read snapshot %s: %w", id, err)
+		}
+		if int64(len(inputRecords)) != m.RowCount {
+			return nil, fmt.Errorf("lode: snapshot %s decoded %d records but manifest records %d",
+				id, len(inputRecords), m.RowCount)
+		}
+		records = append(records, inputRecords...)
+		rowCount += m.RowCount
+
+		if m.MinTimestamp != nil && (minTs == nil || m.MinTimestamp.Before(*minTs)) {
+			minTs = m.MinTimestamp
+		}
+		if m.MaxTimestamp != nil && (maxTs == nil || m.MaxTimestamp.After(*maxTs)) {
+			maxTs = m.MaxTimestamp
+		}
+		if newest == nil || m.CreatedAt.After(newest.Manifest.CreatedAt) {
+			newest = snap
+		}
+	}
+
+	// Inputs are read before locking; only the commit is serialized.
+	unlock, err := d.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Pointer content is needed for the commit CAS; the parent is the
+	// newest input, not the resolved latest.
+	_, pointerID, err := d.resolveStoredParentID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotID := DatasetSnapshotID(generateID())
+
+	partitions, err := d.partitionRecords(records)
+	if err != nil {
+		return nil, fmt.Errorf("lode: partitioning failed: %w", err)
+	}
+	if err := d.checkPartitionLimit(len(partitions)); err != nil {
+		return nil, err
+	}
+
+	// Data files stored before a failed commit are deleted on a best-effort
+	// basis, as in Write.
+	var staged []string
+	put := func(ctx context.Context, path string, data []byte) error {
+		if err := d.putObject(ctx, path, data); err != nil {
+			return err
+		}
+		staged = append(staged, path)
+		return nil
+	}
+
+	var files []FileRef
+	var partitionKeys []string
+	var summaries []PartitionSummary
+	for partKey, partRecords := range partitions {
+		refs, err := d.writeCompactedFiles(ctx, snapshotID, partKey, partRecords, opts, put)
+		if err != nil {
+			return nil, d.cleanupStaged(ctx, staged, fmt.Errorf("lode: failed to write data file: %w", err))
+		}
+		files = append(files, refs...)
+		partitionKeys = append(partitionKeys, partKey)
+		summary := PartitionSummary{Path: partKey, RowCount: int64(len(partRecords))}
+		for _, ref := range refs {
+			summary.SizeBytes += ref.SizeBytes
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sortFileRefs(files)
+
+	manifest := &Manifest{
+		SchemaName:       manifestSchemaName,
+		FormatVersion:    manifestFormatVersion,
+		DatasetID:        d.id,
+		SnapshotID:       snapshotID,
+		CreatedAt:        time.Now().UTC(),
+		Metadata:         metadata,
+		Files:            files,
+		ParentSnapshotID: newest.ID,
+		RowCount:         rowCount,
+		MinTimestamp:     minTs,
+		MaxTimestamp:     maxTs,
+		Codec:            d.codec.Name(),
+		Compressor:       d.compressor.Name(),
+		Partitioner:      d.layout.partitioner().name(),
+		Partitions:       d.partitionSummaries(summaries),
+	}
+	if d.checksum != nil {
+		manifest.ChecksumAlgorithm = d.checksum.Name()
+	}
+
+	// Same pointer-before-manifest protocol as Write.
+	if err := d.writeLatestPointer(ctx, pointerID, snapshotID); err != nil {
+		return nil, d.cleanupStaged(ctx, staged, fmt.Errorf("lode: failed to update latest pointer: %w", err))
+	}
+
+	if err := d.writeManifests(ctx, snapshotID, manifest, partitionKeys); err != nil {
+		return nil, d.cleanupUncommitted(ctx, staged, snapshotID, partitionKeys, fmt.Errorf("lode: failed to write manifest: %w", err))
+	}
+	d.lastSnapshotID = snapshotID
+
+	return d.finishCommit(ctx, &DatasetSnapshot{
+		ID:       snapshotID,
+		Manifest: manifest,
+		layout:   d.layout,
+	})
+}
+
+// readCompactionInput decodes all records of a snapshot using the codec and
+// compressor recorded in its manifest.
+func (d *dataset) readCompactionInput(ctx context.Context, m *Manifest) ([]any, error) {
+	if m.Codec == "" {
+		return nil, errors.New("lode: cannot compact raw blob snapshot")
+	}
+
+	var codec Codec
+	switch {
+	case m.Codec == d.codec.Name():
+		codec = d.codec
+	case d.codecs != nil:
+		c, err := d.codecs.Get(m.Codec)
+		if err != nil {
+			return nil, err
+		}
+		codec = c
+	default:
+		return nil, fmt.Errorf("lode: codec mismatch: snapshot uses %q but dataset configured with %q (use WithCodecRegistry to compact across codecs)",
+			m.Codec, d.codec.Name())
+	}
+
+	compressor := d.compressor
+	if m.Compressor != d.compressor.Name() {
+		c, err := builtinCompressor(m.Compressor)
+		if err != nil {
+			return nil, err
+		}
+		compressor = c
+	}
+
+	var records []any
+	for _, fileRef := range m.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fileRecords, err := d.readDataFile(ctx, compressor, codec, fileRef.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data file %s: %w", fileRef.Path, err)
+		}
+		records = append(records, fileRecords...)
+	}
+	return records, nil
+}
+
+// writeCompactedFiles encodes one partition's records, splitting them into
+// batches when the encoded size exceeds opts.TargetFileBytes. Each file is
+// stored through put.
+func (d *dataset) writeCompactedFiles(ctx context.Context, snapshotID DatasetSnapshotID, partKey string, records []any, opts CompactOptions, put func(ctx context.Context, path string, data []byte) error) ([]FileRef, error) {
+	targetBytes := opts.TargetFileBytes
+	data, stats, err := d.encodeDataFile(records, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetBytes == 0 || int64(len(data)) <= targetBytes || len(records) < 2 {
+		fileName, err := d.dataFileName(partKey, 0, "data")
+		if err != nil {
+			return nil, err
+		}
+		filePath := d.layout.dataFilePath(d.id, snapshotID, partKey, fileName)
+		if err := put(ctx, filePath, data); err != nil {
+			return nil, err
+		}
+		return []FileRef{d.newFileRef(filePath, data, stats)}, nil
+	}
+
+	var batches []encodedBatch
+	if opts.CoalesceSmallFiles {
+		batches, err = d.coalescedBatches(encodedBatch{records: records, data: data, stats: stats}, targetBytes)
+	} else {
+		batches, err = d.evenBatches(records, int64(len(data)), targetBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]FileRef, 0, len(batches))
+	seen := make(map[string]bool, len(batches))
+	for i, batch := range batches {
+		fileName, err := d.dataFileName(partKey, i, fmt.Sprintf("data-%04d", i))
+		if err != nil {
+			return nil, err
+		}
+		if seen[fileName] {
+			return nil, fmt.Errorf("lode: file namer returned duplicate name %q for partition %q", fileName, partKey)
+		}
+		seen[fileName] = true
+		filePath := d.layout.dataFilePath(d.id, snapshotID, partKey, fileName)
+		if err := put(ctx, filePath, batch.data); err != nil {
+			return nil, err
+		}
+		refs = append(refs, d.newFileRef(filePath, batch.data, batch.stats))
+	}
+	return refs, nil
+}
+
+// encodedBatch is a batch of records with its encoded data file.
+type encodedBatch struct {
+	records []any
+	data    []byte
+	stats   *FileStats
+}
+
+func (d *dataset) encodeBatch(records []any) (encodedBatch, error) {
+	data, stats, err := d.encodeDataFile(records, nil)
+	if err != nil {
+		return encodedBatch{}, err
+	}
+	return encodedBatch{records: records, data: data, stats: stats}, nil
+}
+
+// evenBatches splits records into batches holding an equal share of
+// records. Size is approximated from the single-file encoding.
+func (d *dataset) evenBatches(records []any, size, targetBytes int64) ([]encodedBatch, error) {
+	chunks := int((size + targetBytes - 1) / targetBytes)
+	chunks = min(chunks, len(records))
+	perChunk := (len(records) + chunks - 1) / chunks
+
+	batches := make([]encodedBatch, 0, chunks)
+	for start := 0; start < len(records); start += perChunk {
+		batch, err := d.encodeBatch(records[start:min(start+perChunk, len(records))])
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// coalescedBatches halves whole until every batch encodes within
+// targetBytes (or holds a single record), then merges adjacent batches
+// while the merged encoding still fits. Record order is preserved.
+func (d *dataset) coalescedBatches(whole encodedBatch, targetBytes int64) ([]encodedBatch, error) {
+	leaves, err := d.splitToTarget(whole, targetBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leaves are contiguous, in order, so a merged batch is a reslice of
+	// whole.records from the start of the last merged batch.
+	merged := []encodedBatch{leaves[0]}
+	start, end := 0, len(leaves[0].records)
+	for _, next := range leaves[1:] {
+		combined, err := d.encodeBatch(whole.records[start : end+len(next.records)])
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(combined.data)) <= targetBytes {
+			merged[len(merged)-1] = combined
+		} else {
+			merged = append(merged, next)
+			start = end
+		}
+		end += len(next.records)
+	}
+	return merged, nil
+}
+
+// splitToTarget recursively halves b until each part encodes within
+// targetBytes or holds a single record.
+func (d *dataset) splitToTarget(b encodedBatch, targetBytes int64) ([]encodedBatch, error) {
+	if int64(len(b.data)) <= targetBytes || len(b.records) < 2 {
+		return []encodedBatch{b}, nil
+	}
+	mid := len(b.records) / 2
+	var parts []encodedBatch
+	for _, half := range [][]any{b.records[:mid], b.records[mid:]} {
+		enc, err := d.encodeBatch(half)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := d.splitToTarget(enc, targetBytes)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, sub...)
+	}
+	return parts, nil
+}
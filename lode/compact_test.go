@@ -0,0 +1,398 @@
+package lode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDataset_Compact_MergesInputs(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap1, err := ds.Write(t.Context(), []any{D{"id": "1"}, D{"id": "2"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap2, err := ds.Write(t.Context(), []any{D{"id": "3"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap1.ID, snap2.ID}, CompactOptions{
+		Metadata: Metadata{"compacted": true},
+	})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	if out.Manifest.RowCount != 3 {
+		t.Errorf("expected RowCount 3, got %d", out.Manifest.RowCount)
+	}
+	if out.Manifest.ParentSnapshotID != snap2.ID {
+		t.Errorf("expected parent %s, got %s", snap2.ID, out.Manifest.ParentSnapshotID)
+	}
+
+	records, err := ds.Read(t.Context(), out.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("expected 3 records, got %d", len(records))
+	}
+
+	latest, err := ds.Latest(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != out.ID {
+		t.Errorf("expected latest %s, got %s", out.ID, latest.ID)
+	}
+
+	// Inputs remain readable.
+	for _, id := range []DatasetSnapshotID{snap1.ID, snap2.ID} {
+		if _, err := ds.Read(t.Context(), id); err != nil {
+			t.Errorf("input %s not readable after compaction: %v", id, err)
+		}
+	}
+}
+
+func TestDataset_Compact_ManifestPutFailure_DeletesDataObjects(t *testing.T) {
+	mem := NewMemory()
+	fs := newFaultStore(mem)
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap1, err := ds.Write(t.Context(), R(D{"id": 1, "day": "2024-01-01"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap2, err := ds.Write(t.Context(), R(D{"id": 2, "day": "2024-01-02"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errManifest := errors.New("injected: manifest write failure")
+	fs.SetPutError(errManifest, "manifest.json")
+	fs.Reset()
+
+	_, err = ds.Compact(t.Context(), []DatasetSnapshotID{snap1.ID, snap2.ID}, CompactOptions{})
+	if !errors.Is(err, errManifest) {
+		t.Fatalf("expected original error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "cleaned up 2 partial objects") {
+		t.Errorf("expected cleanup note, got: %v", err)
+	}
+
+	var dataPuts []string
+	for _, p := range fs.PutCalls() {
+		if strings.Contains(p, "/data/") {
+			dataPuts = append(dataPuts, p)
+		}
+	}
+	if len(dataPuts) != 2 {
+		t.Fatalf("expected 2 data Puts, got %v", dataPuts)
+	}
+	for _, p := range dataPuts {
+		if exists, _ := mem.Exists(t.Context(), p); exists {
+			t.Errorf("data object %s should have been deleted", p)
+		}
+	}
+
+	// Inputs remain readable.
+	fs.SetPutError(nil)
+	for _, id := range []DatasetSnapshotID{snap1.ID, snap2.ID} {
+		if _, err := ds.Read(t.Context(), id); err != nil {
+			t.Errorf("input %s not readable after failed compaction: %v", id, err)
+		}
+	}
+}
+
+func TestDataset_Compact_TargetFileBytes_SplitsFiles(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]any, 100)
+	for i := range data {
+		data[i] = D{"id": i, "payload": strings.Repeat("x", 100)}
+	}
+	snap, err := ds.Write(t.Context(), data, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap.ID}, CompactOptions{TargetFileBytes: 2048})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	if len(out.Manifest.Files) < 2 {
+		t.Fatalf("expected multiple files, got %d", len(out.Manifest.Files))
+	}
+	for _, f := range out.Manifest.Files {
+		if f.SizeBytes > 2048 {
+			t.Errorf("file %s is %d bytes, exceeds target", f.Path, f.SizeBytes)
+		}
+	}
+	if out.Manifest.RowCount != 100 {
+		t.Errorf("expected RowCount 100, got %d", out.Manifest.RowCount)
+	}
+
+	records, err := ds.Read(t.Context(), out.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 100 {
+		t.Errorf("expected 100 records, got %d", len(records))
+	}
+}
+
+func TestDataset_Compact_MixedCompressors(t *testing.T) {
+	store := NewMemory()
+	factory := NewMemoryFactoryFrom(store)
+
+	var inputs []DatasetSnapshotID
+	for _, c := range []Compressor{NewGzipCompressor(), NewNoOpCompressor()} {
+		ds, err := NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()), WithCompressor(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap, err := ds.Write(t.Context(), []any{D{"compressor": c.Name()}}, Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, snap.ID)
+	}
+
+	ds, err := NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()), WithCompressor(NewZstdCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ds.Compact(t.Context(), inputs, CompactOptions{})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if out.Manifest.Compressor != "zstd" {
+		t.Errorf("expected zstd output, got %q", out.Manifest.Compressor)
+	}
+
+	records, err := ds.Read(t.Context(), out.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 records, got %d", len(records))
+	}
+}
+
+func TestDataset_Compact_MixedCompressors_Brotli(t *testing.T) {
+	brotli, err := NewBrotliCompressor(BrotliDefaultQuality)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCompactMixedCompressor(t, brotli)
+}
+
+func TestDataset_Compact_MixedCompressors_LZ4(t *testing.T) {
+	testCompactMixedCompressor(t, NewLZ4Compressor())
+}
+
+// testCompactMixedCompressor compacts an uncompressed snapshot and one
+// written with c into a zstd snapshot, and checks both records read back.
+func testCompactMixedCompressor(t *testing.T, c Compressor) {
+	t.Helper()
+	factory := NewMemoryFactoryFrom(NewMemory())
+
+	var inputs []DatasetSnapshotID
+	for _, c := range []Compressor{NewNoOpCompressor(), c} {
+		ds, err := NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()), WithCompressor(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap, err := ds.Write(t.Context(), []any{D{"compressor": c.Name()}}, Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, snap.ID)
+	}
+
+	ds, err := NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()), WithCompressor(NewZstdCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ds.Compact(t.Context(), inputs, CompactOptions{})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	records, err := ds.Read(t.Context(), out.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 records, got %d", len(records))
+	}
+}
+
+func TestDataset_Compact_MixedCodecs(t *testing.T) {
+	store := NewMemory()
+	factory := NewMemoryFactoryFrom(store)
+
+	framed, err := NewFramedCodec(NewJSONLCodec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsFramed, err := NewDataset("test-ds", factory, WithCodec(framed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap1, err := dsFramed.Write(t.Context(), []any{D{"id": "1"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a registry the framed input cannot be decoded.
+	ds, err := NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap2, err := ds.Write(t.Context(), []any{D{"id": "2"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap1.ID, snap2.ID}, CompactOptions{}); err == nil {
+		t.Fatal("expected codec mismatch error without registry")
+	}
+
+	registry := NewCodecRegistry()
+	if err := registry.Register(framed.Name(), func() (Codec, error) { return NewFramedCodec(NewJSONLCodec()) }); err != nil {
+		t.Fatal(err)
+	}
+	ds, err = NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()), WithCodecRegistry(registry))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap1.ID, snap2.ID}, CompactOptions{})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if out.Manifest.Codec != "jsonl" || out.Manifest.RowCount != 2 {
+		t.Errorf("unexpected output manifest: codec=%q rows=%d", out.Manifest.Codec, out.Manifest.RowCount)
+	}
+}
+
+func TestDataset_Compact_InvalidInputs(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), []any{D{"id": "1"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ds.Compact(t.Context(), nil, CompactOptions{}); err == nil {
+		t.Error("expected error for empty input")
+	}
+	if _, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap.ID, snap.ID}, CompactOptions{}); err == nil {
+		t.Error("expected error for duplicate input")
+	}
+	if _, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap.ID}, CompactOptions{TargetFileBytes: -1}); err == nil {
+		t.Error("expected error for negative TargetFileBytes")
+	}
+	if _, err := ds.Compact(t.Context(), []DatasetSnapshotID{"missing"}, CompactOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDataset_Compact_RequiresCodec(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.Compact(t.Context(), []DatasetSnapshotID{"any"}, CompactOptions{}); err == nil {
+		t.Error("expected error when no codec configured")
+	}
+}
+
+func TestDataset_Compact_CoalesceSmallFiles_SkewedPartitions(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("bucket"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Bucket "hot" holds a run of large records followed by many small
+	// ones; bucket "cold" holds a handful of small records.
+	var data []any
+	for i := range 20 {
+		data = append(data, D{"bucket": "hot", "id": i, "payload": strings.Repeat("x", 700)})
+	}
+	for i := range 300 {
+		data = append(data, D{"bucket": "hot", "id": 100 + i, "payload": "y"})
+	}
+	for i := range 3 {
+		data = append(data, D{"bucket": "cold", "id": 1000 + i})
+	}
+	snap, err := ds.Write(t.Context(), data, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const target = 4096
+	out, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap.ID}, CompactOptions{
+		TargetFileBytes:    target,
+		CoalesceSmallFiles: true,
+	})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	sizes := make(map[string]int64)
+	for _, f := range out.Manifest.Files {
+		if f.SizeBytes > target {
+			t.Errorf("file %s is %d bytes, exceeds target", f.Path, f.SizeBytes)
+		}
+		sizes[out.layout.extractPartitionPath(f.Path)] += f.SizeBytes
+	}
+	if out.Manifest.RowCount != int64(len(data)) {
+		t.Errorf("expected RowCount %d, got %d", len(data), out.Manifest.RowCount)
+	}
+	if out.TotalBytes() != sizes["bucket=hot"]+sizes["bucket=cold"] {
+		t.Errorf("TotalBytes = %d, want sum of file sizes", out.TotalBytes())
+	}
+
+	counts := out.PartitionCounts()
+	if counts["bucket=cold"] != 1 {
+		t.Errorf("expected 1 file for the small bucket, got %d", counts["bucket=cold"])
+	}
+	// Adjacent files never fit together, so at most two files per target's
+	// worth of data.
+	if limit := 2 * int((sizes["bucket=hot"]+target-1)/target); counts["bucket=hot"] > limit {
+		t.Errorf("hot bucket has %d files for %d bytes, want at most %d", counts["bucket=hot"], sizes["bucket=hot"], limit)
+	}
+	if counts["bucket=hot"] < 2 {
+		t.Errorf("hot bucket must still split, got %d file(s)", counts["bucket=hot"])
+	}
+
+	records, err := ds.Read(t.Context(), out.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(data) {
+		t.Errorf("expected %d records, got %d", len(data), len(records))
+	}
+
+	if _, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap.ID}, CompactOptions{CoalesceSmallFiles: true}); err == nil {
+		t.Error("expected error for CoalesceSmallFiles without TargetFileBytes")
+	}
+}
@@ -1,10 +1,14 @@
 package lode
 
 import (
+	"compress/bzip2"
 	"compress/gzip"
+	"fmt"
 	"io"
 
+	"github.com/andybalholm/brotli"
 	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 )
 
 // -----------------------------------------------------------------------------
@@ -17,6 +21,8 @@ type gzipCompressor struct{}
 // NewGzipCompressor creates a gzip compressor.
 //
 // Files are compressed using standard gzip format with .gz extension.
+// Decompression reads every member of multi-member files, such as those
+// produced by pigz or by concatenating .gz files.
 func NewGzipCompressor() Compressor {
 	return &gzipCompressor{}
 }
@@ -34,7 +40,12 @@ func (g *gzipCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
 }
 
 func (g *gzipCompressor) Decompress(r io.Reader) (io.ReadCloser, error) {
-	return gzip.NewReader(r)
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zr.Multistream(true)
+	return zr, nil
 }
 
 // -----------------------------------------------------------------------------
@@ -72,6 +83,114 @@ func (z *zstdCompressor) Decompress(r io.Reader) (io.ReadCloser, error) {
 	return decoder.IOReadCloser(), nil
 }
 
+// -----------------------------------------------------------------------------
+// Brotli Compressor
+// -----------------------------------------------------------------------------
+
+// BrotliDefaultQuality balances Brotli compression speed and size, matching
+// the Brotli library default.
+const BrotliDefaultQuality = 6
+
+// brotliCompressor implements Compressor using Brotli compression.
+type brotliCompressor struct {
+	quality int
+}
+
+// NewBrotliCompressor creates a Brotli compressor at the given quality,
+// from 0 (fastest) to 11 (smallest).
+//
+// Files are compressed using Brotli format with .br extension, the encoding
+// HTTP clients accept as Content-Encoding "br". Brotli typically yields
+// smaller text payloads than gzip at the cost of slower compression at high
+// quality. Decompression does not depend on quality.
+func NewBrotliCompressor(quality int) (Compressor, error) {
+	if quality < brotli.BestSpeed || quality > brotli.BestCompression {
+		return nil, fmt.Errorf("lode: brotli quality %d out of range [%d, %d]", quality, brotli.BestSpeed, brotli.BestCompression)
+	}
+	return &brotliCompressor{quality: quality}, nil
+}
+
+func (b *brotliCompressor) Name() string {
+	return "brotli"
+}
+
+func (b *brotliCompressor) Extension() string {
+	return ".br"
+}
+
+func (b *brotliCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return brotli.NewWriterLevel(w, b.quality), nil
+}
+
+func (b *brotliCompressor) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
+
+// -----------------------------------------------------------------------------
+// LZ4 Compressor
+// -----------------------------------------------------------------------------
+
+// lz4Compressor implements Compressor using LZ4 compression.
+type lz4Compressor struct{}
+
+// NewLZ4Compressor creates an LZ4 compressor.
+//
+// Files are compressed using the LZ4 frame format with .lz4 extension, which
+// interoperates with the lz4 command-line tool. LZ4 trades compression ratio
+// for speed: it compresses less than gzip or zstd but decompresses several
+// times faster, suiting pipelines bound by read throughput.
+func NewLZ4Compressor() Compressor {
+	return &lz4Compressor{}
+}
+
+func (l *lz4Compressor) Name() string {
+	return "lz4"
+}
+
+func (l *lz4Compressor) Extension() string {
+	return ".lz4"
+}
+
+func (l *lz4Compressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	return lz4.NewWriter(w), nil
+}
+
+func (l *lz4Compressor) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(lz4.NewReader(r)), nil
+}
+
+// -----------------------------------------------------------------------------
+// Bzip2 Compressor (read-only)
+// -----------------------------------------------------------------------------
+
+// bzip2Compressor implements Compressor for reading bzip2 data.
+type bzip2Compressor struct{}
+
+// NewBzip2Compressor creates a read-only bzip2 compressor for legacy data.
+//
+// The standard library only decompresses bzip2, so Compress returns
+// ErrCompressionWriteUnsupported: a dataset configured with this compressor
+// can read snapshots whose manifests record "bzip2" but cannot write.
+func NewBzip2Compressor() Compressor {
+	return &bzip2Compressor{}
+}
+
+func (b *bzip2Compressor) Name() string {
+	return "bzip2"
+}
+
+func (b *bzip2Compressor) Extension() string {
+	return ".bz2"
+}
+
+func (b *bzip2Compressor) Compress(io.Writer) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("lode: bzip2: %w", ErrCompressionWriteUnsupported)
+}
+
+func (b *bzip2Compressor) Decompress(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(bzip2.NewReader(r)), nil
+}
+
 // -----------------------------------------------------------------------------
 // NoOp Compressor
 // -----------------------------------------------------------------------------
@@ -110,3 +229,27 @@ type noopWriteCloser struct {
 func (n *noopWriteCloser) Close() error {
 	return nil
 }
+
+// -----------------------------------------------------------------------------
+// Built-in Compressor Lookup
+// -----------------------------------------------------------------------------
+
+// builtinCompressors maps each built-in compressor name to a constructor
+// with default settings.
+var builtinCompressors = map[string]func() (Compressor, error){
+	"noop":   func() (Compressor, error) { return NewNoOpCompressor(), nil },
+	"gzip":   func() (Compressor, error) { return NewGzipCompressor(), nil },
+	"zstd":   func() (Compressor, error) { return NewZstdCompressor(), nil },
+	"bzip2":  func() (Compressor, error) { return NewBzip2Compressor(), nil },
+	"brotli": func() (Compressor, error) { return NewBrotliCompressor(BrotliDefaultQuality) },
+	"lz4":    func() (Compressor, error) { return NewLZ4Compressor(), nil },
+}
+
+// builtinCompressor returns the built-in compressor registered under name.
+func builtinCompressor(name string) (Compressor, error) {
+	newCompressor, ok := builtinCompressors[name]
+	if !ok {
+		return nil, fmt.Errorf("lode: unknown compressor %q", name)
+	}
+	return newCompressor()
+}
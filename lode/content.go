@@ -0,0 +1,70 @@
+package lode
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// -----------------------------------------------------------------------------
+// HTTP Content Metadata
+// -----------------------------------------------------------------------------
+
+// codecContentTypes maps codec file extensions to media types. Codecs with
+// other extensions, and raw blobs, are recorded as application/octet-stream.
+var codecContentTypes = map[string]string{
+	".jsonl":   "application/x-ndjson",
+	".json":    "application/json",
+	".csv":     "text/csv",
+	".tsv":     "text/tab-separated-values",
+	".psv":     "text/plain",
+	".txt":     "text/plain",
+	".parquet": "application/vnd.apache.parquet",
+}
+
+// compressorContentEncodings maps compressor names to HTTP content codings.
+var compressorContentEncodings = map[string]string{
+	"gzip":   "gzip",
+	"zstd":   "zstd",
+	"brotli": "br",
+}
+
+// compressorContentTypes maps compressor names without an HTTP content
+// coding to the media type of their compressed output.
+var compressorContentTypes = map[string]string{
+	"lz4":   "application/x-lz4",
+	"bzip2": "application/x-bzip2",
+}
+
+// contentMetadata returns the FileRef ContentType and ContentEncoding for
+// data files written with codec (nil for raw blobs) and compressor.
+//
+// Compressors that HTTP clients can decode report a Content-Encoding over
+// the codec's media type. Others make the compressed format the media type,
+// since the body cannot be decoded transparently.
+func contentMetadata(codec Codec, compressor Compressor) (contentType, contentEncoding string) {
+	if ct, ok := compressorContentTypes[compressor.Name()]; ok {
+		return ct, ""
+	}
+	contentType = "application/octet-stream"
+	if codec != nil {
+		if ct, ok := codecContentTypes[codec.Extension()]; ok {
+			contentType = ct
+		}
+	}
+	return contentType, compressorContentEncodings[compressor.Name()]
+}
+
+// HTTPHeaders returns the headers for serving the file's stored bytes over
+// HTTP: Content-Type, Content-Encoding, and Content-Length. Content-Type and
+// Content-Encoding are omitted when the manifest did not record them.
+func (f FileRef) HTTPHeaders() http.Header {
+	h := make(http.Header)
+	if f.ContentType != "" {
+		h.Set("Content-Type", f.ContentType)
+	}
+	if f.ContentEncoding != "" {
+		h.Set("Content-Encoding", f.ContentEncoding)
+	}
+	h.Set("Content-Length", strconv.FormatInt(f.SizeBytes, 10))
+	return h
+}
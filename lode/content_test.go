@@ -0,0 +1,111 @@
+package lode
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDataset_Write_RecordsContentMetadata(t *testing.T) {
+	csv, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	gob, err := NewGobCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	brotli, err := NewBrotliCompressor(BrotliDefaultQuality)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		opts         []Option
+		data         []any
+		wantType     string
+		wantEncoding string
+	}{
+		{"jsonl", []Option{WithCodec(NewJSONLCodec())}, R(D{"a": 1}), "application/x-ndjson", ""},
+		{"jsonl gzip", []Option{WithCodec(NewJSONLCodec()), WithCompressor(NewGzipCompressor())}, R(D{"a": 1}), "application/x-ndjson", "gzip"},
+		{"csv zstd", []Option{WithCodec(csv), WithCompressor(NewZstdCompressor())}, R(D{"a": "1"}), "text/csv", "zstd"},
+		{"json array brotli", []Option{WithCodec(NewJSONArrayCodec()), WithCompressor(brotli)}, R(D{"a": 1}), "application/json", "br"},
+		{"jsonl lz4", []Option{WithCodec(NewJSONLCodec()), WithCompressor(NewLZ4Compressor())}, R(D{"a": 1}), "application/x-lz4", ""},
+		{"gob", []Option{WithCodec(gob)}, R(D{"a": 1}), "application/octet-stream", ""},
+		{"raw blob gzip", []Option{WithCompressor(NewGzipCompressor())}, []any{[]byte("blob")}, "application/octet-stream", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := NewDataset("ds", NewMemoryFactory(), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			snap, err := ds.Write(t.Context(), tt.data, Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := snap.Manifest.Files[0]
+			if f.ContentType != tt.wantType || f.ContentEncoding != tt.wantEncoding {
+				t.Errorf("content type %q encoding %q, want %q %q", f.ContentType, f.ContentEncoding, tt.wantType, tt.wantEncoding)
+			}
+
+			h := f.HTTPHeaders()
+			if got := h.Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type header = %q, want %q", got, tt.wantType)
+			}
+			if got := h.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding header = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := h.Get("Content-Length"); got != strconv.FormatInt(f.SizeBytes, 10) {
+				t.Errorf("Content-Length header = %q, want %d", got, f.SizeBytes)
+			}
+		})
+	}
+}
+
+func TestDataset_StreamWrite_RecordsContentMetadata(t *testing.T) {
+	ds, err := NewDataset("ds", NewMemoryFactory(), WithCompressor(NewGzipCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sw.Write([]byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := snap.Manifest.Files[0]
+	if f.ContentType != "application/octet-stream" || f.ContentEncoding != "gzip" {
+		t.Errorf("content type %q encoding %q, want application/octet-stream gzip", f.ContentType, f.ContentEncoding)
+	}
+}
+
+func TestFileRef_HTTPHeaders_LegacyManifest(t *testing.T) {
+	// Manifests written before content metadata existed still validate and
+	// produce only Content-Length.
+	m := &Manifest{
+		SchemaName:    manifestSchemaName,
+		FormatVersion: manifestFormatVersion,
+		DatasetID:     "ds",
+		SnapshotID:    "snap",
+		CreatedAt:     time.Now().UTC(),
+		Metadata:      Metadata{},
+		Files:         []FileRef{{Path: "ds/snapshots/snap/data/data.jsonl", SizeBytes: 12}},
+		Compressor:    "noop",
+		Partitioner:   "noop",
+	}
+	if err := validateManifest(m); err != nil {
+		t.Fatal(err)
+	}
+	want := http.Header{"Content-Length": {"12"}}
+	if got := m.Files[0].HTTPHeaders(); !reflect.DeepEqual(got, want) {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -24,10 +26,31 @@ const (
 
 // datasetConfig holds the resolved configuration for a dataset.
 type datasetConfig struct {
-	layout     layout
-	compressor Compressor
-	codec      Codec
-	checksum   Checksum
+	layout       layout
+	compressor   Compressor
+	codec        Codec
+	checksum     Checksum
+	codecs       *CodecRegistry
+	pretty       bool
+	manifestC    Compressor
+	schema       *Schema
+	tsField      string
+	tsFormats    []string
+	namer        FileNamer
+	onDecode     decodeErrorPolicy
+	dedup        bool
+	manifestName string
+	uploads      int
+	verifyBounds bool
+	committer    Committer
+	writeStats   bool
+	appendOnly   bool
+	ordered      bool
+	orderKey     string
+	success      bool
+	maxParts     int
+	uniqueKey    string
+	locker       Locker
 }
 
 // Option configures dataset or reader construction.
@@ -68,6 +91,55 @@ func (o *layoutOption) applyReader(cfg *readerConfig) error {
 	return nil
 }
 
+// manifestFilenameOption implements Option for WithManifestFilename.
+type manifestFilenameOption struct {
+	name string
+}
+
+// WithManifestFilename names manifest objects name instead of manifest.json
+// in the configured layout, whichever option sets the layout.
+// Default: "manifest.json".
+//
+// Writers and readers of a dataset must use the same name: manifests under
+// any other name are not recognized (DebugParseManifestPath reports
+// ManifestPathWrongFilename). The name must be a single path component
+// other than "latest", which is reserved for the latest pointer.
+//
+// Example:
+//
+//	ds, err := lode.NewDataset("events", factory,
+//	    lode.WithManifestFilename("meta.json"),
+//	    lode.WithCodec(lode.NewJSONLCodec()),
+//	)
+//	reader, err := lode.NewDatasetReader(factory, lode.WithManifestFilename("meta.json"))
+func WithManifestFilename(name string) Option {
+	return &manifestFilenameOption{name: name}
+}
+
+func (o *manifestFilenameOption) validate() error {
+	switch {
+	case o.name == "", o.name == ".", o.name == "..", o.name == "latest", strings.Contains(o.name, "/"):
+		return fmt.Errorf("WithManifestFilename: invalid name %q: must be a single path component other than \"latest\"", o.name)
+	}
+	return nil
+}
+
+func (o *manifestFilenameOption) applyDataset(cfg *datasetConfig) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+	cfg.manifestName = o.name
+	return nil
+}
+
+func (o *manifestFilenameOption) applyReader(cfg *readerConfig) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+	cfg.manifestName = o.name
+	return nil
+}
+
 // hiveLayoutOption implements Option for WithHiveLayout.
 type hiveLayoutOption struct {
 	keys []string
@@ -162,7 +234,9 @@ type checksumOption struct {
 // This option is only valid for NewDataset.
 //
 // When a checksum is configured, checksums are computed during write
-// and recorded in the manifest for each data file.
+// and recorded in the manifest for each data file. Checksums cover the
+// bytes as stored, after compression, so they verify the stored object
+// directly. The algorithm name is recorded once as ChecksumAlgorithm.
 func WithChecksum(c Checksum) Option {
 	return &checksumOption{checksum: c}
 }
@@ -176,6 +250,355 @@ func (o *checksumOption) applyReader(*readerConfig) error {
 	return fmt.Errorf("WithChecksum: %w", ErrOptionNotValidForDatasetReader)
 }
 
+// codecRegistryOption implements Option for WithCodecRegistry (dataset-only).
+type codecRegistryOption struct {
+	registry *CodecRegistry
+}
+
+// WithCodecRegistry sets the codec registry used on the read path.
+// Default: none (the snapshot codec must match the configured codec).
+// This option is only valid for NewDataset.
+//
+// When a registry is set, Read selects the codec by the name recorded in
+// the snapshot manifest. Snapshots whose codec differs from the configured
+// codec are decoded with the registered codec instead of failing with a
+// codec mismatch. Unregistered codec names return ErrUnknownCodec. The
+// compressor is likewise taken from the manifest, among the built-in
+// compressors, so a dataset configured with only a registry reads any
+// snapshot.
+//
+// The registry does not affect writes; Write always uses WithCodec.
+func WithCodecRegistry(r *CodecRegistry) Option {
+	return &codecRegistryOption{registry: r}
+}
+
+func (o *codecRegistryOption) applyDataset(cfg *datasetConfig) error {
+	if o.registry == nil {
+		return errors.New("WithCodecRegistry: registry must not be nil")
+	}
+	cfg.codecs = o.registry
+	return nil
+}
+
+func (o *codecRegistryOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithCodecRegistry: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// prettyManifestOption implements Option for WithPrettyManifest (dataset-only).
+type prettyManifestOption struct {
+	enabled bool
+}
+
+// WithPrettyManifest controls whether committed manifests are indented.
+// Default: true (indented for inspection in object browsers).
+// This option is only valid for NewDataset.
+//
+// Reads accept both compact and indented manifests. Use ManifestChecksum
+// for a checksum that does not depend on this setting.
+func WithPrettyManifest(enabled bool) Option {
+	return &prettyManifestOption{enabled: enabled}
+}
+
+func (o *prettyManifestOption) applyDataset(cfg *datasetConfig) error {
+	cfg.pretty = o.enabled
+	return nil
+}
+
+func (o *prettyManifestOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithPrettyManifest: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// manifestCompressorOption implements Option for WithManifestCompressor (dataset-only).
+type manifestCompressorOption struct {
+	compressor Compressor
+}
+
+// WithManifestCompressor compresses committed manifest objects at rest.
+// Default: NewNoOpCompressor() (plain JSON).
+// This option is only valid for NewDataset.
+//
+// Only the built-in gzip, zstd, and noop compressors are accepted: readers
+// detect a compressed manifest from its header, so manifests stay at the
+// layout's manifest.json path and old uncompressed manifests still load.
+// The decoded Manifest, its validation, and ManifestChecksum are unaffected.
+func WithManifestCompressor(c Compressor) Option {
+	return &manifestCompressorOption{compressor: c}
+}
+
+func (o *manifestCompressorOption) applyDataset(cfg *datasetConfig) error {
+	switch o.compressor.(type) {
+	case *gzipCompressor, *zstdCompressor, *noopCompressor:
+		cfg.manifestC = o.compressor
+		return nil
+	case nil:
+		return errors.New("WithManifestCompressor: compressor must not be nil")
+	default:
+		return fmt.Errorf("WithManifestCompressor: unsupported compressor %q (use gzip, zstd, or noop)", o.compressor.Name())
+	}
+}
+
+func (o *manifestCompressorOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithManifestCompressor: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// FileNamer returns the leaf name, without extension, of the index-th data
+// file written to a partition of a snapshot. partition is "" for
+// unpartitioned files. Index counts from 0 and exceeds 0 only when one
+// write splits a partition into several files (Compact with TargetFileBytes).
+type FileNamer func(partition string, index int) string
+
+// fileNamerOption implements Option for WithFileNamer (dataset-only).
+type fileNamerOption struct {
+	namer FileNamer
+}
+
+// WithFileNamer sets the naming scheme for data files.
+// Default: "data" (or "blob" in raw blob mode), with "-NNNN" suffixes when
+// Compact splits a partition.
+// This option is only valid for NewDataset.
+//
+// The writer appends the codec extension (none in raw blob mode) and the
+// compressor extension, so a namer returning fmt.Sprintf("part-%05d", i+1)
+// yields "part-00001.jsonl.gz" with the JSONL codec and gzip. Names must be
+// non-empty, must not contain "/", and must be unique within a partition;
+// a violating name fails the write before the manifest is committed.
+func WithFileNamer(namer FileNamer) Option {
+	return &fileNamerOption{namer: namer}
+}
+
+func (o *fileNamerOption) applyDataset(cfg *datasetConfig) error {
+	if o.namer == nil {
+		return errors.New("WithFileNamer: namer must not be nil")
+	}
+	cfg.namer = o.namer
+	return nil
+}
+
+func (o *fileNamerOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithFileNamer: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// dedupOption implements Option for WithDedup (dataset-only).
+type dedupOption struct {
+	enabled bool
+}
+
+// WithDedup makes Write and Append reference an identical data file of the
+// parent snapshot instead of uploading a new copy.
+// Default: false.
+// This option is only valid for NewDataset and requires WithChecksum.
+//
+// The parent manifest is the dedup index: a newly encoded file is shared
+// when a parent file in the same partition has the same checksum and size,
+// and the parent used the same checksum algorithm, codec, and compressor.
+// The new manifest's FileRef then points at the existing object, which
+// stays under the segment that first wrote it. Loading the parent manifest
+// costs one extra Get per write. StreamWrite, StreamWriteRecords, and
+// Compact always upload.
+//
+// A segment's data objects may therefore be referenced by later snapshots.
+// Do not garbage-collect a segment by deleting its data prefix, for example
+// after Compact, unless no retained manifest has a FileRef under it.
+// DatasetReader.VerifySegment reports a descendant whose shared files were
+// deleted as Missing.
+func WithDedup(enabled bool) Option {
+	return &dedupOption{enabled: enabled}
+}
+
+func (o *dedupOption) applyDataset(cfg *datasetConfig) error {
+	cfg.dedup = o.enabled
+	return nil
+}
+
+func (o *dedupOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithDedup: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// successMarkerOption implements Option for WithSuccessMarker (dataset-only).
+type successMarkerOption struct {
+	enabled bool
+}
+
+// WithSuccessMarker controls whether each commit writes an empty _SUCCESS
+// object next to the snapshot's canonical manifest, for Hadoop and Spark
+// tools that wait for one before reading a directory.
+// Default: false.
+// This option is only valid for NewDataset.
+//
+// The marker is written only after every manifest object is committed, so
+// its presence implies a complete snapshot. Manifest discovery ignores it.
+// A failed marker Put is returned as a *SuccessMarkerError carrying the
+// committed snapshot.
+func WithSuccessMarker(enabled bool) Option {
+	return &successMarkerOption{enabled: enabled}
+}
+
+func (o *successMarkerOption) applyDataset(cfg *datasetConfig) error {
+	cfg.success = o.enabled
+	return nil
+}
+
+func (o *successMarkerOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithSuccessMarker: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// SuccessMarkerError reports a commit whose _SUCCESS marker could not be
+// written. The snapshot is committed and readable; writing the records
+// again would create a second snapshot.
+type SuccessMarkerError struct {
+	// Snapshot is the committed snapshot.
+	Snapshot *DatasetSnapshot
+	// Path is the marker key.
+	Path string
+	// Err is the marker Put error.
+	Err error
+}
+
+func (e *SuccessMarkerError) Error() string {
+	return fmt.Sprintf("lode: snapshot %s committed, failed to write success marker %s: %v", e.Snapshot.ID, e.Path, e.Err)
+}
+
+func (e *SuccessMarkerError) Unwrap() error {
+	return e.Err
+}
+
+// maxPartitionsOption implements Option for WithMaxPartitions (dataset-only).
+type maxPartitionsOption struct {
+	n int
+}
+
+// WithMaxPartitions limits how many partitions a single Write, Append, or
+// Compact may produce. A write over the limit fails with a
+// *TooManyPartitionsError before any object is stored, guarding against
+// partitioning by a high-cardinality field.
+// Default: 0 (no limit).
+// This option is only valid for NewDataset.
+func WithMaxPartitions(n int) Option {
+	return &maxPartitionsOption{n: n}
+}
+
+func (o *maxPartitionsOption) applyDataset(cfg *datasetConfig) error {
+	if o.n < 0 {
+		return fmt.Errorf("WithMaxPartitions: limit must be non-negative, got %d", o.n)
+	}
+	cfg.maxParts = o.n
+	return nil
+}
+
+func (o *maxPartitionsOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithMaxPartitions: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// TooManyPartitionsError reports a write that would exceed
+// WithMaxPartitions. It matches ErrTooManyPartitions.
+type TooManyPartitionsError struct {
+	// Partitions is the number of partitions the write would produce.
+	Partitions int
+	// Limit is the configured maximum.
+	Limit int
+}
+
+func (e *TooManyPartitionsError) Error() string {
+	return fmt.Sprintf("lode: write produces %d partitions, limit is %d", e.Partitions, e.Limit)
+}
+
+func (e *TooManyPartitionsError) Unwrap() error {
+	return ErrTooManyPartitions
+}
+
+// writeConcurrencyOption implements Option for WithWriteConcurrency (dataset-only).
+type writeConcurrencyOption struct {
+	n int
+}
+
+// WithWriteConcurrency sets how many data files Write and Append upload in
+// parallel. Default: 1 (sequential).
+// This option is only valid for NewDataset.
+//
+// Files are encoded one at a time and handed to at most n concurrent Puts,
+// so at most n+1 encoded files are held in memory. The first failed upload
+// cancels the rest; objects already stored are cleaned up like any failed
+// write. Manifests are written only after every upload has succeeded.
+func WithWriteConcurrency(n int) Option {
+	return &writeConcurrencyOption{n: n}
+}
+
+func (o *writeConcurrencyOption) applyDataset(cfg *datasetConfig) error {
+	if o.n < 1 {
+		return fmt.Errorf("WithWriteConcurrency: concurrency must be positive, got %d", o.n)
+	}
+	cfg.uploads = o.n
+	return nil
+}
+
+func (o *writeConcurrencyOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithWriteConcurrency: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// DecodeErrorMode selects how reads handle a record that fails to decode.
+type DecodeErrorMode int
+
+const (
+	// DecodeErrorFail fails the read at the first undecodable record.
+	DecodeErrorFail DecodeErrorMode = iota
+	// DecodeErrorSkip drops undecodable records and continues.
+	DecodeErrorSkip
+	// DecodeErrorCallback passes each undecodable record's *DecodeError to
+	// a callback, then drops the record and continues.
+	DecodeErrorCallback
+)
+
+// decodeErrorPolicy is the resolved WithOnDecodeError configuration.
+type decodeErrorPolicy struct {
+	mode     DecodeErrorMode
+	callback func(*DecodeError)
+}
+
+// onDecodeErrorOption implements Option for WithOnDecodeError (dataset-only).
+type onDecodeErrorOption struct {
+	policy decodeErrorPolicy
+}
+
+// WithOnDecodeError sets how reads handle records that fail to decode.
+// Default: DecodeErrorFail.
+// This option is only valid for NewDataset.
+//
+// Tolerance applies to JSONL snapshots, where each line is a separate
+// record: a line that is not valid JSON (or, for ReadTyped, does not decode
+// into T) or that exceeds WithJSONLMaxLineBytes is dropped. Handling is per
+// file: DecodeError line numbers count from the start of each file, and a
+// skipped line never affects other files. I/O and decompression errors
+// still fail the read, as do decode errors in other codecs, whose record
+// boundaries cannot be recovered. Compact never drops records.
+//
+// fn is called with DecodeErrorCallback and must be nil otherwise. Use
+// FileRecordIterator.SkippedCount, or count in fn, to enforce data-quality
+// thresholds.
+func WithOnDecodeError(mode DecodeErrorMode, fn func(*DecodeError)) Option {
+	return &onDecodeErrorOption{policy: decodeErrorPolicy{mode: mode, callback: fn}}
+}
+
+func (o *onDecodeErrorOption) applyDataset(cfg *datasetConfig) error {
+	switch o.policy.mode {
+	case DecodeErrorFail, DecodeErrorSkip:
+		if o.policy.callback != nil {
+			return errors.New("WithOnDecodeError: callback requires DecodeErrorCallback")
+		}
+	case DecodeErrorCallback:
+		if o.policy.callback == nil {
+			return errors.New("WithOnDecodeError: DecodeErrorCallback requires a callback")
+		}
+	default:
+		return fmt.Errorf("WithOnDecodeError: unknown mode %d", o.policy.mode)
+	}
+	cfg.onDecode = o.policy
+	return nil
+}
+
+func (o *onDecodeErrorOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithOnDecodeError: %w", ErrOptionNotValidForDatasetReader)
+}
+
 // -----------------------------------------------------------------------------
 // Dataset Implementation
 // -----------------------------------------------------------------------------
@@ -188,6 +611,26 @@ type dataset struct {
 	compressor Compressor
 	codec      Codec
 	checksum   Checksum
+	codecs     *CodecRegistry
+	pretty     bool
+	manifestC  Compressor
+	schema     *Schema
+	timer      recordTimer // nil when records carry no parsed timestamp
+	namer      FileNamer
+	onDecode   decodeErrorPolicy
+	dedup      bool
+	uploads    int // concurrent data file uploads per write
+
+	verifyBounds bool // check records against manifest timestamp bounds on read
+	committer    Committer
+	writeStats   bool // report WriteStats from Write and Append
+	appendOnly   bool // reject replacing or deleting committed manifests
+	ordered      bool // declare ordering in written manifests
+	orderKey     string
+	success      bool   // write a _SUCCESS marker after each manifest commit
+	maxParts     int    // partitions allowed per write; 0 means no limit
+	uniqueKey    string // drop records repeating this field's value within a write
+	locker       Locker // serializes commits within the process
 
 	// lastSnapshotID is set after each successful commit. It guards against
 	// stale-but-existing pointers: if the pointer write fails after a commit,
@@ -195,6 +638,12 @@ type dataset struct {
 	// field, the next write would trust the stale pointer and break linear
 	// history. Single-writer constraint means no mutex is required.
 	lastSnapshotID DatasetSnapshotID
+
+	// lastPointerID is the latest pointer content written by this handle.
+	// It is the expected value for the next compare-and-swap when parent
+	// resolution is served from the in-memory cache. It may run ahead of
+	// lastSnapshotID when a manifest write fails after the pointer swap.
+	lastPointerID DatasetSnapshotID
 }
 
 // NewDataset creates a dataset with documented defaults.
@@ -209,6 +658,15 @@ type dataset struct {
 //   - WithCompressor(c) to use compression
 //   - WithCodec(c) to use structured records with a codec
 //   - WithChecksum(c) to enable file checksums
+//   - WithCodecRegistry(r) to select the read codec from the manifest
+//   - WithPrettyManifest(false) to write compact manifests
+//   - WithSchema(s) to validate records before encoding
+//   - WithTimestampField(f) to record manifest timestamps from a field
+//   - WithFileNamer(n) to control data file names
+//   - WithWriteConcurrency(n) to upload data files in parallel
+//   - WithCommitter(c) to control how manifests are committed
+//   - WithAppendOnly(true) to make committed snapshots immutable
+//   - WithLocker(l) to serialize commits within the process
 func NewDataset(id DatasetID, factory StoreFactory, opts ...Option) (Dataset, error) {
 	if factory == nil {
 		return nil, errors.New("lode: store factory is required")
@@ -226,6 +684,11 @@ func NewDataset(id DatasetID, factory StoreFactory, opts ...Option) (Dataset, er
 		layout:     NewDefaultLayout(),
 		compressor: NewNoOpCompressor(),
 		codec:      nil,
+		pretty:     true,
+		manifestC:  NewNoOpCompressor(),
+		uploads:    1,
+		committer:  NewPutCommitter(),
+		locker:     NewNoOpLocker(),
 	}
 
 	for _, opt := range opts {
@@ -237,6 +700,9 @@ func NewDataset(id DatasetID, factory StoreFactory, opts ...Option) (Dataset, er
 	if cfg.layout == nil {
 		return nil, errors.New("lode: layout must not be nil")
 	}
+	if cfg.manifestName != "" {
+		cfg.layout = cfg.layout.withManifestName(cfg.manifestName)
+	}
 	if cfg.compressor == nil {
 		return nil, errors.New("lode: compressor must not be nil")
 	}
@@ -244,6 +710,36 @@ func NewDataset(id DatasetID, factory StoreFactory, opts ...Option) (Dataset, er
 	if cfg.codec == nil && !cfg.layout.partitioner().isNoop() {
 		return nil, errors.New("lode: raw blob mode (no codec) requires a layout with noop partitioner")
 	}
+	if cfg.codec == nil && cfg.schema != nil {
+		return nil, errors.New("lode: WithSchema requires a codec")
+	}
+	if cfg.codec == nil && cfg.tsField != "" {
+		return nil, errors.New("lode: WithTimestampField requires a codec")
+	}
+	if cfg.tsFormats != nil && cfg.tsField == "" {
+		return nil, errors.New("lode: WithTimestampFormats requires WithTimestampField")
+	}
+	if cfg.codec == nil && cfg.ordered {
+		return nil, errors.New("lode: WithOrdering requires a codec")
+	}
+	if cfg.codec == nil && cfg.uniqueKey != "" {
+		return nil, errors.New("lode: WithRecordDedup requires a codec")
+	}
+	if cfg.dedup && cfg.checksum == nil {
+		return nil, errors.New("lode: WithDedup requires WithChecksum")
+	}
+
+	// An explicit timestamp field takes precedence over a time-range partitioner.
+	timer, _ := cfg.layout.partitioner().(recordTimer)
+	if cfg.tsField != "" {
+		timer = timestampField(cfg.tsField)
+	}
+	if cfg.tsFormats != nil {
+		timer = formattedTimestampField{field: cfg.tsField, formats: cfg.tsFormats}
+	}
+	if cfg.verifyBounds && timer == nil {
+		return nil, errors.New("lode: WithVerifyTimeBounds requires WithTimestampField or a time-range layout")
+	}
 
 	return &dataset{
 		id:         id,
@@ -252,6 +748,26 @@ func NewDataset(id DatasetID, factory StoreFactory, opts ...Option) (Dataset, er
 		compressor: cfg.compressor,
 		codec:      cfg.codec,
 		checksum:   cfg.checksum,
+		codecs:     cfg.codecs,
+		pretty:     cfg.pretty,
+		manifestC:  cfg.manifestC,
+		schema:     cfg.schema,
+		timer:      timer,
+		namer:      cfg.namer,
+		onDecode:   cfg.onDecode,
+		dedup:      cfg.dedup,
+		uploads:    cfg.uploads,
+
+		verifyBounds: cfg.verifyBounds,
+		committer:    cfg.committer,
+		writeStats:   cfg.writeStats,
+		appendOnly:   cfg.appendOnly,
+		ordered:      cfg.ordered,
+		orderKey:     cfg.orderKey,
+		success:      cfg.success,
+		maxParts:     cfg.maxParts,
+		uniqueKey:    cfg.uniqueKey,
+		locker:       cfg.locker,
 	}, nil
 }
 
@@ -260,18 +776,51 @@ func (d *dataset) ID() DatasetID {
 }
 
 // resolveParentID resolves the most recent snapshot ID for parent linking.
+// It also returns the latest pointer content observed during resolution,
+// which is the expected value for the compare-and-swap at commit time.
 //
 // Resolution order:
 //  1. In-memory cache (always correct within a process; guards against stale pointers)
 //  2. Persistent pointer + Exists verification (O(1) cold start)
 //  3. Full scan fallback (backward compat for pre-pointer datasets)
-func (d *dataset) resolveParentID(ctx context.Context) (DatasetSnapshotID, error) {
+func (d *dataset) resolveParentID(ctx context.Context) (parentID, pointerID DatasetSnapshotID, err error) {
 	// In-memory cache is authoritative within this process.
 	// It guards against stale-but-existing pointers after a pointer write failure.
 	if d.lastSnapshotID != "" {
-		return d.lastSnapshotID, nil
+		return d.lastSnapshotID, d.lastPointerID, nil
+	}
+	return d.resolveStoredParentID(ctx)
+}
+
+// resolveLockedParentID resolves the parent of a commit made under the
+// dataset lock. Other handles sharing a Locker may have committed since this
+// handle's last write, so the cache is bypassed unless locking is disabled.
+func (d *dataset) resolveLockedParentID(ctx context.Context) (parentID, pointerID DatasetSnapshotID, err error) {
+	if _, ok := d.locker.(noopLocker); ok {
+		return d.resolveParentID(ctx)
+	}
+	return d.resolveStoredParentID(ctx)
+}
+
+// resolveStoredParentID resolves the parent from storage, bypassing the
+// in-memory cache. Steps 2 and 3 of resolveParentID.
+func (d *dataset) resolveStoredParentID(ctx context.Context) (parentID, pointerID DatasetSnapshotID, err error) {
+	parentID, pointerID, err = d.lookupStoredParentID(ctx)
+	if err != nil || parentID == "" || parentID == pointerID {
+		return parentID, pointerID, err
+	}
+
+	// Resolved by scan. Self-heal: write the pointer so subsequent calls are O(1).
+	if err := d.writeLatestPointer(ctx, pointerID, parentID); err == nil {
+		pointerID = parentID
 	}
+	return parentID, pointerID, nil
+}
 
+// lookupStoredParentID resolves the parent from storage without repairing
+// the latest pointer. The returned pointerID equals parentID only when the
+// pointer was valid.
+func (d *dataset) lookupStoredParentID(ctx context.Context) (parentID, pointerID DatasetSnapshotID, err error) {
 	id, err := d.readLatestPointer(ctx)
 	if err == nil {
 		// Verify the referenced snapshot exists (1 Exists call).
@@ -279,20 +828,22 @@ func (d *dataset) resolveParentID(ctx context.Context) (DatasetSnapshotID, error
 		manifestPath := d.layout.manifestPath(d.id, id)
 		exists, existsErr := d.store.Exists(ctx, manifestPath)
 		if existsErr == nil && exists {
-			return id, nil
+			return id, id, nil
 		}
 		// Pointer is stale or corrupt — fall through to scan.
+		pointerID = id
 	}
 
 	// Pointer missing or stale: fall back to scan for backward compat.
 	latest, err := d.latestByScan(ctx)
 	if err != nil {
 		if errors.Is(err, ErrNoSnapshots) {
-			return "", nil
+			return "", pointerID, nil
 		}
-		return "", fmt.Errorf("lode: failed to get latest snapshot: %w", err)
+		return "", "", fmt.Errorf("lode: failed to get latest snapshot: %w", err)
 	}
-	return latest.ID, nil
+
+	return latest.ID, pointerID, nil
 }
 
 // readLatestPointer reads the persistent latest-snapshot pointer file.
@@ -318,197 +869,1100 @@ func (d *dataset) readLatestPointer(ctx context.Context) (DatasetSnapshotID, err
 }
 
 // writeLatestPointer persists the snapshot ID as the latest pointer.
-// Uses Delete+Put because Store.Put is no-overwrite.
-func (d *dataset) writeLatestPointer(ctx context.Context, id DatasetSnapshotID) error {
+//
+// When the store implements ConditionalWriter, the pointer is swapped
+// atomically against expected and a concurrent commit surfaces as
+// ErrSnapshotConflict. Otherwise falls back to resetLatestPointer.
+func (d *dataset) writeLatestPointer(ctx context.Context, expected, id DatasetSnapshotID) error {
+	cw, ok := d.store.(ConditionalWriter)
+	if !ok {
+		return d.resetLatestPointer(ctx, id)
+	}
+	pointerPath := d.layout.latestPointerPath(d.id)
+	if err := cw.CompareAndSwap(ctx, pointerPath, string(expected), string(id)); err != nil {
+		return err
+	}
+	d.lastPointerID = id
+	return nil
+}
+
+// resetLatestPointer unconditionally persists the snapshot ID as the latest
+// pointer. Uses Delete+Put because Store.Put is no-overwrite.
+func (d *dataset) resetLatestPointer(ctx context.Context, id DatasetSnapshotID) error {
 	pointerPath := d.layout.latestPointerPath(d.id)
 	_ = d.store.Delete(ctx, pointerPath) // ignore error; path may not exist
-	return d.store.Put(ctx, pointerPath, strings.NewReader(string(id)))
+	if err := d.store.Put(ctx, pointerPath, strings.NewReader(string(id))); err != nil {
+		return err
+	}
+	d.lastPointerID = id
+	return nil
 }
 
 func (d *dataset) Write(ctx context.Context, data []any, metadata Metadata) (*DatasetSnapshot, error) {
-	if metadata == nil {
-		metadata = Metadata{}
+	unlock, err := d.lock(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	parentID, err := d.resolveParentID(ctx)
+	parentID, pointerID, err := d.resolveLockedParentID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return d.write(ctx, data, metadata, parentID, pointerID)
+}
 
-	snapshotID := DatasetSnapshotID(generateID())
-
-	var files []FileRef
-	var rowCount int64
-	var partitionKeys []string
-	var codecName string
+// Append commits records as a new snapshot whose parent is the dataset's
+// current latest snapshot in storage.
+//
+// Unlike Write, Append always re-resolves the parent from the store rather
+// than this handle's in-memory cache, so it links to snapshots committed by
+// other writers. When the store implements ConditionalWriter, a concurrent
+// commit between resolution and commit returns ErrSnapshotConflict.
+func (d *dataset) Append(ctx context.Context, data []any, metadata Metadata) (*DatasetSnapshot, error) {
+	unlock, err := d.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	if d.codec == nil {
-		// Raw blob mode
-		if len(data) != 1 {
-			return nil, errors.New("lode: raw blob mode requires exactly one data element")
-		}
-		blob, ok := data[0].([]byte)
-		if !ok {
-			return nil, fmt.Errorf("lode: raw blob mode requires []byte, got %T", data[0])
-		}
+	parentID, pointerID, err := d.resolveStoredParentID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.write(ctx, data, metadata, parentID, pointerID)
+}
 
-		fileRef, err := d.writeRawBlob(ctx, snapshotID, blob)
-		if err != nil {
-			return nil, fmt.Errorf("lode: failed to write blob: %w", err)
-		}
-		files = []FileRef{fileRef}
-		rowCount = 1
-		partitionKeys = []string{""}
-		codecName = ""
-	} else {
-		// Structured records mode
-		partitions, err := d.partitionRecords(data)
+// PlanWrite computes the manifest and object keys Write would produce
+// without calling Put or Delete on the store. The parent is resolved like
+// Write, except that a stale latest pointer is not repaired.
+func (d *dataset) PlanWrite(ctx context.Context, data []any, metadata Metadata) (*WritePlan, error) {
+	parentID := d.lastSnapshotID
+	if parentID == "" {
+		var err error
+		parentID, _, err = d.lookupStoredParentID(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("lode: partitioning failed: %w", err)
-		}
-
-		for partKey, partRecords := range partitions {
-			fileRef, err := d.writeDataFile(ctx, snapshotID, partKey, partRecords)
-			if err != nil {
-				return nil, fmt.Errorf("lode: failed to write data file: %w", err)
-			}
-			files = append(files, fileRef)
-			partitionKeys = append(partitionKeys, partKey)
+			return nil, err
 		}
-
-		rowCount = int64(len(data))
-		codecName = d.codec.Name()
 	}
 
-	// Extract timestamps from records that implement Timestamped
-	minTs, maxTs := extractTimestamps(data)
+	manifest, partitionKeys, err := d.stageSnapshot(ctx, data, metadata, parentID, discardObject, nil)
+	if err != nil {
+		return nil, err
+	}
 
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].Path < files[j].Path
-	})
+	dataPaths := make([]string, len(manifest.Files))
+	for i, f := range manifest.Files {
+		dataPaths[i] = f.Path
+	}
+	return &WritePlan{
+		Manifest:      manifest,
+		DataPaths:     dataPaths,
+		ManifestPaths: d.manifestPaths(manifest.SnapshotID, partitionKeys),
+	}, nil
+}
 
-	manifest := &Manifest{
-		SchemaName:       manifestSchemaName,
-		FormatVersion:    manifestFormatVersion,
-		DatasetID:        d.id,
-		SnapshotID:       snapshotID,
-		CreatedAt:        time.Now().UTC(),
-		Metadata:         metadata,
-		Files:            files,
-		ParentSnapshotID: parentID,
-		RowCount:         rowCount,
-		MinTimestamp:     minTs,
-		MaxTimestamp:     maxTs,
-		Codec:            codecName,
-		Compressor:       d.compressor.Name(),
-		Partitioner:      d.layout.partitioner().name(),
+// write encodes data and commits a snapshot linked to parentID.
+// pointerID is the expected latest pointer content for the commit CAS.
+//
+// Data objects stored before a failed commit are deleted on a best-effort
+// basis; see cleanupStaged.
+func (d *dataset) write(ctx context.Context, data []any, metadata Metadata, parentID, pointerID DatasetSnapshotID) (*DatasetSnapshot, error) {
+	var (
+		mu     sync.Mutex
+		staged []string
+		rec    *writeStatsRecorder
+	)
+	if d.writeStats {
+		rec = &writeStatsRecorder{}
+	}
+	put := func(ctx context.Context, path string, data []byte) error {
+		var start time.Time
+		if rec != nil {
+			start = time.Now()
+		}
+		if err := d.putObject(ctx, path, data); err != nil {
+			return err
+		}
+		if rec != nil {
+			rec.recordUpload(time.Since(start))
+		}
+		mu.Lock()
+		staged = append(staged, path)
+		mu.Unlock()
+		return nil
 	}
-	if d.checksum != nil {
-		manifest.ChecksumAlgorithm = d.checksum.Name()
+
+	manifest, partitionKeys, err := d.stageSnapshot(ctx, data, metadata, parentID, put, rec)
+	if err != nil {
+		return nil, d.cleanupStaged(ctx, staged, err)
 	}
+	snapshotID := manifest.SnapshotID
 
 	// Pointer must be written before manifest to prevent stale-but-existing
 	// pointers on cold start. If this fails, no manifest is written and the
 	// commit is aborted. A pointer referencing a not-yet-existing snapshot is
 	// harmless (Exists check falls through to scan on the next cold start).
-	if err := d.writeLatestPointer(ctx, snapshotID); err != nil {
-		return nil, fmt.Errorf("lode: failed to update latest pointer: %w", err)
+	if err := d.writeLatestPointer(ctx, pointerID, snapshotID); err != nil {
+		return nil, d.cleanupStaged(ctx, staged, fmt.Errorf("lode: failed to update latest pointer: %w", err))
 	}
 
 	if err := d.writeManifests(ctx, snapshotID, manifest, partitionKeys); err != nil {
-		return nil, fmt.Errorf("lode: failed to write manifest: %w", err)
+		return nil, d.cleanupUncommitted(ctx, staged, snapshotID, partitionKeys, fmt.Errorf("lode: failed to write manifest: %w", err))
+	}
+	d.lastSnapshotID = snapshotID
+
+	return d.finishCommit(ctx, &DatasetSnapshot{
+		ID:         snapshotID,
+		Manifest:   manifest,
+		WriteStats: rec.result(len(manifest.Files)),
+		layout:     d.layout,
+	})
+}
+
+// cleanupStaged deletes data objects stored by a write that failed before
+// its manifest was committed, and annotates cause with the outcome. cause
+// remains the wrapped error. Deletion ignores ctx cancellation so a
+// canceled write still cleans up.
+func (d *dataset) cleanupStaged(ctx context.Context, paths []string, cause error) error {
+	if len(paths) == 0 {
+		return cause
+	}
+	ctx = context.WithoutCancel(ctx)
+	var failed []string
+	for _, p := range paths {
+		if err := d.store.Delete(ctx, p); err != nil && !errors.Is(err, ErrNotFound) {
+			failed = append(failed, fmt.Sprintf("%s: %v", p, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%w (cleanup failed for %d of %d partial objects: %s)", cause, len(failed), len(paths), strings.Join(failed, "; "))
+	}
+	return fmt.Errorf("%w (cleaned up %d partial objects)", cause, len(paths))
+}
+
+// cleanupUncommitted handles a failed manifest write. The first manifest
+// Put is the commit point. A failed Put may still have stored the object,
+// so staged data is only removed once the manifest is known to be absent.
+func (d *dataset) cleanupUncommitted(ctx context.Context, staged []string, snapshotID DatasetSnapshotID, partitionKeys []string, cause error) error {
+	first := d.manifestPaths(snapshotID, partitionKeys)[0]
+	if exists, err := d.store.Exists(context.WithoutCancel(ctx), first); err != nil || exists {
+		return cause
+	}
+	return d.cleanupStaged(ctx, staged, cause)
+}
+
+// stageSnapshot encodes data into data files and builds the manifest of a
+// new snapshot linked to parentID. Each encoded file is handed to put before
+// it is referenced; write stores it, PlanWrite discards it. Encoding work
+// is recorded in rec when it is non-nil.
+// Returns the manifest and the partition key of each data file.
+func (d *dataset) stageSnapshot(ctx context.Context, data []any, metadata Metadata, parentID DatasetSnapshotID, put func(ctx context.Context, path string, data []byte) error, rec *writeStatsRecorder) (*Manifest, []string, error) {
+	if metadata == nil {
+		metadata = Metadata{}
+	}
+
+	snapshotID := DatasetSnapshotID(generateID())
+
+	shared, err := d.dedupIndex(ctx, parentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	// storeFile references an identical parent file when one exists and
+	// otherwise hands the encoded file to put.
+	storeFile := func(ctx context.Context, partKey, filePath string, encoded []byte, stats *FileStats) (FileRef, error) {
+		ref := d.newFileRef(filePath, encoded, stats)
+		if existing, ok := shared[dedupKey{partition: partKey, checksum: ref.Checksum, size: ref.SizeBytes}]; ok {
+			return existing, nil
+		}
+		return ref, put(ctx, filePath, encoded)
+	}
+
+	var files []FileRef
+	var rowCount int64
+	var partitionKeys []string
+	var summaries []PartitionSummary
+	var codecName string
+
+	if d.codec == nil {
+		// Raw blob mode
+		if len(data) != 1 {
+			return nil, nil, errors.New("lode: raw blob mode requires exactly one data element")
+		}
+		blob, ok := data[0].([]byte)
+		if !ok {
+			return nil, nil, fmt.Errorf("lode: raw blob mode requires []byte, got %T", data[0])
+		}
+
+		fileName, err := d.dataFileName("", 0, "blob")
+		if err != nil {
+			return nil, nil, err
+		}
+		filePath := d.layout.dataFilePath(d.id, snapshotID, "", fileName)
+		encoded, err := d.encodeRawBlob(blob, rec)
+		var ref FileRef
+		if err == nil {
+			ref, err = storeFile(ctx, "", filePath, encoded, nil)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("lode: failed to write blob: %w", err)
+		}
+		files = []FileRef{ref}
+		rowCount = 1
+		partitionKeys = []string{""}
+		codecName = ""
+	} else {
+		// Structured records mode. Validate before any object is written.
+		if d.schema != nil {
+			if err := d.schema.validateRecords(data); err != nil {
+				return nil, nil, fmt.Errorf("lode: %w", err)
+			}
+		}
+		if d.uniqueKey != "" {
+			var dropped int
+			if data, dropped, err = dropDuplicateRecords(data, d.uniqueKey); err != nil {
+				return nil, nil, fmt.Errorf("lode: %w", err)
+			}
+			rec.recordDuplicates(dropped)
+		}
+		partitions, err := d.partitionRecords(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("lode: partitioning failed: %w", err)
+		}
+		if err := d.checkPartitionLimit(len(partitions)); err != nil {
+			return nil, nil, err
+		}
+
+		refs := make([]FileRef, len(partitions))
+		uploads := newUploadPool(ctx, d.uploads)
+		for partKey, partRecords := range partitions {
+			if uploads.failed() {
+				break
+			}
+			fileName, err := d.dataFileName(partKey, 0, "data")
+			if err != nil {
+				uploads.abort(err)
+				break
+			}
+			filePath := d.layout.dataFilePath(d.id, snapshotID, partKey, fileName)
+			encoded, stats, err := d.encodeDataFile(partRecords, rec)
+			if err != nil {
+				uploads.abort(fmt.Errorf("lode: failed to write data file: %w", err))
+				break
+			}
+			partitionKeys = append(partitionKeys, partKey)
+			summaries = append(summaries, PartitionSummary{Path: partKey, RowCount: int64(len(partRecords)), SizeBytes: int64(len(encoded))})
+			slot := len(partitionKeys) - 1
+			uploads.run(func(ctx context.Context) error {
+				ref, err := storeFile(ctx, partKey, filePath, encoded, stats)
+				if err != nil {
+					return fmt.Errorf("lode: failed to write data file: %w", err)
+				}
+				refs[slot] = ref
+				return nil
+			})
+		}
+		if err := uploads.wait(); err != nil {
+			return nil, nil, err
+		}
+		files = append(files, refs...)
+
+		rowCount = int64(len(data))
+		codecName = d.codec.Name()
+	}
+
+	// Extract timestamps from Timestamped records or the timestamp field
+	minTs, maxTs, skipped := extractTimestamps(data, d.timer)
+
+	sortFileRefs(files)
+
+	manifest := &Manifest{
+		SchemaName:        manifestSchemaName,
+		FormatVersion:     manifestFormatVersion,
+		DatasetID:         d.id,
+		SnapshotID:        snapshotID,
+		CreatedAt:         time.Now().UTC(),
+		Metadata:          metadata,
+		Files:             files,
+		ParentSnapshotID:  parentID,
+		RowCount:          rowCount,
+		MinTimestamp:      minTs,
+		MaxTimestamp:      maxTs,
+		TimestampsSkipped: skipped,
+		Codec:             codecName,
+		Compressor:        d.compressor.Name(),
+		Partitioner:       d.layout.partitioner().name(),
+		Partitions:        d.partitionSummaries(summaries),
+		Ordered:           d.ordered,
+		OrderKey:          d.orderKey,
+	}
+	if d.checksum != nil {
+		manifest.ChecksumAlgorithm = d.checksum.Name()
+	}
+	if d.schema != nil {
+		manifest.RecordSchemaName = d.schema.Name
+		manifest.RecordSchemaVersion = d.schema.Version
+	}
+	return manifest, partitionKeys, nil
+}
+
+// uploadPool runs data file uploads with bounded concurrency. The first
+// failure cancels the pool's context so in-flight uploads stop early and
+// no further uploads start. With a concurrency of 1, uploads run inline.
+type uploadPool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	firstErr error
+}
+
+func newUploadPool(ctx context.Context, concurrency int) *uploadPool {
+	ctx, cancel := context.WithCancel(ctx)
+	return &uploadPool{ctx: ctx, cancel: cancel, sem: make(chan struct{}, concurrency)}
+}
+
+// run starts fn once a slot is free.
+func (p *uploadPool) run(fn func(ctx context.Context) error) {
+	if cap(p.sem) == 1 {
+		if err := fn(p.ctx); err != nil {
+			p.abort(err)
+		}
+		return
+	}
+	select {
+	case p.sem <- struct{}{}:
+	case <-p.ctx.Done():
+		p.abort(p.ctx.Err())
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.sem }()
+		if err := fn(p.ctx); err != nil {
+			p.abort(err)
+		}
+	}()
+}
+
+// abort records err unless an earlier failure was recorded, and cancels
+// outstanding uploads.
+func (p *uploadPool) abort(err error) {
+	p.mu.Lock()
+	if p.firstErr == nil {
+		p.firstErr = err
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// failed reports whether an upload or the dispatcher has failed.
+func (p *uploadPool) failed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.firstErr != nil
+}
+
+// wait blocks until all started uploads finish and returns the first error.
+func (p *uploadPool) wait() error {
+	p.wg.Wait()
+	p.cancel()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.firstErr
+}
+
+// dedupKey identifies a data file's content within a partition.
+type dedupKey struct {
+	partition string
+	checksum  string
+	size      int64
+}
+
+// dedupIndex returns the files of the parent snapshot that a new file with
+// identical content may reference (see WithDedup). It is empty when dedup
+// is disabled, there is no parent, or the parent was written with a
+// different checksum algorithm, codec, or compressor.
+func (d *dataset) dedupIndex(ctx context.Context, parentID DatasetSnapshotID) (map[dedupKey]FileRef, error) {
+	if !d.dedup || parentID == "" {
+		return nil, nil
+	}
+	parent, err := d.Snapshot(ctx, parentID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to load parent snapshot for dedup: %w", err)
+	}
+	m := parent.Manifest
+	codecName := ""
+	if d.codec != nil {
+		codecName = d.codec.Name()
+	}
+	if m.ChecksumAlgorithm != d.checksum.Name() || m.Codec != codecName || m.Compressor != d.compressor.Name() {
+		return nil, nil
+	}
+	index := make(map[dedupKey]FileRef, len(m.Files))
+	for _, f := range m.Files {
+		if f.Checksum == "" {
+			continue
+		}
+		index[dedupKey{partition: d.layout.extractPartitionPath(f.Path), checksum: f.Checksum, size: f.SizeBytes}] = f
+	}
+	return index, nil
+}
+
+// partitionSummaries returns summaries sorted by path for the manifest, or
+// nil when the layout does not partition.
+func (d *dataset) partitionSummaries(summaries []PartitionSummary) []PartitionSummary {
+	if d.layout.partitioner().isNoop() {
+		return nil
+	}
+	slices.SortFunc(summaries, func(a, b PartitionSummary) int { return strings.Compare(a.Path, b.Path) })
+	return summaries
+}
+
+// sortFileRefs orders manifest files by path, so the manifest does not
+// depend on partition map iteration or upload completion order.
+func sortFileRefs(files []FileRef) {
+	slices.SortFunc(files, func(a, b FileRef) int { return strings.Compare(a.Path, b.Path) })
+}
+
+// putObject stores encoded bytes at path.
+func (d *dataset) putObject(ctx context.Context, path string, data []byte) error {
+	return d.store.Put(ctx, path, bytes.NewReader(data))
+}
+
+// discardObject is the put function for PlanWrite: nothing is stored.
+func discardObject(ctx context.Context, _ string, _ []byte) error {
+	return ctx.Err()
+}
+
+func (d *dataset) Snapshot(ctx context.Context, id DatasetSnapshotID) (*DatasetSnapshot, error) {
+	manifestPath := d.layout.manifestPath(d.id, id)
+
+	rc, err := d.store.Get(ctx, manifestPath)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return d.findSnapshotByID(ctx, id)
+		}
+		return nil, fmt.Errorf("lode: failed to get manifest: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	manifest, err := decodeManifest(rc)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to decode manifest: %w", err)
+	}
+	if err := checkFormatVersion(manifest.FormatVersion, manifestFormatVersion); err != nil {
+		return nil, fmt.Errorf("lode: %w", err)
+	}
+	if err := checkFilePaths(manifest); err != nil {
+		return nil, fmt.Errorf("lode: %w", err)
+	}
+
+	return &DatasetSnapshot{ID: id, Manifest: manifest, layout: d.layout}, nil
+}
+
+func (d *dataset) Snapshots(ctx context.Context) ([]*DatasetSnapshot, error) {
+	prefix := d.layout.segmentsPrefix(d.id)
+
+	paths, err := d.store.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to list snapshots: %w", err)
+	}
+
+	seen := make(map[DatasetSnapshotID]bool)
+	var snapshots []*DatasetSnapshot
+
+	for _, p := range paths {
+		if !d.layout.isManifest(p) {
+			continue
+		}
+
+		snapshotID := d.layout.parseSegmentID(p)
+		if snapshotID == "" || seen[snapshotID] {
+			continue
+		}
+		seen[snapshotID] = true
+
+		snapshot, err := d.loadSnapshotFromPath(ctx, snapshotID, p)
+		if err != nil {
+			return nil, fmt.Errorf("lode: failed to load snapshot %s: %w", snapshotID, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Manifest.CreatedAt.Before(snapshots[j].Manifest.CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+func (d *dataset) Read(ctx context.Context, id DatasetSnapshotID) ([]any, error) {
+	snapshot, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, compressor, err := d.resolveReadCodec(snapshot.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if codec == nil {
+		if len(snapshot.Manifest.Files) != 1 {
+			return nil, fmt.Errorf("lode: raw blob snapshot must have exactly one file, got %d", len(snapshot.Manifest.Files))
+		}
+		data, err := d.readRawBlob(ctx, compressor, snapshot.Manifest.Files[0].Path)
+		if err != nil {
+			return nil, fmt.Errorf("lode: failed to read blob %s: %w", snapshot.Manifest.Files[0].Path, err)
+		}
+		return []any{data}, nil
+	}
+
+	return d.readFiles(ctx, compressor, codec, snapshot.Manifest.Files, d.timeBounds(snapshot.Manifest))
+}
+
+// ReadN reads the first n records of the snapshot through a chain of file
+// iterators limited to n records. Streaming codecs stop decoding at the n-th
+// record; other codecs decode whole files, but only the files reached.
+func (d *dataset) ReadN(ctx context.Context, id DatasetSnapshotID, n int) ([]any, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("lode: record limit must be non-negative, got %d", n)
+	}
+
+	all, err := d.snapshotRecords(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	it := LimitRecords(all, n)
+	defer func() { _ = it.Close() }()
+
+	var records []any
+	for it.Next() {
+		records = append(records, it.Record())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Sample draws a uniform random sample of k records from the snapshot with
+// reservoir sampling (Algorithm R). Every file is streamed once and only the
+// k sampled records are held in memory.
+func (d *dataset) Sample(ctx context.Context, id DatasetSnapshotID, k int, opts SampleOptions) ([]any, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("lode: sample size must be non-negative, got %d", k)
+	}
+
+	it, err := d.snapshotRecords(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = it.Close() }()
+	if k == 0 {
+		return nil, nil
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	var (
+		sample []any
+		seen   int64
+	)
+	for it.Next() {
+		if len(sample) < k {
+			sample = append(sample, it.Record())
+		} else if j := rng.Int64N(seen + 1); j < int64(k) {
+			sample[j] = it.Record()
+		}
+		seen++
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return sample, nil
+}
+
+// ReadChan runs the snapshot's record iterator in a goroutine, sending each
+// record on a channel of opts.Buffer capacity. Every send also watches ctx so
+// a consumer that stops receiving can cancel without leaking the goroutine.
+func (d *dataset) ReadChan(ctx context.Context, id DatasetSnapshotID, opts ReadChanOptions) (<-chan any, <-chan error) {
+	errs := make(chan error, 1)
+	if opts.Buffer < 0 {
+		records := make(chan any)
+		close(records)
+		errs <- fmt.Errorf("lode: channel buffer must be non-negative, got %d", opts.Buffer)
+		close(errs)
+		return records, errs
+	}
+
+	records := make(chan any, opts.Buffer)
+	go func() {
+		err := d.sendRecords(ctx, id, records)
+		close(records)
+		if err != nil {
+			errs <- err
+		}
+		close(errs)
+	}()
+	return records, errs
+}
+
+// sendRecords sends the snapshot's records on out until the iterator is
+// exhausted, fails, or ctx is cancelled. The iterator is always closed.
+func (d *dataset) sendRecords(ctx context.Context, id DatasetSnapshotID, out chan<- any) error {
+	it, err := d.snapshotRecords(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = it.Close() }()
+
+	for it.Next() {
+		select {
+		case out <- it.Record():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// snapshotRecords returns an iterator over the records of a snapshot in
+// manifest file order. Files are opened one at a time as iteration reaches
+// them and checked against the snapshot's timestamp bounds. A raw blob
+// snapshot yields its blob as a single record.
+func (d *dataset) snapshotRecords(ctx context.Context, id DatasetSnapshotID) (FileRecordIterator, error) {
+	snapshot, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	it, err := d.manifestRecords(ctx, snapshot.Manifest, snapshot.Manifest.Files)
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// manifestRecords returns an iterator over the records of files, a subset
+// of m.Files, in the given order.
+func (d *dataset) manifestRecords(ctx context.Context, m *Manifest, files []FileRef) (*chainedRecordIterator, error) {
+	codec, compressor, err := d.resolveReadCodec(m)
+	if err != nil {
+		return nil, err
+	}
+	if codec == nil && len(m.Files) != 1 {
+		return nil, fmt.Errorf("lode: raw blob snapshot must have exactly one file, got %d", len(m.Files))
+	}
+
+	bounds := d.timeBounds(m)
+	opens := make([]func() (FileRecordIterator, error), len(files))
+	for i, f := range files {
+		opens[i] = func() (FileRecordIterator, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			it, err := d.openFileRecords(ctx, compressor, codec, f.Path)
+			if err != nil {
+				return nil, err
+			}
+			return bounds.wrap(it, f.Path), nil
+		}
+	}
+	return newChainedRecordIterator(opens), nil
+}
+
+// OpenStream concatenates the decompressed bytes of the snapshot's files in
+// manifest file order. Each file is fetched only when the stream reaches it
+// and closed once consumed; bytes are passed through without decoding.
+func (d *dataset) OpenStream(ctx context.Context, id DatasetSnapshotID) (io.ReadCloser, error) {
+	snapshot, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, compressor, err := d.resolveReadCodec(snapshot.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	opens := make([]func() (io.ReadCloser, error), len(snapshot.Manifest.Files))
+	for i, f := range snapshot.Manifest.Files {
+		opens[i] = func() (io.ReadCloser, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			rc, err := d.openDecompressed(ctx, compressor, f.Path)
+			if err != nil {
+				return nil, fmt.Errorf("lode: failed to read data file %s: %w", f.Path, err)
+			}
+			return rc, nil
+		}
+	}
+	return newConcatReader(opens), nil
+}
+
+// openDecompressed opens a data file and returns its decompressed bytes.
+// Closing the result closes both the decompressor and the object.
+func (d *dataset) openDecompressed(ctx context.Context, compressor Compressor, filePath string) (io.ReadCloser, error) {
+	rc, err := d.store.Get(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	decompReader, err := compressor.Decompress(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	return &decompressedObject{ReadCloser: decompReader, object: rc}, nil
+}
+
+// decompressedObject is a decompressing reader that also closes the
+// underlying object.
+type decompressedObject struct {
+	io.ReadCloser
+	object io.Closer
+}
+
+func (o *decompressedObject) Close() error {
+	return errors.Join(o.ReadCloser.Close(), o.object.Close())
+}
+
+// ReadPartitionsWhere reads only the data files whose partition values,
+// parsed from their paths, satisfy pred. Files of unpartitioned snapshots
+// are offered to pred with an empty map. Requires a codec snapshot.
+func (d *dataset) ReadPartitionsWhere(ctx context.Context, id DatasetSnapshotID, pred func(partition map[string]string) bool) ([]any, error) {
+	if pred == nil {
+		return nil, errors.New("lode: partition predicate must not be nil")
+	}
+
+	snapshot, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, compressor, err := d.resolveReadCodec(snapshot.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if codec == nil {
+		return nil, errors.New("lode: ReadPartitionsWhere requires a codec snapshot")
+	}
+
+	// Pruning uses path metadata only; excluded files are never fetched.
+	// With a partition summary, pred runs once per partition.
+	if len(snapshot.Manifest.Partitions) > 0 {
+		selected, err := d.prunePartitionSummaries(snapshot.Manifest, pred)
+		if err != nil {
+			return nil, err
+		}
+		return d.readFiles(ctx, compressor, codec, selected, d.timeBounds(snapshot.Manifest))
+	}
+	var selected []FileRef
+	for _, fileRef := range snapshot.Manifest.Files {
+		values, err := parsePartitionValues(d.layout.extractPartitionPath(fileRef.Path))
+		if err != nil {
+			return nil, fmt.Errorf("lode: data file %s: %w", fileRef.Path, err)
+		}
+		if pred(values) {
+			selected = append(selected, fileRef)
+		}
+	}
+
+	return d.readFiles(ctx, compressor, codec, selected, d.timeBounds(snapshot.Manifest))
+}
+
+// ReadPartition matches the layout's partition path of each file against
+// partition exactly; values stay percent-encoded as they are in paths.
+func (d *dataset) ReadPartition(ctx context.Context, id DatasetSnapshotID, partition string, opts ReadPartitionOptions) ([]any, error) {
+	partition = strings.Trim(partition, "/")
+
+	snapshot, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	codec, compressor, err := d.resolveReadCodec(snapshot.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if codec == nil {
+		return nil, errors.New("lode: ReadPartition requires a codec snapshot")
+	}
+
+	var selected []FileRef
+	for _, fileRef := range snapshot.Manifest.Files {
+		if d.layout.extractPartitionPath(fileRef.Path) == partition {
+			selected = append(selected, fileRef)
+		}
+	}
+	if len(selected) == 0 {
+		if opts.RequirePresent {
+			return nil, fmt.Errorf("lode: snapshot %s partition %q: %w", id, partition, ErrPartitionNotFound)
+		}
+		return []any{}, nil
+	}
+	return d.readFiles(ctx, compressor, codec, selected, d.timeBounds(snapshot.Manifest))
+}
+
+// prunePartitionSummaries selects the files of the partitions in
+// m.Partitions that satisfy pred.
+func (d *dataset) prunePartitionSummaries(m *Manifest, pred func(partition map[string]string) bool) ([]FileRef, error) {
+	keep := make(map[string]bool, len(m.Partitions))
+	for _, p := range m.Partitions {
+		values, err := parsePartitionValues(p.Path)
+		if err != nil {
+			return nil, fmt.Errorf("lode: partition %s: %w", p.Path, err)
+		}
+		if pred(values) {
+			keep[p.Path] = true
+		}
+	}
+	var selected []FileRef
+	if len(keep) == 0 {
+		return selected, nil
+	}
+	for _, fileRef := range m.Files {
+		if keep[d.layout.extractPartitionPath(fileRef.Path)] {
+			selected = append(selected, fileRef)
+		}
+	}
+	return selected, nil
+}
+
+// ReadFile reads the records of one data file listed in the snapshot manifest.
+// A raw blob file is returned as a single []byte record.
+func (d *dataset) ReadFile(ctx context.Context, id DatasetSnapshotID, filePath string) ([]any, error) {
+	codec, compressor, bounds, err := d.resolveFileCodec(ctx, id, filePath)
+	if err != nil {
+		return nil, err
+	}
+	records, err := d.readFile(ctx, compressor, codec, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := bounds.checkAll(filePath, records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ReadFileRecords iterates the records of one data file listed in the
+// snapshot manifest. Codecs implementing StreamingDecodeCodec are decoded
+// incrementally from the open object; others are decoded up front.
+func (d *dataset) ReadFileRecords(ctx context.Context, id DatasetSnapshotID, filePath string) (FileRecordIterator, error) {
+	codec, compressor, bounds, err := d.resolveFileCodec(ctx, id, filePath)
+	if err != nil {
+		return nil, err
+	}
+	it, err := d.openFileRecords(ctx, compressor, codec, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return bounds.wrap(it, filePath), nil
+}
+
+// ReadSince iterates the records of the snapshots descending from since,
+// in CreatedAt order and manifest file order within a snapshot. Files are
+// opened one at a time as iteration reaches them.
+func (d *dataset) ReadSince(ctx context.Context, since DatasetSnapshotID) (FileRecordIterator, error) {
+	snapshots, err := d.Snapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]*Manifest, len(snapshots))
+	for i, snap := range snapshots {
+		manifests[i] = snap.Manifest
+	}
+	descendants, err := snapshotsSince(manifests, since)
+	if err != nil {
+		return nil, err
 	}
-	d.lastSnapshotID = snapshotID
 
-	return &DatasetSnapshot{
-		ID:       snapshotID,
-		Manifest: manifest,
-	}, nil
+	var opens []func() (FileRecordIterator, error)
+	for _, m := range descendants {
+		codec, compressor, err := d.resolveReadCodec(m)
+		if err != nil {
+			return nil, fmt.Errorf("lode: snapshot %s: %w", m.SnapshotID, err)
+		}
+		bounds := d.timeBounds(m)
+		for _, f := range m.Files {
+			opens = append(opens, func() (FileRecordIterator, error) {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				it, err := d.openFileRecords(ctx, compressor, codec, f.Path)
+				if err != nil {
+					return nil, err
+				}
+				return bounds.wrap(it, f.Path), nil
+			})
+		}
+	}
+	return newChainedRecordIterator(opens), nil
 }
 
-func (d *dataset) Snapshot(ctx context.Context, id DatasetSnapshotID) (*DatasetSnapshot, error) {
-	manifestPath := d.layout.manifestPath(d.id, id)
-
-	rc, err := d.store.Get(ctx, manifestPath)
+// ReadFrom resumes a snapshot read by chaining the manifest files that cp
+// has not completed, in manifest file order.
+func (d *dataset) ReadFrom(ctx context.Context, cp ReadCheckpoint) (CheckpointIterator, error) {
+	snapshot, err := d.Snapshot(ctx, cp.SnapshotID)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return d.findSnapshotByID(ctx, id)
+		return nil, err
+	}
+	done := make(map[string]bool, len(cp.Completed))
+	for _, p := range cp.Completed {
+		done[p] = true
+	}
+	var (
+		completed []string
+		remaining []FileRef
+		paths     []string
+	)
+	for _, f := range snapshot.Manifest.Files {
+		if done[f.Path] {
+			delete(done, f.Path)
+			completed = append(completed, f.Path)
+			continue
 		}
-		return nil, fmt.Errorf("lode: failed to get manifest: %w", err)
+		remaining = append(remaining, f)
+		paths = append(paths, f.Path)
 	}
-	defer func() { _ = rc.Close() }()
-
-	var manifest Manifest
-	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
-		return nil, fmt.Errorf("lode: failed to decode manifest: %w", err)
+	for p := range done {
+		return nil, fmt.Errorf("lode: checkpoint file %s: %w", p, ErrFileNotInManifest)
 	}
 
-	return &DatasetSnapshot{ID: id, Manifest: &manifest}, nil
-}
-
-func (d *dataset) Snapshots(ctx context.Context) ([]*DatasetSnapshot, error) {
-	prefix := d.layout.segmentsPrefix(d.id)
-
-	paths, err := d.store.List(ctx, prefix)
+	it, err := d.manifestRecords(ctx, snapshot.Manifest, remaining)
 	if err != nil {
-		return nil, fmt.Errorf("lode: failed to list snapshots: %w", err)
+		return nil, err
 	}
+	return &checkpointRecordIterator{
+		chainedRecordIterator: it,
+		snapshotID:            cp.SnapshotID,
+		completed:             completed,
+		paths:                 paths,
+	}, nil
+}
 
-	seen := make(map[DatasetSnapshotID]bool)
-	var snapshots []*DatasetSnapshot
-
-	for _, p := range paths {
-		if !d.layout.isManifest(p) {
-			continue
+// openFileRecords opens an iterator over one data file. Codecs implementing
+// StreamingDecodeCodec decode from the open object; others, and raw blobs
+// (nil codec), are decoded up front.
+func (d *dataset) openFileRecords(ctx context.Context, compressor Compressor, codec Codec, filePath string) (FileRecordIterator, error) {
+	streaming, ok := codec.(StreamingDecodeCodec)
+	if !ok {
+		records, err := d.readFile(ctx, compressor, codec, filePath)
+		if err != nil {
+			return nil, err
 		}
+		return newFileRecordIterator(&sliceRecordIterator{records: records}), nil
+	}
 
-		snapshotID := d.layout.parseSegmentID(p)
-		if snapshotID == "" || seen[snapshotID] {
-			continue
-		}
-		seen[snapshotID] = true
+	rc, err := d.store.Get(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to read data file %s: %w", filePath, err)
+	}
+	decompReader, err := compressor.Decompress(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, fmt.Errorf("lode: failed to read data file %s: %w", filePath, err)
+	}
+	var it RecordIterator
+	if dec := d.tolerantDecoder(codec, decompReader, filePath); dec != nil {
+		it = dec
+	} else if it, err = streaming.NewStreamDecoder(decompReader); err != nil {
+		_ = decompReader.Close()
+		_ = rc.Close()
+		return nil, fmt.Errorf("lode: failed to read data file %s: %w", filePath, err)
+	}
+	return newFileRecordIterator(it, decompReader, rc), nil
+}
 
-		snapshot, err := d.loadSnapshotFromPath(ctx, snapshotID, p)
+// readFile decodes one data file, or returns a raw blob as a single record
+// when codec is nil.
+func (d *dataset) readFile(ctx context.Context, compressor Compressor, codec Codec, filePath string) ([]any, error) {
+	if codec == nil {
+		data, err := d.readRawBlob(ctx, compressor, filePath)
 		if err != nil {
-			return nil, fmt.Errorf("lode: failed to load snapshot %s: %w", snapshotID, err)
+			return nil, fmt.Errorf("lode: failed to read blob %s: %w", filePath, err)
 		}
-		snapshots = append(snapshots, snapshot)
+		return []any{data}, nil
 	}
 
-	sort.Slice(snapshots, func(i, j int) bool {
-		return snapshots[i].Manifest.CreatedAt.Before(snapshots[j].Manifest.CreatedAt)
-	})
-
-	return snapshots, nil
+	records, err := d.readRecords(ctx, compressor, codec, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to read data file %s: %w", filePath, err)
+	}
+	return records, nil
 }
 
-func (d *dataset) Read(ctx context.Context, id DatasetSnapshotID) ([]any, error) {
+// resolveFileCodec loads the snapshot, checks that filePath is one of its
+// data files, and returns the codec and compressor to decode it with (nil
+// codec for raw blobs) and the snapshot's timestamp bounds checker.
+func (d *dataset) resolveFileCodec(ctx context.Context, id DatasetSnapshotID, filePath string) (Codec, Compressor, *timeBounds, error) {
 	snapshot, err := d.Snapshot(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+	if !slices.ContainsFunc(snapshot.Manifest.Files, func(f FileRef) bool { return f.Path == filePath }) {
+		return nil, nil, nil, fmt.Errorf("lode: %w: %s", ErrFileNotInManifest, filePath)
+	}
+	codec, compressor, err := d.resolveReadCodec(snapshot.Manifest)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+	return codec, compressor, d.timeBounds(snapshot.Manifest), nil
+}
 
-	if err := d.validateComponentsMatch(snapshot.Manifest); err != nil {
-		return nil, err
+// dataFileName returns the leaf name of the index-th data file of a
+// partition: defaultBase, or the FileNamer's validated result, followed by
+// the file extension.
+func (d *dataset) dataFileName(partKey string, index int, defaultBase string) (string, error) {
+	if d.namer == nil {
+		return defaultBase + d.fileExtension(), nil
+	}
+	base := d.namer(partKey, index)
+	if base == "" || base == "." || base == ".." || strings.ContainsAny(base, "/\\") {
+		return "", fmt.Errorf("lode: file namer returned invalid name %q for partition %q index %d", base, partKey, index)
 	}
+	return base + d.fileExtension(), nil
+}
 
+// fileExtension composes the codec extension (none in raw blob mode) with
+// the compressor extension, e.g. ".jsonl.gz".
+func (d *dataset) fileExtension() string {
 	if d.codec == nil {
-		if len(snapshot.Manifest.Files) != 1 {
-			return nil, fmt.Errorf("lode: raw blob snapshot must have exactly one file, got %d", len(snapshot.Manifest.Files))
-		}
-		data, err := d.readRawBlob(ctx, snapshot.Manifest.Files[0].Path)
-		if err != nil {
-			return nil, fmt.Errorf("lode: failed to read blob %s: %w", snapshot.Manifest.Files[0].Path, err)
-		}
-		return []any{data}, nil
+		return d.compressor.Extension()
 	}
+	return d.codec.Extension() + d.compressor.Extension()
+}
 
+// readFiles decodes and concatenates the records of the given data files,
+// checking each file's records against bounds. Cancellation is checked
+// before each file; each file's reader is closed before the next is opened.
+func (d *dataset) readFiles(ctx context.Context, compressor Compressor, codec Codec, files []FileRef, bounds *timeBounds) ([]any, error) {
 	var allRecords []any
-	for _, fileRef := range snapshot.Manifest.Files {
-		records, err := d.readDataFile(ctx, fileRef.Path)
+	for _, fileRef := range files {
+		// Stop between files once the caller has gone away.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		records, err := d.readRecords(ctx, compressor, codec, fileRef.Path)
 		if err != nil {
 			return nil, fmt.Errorf("lode: failed to read data file %s: %w", fileRef.Path, err)
 		}
+		if err := bounds.checkAll(fileRef.Path, records); err != nil {
+			return nil, err
+		}
 		allRecords = append(allRecords, records...)
 	}
 
@@ -526,7 +1980,70 @@ func (d *dataset) Latest(ctx context.Context) (*DatasetSnapshot, error) {
 		// Pointer references a nonexistent snapshot — fall through to scan.
 	}
 
-	return d.latestByScan(ctx)
+	snap, err := d.latestByScan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Self-heal: write the pointer so subsequent calls are O(1).
+	_ = d.writeLatestPointer(ctx, id, snap.ID)
+
+	return snap, nil
+}
+
+func (d *dataset) LatestBy(ctx context.Context, strategy LatestStrategy) (*DatasetSnapshot, error) {
+	switch strategy {
+	case LatestByPointer:
+		return d.Latest(ctx)
+	case LatestByStorageModTime:
+		return d.latestByModTime(ctx)
+	default:
+		return nil, fmt.Errorf("lode: unknown latest strategy %d", strategy)
+	}
+}
+
+// latestByModTime finds the snapshot with the most recently written
+// manifest via a single List, one Stat per manifest path, and one Get.
+func (d *dataset) latestByModTime(ctx context.Context) (*DatasetSnapshot, error) {
+	prefix := d.layout.segmentsPrefix(d.id)
+	paths, err := d.store.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to list snapshots: %w", err)
+	}
+
+	var (
+		latestID   DatasetSnapshotID
+		latestPath string
+		latestMod  time.Time
+	)
+	for _, p := range paths {
+		if !d.layout.isManifest(p) {
+			continue
+		}
+		id := d.layout.parseSegmentID(p)
+		if id == "" {
+			continue
+		}
+		info, err := StatObject(ctx, d.store, p)
+		if err != nil {
+			return nil, fmt.Errorf("lode: failed to stat manifest %s: %w", p, err)
+		}
+		if info.ModTime.IsZero() {
+			return nil, fmt.Errorf("lode: store reports no modification time for %s", p)
+		}
+		if c := info.ModTime.Compare(latestMod); c > 0 || c == 0 && id > latestID {
+			latestID, latestPath, latestMod = id, p, info.ModTime
+		}
+	}
+	if latestID == "" {
+		return nil, ErrNoSnapshots
+	}
+
+	snap, err := d.loadSnapshotFromPath(ctx, latestID, latestPath)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to load latest snapshot: %w", err)
+	}
+	return snap, nil
 }
 
 // latestByScan finds the latest snapshot via a single List + single Get.
@@ -564,9 +2081,6 @@ func (d *dataset) latestByScan(ctx context.Context) (*DatasetSnapshot, error) {
 		return nil, fmt.Errorf("lode: failed to load latest snapshot: %w", err)
 	}
 
-	// Self-heal: write the pointer so subsequent calls are O(1).
-	_ = d.writeLatestPointer(ctx, latestID)
-
 	return snap, nil
 }
 
@@ -578,13 +2092,16 @@ func (d *dataset) StreamWrite(ctx context.Context, metadata Metadata) (StreamWri
 		return nil, ErrCodecConfigured
 	}
 
-	parentID, err := d.resolveParentID(ctx)
+	parentID, pointerID, err := d.resolveParentID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	fileName, err := d.dataFileName("", 0, "blob")
+	if err != nil {
+		return nil, err
+	}
 	snapshotID := DatasetSnapshotID(generateID())
-	fileName := "blob" + d.compressor.Extension()
 	filePath := d.layout.dataFilePath(d.id, snapshotID, "", fileName)
 
 	// Create pipe for streaming to store
@@ -620,6 +2137,7 @@ func (d *dataset) StreamWrite(ctx context.Context, metadata Metadata) (StreamWri
 		metadata:    metadata,
 		snapshotID:  snapshotID,
 		parentID:    parentID,
+		pointerID:   pointerID,
 		filePath:    filePath,
 		pipeWriter:  pw,
 		compWriter:  compWriter,
@@ -649,13 +2167,22 @@ func (d *dataset) StreamWriteRecords(ctx context.Context, records RecordIterator
 		return nil, ErrPartitioningNotSupported
 	}
 
-	parentID, err := d.resolveParentID(ctx)
+	unlock, err := d.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	parentID, pointerID, err := d.resolveLockedParentID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	fileName, err := d.dataFileName("", 0, "data")
+	if err != nil {
+		return nil, err
+	}
 	snapshotID := DatasetSnapshotID(generateID())
-	fileName := "data" + d.compressor.Extension()
 	filePath := d.layout.dataFilePath(d.id, snapshotID, "", fileName)
 
 	// Create pipe for streaming to store
@@ -694,12 +2221,23 @@ func (d *dataset) StreamWriteRecords(ctx context.Context, records RecordIterator
 	}()
 
 	// Stream records through encoder, tracking count and timestamps
-	var rowCount int64
+	var rowCount, skipped int64
 	var minTs, maxTs *time.Time
 
 	for records.Next() {
 		record := records.Record()
 
+		if d.schema != nil {
+			if err := d.schema.validateRecord(int(rowCount), record); err != nil {
+				_ = encoder.Close()
+				_ = compWriter.Close()
+				_ = pw.CloseWithError(err)
+				<-putDone
+				_ = d.store.Delete(ctx, filePath)
+				return nil, fmt.Errorf("lode: %w", err)
+			}
+		}
+
 		if err := encoder.WriteRecord(record); err != nil {
 			// Abort on error
 			_ = encoder.Close()
@@ -712,9 +2250,12 @@ func (d *dataset) StreamWriteRecords(ctx context.Context, records RecordIterator
 
 		rowCount++
 
-		// Track timestamps from Timestamped records
-		if ts, ok := record.(Timestamped); ok {
-			t := ts.Timestamp()
+		// Track timestamps from Timestamped records or the timestamp field
+		t, ok, skip := recordTimestamp(record, d.timer)
+		if skip {
+			skipped++
+		}
+		if ok {
 			if minTs == nil || t.Before(*minTs) {
 				minTs = &t
 			}
@@ -776,36 +2317,42 @@ func (d *dataset) StreamWriteRecords(ctx context.Context, records RecordIterator
 		SizeBytes: cw.n,
 		Stats:     fileStats,
 	}
+	fileRef.ContentType, fileRef.ContentEncoding = contentMetadata(d.codec, d.compressor)
 	if hasher != nil {
 		fileRef.Checksum = hasher.Sum()
 	}
 
 	// Build manifest
 	manifest := &Manifest{
-		SchemaName:       manifestSchemaName,
-		FormatVersion:    manifestFormatVersion,
-		DatasetID:        d.id,
-		SnapshotID:       snapshotID,
-		CreatedAt:        time.Now().UTC(),
-		Metadata:         metadata,
-		Files:            []FileRef{fileRef},
-		ParentSnapshotID: parentID,
-		RowCount:         rowCount,
-		MinTimestamp:     minTs,
-		MaxTimestamp:     maxTs,
-		Codec:            d.codec.Name(),
-		Compressor:       d.compressor.Name(),
-		Partitioner:      d.layout.partitioner().name(),
+		SchemaName:        manifestSchemaName,
+		FormatVersion:     manifestFormatVersion,
+		DatasetID:         d.id,
+		SnapshotID:        snapshotID,
+		CreatedAt:         time.Now().UTC(),
+		Metadata:          metadata,
+		Files:             []FileRef{fileRef},
+		ParentSnapshotID:  parentID,
+		RowCount:          rowCount,
+		MinTimestamp:      minTs,
+		MaxTimestamp:      maxTs,
+		TimestampsSkipped: skipped,
+		Codec:             d.codec.Name(),
+		Compressor:        d.compressor.Name(),
+		Partitioner:       d.layout.partitioner().name(),
 	}
 	if d.checksum != nil {
 		manifest.ChecksumAlgorithm = d.checksum.Name()
 	}
+	if d.schema != nil {
+		manifest.RecordSchemaName = d.schema.Name
+		manifest.RecordSchemaVersion = d.schema.Version
+	}
 
 	// Pointer must be written before manifest to prevent stale-but-existing
 	// pointers on cold start. If this fails, no manifest is written and the
 	// commit is aborted. A pointer referencing a not-yet-existing snapshot is
 	// harmless (Exists check falls through to scan on the next cold start).
-	if err := d.writeLatestPointer(ctx, snapshotID); err != nil {
+	if err := d.writeLatestPointer(ctx, pointerID, snapshotID); err != nil {
 		_ = d.store.Delete(ctx, filePath) // best-effort cleanup
 		return nil, fmt.Errorf("lode: failed to update latest pointer: %w", err)
 	}
@@ -816,10 +2363,11 @@ func (d *dataset) StreamWriteRecords(ctx context.Context, records RecordIterator
 	}
 	d.lastSnapshotID = snapshotID
 
-	return &DatasetSnapshot{
+	return d.finishCommit(ctx, &DatasetSnapshot{
 		ID:       snapshotID,
 		Manifest: manifest,
-	}, nil
+		layout:   d.layout,
+	})
 }
 
 func (d *dataset) partitionRecords(records []any) (map[string][]any, error) {
@@ -837,73 +2385,102 @@ func (d *dataset) partitionRecords(records []any) (map[string][]any, error) {
 	return partitions, nil
 }
 
-func (d *dataset) writeRawBlob(ctx context.Context, snapshotID DatasetSnapshotID, data []byte) (FileRef, error) {
-	fileName := "blob" + d.compressor.Extension()
-	filePath := d.layout.dataFilePath(d.id, snapshotID, "", fileName)
+// checkPartitionLimit enforces WithMaxPartitions for a write producing n
+// partitions.
+func (d *dataset) checkPartitionLimit(n int) error {
+	if d.maxParts > 0 && n > d.maxParts {
+		return &TooManyPartitionsError{Partitions: n, Limit: d.maxParts}
+	}
+	return nil
+}
+
+// encodeRawBlob compresses a raw blob into the stored file bytes.
+func (d *dataset) encodeRawBlob(data []byte, rec *writeStatsRecorder) ([]byte, error) {
+	if rec == nil {
+		return compressBytes(d.compressor, data)
+	}
+	start := time.Now()
+	encoded, err := compressBytes(d.compressor, data)
+	if err != nil {
+		return nil, err
+	}
+	rec.recordEncode(int64(len(data)), int64(len(encoded)), 0, time.Since(start))
+	return encoded, nil
+}
 
+// compressBytes returns data compressed with c.
+func compressBytes(c Compressor, data []byte) ([]byte, error) {
 	var buf bytes.Buffer
-	compWriter, err := d.compressor.Compress(&buf)
+	compWriter, err := c.Compress(&buf)
 	if err != nil {
-		return FileRef{}, err
+		return nil, err
 	}
 
 	if _, err := compWriter.Write(data); err != nil {
 		_ = compWriter.Close()
-		return FileRef{}, err
+		return nil, err
 	}
 
 	if err := compWriter.Close(); err != nil {
-		return FileRef{}, err
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	compressedData := buf.Bytes()
-	if err := d.store.Put(ctx, filePath, bytes.NewReader(compressedData)); err != nil {
-		return FileRef{}, err
+// encodeDataFile encodes and compresses records into the stored file bytes.
+// Returns per-file stats when the codec implements StatisticalCodec.
+// Encoding work is recorded in rec when it is non-nil.
+func (d *dataset) encodeDataFile(records []any, rec *writeStatsRecorder) ([]byte, *FileStats, error) {
+	var start time.Time
+	if rec != nil {
+		start = time.Now()
 	}
 
-	fileRef := FileRef{
-		Path:      filePath,
-		SizeBytes: int64(len(compressedData)),
+	var buf bytes.Buffer
+	compWriter, err := d.compressor.Compress(&buf)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Compute checksum on stored (compressed) bytes
-	if d.checksum != nil {
-		hasher := d.checksum.NewHasher()
-		_, _ = hasher.Write(compressedData)
-		fileRef.Checksum = hasher.Sum()
+	// Time spent inside the compressor is separated from encoding by
+	// routing the codec's output through a timedWriter.
+	var timed *timedWriter
+	var w io.WriteCloser = compWriter
+	if rec != nil {
+		timed = &timedWriter{w: compWriter}
+		w = timed
 	}
 
-	return fileRef, nil
-}
-
-func (d *dataset) writeDataFile(ctx context.Context, snapshotID DatasetSnapshotID, partKey string, records []any) (FileRef, error) {
-	fileName := "data" + d.compressor.Extension()
-	filePath := d.layout.dataFilePath(d.id, snapshotID, partKey, fileName)
-
-	var buf bytes.Buffer
-	compWriter, err := d.compressor.Compress(&buf)
-	if err != nil {
-		return FileRef{}, err
+	if err := d.codec.Encode(w, records); err != nil {
+		_ = w.Close()
+		return nil, nil, err
 	}
 
-	if err := d.codec.Encode(compWriter, records); err != nil {
-		_ = compWriter.Close()
-		return FileRef{}, err
+	if err := w.Close(); err != nil {
+		return nil, nil, err
 	}
 
-	if err := compWriter.Close(); err != nil {
-		return FileRef{}, err
+	if rec != nil {
+		rec.recordEncode(timed.n, int64(buf.Len()), time.Since(start)-timed.elapsed, timed.elapsed)
 	}
 
-	data := buf.Bytes()
-	if err := d.store.Put(ctx, filePath, bytes.NewReader(data)); err != nil {
-		return FileRef{}, err
+	// Collect per-file stats if the codec supports it
+	var stats *FileStats
+	if sc, ok := d.codec.(StatisticalCodec); ok {
+		stats = sc.FileStats()
 	}
 
+	return buf.Bytes(), stats, nil
+}
+
+// newFileRef builds the manifest reference for stored file bytes.
+func (d *dataset) newFileRef(filePath string, data []byte, stats *FileStats) FileRef {
 	fileRef := FileRef{
 		Path:      filePath,
 		SizeBytes: int64(len(data)),
+		Stats:     stats,
 	}
+	fileRef.ContentType, fileRef.ContentEncoding = contentMetadata(d.codec, d.compressor)
 
 	// Compute checksum on stored (compressed) bytes
 	if d.checksum != nil {
@@ -911,23 +2488,17 @@ func (d *dataset) writeDataFile(ctx context.Context, snapshotID DatasetSnapshotI
 		_, _ = hasher.Write(data)
 		fileRef.Checksum = hasher.Sum()
 	}
-
-	// Collect per-file stats if the codec supports it
-	if sc, ok := d.codec.(StatisticalCodec); ok {
-		fileRef.Stats = sc.FileStats()
-	}
-
-	return fileRef, nil
+	return fileRef
 }
 
-func (d *dataset) readRawBlob(ctx context.Context, filePath string) ([]byte, error) {
+func (d *dataset) readRawBlob(ctx context.Context, compressor Compressor, filePath string) ([]byte, error) {
 	rc, err := d.store.Get(ctx, filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rc.Close() }()
 
-	decompReader, err := d.compressor.Decompress(rc)
+	decompReader, err := compressor.Decompress(rc)
 	if err != nil {
 		return nil, err
 	}
@@ -940,28 +2511,117 @@ func (d *dataset) readRawBlob(ctx context.Context, filePath string) ([]byte, err
 	return buf.Bytes(), nil
 }
 
-func (d *dataset) readDataFile(ctx context.Context, filePath string) ([]any, error) {
+func (d *dataset) readDataFile(ctx context.Context, compressor Compressor, codec Codec, filePath string) ([]any, error) {
 	rc, err := d.store.Get(ctx, filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rc.Close() }()
 
-	decompReader, err := d.compressor.Decompress(rc)
+	decompReader, err := compressor.Decompress(rc)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = decompReader.Close() }()
 
-	return d.codec.Decode(decompReader)
+	return codec.Decode(decompReader)
+}
+
+// readRecords decodes one data file for a read, applying the dataset's
+// WithOnDecodeError mode. Compaction calls readDataFile so it never drops
+// records.
+func (d *dataset) readRecords(ctx context.Context, compressor Compressor, codec Codec, filePath string) ([]any, error) {
+	if _, ok := codec.(*jsonlCodec); !ok || d.onDecode.mode == DecodeErrorFail {
+		return d.readDataFile(ctx, compressor, codec, filePath)
+	}
+
+	rc, err := d.store.Get(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	decompReader, err := compressor.Decompress(rc)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = decompReader.Close() }()
+
+	return decodeAll(d.tolerantDecoder(codec, decompReader, filePath))
+}
+
+// tolerantDecoder returns a JSONL decoder over r that drops undecodable
+// lines of filePath per the dataset's WithOnDecodeError mode, or nil when
+// decode errors fail the read or codec is not JSONL.
+func (d *dataset) tolerantDecoder(codec Codec, r io.Reader, filePath string) *jsonlStreamDecoder {
+	j, ok := codec.(*jsonlCodec)
+	if !ok || d.onDecode.mode == DecodeErrorFail {
+		return nil
+	}
+	dec := j.newStreamDecoder(r)
+	dec.onBad = func(line int, err error) {
+		d.dropUndecodable(&DecodeError{Path: filePath, Line: line, Err: err})
+	}
+	return dec
+}
+
+// dropUndecodable reports whether the dataset's WithOnDecodeError mode
+// drops the record described by decodeErr, calling the callback if set.
+func (d *dataset) dropUndecodable(decodeErr *DecodeError) bool {
+	if d.onDecode.mode == DecodeErrorFail {
+		return false
+	}
+	if d.onDecode.callback != nil {
+		d.onDecode.callback(decodeErr)
+	}
+	return true
+}
+
+// marshalManifest encodes a manifest, indented when pretty is set.
+func marshalManifest(m *Manifest, pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(m, "", "  ")
+	}
+	return json.Marshal(m)
 }
 
 func (d *dataset) writeManifests(ctx context.Context, snapshotID DatasetSnapshotID, manifest *Manifest, partitionKeys []string) error {
-	data, err := json.MarshalIndent(manifest, "", "  ")
+	data, err := marshalManifest(manifest, d.pretty)
 	if err != nil {
 		return err
 	}
+	if _, plain := d.manifestC.(*noopCompressor); !plain {
+		if data, err = compressBytes(d.manifestC, data); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range d.manifestPaths(snapshotID, partitionKeys) {
+		if err := d.commitManifest(ctx, path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishCommit writes the _SUCCESS marker for a committed snapshot when
+// WithSuccessMarker is set. A failed marker Put returns a
+// *SuccessMarkerError instead of the snapshot.
+func (d *dataset) finishCommit(ctx context.Context, snap *DatasetSnapshot) (*DatasetSnapshot, error) {
+	if !d.success {
+		return snap, nil
+	}
+	marker := successMarkerPath(d.layout.manifestPath(d.id, snap.ID))
+	if err := d.store.Put(ctx, marker, bytes.NewReader(nil)); err != nil {
+		return nil, &SuccessMarkerError{Snapshot: snap, Path: marker, Err: err}
+	}
+	return snap, nil
+}
 
+// manifestPaths returns the object keys a snapshot's manifest is written to:
+// the canonical path, followed by one path per distinct partition when the
+// layout stores manifests inside partitions.
+func (d *dataset) manifestPaths(snapshotID DatasetSnapshotID, partitionKeys []string) []string {
 	pathSet := make(map[string]bool)
 	var manifestPaths []string
 
@@ -1009,29 +2669,51 @@ func (d *dataset) writeManifests(ctx context.Context, snapshotID DatasetSnapshot
 		manifestPaths = []string{d.layout.manifestPath(d.id, snapshotID)}
 	}
 
-	for _, path := range manifestPaths {
-		if err := d.store.Put(ctx, path, bytes.NewReader(data)); err != nil {
-			return err
+	return manifestPaths
+}
+
+// resolveReadCodec validates manifest components against the dataset
+// config and returns the codec and compressor to decode the snapshot with.
+// A nil codec means the snapshot is a raw blob.
+//
+// Without a codec registry the snapshot codec and compressor must match the
+// configured ones. With a registry, the manifest's recorded names are
+// authoritative: the codec is looked up in the registry and the compressor
+// among the built-ins when they differ. Each is chosen independently, so
+// any recorded combination decodes.
+func (d *dataset) resolveReadCodec(m *Manifest) (Codec, Compressor, error) {
+	compressor := d.compressor
+	if m.Compressor != d.compressor.Name() {
+		if d.codecs == nil {
+			return nil, nil, fmt.Errorf("lode: compressor mismatch: snapshot uses %q but dataset configured with %q",
+				m.Compressor, d.compressor.Name())
+		}
+		c, err := builtinCompressor(m.Compressor)
+		if err != nil {
+			return nil, nil, err
 		}
+		compressor = c
 	}
 
-	return nil
-}
-
-func (d *dataset) validateComponentsMatch(m *Manifest) error {
 	var expectedCodec string
 	if d.codec != nil {
 		expectedCodec = d.codec.Name()
 	}
-	if m.Codec != expectedCodec {
-		return fmt.Errorf("lode: codec mismatch: snapshot uses %q but dataset configured with %q",
+	if m.Codec == expectedCodec {
+		return d.codec, compressor, nil
+	}
+	if d.codecs == nil {
+		return nil, nil, fmt.Errorf("lode: codec mismatch: snapshot uses %q but dataset configured with %q",
 			m.Codec, expectedCodec)
 	}
-	if m.Compressor != d.compressor.Name() {
-		return fmt.Errorf("lode: compressor mismatch: snapshot uses %q but dataset configured with %q",
-			m.Compressor, d.compressor.Name())
+	if m.Codec == "" {
+		return nil, compressor, nil
 	}
-	return nil
+	codec, err := d.codecs.Get(m.Codec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return codec, compressor, nil
 }
 
 func (d *dataset) loadSnapshotFromPath(ctx context.Context, id DatasetSnapshotID, manifestPath string) (*DatasetSnapshot, error) {
@@ -1041,12 +2723,18 @@ func (d *dataset) loadSnapshotFromPath(ctx context.Context, id DatasetSnapshotID
 	}
 	defer func() { _ = rc.Close() }()
 
-	var manifest Manifest
-	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+	manifest, err := decodeManifest(rc)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode manifest: %w", err)
 	}
+	if err := checkFormatVersion(manifest.FormatVersion, manifestFormatVersion); err != nil {
+		return nil, err
+	}
+	if err := checkFilePaths(manifest); err != nil {
+		return nil, err
+	}
 
-	return &DatasetSnapshot{ID: id, Manifest: &manifest}, nil
+	return &DatasetSnapshot{ID: id, Manifest: manifest, layout: d.layout}, nil
 }
 
 func (d *dataset) findSnapshotByID(ctx context.Context, id DatasetSnapshotID) (*DatasetSnapshot, error) {
@@ -1074,18 +2762,22 @@ func generateID() string {
 }
 
 // extractTimestamps iterates over records and extracts min/max timestamps
-// from records that implement the Timestamped interface.
-// Returns nil pointers if no records implement Timestamped.
-func extractTimestamps(data []any) (minTs, maxTs *time.Time) {
+// from records that implement the Timestamped interface, or, failing that,
+// from the time timer parses from the record. Records timer cannot parse
+// are counted in skipped.
+// Returns nil pointers if no record has a timestamp.
+func extractTimestamps(data []any, timer recordTimer) (minTs, maxTs *time.Time, skipped int64) {
 	var hasTimestamp bool
 
 	for _, record := range data {
-		ts, ok := record.(Timestamped)
+		t, ok, skip := recordTimestamp(record, timer)
+		if skip {
+			skipped++
+		}
 		if !ok {
 			continue
 		}
 
-		t := ts.Timestamp()
 		if !hasTimestamp {
 			minTs = &t
 			maxTs = &t
@@ -1101,7 +2793,7 @@ func extractTimestamps(data []any) (minTs, maxTs *time.Time) {
 		}
 	}
 
-	return minTs, maxTs
+	return minTs, maxTs, skipped
 }
 
 // -----------------------------------------------------------------------------
@@ -1115,6 +2807,7 @@ type streamWriter struct {
 	metadata    Metadata
 	snapshotID  DatasetSnapshotID
 	parentID    DatasetSnapshotID
+	pointerID   DatasetSnapshotID
 	filePath    string
 	pipeWriter  *io.PipeWriter
 	compWriter  io.WriteCloser
@@ -1203,6 +2896,7 @@ func (sw *streamWriter) Commit(ctx context.Context) (*DatasetSnapshot, error) {
 		Path:      sw.filePath,
 		SizeBytes: sw.countWriter.n,
 	}
+	fileRef.ContentType, fileRef.ContentEncoding = contentMetadata(nil, sw.ds.compressor)
 	if sw.hasher != nil {
 		fileRef.Checksum = sw.hasher.Sum()
 	}
@@ -1230,7 +2924,7 @@ func (sw *streamWriter) Commit(ctx context.Context) (*DatasetSnapshot, error) {
 	// pointers on cold start. If this fails, no manifest is written and the
 	// commit is aborted. A pointer referencing a not-yet-existing snapshot is
 	// harmless (Exists check falls through to scan on the next cold start).
-	if err := sw.ds.writeLatestPointer(ctx, sw.snapshotID); err != nil {
+	if err := sw.ds.writeLatestPointer(ctx, sw.pointerID, sw.snapshotID); err != nil {
 		_ = sw.ds.store.Delete(ctx, sw.filePath) // best-effort cleanup
 		return nil, fmt.Errorf("lode: failed to update latest pointer: %w", err)
 	}
@@ -1246,10 +2940,11 @@ func (sw *streamWriter) Commit(ctx context.Context) (*DatasetSnapshot, error) {
 	sw.committed = true
 	sw.mu.Unlock()
 
-	return &DatasetSnapshot{
+	return sw.ds.finishCommit(ctx, &DatasetSnapshot{
 		ID:       sw.snapshotID,
 		Manifest: manifest,
-	}, nil
+		layout:   sw.ds.layout,
+	})
 }
 
 func (sw *streamWriter) Abort(ctx context.Context) error {
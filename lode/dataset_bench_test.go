@@ -1,8 +1,12 @@
 package lode
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -120,3 +124,193 @@ func BenchmarkDataset_SequentialWrites_StoreCallCount(b *testing.B) {
 		}
 	}
 }
+
+// discardDataStore wraps a Store and drains data file uploads without
+// retaining them, so allocation measurements reflect the write path rather
+// than the store's copy of the payload. Manifests and pointers are stored.
+type discardDataStore struct {
+	Store
+}
+
+func (s *discardDataStore) Put(ctx context.Context, p string, r io.Reader) error {
+	if strings.Contains(p, "/data/") {
+		_, err := io.Copy(io.Discard, r)
+		return err
+	}
+	return s.Store.Put(ctx, p, r)
+}
+
+// streamLargePayload streams size bytes of compressible synthetic data in
+// chunk-sized writes through StreamWrite.
+func streamLargePayload(ctx context.Context, ds Dataset, size, chunk int) error {
+	sw, err := ds.StreamWrite(ctx, Metadata{})
+	if err != nil {
+		return err
+	}
+	buf := bytes.Repeat([]byte("lode-synthetic-segment-"), chunk/23+1)[:chunk]
+	for written := 0; written < size; written += chunk {
+		if _, err := sw.Write(buf); err != nil {
+			_ = sw.Abort(ctx)
+			return err
+		}
+	}
+	_, err = sw.Commit(ctx)
+	return err
+}
+
+// BenchmarkDataset_StreamWrite_LargeSegment reports allocation (B/op) when
+// streaming a 64 MiB segment through each compressor.
+// StreamWrite pipes compressed output into Store.Put, so allocation stays
+// bounded by compressor and pipe buffers rather than growing with file size.
+func BenchmarkDataset_StreamWrite_LargeSegment(b *testing.B) {
+	const (
+		size  = 64 << 20
+		chunk = 64 << 10
+	)
+
+	compressors := map[string]Compressor{
+		"noop": NewNoOpCompressor(),
+		"gzip": NewGzipCompressor(),
+		"zstd": NewZstdCompressor(),
+	}
+	for name, comp := range compressors {
+		b.Run(name, func(b *testing.B) {
+			store := &discardDataStore{Store: NewMemory()}
+			ds, err := NewDataset("bench-ds", func() (Store, error) { return store, nil }, WithCompressor(comp))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.SetBytes(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := streamLargePayload(b.Context(), ds, size, chunk); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestDataset_StreamWrite_LargeSegment_BoundedAllocation verifies that the
+// streaming write path does not buffer the file: allocation for a 64 MiB
+// gzip segment stays far below the payload size.
+func TestDataset_StreamWrite_LargeSegment_BoundedAllocation(t *testing.T) {
+	if testing.Short() {
+		t.Skip("streams a 64 MiB payload")
+	}
+	const (
+		size  = 64 << 20
+		chunk = 64 << 10
+		limit = 8 << 20
+	)
+
+	store := &discardDataStore{Store: NewMemory()}
+	ds, err := NewDataset("test-ds", func() (Store, error) { return store, nil }, WithCompressor(NewGzipCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := streamLargePayload(t.Context(), ds, size, chunk); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	if got := after.TotalAlloc - before.TotalAlloc; got > limit {
+		t.Errorf("streaming a %d byte segment allocated %d bytes, want <= %d", size, got, limit)
+	}
+}
+
+// putLatencyStore wraps a Store and injects artificial latency on Put,
+// simulating per-request upload cost on remote stores.
+type putLatencyStore struct {
+	Store
+	latency time.Duration
+}
+
+func (s *putLatencyStore) Put(ctx context.Context, path string, r io.Reader) error {
+	time.Sleep(s.latency)
+	return s.Store.Put(ctx, path, r)
+}
+
+// BenchmarkDataset_WriteConcurrency measures a write of 16 partition files
+// with 5ms of Put latency. Sequential data uploads cost ~16 round trips;
+// with WithWriteConcurrency(8) they cost ~2. The per-partition manifests
+// and the latest pointer are still written one at a time.
+func BenchmarkDataset_WriteConcurrency(b *testing.B) {
+	var records []any
+	for i := range 16 {
+		records = append(records, D{"id": i, "day": i})
+	}
+
+	for _, concurrency := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			ps := &putLatencyStore{Store: NewMemory(), latency: 5 * time.Millisecond}
+			factory := func() (Store, error) { return ps, nil }
+
+			ds, err := NewDataset("bench-ds", factory,
+				WithCodec(NewJSONLCodec()),
+				WithHiveLayout("day"),
+				WithWriteConcurrency(concurrency))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := ds.Write(b.Context(), records, Metadata{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkCompressor_Throughput compares the built-in compressors on
+// representative JSONL. Reported MB/s is relative to the uncompressed size;
+// the ratio metric is uncompressed bytes per stored byte.
+func BenchmarkCompressor_Throughput(b *testing.B) {
+	var buf bytes.Buffer
+	for i := range 20000 {
+		fmt.Fprintf(&buf, `{"id":%d,"event":"page_view","path":"/products/%d","user_agent":"Mozilla/5.0 (X11; Linux x86_64)","ts":"2024-01-01T00:%02d:00Z"}`+"\n", i, i%37, i%60)
+	}
+	input := buf.Bytes()
+
+	brotli, err := NewBrotliCompressor(BrotliDefaultQuality)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, c := range []Compressor{NewGzipCompressor(), NewZstdCompressor(), brotli, NewLZ4Compressor()} {
+		compressed, err := compressBytes(c, input)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ratio := float64(len(input)) / float64(len(compressed))
+
+		b.Run(c.Name()+"/compress", func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for b.Loop() {
+				if _, err := compressBytes(c, input); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(ratio, "ratio")
+		})
+		b.Run(c.Name()+"/decompress", func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for b.Loop() {
+				rc, err := c.Decompress(bytes.NewReader(compressed))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := io.Copy(io.Discard, rc); err != nil {
+					b.Fatal(err)
+				}
+				_ = rc.Close()
+			}
+		})
+	}
+}
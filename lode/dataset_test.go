@@ -2,10 +2,20 @@ package lode
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"path"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -241,2651 +251,5364 @@ func TestDataset_Read_CompressorMismatch_ReturnsError(t *testing.T) {
 }
 
 // -----------------------------------------------------------------------------
-// G2-15: Empty metadata explicitly valid and persisted
+// ReadPartitionsWhere
 // -----------------------------------------------------------------------------
 
-func TestDataset_Write_EmptyMetadata_ValidAndPersisted(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_ReadPartitionsWhere_FetchesOnlyMatchingFiles(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write with explicitly empty metadata (not nil)
-	snap, err := ds.Write(t.Context(), []any{[]byte("data")}, Metadata{})
+	var records []any
+	for day := 1; day <= 10; day++ {
+		records = append(records, D{"id": day, "day": fmt.Sprintf("2024-01-%02d", day)})
+	}
+	snap, err := ds.Write(t.Context(), records, Metadata{})
 	if err != nil {
-		t.Fatalf("expected empty metadata to be valid, got error: %v", err)
+		t.Fatal(err)
+	}
+	if len(snap.Manifest.Files) != 10 {
+		t.Fatalf("expected 10 partition files, got %d", len(snap.Manifest.Files))
 	}
 
-	// Verify metadata is persisted as empty object, not nil
-	if snap.Manifest.Metadata == nil {
-		t.Fatal("expected metadata to be non-nil empty map, got nil")
+	fs.Reset()
+
+	got, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, func(p map[string]string) bool {
+		return p["day"] >= "2024-01-08"
+	})
+	if err != nil {
+		t.Fatalf("ReadPartitionsWhere failed: %v", err)
 	}
-	if len(snap.Manifest.Metadata) != 0 {
-		t.Errorf("expected empty metadata, got %d keys", len(snap.Manifest.Metadata))
+	if len(got) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(got))
+	}
+	for _, rec := range got {
+		if day := rec.(map[string]any)["day"].(string); day < "2024-01-08" {
+			t.Errorf("unexpected record from excluded partition %s", day)
+		}
 	}
 
-	// Verify we can read it back and metadata is preserved
-	retrieved, err := ds.Snapshot(t.Context(), snap.ID)
-	if err != nil {
-		t.Fatalf("failed to retrieve snapshot: %v", err)
+	var dataGets []string
+	for _, p := range fs.GetCalls() {
+		if !strings.HasSuffix(p, "manifest.json") {
+			dataGets = append(dataGets, p)
+		}
 	}
-	if retrieved.Manifest.Metadata == nil {
-		t.Fatal("retrieved metadata should be non-nil empty map, got nil")
+	if len(dataGets) != 3 {
+		t.Errorf("expected 3 data file Gets, got %d: %v", len(dataGets), dataGets)
 	}
-	if len(retrieved.Manifest.Metadata) != 0 {
-		t.Errorf("retrieved metadata should be empty, got %d keys", len(retrieved.Manifest.Metadata))
+	for _, p := range dataGets {
+		if !strings.Contains(p, "day=2024-01-08") && !strings.Contains(p, "day=2024-01-09") && !strings.Contains(p, "day=2024-01-10") {
+			t.Errorf("fetched excluded partition file %s", p)
+		}
 	}
 }
 
-func TestDataset_StreamWrite_EmptyMetadata_ValidAndPersisted(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_ReadPartition_FetchesOnlyTargetPartition(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("region", "day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
-	if err != nil {
-		t.Fatalf("expected empty metadata to be valid, got error: %v", err)
-	}
-
-	_, err = sw.Write([]byte("streaming data"))
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": 1, "region": "us", "day": "1"},
+		D{"id": 2, "region": "us", "day": "2"},
+		D{"id": 3, "region": "eu", "day": "1"},
+		D{"id": 4, "region": "us", "day": "1"},
+	), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	snap, err := sw.Commit(t.Context())
+	fs.Reset()
+	got, err := ds.ReadPartition(t.Context(), snap.ID, "region=us/day=1", ReadPartitionOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Verify metadata is persisted as empty object
-	if snap.Manifest.Metadata == nil {
-		t.Fatal("expected metadata to be non-nil empty map, got nil")
+	var ids []float64
+	for _, rec := range got {
+		ids = append(ids, rec.(map[string]any)["id"].(float64))
 	}
-	if len(snap.Manifest.Metadata) != 0 {
-		t.Errorf("expected empty metadata, got %d keys", len(snap.Manifest.Metadata))
+	if !reflect.DeepEqual(ids, []float64{1, 4}) {
+		t.Errorf("ids = %v, want [1 4]", ids)
+	}
+	var dataGets []string
+	for _, p := range fs.GetCalls() {
+		if !strings.HasSuffix(p, "manifest.json") {
+			dataGets = append(dataGets, p)
+		}
+	}
+	if len(dataGets) != 1 || !strings.Contains(dataGets[0], "region=us/day=1/") {
+		t.Errorf("data Gets = %v, want only the region=us/day=1 file", dataGets)
 	}
-}
 
-func TestDataset_StreamWriteRecords_EmptyMetadata_ValidAndPersisted(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	// A partition prefix is not a partition.
+	fs.Reset()
+	got, err = ds.ReadPartition(t.Context(), snap.ID, "region=us", ReadPartitionOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	iter := &sliceIterator{records: []any{D{"id": "1"}}}
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if err != nil {
-		t.Fatalf("expected empty metadata to be valid, got error: %v", err)
+	if got == nil || len(got) != 0 {
+		t.Errorf("expected empty non-nil result for absent partition, got %v", got)
 	}
-
-	// Verify metadata is persisted as empty object
-	if snap.Manifest.Metadata == nil {
-		t.Fatal("expected metadata to be non-nil empty map, got nil")
+	for _, p := range fs.GetCalls() {
+		if !strings.HasSuffix(p, "manifest.json") {
+			t.Errorf("fetched %s for an absent partition", p)
+		}
 	}
-	if len(snap.Manifest.Metadata) != 0 {
-		t.Errorf("expected empty metadata, got %d keys", len(snap.Manifest.Metadata))
+	_, err = ds.ReadPartition(t.Context(), snap.ID, "region=ap/day=1", ReadPartitionOptions{RequirePresent: true})
+	if !errors.Is(err, ErrPartitionNotFound) {
+		t.Errorf("expected ErrPartitionNotFound, got: %v", err)
 	}
 }
 
-// -----------------------------------------------------------------------------
-// G3-1: Snapshot NOT visible before Commit
-// Per CONTRACT_WRITE_API.md: "A snapshot MUST NOT be visible before Commit writes the manifest."
-// -----------------------------------------------------------------------------
-
-func TestDataset_StreamWrite_NotVisibleBeforeCommit(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_Write_HiveLayout_RecordsPartitionSummaries(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Start streaming write
-	sw, err := ds.StreamWrite(t.Context(), Metadata{"test": "value"})
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": 1, "day": "b"}, D{"id": 2, "day": "a"}, D{"id": 3, "day": "a"},
+	), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write data but don't commit yet
-	_, err = sw.Write([]byte("streaming data before commit"))
-	if err != nil {
-		t.Fatal(err)
+	got := snap.Manifest.Partitions
+	if len(got) != 2 || got[0].Path != "day=a" || got[1].Path != "day=b" {
+		t.Fatalf("expected sorted summaries for day=a and day=b, got %+v", got)
 	}
-
-	// Snapshot should NOT be visible before commit
-	_, err = ds.Latest(t.Context())
-	if !errors.Is(err, ErrNoSnapshots) {
-		t.Errorf("expected ErrNoSnapshots before commit, got: %v", err)
+	if got[0].RowCount != 2 || got[1].RowCount != 1 {
+		t.Errorf("row counts = %d, %d; want 2, 1", got[0].RowCount, got[1].RowCount)
+	}
+	for _, f := range snap.Manifest.Files {
+		i := slices.IndexFunc(got, func(p PartitionSummary) bool { return strings.Contains(f.Path, "/"+p.Path+"/") })
+		if i < 0 || got[i].SizeBytes != f.SizeBytes {
+			t.Errorf("summary for %s does not match its size %d: %+v", f.Path, f.SizeBytes, got)
+		}
+	}
+	if err := validateManifest(snap.Manifest); err != nil {
+		t.Errorf("written manifest fails validation: %v", err)
 	}
 
-	snapshots, err := ds.Snapshots(t.Context())
+	var calls int
+	records, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, func(p map[string]string) bool {
+		calls++
+		return p["day"] == "a"
+	})
 	if err != nil {
-		t.Fatalf("Snapshots() failed: %v", err)
+		t.Fatal(err)
 	}
-	if len(snapshots) != 0 {
-		t.Errorf("expected 0 snapshots before commit, got %d", len(snapshots))
+	if len(records) != 2 || calls != 2 {
+		t.Errorf("ReadPartitionsWhere: %d records with %d predicate calls, want 2 and 2", len(records), calls)
 	}
 
-	// Now commit
-	snap, err := sw.Commit(t.Context())
+	flat, err := NewDataset("flat-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
 	if err != nil {
-		t.Fatalf("Commit failed: %v", err)
+		t.Fatal(err)
 	}
-
-	// Snapshot should now be visible
-	latest, err := ds.Latest(t.Context())
+	flatSnap, err := flat.Write(t.Context(), R(D{"id": 1}), Metadata{})
 	if err != nil {
-		t.Fatalf("Latest() failed after commit: %v", err)
+		t.Fatal(err)
 	}
-	if latest.ID != snap.ID {
-		t.Errorf("expected latest ID %s, got %s", snap.ID, latest.ID)
+	if flatSnap.Manifest.Partitions != nil {
+		t.Errorf("unpartitioned manifest must omit summaries, got %+v", flatSnap.Manifest.Partitions)
 	}
 }
 
-func TestDataset_StreamWrite_NotVisibleBeforeCommit_WithExistingSnapshot(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_WriteConcurrency_UploadsInParallel(t *testing.T) {
+	const concurrency = 4
+
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"),
+		WithWriteConcurrency(concurrency))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Create an existing snapshot first
-	existingSnap, err := ds.Write(t.Context(), []any{[]byte("existing data")}, Metadata{})
+	// Data Puts block until `concurrency` of them are in flight at once.
+	var mu sync.Mutex
+	inFlight := 0
+	release := make(chan struct{})
+	unblock := sync.OnceFunc(func() { close(release) })
+	fs.SetPutBlock(release)
+	fs.SetBeforePut(func(p string) {
+		if !strings.Contains(p, "/data/") {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		inFlight++
+		if inFlight == concurrency {
+			unblock()
+		}
+	})
+	go func() {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+			mu.Lock()
+			t.Errorf("only %d uploads in flight, want %d", inFlight, concurrency)
+			mu.Unlock()
+			unblock()
+		}
+	}()
+
+	var records []any
+	for i := range 8 {
+		records = append(records, D{"id": i, "day": fmt.Sprintf("%02d", i)})
+	}
+	snap, err := ds.Write(t.Context(), records, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Start streaming write for second snapshot
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if len(snap.Manifest.Files) != 8 {
+		t.Fatalf("expected 8 files, got %d", len(snap.Manifest.Files))
+	}
+	for _, f := range snap.Manifest.Files {
+		if f.Path == "" {
+			t.Fatalf("manifest has an empty file ref: %+v", snap.Manifest.Files)
+		}
+	}
+	got, err := ds.Read(t.Context(), snap.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(got) != 8 {
+		t.Errorf("expected 8 records, got %d", len(got))
+	}
+}
 
-	_, err = sw.Write([]byte("new streaming data"))
-	if err != nil {
-		t.Fatal(err)
+func TestDataset_Write_FilesSortedForReproducibility(t *testing.T) {
+	var records []any
+	for i := range 16 {
+		records = append(records, D{"id": i, "day": fmt.Sprintf("%02d", 15-i)})
+	}
+
+	// Each write uses its own snapshot ID, which is part of every file
+	// path; everything else in Files must match across writes.
+	writeFiles := func() []FileRef {
+		t.Helper()
+		ds, err := NewDataset("test-ds", NewMemoryFactory(),
+			WithCodec(NewJSONLCodec()),
+			WithChecksum(NewMD5Checksum()),
+			WithHiveLayout("day"),
+			WithWriteConcurrency(8))
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap, err := ds.Write(t.Context(), records, Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		files := slices.Clone(snap.Manifest.Files)
+		for i := range files {
+			files[i].Path = strings.ReplaceAll(files[i].Path, string(snap.ID), "<snapshot>")
+		}
+		return files
 	}
 
-	// Latest should still be the existing snapshot, not the uncommitted one
-	latest, err := ds.Latest(t.Context())
-	if err != nil {
-		t.Fatalf("Latest() failed: %v", err)
+	first := writeFiles()
+	if len(first) != 16 {
+		t.Fatalf("expected 16 files, got %d", len(first))
 	}
-	if latest.ID != existingSnap.ID {
-		t.Errorf("expected latest to be existing snapshot %s, got %s", existingSnap.ID, latest.ID)
+	if !slices.IsSortedFunc(first, func(a, b FileRef) int { return strings.Compare(a.Path, b.Path) }) {
+		t.Errorf("manifest files are not sorted by path: %+v", first)
 	}
+	for range 5 {
+		if again := writeFiles(); !reflect.DeepEqual(first, again) {
+			t.Fatalf("repeated writes produced different Files:\n%+v\n%+v", first, again)
+		}
+	}
+}
 
-	// Snapshots list should only have one entry
-	snapshots, err := ds.Snapshots(t.Context())
+func TestDataset_WriteConcurrency_FailedUploadCleansUp(t *testing.T) {
+	errBoom := errors.New("boom")
+	mem := NewMemory()
+	fs := newFaultStore(mem)
+	fs.SetPutError(errBoom, "day=03")
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"),
+		WithWriteConcurrency(3))
 	if err != nil {
-		t.Fatalf("Snapshots() failed: %v", err)
+		t.Fatal(err)
 	}
-	if len(snapshots) != 1 {
-		t.Errorf("expected 1 snapshot before commit, got %d", len(snapshots))
+
+	var records []any
+	for i := range 6 {
+		records = append(records, D{"id": i, "day": fmt.Sprintf("%02d", i)})
+	}
+	if _, err := ds.Write(t.Context(), records, Metadata{}); !errors.Is(err, errBoom) {
+		t.Fatalf("expected upload error, got %v", err)
 	}
 
-	// Commit and verify new snapshot is visible
-	newSnap, err := sw.Commit(t.Context())
+	left, err := mem.List(t.Context(), "")
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(left) != 0 {
+		t.Errorf("expected failed write to leave no objects, got %v", left)
+	}
+}
 
-	latest, err = ds.Latest(t.Context())
-	if err != nil {
-		t.Fatalf("Latest() failed after commit: %v", err)
+func TestWithWriteConcurrency_Validation(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		if _, err := NewDataset("test-ds", NewMemoryFactory(), WithWriteConcurrency(n)); err == nil {
+			t.Errorf("WithWriteConcurrency(%d): expected error", n)
+		}
 	}
-	if latest.ID != newSnap.ID {
-		t.Errorf("expected latest to be new snapshot %s, got %s", newSnap.ID, latest.ID)
+	_, err := NewDatasetReader(NewMemoryFactory(), WithWriteConcurrency(2))
+	if !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got %v", err)
 	}
 }
 
-// -----------------------------------------------------------------------------
-// G3-2, G3-3: Abort semantics tests
-// Per CONTRACT_WRITE_API.md:
-// - "StreamWriter.Abort(ctx) MUST ensure no manifest is written."
-// - "StreamWriter.Close() without Commit MUST behave as Abort."
-//
-// RISK NOTE: Context cancellation during streaming has nondeterministic outcomes.
-// If the background store.Put completes before cancellation takes effect, the
-// data object is written. However, no manifest is written unless Commit succeeds.
-// The Abort path is deterministic and is tested explicitly below.
-// -----------------------------------------------------------------------------
-
-func TestDataset_StreamWrite_AbortDuringWrite_NoManifest(t *testing.T) {
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
+func TestDataset_Dedup_IdenticalRewriteUploadsNothing(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithChecksum(NewMD5Checksum()),
+		WithHiveLayout("day"),
+		WithDedup(true))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	records := R(D{"id": 1, "day": "a"}, D{"id": 2, "day": "b"})
+	first, err := ds.Write(t.Context(), records, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write some data
-	_, err = sw.Write([]byte("data that will be aborted"))
+	dataPuts := func() []string {
+		var paths []string
+		for _, p := range fs.PutCalls() {
+			if strings.Contains(p, "/data/") {
+				paths = append(paths, p)
+			}
+		}
+		return paths
+	}
+
+	fs.Reset()
+	second, err := ds.Write(t.Context(), records, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Abort explicitly
-	err = sw.Abort(t.Context())
+	if puts := dataPuts(); len(puts) != 0 {
+		t.Errorf("identical rewrite uploaded data files: %v", puts)
+	}
+	if !reflect.DeepEqual(second.Manifest.Files, first.Manifest.Files) {
+		t.Errorf("expected shared file refs %v, got %v", first.Manifest.Files, second.Manifest.Files)
+	}
+	got, err := ds.Read(t.Context(), second.ID)
 	if err != nil {
-		t.Fatalf("Abort failed: %v", err)
+		t.Fatal(err)
 	}
-
-	// Verify no snapshot is visible
-	_, latestErr := ds.Latest(t.Context())
-	if !errors.Is(latestErr, ErrNoSnapshots) {
-		t.Errorf("expected ErrNoSnapshots after abort, got: %v", latestErr)
+	if len(got) != 2 {
+		t.Errorf("expected 2 records from deduplicated snapshot, got %d", len(got))
 	}
 
-	// Verify no manifest in store
-	paths, err := store.List(t.Context(), "")
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	for _, p := range paths {
-		if strings.Contains(p, "manifest.json") {
-			t.Errorf("manifest should not exist after abort, found: %s", p)
+	for _, p := range []string{"day=a", "day=b"} {
+		report, err := reader.VerifySegment(t.Context(), "test-ds", ManifestRef{ID: second.ID, Partition: p}, VerifySegmentOptions{CheckSizes: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Missing) != 0 || len(report.Extra) != 0 || len(report.SizeMismatches) != 0 {
+			t.Errorf("shared files reported as drift: %+v", report)
 		}
 	}
-}
 
-func TestDataset_StreamWrite_CloseDuringWrite_NoManifest(t *testing.T) {
-	// Per CONTRACT_WRITE_API.md: "Close() without Commit MUST behave as Abort"
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
+	// Shared files are checked with one batch call, and a deleted one is
+	// reported missing.
+	shared := first.Manifest.Files[0].Path
+	saved, err := fs.Get(t.Context(), shared)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	sharedData, err := io.ReadAll(saved)
+	_ = saved.Close()
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	_, err = sw.Write([]byte("data that will be abandoned"))
+	if err := fs.Delete(t.Context(), shared); err != nil {
+		t.Fatal(err)
+	}
+	batch := &batchExistsStore{Store: fs}
+	reader, err = NewDatasetReader(NewMemoryFactoryFrom(batch), WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Close without commit (should behave as abort)
-	err = sw.Close()
+	report, err := reader.VerifySegment(t.Context(), "test-ds", ManifestRef{ID: second.ID}, VerifySegmentOptions{})
 	if err != nil {
-		t.Fatalf("Close failed: %v", err)
+		t.Fatal(err)
 	}
-
-	// Verify no snapshot is visible
-	_, latestErr := ds.Latest(t.Context())
-	if !errors.Is(latestErr, ErrNoSnapshots) {
-		t.Errorf("expected ErrNoSnapshots after close-without-commit, got: %v", latestErr)
+	if batch.calls != 1 {
+		t.Errorf("expected 1 ExistsMany call for shared files, got %d", batch.calls)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{shared}) {
+		t.Errorf("Missing = %v, want [%s]", report.Missing, shared)
+	}
+	if err := fs.Put(t.Context(), shared, bytes.NewReader(sharedData)); err != nil {
+		t.Fatal(err)
 	}
 
-	// Verify no manifest in store
-	paths, err := store.List(t.Context(), "")
+	// Only the changed partition is uploaded.
+	fs.Reset()
+	third, err := ds.Write(t.Context(), R(D{"id": 1, "day": "a"}, D{"id": 3, "day": "b"}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	for _, p := range paths {
-		if strings.Contains(p, "manifest.json") {
-			t.Errorf("manifest should not exist after close-without-commit, found: %s", p)
-		}
+	puts := dataPuts()
+	if len(puts) != 1 || !strings.Contains(puts[0], "day=b") || !strings.Contains(puts[0], string(third.ID)) {
+		t.Errorf("expected one upload for day=b, got %v", puts)
+	}
+
+	if _, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithDedup(true)); err == nil {
+		t.Error("expected error for WithDedup without WithChecksum")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithDedup(true)); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
 	}
 }
 
-func TestDataset_StreamWrite_WriteError_NoManifest(t *testing.T) {
-	// Test that write errors prevent manifest creation
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
+func TestDataset_ReadPartitionsWhere_UnescapesValues(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("region"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	snap, err := ds.Write(t.Context(), R(D{"id": 1, "region": "us east"}, D{"id": 2, "region": "eu"}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write data then abort to simulate failure path
-	_, err = sw.Write([]byte("data"))
+	got, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, func(p map[string]string) bool {
+		return p["region"] == "us east"
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Simulate failure by aborting
-	_ = sw.Abort(t.Context())
-
-	// Verify no manifest exists
-	paths, _ := store.List(t.Context(), "")
-	for _, p := range paths {
-		if strings.Contains(p, "manifest.json") {
-			t.Errorf("manifest should not exist after simulated failure, found: %s", p)
-		}
+	if len(got) != 1 {
+		t.Errorf("expected 1 record, got %d", len(got))
 	}
 }
 
-// RISK NOTE: Context cancellation during StreamWriteRecords
-//
-// StreamWriteRecords is an atomic operation from the caller's perspective.
-// If the context is cancelled mid-stream:
-// - The background store.Put may or may not have completed
-// - Partial data may remain in storage (best-effort cleanup attempted)
-// - No manifest will be written (the commit signal)
-//
-// The cleanup uses the caller's context for Delete, which may already be
-// cancelled. Per CONTRACT_STORAGE.md, cleanup SHOULD use an independent
-// context. Current implementation risk: cleanup may fail if context is
-// already cancelled.
-//
-// This is documented behavior, not a test failure condition.
-
-// -----------------------------------------------------------------------------
-// G3-4: Commit signal = manifest presence
-// Per CONTRACT_WRITE_API.md: "Manifest presence is the commit signal."
-// -----------------------------------------------------------------------------
-
-func TestDataset_StreamWrite_ManifestPresenceIsCommitSignal(t *testing.T) {
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
+func TestDataset_ReadPartitionsWhere_Unpartitioned(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	snap, err := ds.Write(t.Context(), R(D{"id": 1}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = sw.Write([]byte("test data"))
+	got, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, func(p map[string]string) bool {
+		return len(p) == 0
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Before commit: check that no manifest exists in store
-	paths, err := store.List(t.Context(), "")
-	if err != nil {
-		t.Fatal(err)
+	if len(got) != 1 {
+		t.Errorf("expected 1 record, got %d", len(got))
 	}
-	for _, p := range paths {
-		if strings.Contains(p, "manifest.json") {
-			t.Errorf("manifest should not exist before commit, found: %s", p)
-		}
+
+	if _, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, nil); err == nil {
+		t.Error("expected error for nil predicate")
 	}
+}
 
-	// Commit
-	_, err = sw.Commit(t.Context())
+func TestDataset_ReadFile_FetchesOnlyRequestedFile(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// After commit: manifest must exist
-	paths, err = store.List(t.Context(), "")
+	snap, err := ds.Write(t.Context(), R(D{"id": 1, "day": "a"}, D{"id": 2, "day": "b"}, D{"id": 3, "day": "b"}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	hasManifest := false
-	for _, p := range paths {
-		if strings.Contains(p, "manifest.json") {
-			hasManifest = true
-			break
+
+	var target string
+	for _, f := range snap.Manifest.Files {
+		if strings.Contains(f.Path, "day=b") {
+			target = f.Path
 		}
 	}
-	if !hasManifest {
-		t.Error("manifest should exist after commit")
+	fs.Reset()
+
+	got, err := ds.ReadFile(t.Context(), snap.ID, target)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 records, got %d", len(got))
+	}
+	for _, p := range fs.GetCalls() {
+		if !strings.HasSuffix(p, "manifest.json") && p != target {
+			t.Errorf("fetched unrequested file %s", p)
+		}
 	}
 }
 
-func TestDataset_StreamWriteRecords_ManifestPresenceIsCommitSignal(t *testing.T) {
+func TestDataset_ReadFile_NotInManifest(t *testing.T) {
 	store := NewMemory()
 	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	iter := &sliceIterator{records: []any{D{"id": "1"}, D{"id": "2"}}}
-	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	snap, err := ds.Write(t.Context(), R(D{"id": 1}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// After successful StreamWriteRecords: manifest must exist
-	paths, err := store.List(t.Context(), "")
-	if err != nil {
+	// Present in storage but not referenced by the manifest.
+	stray := snap.Manifest.Files[0].Path + ".stray"
+	if err := store.Put(t.Context(), stray, strings.NewReader("{}\n")); err != nil {
 		t.Fatal(err)
 	}
-	hasManifest := false
-	for _, p := range paths {
-		if strings.Contains(p, "manifest.json") {
-			hasManifest = true
-			break
-		}
+	if _, err := ds.ReadFile(t.Context(), snap.ID, stray); !errors.Is(err, ErrFileNotInManifest) {
+		t.Errorf("ReadFile: expected ErrFileNotInManifest, got: %v", err)
 	}
-	if !hasManifest {
-		t.Error("manifest should exist after successful StreamWriteRecords")
+	if _, err := ds.ReadFileRecords(t.Context(), snap.ID, stray); !errors.Is(err, ErrFileNotInManifest) {
+		t.Errorf("ReadFileRecords: expected ErrFileNotInManifest, got: %v", err)
 	}
 }
 
-func TestDataset_StreamWriteRecords_IteratorError_NoManifestWritten(t *testing.T) {
-	// Explicitly verifies manifest is not written on iterator error
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+func TestDataset_ReadFile_RawBlob(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	iterErr := errors.New("iterator failure")
-	iter := &errorIterator{err: iterErr}
-
-	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if err == nil {
-		t.Fatal("expected error from iterator, got nil")
-	}
-
-	// Verify no manifest was written
-	paths, err := store.List(t.Context(), "")
+	snap, err := ds.Write(t.Context(), []any{[]byte("payload")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	for _, p := range paths {
-		if strings.Contains(p, "manifest.json") {
-			t.Errorf("manifest should not exist after iterator error, found: %s", p)
-		}
-	}
-}
-
-// -----------------------------------------------------------------------------
-// Option validation tests
-// -----------------------------------------------------------------------------
 
-func TestDatasetReader_WithCompressor_ReturnsError(t *testing.T) {
-	// WithCompressor is a dataset-only option
-	_, err := NewDatasetReader(NewMemoryFactory(), WithCompressor(NewNoOpCompressor()))
-	if err == nil {
-		t.Fatal("expected error for WithCompressor on reader, got nil")
+	got, err := ds.ReadFile(t.Context(), snap.ID, snap.Manifest.Files[0].Path)
+	if err != nil {
+		t.Fatal(err)
 	}
-	if !strings.Contains(err.Error(), "not valid for reader") {
-		t.Errorf("expected 'not valid for reader' error, got: %v", err)
+	if len(got) != 1 || string(got[0].([]byte)) != "payload" {
+		t.Errorf("unexpected blob records: %v", got)
 	}
 }
 
-func TestDatasetReader_WithCodec_ReturnsError(t *testing.T) {
-	// WithCodec is a dataset-only option
-	_, err := NewDatasetReader(NewMemoryFactory(), WithCodec(&testCodec{}))
-	if err == nil {
-		t.Fatal("expected error for WithCodec on reader, got nil")
+func TestDataset_ReadSince_StreamsDescendants(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
 	}
-	if !strings.Contains(err.Error(), "not valid for reader") {
-		t.Errorf("expected 'not valid for reader' error, got: %v", err)
+	first, err := ds.Write(t.Context(), R(D{"day": "1", "n": 0}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
 	}
-}
-
-func TestDatasetReader_WithChecksum_ReturnsError(t *testing.T) {
-	// WithChecksum is a dataset-only option
-	_, err := NewDatasetReader(NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
-	if err == nil {
-		t.Fatal("expected error for WithChecksum on reader, got nil")
+	if _, err := ds.Write(t.Context(), R(D{"day": "1", "n": 1}, D{"day": "2", "n": 2}), Metadata{}); err != nil {
+		t.Fatal(err)
 	}
-	if !strings.Contains(err.Error(), "not valid for reader") {
-		t.Errorf("expected 'not valid for reader' error, got: %v", err)
+	latest, err := ds.Write(t.Context(), R(D{"day": "3", "n": 3}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
 	}
-}
 
-// -----------------------------------------------------------------------------
-// Empty dataset behavior tests
-// -----------------------------------------------------------------------------
-
-func TestDataset_Latest_EmptyDataset_ReturnsErrNoSnapshots(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	it, err := ds.ReadSince(t.Context(), first.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	_, err = ds.Latest(t.Context())
-	if !errors.Is(err, ErrNoSnapshots) {
-		t.Errorf("expected ErrNoSnapshots, got: %v", err)
+	var got []float64
+	for it.Next() {
+		got = append(got, it.Record().(map[string]any)["n"].(float64))
 	}
-}
-
-func TestDataset_Snapshots_EmptyDataset_ReturnsEmptyList(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
-	if err != nil {
+	if err := it.Err(); err != nil {
 		t.Fatal(err)
 	}
-
-	snapshots, err := ds.Snapshots(t.Context())
-	if err != nil {
-		t.Fatalf("expected no error, got: %v", err)
+	if err := it.Close(); err != nil {
+		t.Fatal(err)
 	}
-	if len(snapshots) != 0 {
-		t.Errorf("expected empty list, got %d snapshots", len(snapshots))
+	slices.Sort(got[:2])
+	if !slices.Equal(got, []float64{1, 2, 3}) {
+		t.Errorf("ReadSince records = %v, want [1 2 3]", got)
 	}
-}
 
-func TestDataset_Snapshot_EmptyDataset_ReturnsErrNotFound(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	empty, err := ds.ReadSince(t.Context(), latest.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if empty.Next() {
+		t.Errorf("ReadSince(latest) yielded %v, want no records", empty.Record())
+	}
+	_ = empty.Close()
 
-	_, err = ds.Snapshot(t.Context(), "nonexistent-id")
-	if !errors.Is(err, ErrNotFound) {
+	if _, err := ds.ReadSince(t.Context(), "missing"); !errors.Is(err, ErrNotFound) {
 		t.Errorf("expected ErrNotFound, got: %v", err)
 	}
 }
 
-// -----------------------------------------------------------------------------
-// Write validation tests
-// -----------------------------------------------------------------------------
-
-func TestDataset_Write_NilMetadata_CoalescesToEmpty(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_ReadN_OpensOnlyNeededFiles(t *testing.T) {
+	store := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	snap, err := ds.Write(t.Context(), []any{[]byte("data")}, nil)
+	snap, err := ds.Write(t.Context(), R(
+		D{"day": "1", "n": 0}, D{"day": "1", "n": 1},
+		D{"day": "2", "n": 2}, D{"day": "2", "n": 3},
+		D{"day": "3", "n": 4}, D{"day": "3", "n": 5},
+	), Metadata{})
 	if err != nil {
-		t.Fatalf("expected nil metadata to succeed, got: %v", err)
+		t.Fatal(err)
 	}
-	if snap.Manifest.Metadata == nil {
-		t.Fatal("expected non-nil metadata in manifest after nil coalescing")
+	dataFiles := make(map[string]bool)
+	for _, f := range snap.Manifest.Files {
+		dataFiles[f.Path] = true
 	}
-	if len(snap.Manifest.Metadata) != 0 {
-		t.Errorf("expected empty metadata, got %v", snap.Manifest.Metadata)
+	dataGets := func() int {
+		n := 0
+		for _, p := range store.GetCalls() {
+			if dataFiles[p] {
+				n++
+			}
+		}
+		return n
 	}
-}
 
-func TestDataset_RawBlobWrite_MultipleElements_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
-	if err != nil {
-		t.Fatal(err)
+	tests := []struct {
+		n, wantRecords, wantFiles int
+	}{
+		{0, 0, 0},
+		{1, 1, 1},
+		{3, 3, 2},
+		{6, 6, 3},
+		{100, 6, 3},
 	}
-
-	// Raw blob mode requires exactly one []byte element
-	_, err = ds.Write(t.Context(), []any{[]byte("one"), []byte("two")}, Metadata{})
-	if err == nil {
-		t.Fatal("expected error for multiple elements in raw blob mode, got nil")
+	for _, tt := range tests {
+		store.Reset()
+		records, err := ds.ReadN(t.Context(), snap.ID, tt.n)
+		if err != nil {
+			t.Fatalf("ReadN(%d): %v", tt.n, err)
+		}
+		if len(records) != tt.wantRecords {
+			t.Errorf("ReadN(%d) returned %d records, want %d", tt.n, len(records), tt.wantRecords)
+		}
+		if got := dataGets(); got != tt.wantFiles {
+			t.Errorf("ReadN(%d) opened %d data files, want %d", tt.n, got, tt.wantFiles)
+		}
 	}
-	if !strings.Contains(err.Error(), "exactly one data element") {
-		t.Errorf("expected 'exactly one data element' error, got: %v", err)
+
+	if _, err := ds.ReadN(t.Context(), snap.ID, -1); err == nil {
+		t.Error("expected error for negative limit")
 	}
 }
 
-func TestDataset_RawBlobWrite_WrongType_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
-	if err != nil {
+// closeCounter counts Close calls.
+type closeCounter struct{ n int }
+
+func (c *closeCounter) Close() error {
+	c.n++
+	return nil
+}
+
+func TestLimitRecords_ClosesAtLimit(t *testing.T) {
+	closer := &closeCounter{}
+	inner := newFileRecordIterator(&sliceRecordIterator{records: []any{"a", "b", "c"}}, closer)
+	it := LimitRecords(inner, 2)
+
+	var got []any
+	for it.Next() {
+		got = append(got, it.Record())
+		if len(got) == 2 && closer.n != 1 {
+			t.Errorf("inner iterator closed %d times at the limit, want 1", closer.n)
+		}
+	}
+	if err := it.Err(); err != nil {
 		t.Fatal(err)
 	}
-
-	// Raw blob mode requires []byte, not string
-	_, err = ds.Write(t.Context(), []any{"not a byte slice"}, Metadata{})
-	if err == nil {
-		t.Fatal("expected error for wrong type in raw blob mode, got nil")
+	if !reflect.DeepEqual(got, []any{"a", "b"}) {
+		t.Errorf("records = %v, want [a b]", got)
 	}
-	if !strings.Contains(err.Error(), "requires []byte") {
-		t.Errorf("expected '[]byte' error, got: %v", err)
+	if err := it.Close(); err != nil || closer.n != 1 {
+		t.Errorf("Close() = %v after %d inner closes, want one close", err, closer.n)
 	}
-}
 
-// -----------------------------------------------------------------------------
-// StreamWrite tests
-// -----------------------------------------------------------------------------
+	closer = &closeCounter{}
+	none := LimitRecords(newFileRecordIterator(&sliceRecordIterator{records: []any{"a"}}, closer), 0)
+	if none.Next() || closer.n != 1 {
+		t.Errorf("zero limit: Next() should be false and close the inner iterator once, closes = %d", closer.n)
+	}
+}
 
-func TestDataset_StreamWrite_Success(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_ReadN_RawBlob(t *testing.T) {
+	ds, err := NewDataset("blobs", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{"source": "test"})
+	snap, err := ds.Write(t.Context(), []any{[]byte("blob")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Write some data
-	data := []byte("hello stream world")
-	n, err := sw.Write(data)
+	records, err := ds.ReadN(t.Context(), snap.ID, 5)
 	if err != nil {
-		t.Fatalf("Write failed: %v", err)
+		t.Fatal(err)
 	}
-	if n != len(data) {
-		t.Errorf("expected %d bytes written, got %d", len(data), n)
+	if len(records) != 1 || string(records[0].([]byte)) != "blob" {
+		t.Errorf("ReadN = %v, want the blob", records)
 	}
+}
 
-	// Commit and verify snapshot
-	snap, err := sw.Commit(t.Context())
+func TestDataset_Sample(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
-		t.Fatalf("Commit failed: %v", err)
-	}
-
-	if snap == nil {
-		t.Fatal("expected snapshot, got nil")
+		t.Fatal(err)
 	}
-	if snap.Manifest.RowCount != 1 {
-		t.Errorf("expected RowCount 1, got %d", snap.Manifest.RowCount)
+	var records []any
+	for i := range 100 {
+		records = append(records, D{"day": fmt.Sprint(i % 4), "n": i})
 	}
-	if len(snap.Manifest.Files) != 1 {
-		t.Errorf("expected 1 file, got %d", len(snap.Manifest.Files))
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
 	}
-	if snap.Manifest.Metadata["source"] != "test" {
-		t.Errorf("expected metadata source=test, got %v", snap.Manifest.Metadata)
+	sampleN := func(k int, seed uint64) []float64 {
+		t.Helper()
+		sample, err := ds.Sample(t.Context(), snap.ID, k, SampleOptions{Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ns := make([]float64, len(sample))
+		for i, r := range sample {
+			ns[i] = r.(map[string]any)["n"].(float64)
+		}
+		return ns
 	}
 
-	// Verify snapshot is visible via Latest
-	latest, err := ds.Latest(t.Context())
-	if err != nil {
-		t.Fatalf("Latest failed: %v", err)
+	first := sampleN(10, 42)
+	if len(first) != 10 {
+		t.Fatalf("sample size = %d, want 10", len(first))
 	}
-	if latest.ID != snap.ID {
-		t.Errorf("expected latest ID %s, got %s", snap.ID, latest.ID)
+	if again := sampleN(10, 42); !slices.Equal(first, again) {
+		t.Errorf("same seed gave different samples: %v vs %v", first, again)
 	}
-
-	// Verify data can be read back
-	readData, err := ds.Read(t.Context(), snap.ID)
-	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+	if distinct := len(slices.Compact(slices.Sorted(slices.Values(first)))); distinct != 10 {
+		t.Errorf("sample has %d distinct records, want 10", distinct)
 	}
-	if len(readData) != 1 {
-		t.Fatalf("expected 1 element, got %d", len(readData))
+	if all := sampleN(1000, 42); len(all) != 100 {
+		t.Errorf("oversized sample returned %d records, want all 100", len(all))
 	}
-	readBytes, ok := readData[0].([]byte)
-	if !ok {
-		t.Fatalf("expected []byte, got %T", readData[0])
+	if none := sampleN(0, 42); len(none) != 0 {
+		t.Errorf("empty sample returned %d records", len(none))
 	}
-	if string(readBytes) != string(data) {
-		t.Errorf("expected %q, got %q", data, readBytes)
+	if _, err := ds.Sample(t.Context(), snap.ID, -1, SampleOptions{}); err == nil {
+		t.Error("expected error for negative sample size")
 	}
-}
 
-func TestDataset_StreamWrite_Abort_NoManifest(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
-	if err != nil {
-		t.Fatal(err)
+	// Every record is equally likely to be sampled: over 400 fixed seeds each
+	// record's inclusion count is Binomial(400, 0.1), mean 40, sd 6.
+	counts := make(map[float64]int)
+	for seed := range uint64(400) {
+		for _, n := range sampleN(10, seed+1) {
+			counts[n]++
+		}
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
-	if err != nil {
-		t.Fatal(err)
+	for i := range 100 {
+		if c := counts[float64(i)]; c < 15 || c > 65 {
+			t.Errorf("record %d sampled %d times in 400 draws, want about 40", i, c)
+		}
 	}
+}
 
-	// Write some data
-	_, err = sw.Write([]byte("partial data"))
-	if err != nil {
-		t.Fatalf("Write failed: %v", err)
-	}
+// openTrackingStore counts objects returned by Get that are not yet closed.
+type openTrackingStore struct {
+	Store
+	open atomic.Int64
+}
 
-	// Abort
-	err = sw.Abort(t.Context())
+func (s *openTrackingStore) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	rc, err := s.Store.Get(ctx, path)
 	if err != nil {
-		t.Fatalf("Abort failed: %v", err)
+		return nil, err
 	}
+	s.open.Add(1)
+	return &trackedReadCloser{ReadCloser: rc, open: &s.open}, nil
+}
 
-	// Verify no snapshot visible
-	_, err = ds.Latest(t.Context())
-	if !errors.Is(err, ErrNoSnapshots) {
-		t.Errorf("expected ErrNoSnapshots after abort, got: %v", err)
-	}
+type trackedReadCloser struct {
+	io.ReadCloser
+	open *atomic.Int64
+	once sync.Once
+}
 
-	snapshots, err := ds.Snapshots(t.Context())
-	if err != nil {
-		t.Fatalf("Snapshots failed: %v", err)
-	}
-	if len(snapshots) != 0 {
-		t.Errorf("expected 0 snapshots after abort, got %d", len(snapshots))
-	}
+func (r *trackedReadCloser) Close() error {
+	r.once.Do(func() { r.open.Add(-1) })
+	return r.ReadCloser.Close()
 }
 
-func TestDataset_StreamWrite_CloseWithoutCommit_BehavesAsAbort(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_ReadChan(t *testing.T) {
+	store := &openTrackingStore{Store: NewMemory()}
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	var records []any
+	for i := range 100 {
+		records = append(records, D{"day": fmt.Sprint(i % 4), "n": i})
+	}
+	snap, err := ds.Write(t.Context(), records, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Write some data
-	_, err = sw.Write([]byte("will be abandoned"))
+	want, err := ds.Read(t.Context(), snap.ID)
 	if err != nil {
-		t.Fatalf("Write failed: %v", err)
+		t.Fatal(err)
 	}
 
-	// Close without commit
-	err = sw.Close()
-	if err != nil {
-		t.Fatalf("Close failed: %v", err)
+	recs, errs := ds.ReadChan(t.Context(), snap.ID, ReadChanOptions{Buffer: 4})
+	var got []any
+	for r := range recs {
+		got = append(got, r)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadChan returned %d records, want the %d records of Read in order", len(got), len(want))
+	}
+	if n := store.open.Load(); n != 0 {
+		t.Errorf("%d objects left open after ReadChan", n)
 	}
 
-	// Verify no snapshot visible
-	_, err = ds.Latest(t.Context())
-	if !errors.Is(err, ErrNoSnapshots) {
-		t.Errorf("expected ErrNoSnapshots after close without commit, got: %v", err)
+	_, errs = ds.ReadChan(t.Context(), "missing", ReadChanOptions{})
+	if err := <-errs; !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing snapshot, got: %v", err)
+	}
+	_, errs = ds.ReadChan(t.Context(), snap.ID, ReadChanOptions{Buffer: -1})
+	if err := <-errs; err == nil {
+		t.Error("expected error for negative buffer")
 	}
 }
 
-func TestDataset_StreamWrite_NilMetadata_CoalescesToEmpty(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_ReadChan_Cancel(t *testing.T) {
+	store := &openTrackingStore{Store: NewMemory()}
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), nil)
-	if err != nil {
-		t.Fatalf("expected nil metadata to succeed, got: %v", err)
+	var records []any
+	for i := range 1000 {
+		records = append(records, D{"n": i})
 	}
-	_, _ = sw.Write([]byte("data"))
-	snap, err := sw.Commit(t.Context())
+	snap, err := ds.Write(t.Context(), records, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if snap.Manifest.Metadata == nil {
-		t.Fatal("expected non-nil metadata in manifest after nil coalescing")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	recs, errs := ds.ReadChan(ctx, snap.ID, ReadChanOptions{})
+	for range 3 {
+		<-recs
 	}
-	if len(snap.Manifest.Metadata) != 0 {
-		t.Errorf("expected empty metadata, got %v", snap.Manifest.Metadata)
+	cancel()
+
+	// The producer is blocked sending; cancellation must close both channels
+	// promptly without the consumer draining the remaining records.
+	timeout := time.After(5 * time.Second)
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	case <-timeout:
+		t.Fatal("error channel not closed after cancellation")
+	}
+	if _, ok := <-errs; ok {
+		t.Error("error channel delivered more than one error")
+	}
+	drained := 0
+	for range recs {
+		drained++
+	}
+	if drained > 1 {
+		t.Errorf("%d records sent after cancellation", drained)
+	}
+	if n := store.open.Load(); n != 0 {
+		t.Errorf("%d objects left open after cancellation", n)
 	}
 }
 
-func TestDataset_StreamWrite_WithCodec_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_ReadFrom_ResumesFromCheckpoint(t *testing.T) {
+	ctx := t.Context()
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	_, err = ds.StreamWrite(t.Context(), Metadata{})
-	if !errors.Is(err, ErrCodecConfigured) {
-		t.Errorf("expected ErrCodecConfigured, got: %v", err)
+	var records []any
+	for i := range 40 {
+		records = append(records, D{"day": fmt.Sprint(i % 4), "n": i})
 	}
-}
-
-func TestDataset_StreamWrite_WriteAfterCommit_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	snap, err := ds.Write(ctx, records, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	// An export flushes its output whenever the checkpoint advances, then
+	// persists the checkpoint. Records after the last flush are lost when
+	// it stops halfway.
+	var (
+		exported []float64
+		pending  []float64
+		saved    []byte
+		flushed  int
+	)
+	it, err := ds.ReadFrom(ctx, ReadCheckpoint{SnapshotID: snap.ID})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	_, err = sw.Write([]byte("data"))
-	if err != nil {
+	for read := 0; read < 25 && it.Next(); read++ {
+		if cp := it.Checkpoint(); len(cp.Completed) > flushed {
+			exported = append(exported, pending...)
+			pending = nil
+			flushed = len(cp.Completed)
+			if saved, err = json.Marshal(cp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		pending = append(pending, it.Record().(map[string]any)["n"].(float64))
+	}
+	if err := it.Close(); err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = sw.Commit(t.Context())
-	if err != nil {
+	var cp ReadCheckpoint
+	if err := json.Unmarshal(saved, &cp); err != nil {
 		t.Fatal(err)
 	}
-
-	// Write after commit should fail
-	_, err = sw.Write([]byte("more data"))
-	if err == nil {
-		t.Fatal("expected error writing after commit")
+	if len(cp.Completed) != 2 {
+		t.Fatalf("checkpoint after 25 of 40 records completed %d files, want 2", len(cp.Completed))
 	}
-}
 
-func TestDataset_StreamWrite_WriteAfterAbort_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	it, err = ds.ReadFrom(ctx, cp)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
-	if err != nil {
-		t.Fatal(err)
+	defer func() { _ = it.Close() }()
+	for it.Next() {
+		exported = append(exported, it.Record().(map[string]any)["n"].(float64))
 	}
-
-	_, err = sw.Write([]byte("data"))
-	if err != nil {
+	if err := it.Err(); err != nil {
 		t.Fatal(err)
 	}
 
-	err = sw.Abort(t.Context())
-	if err != nil {
-		t.Fatal(err)
+	slices.Sort(exported)
+	want := make([]float64, 40)
+	for i := range want {
+		want[i] = float64(i)
+	}
+	if !slices.Equal(exported, want) {
+		t.Errorf("resumed export = %v, want each of 0..39 once", exported)
+	}
+	if final := it.Checkpoint(); len(final.Completed) != len(snap.Manifest.Files) {
+		t.Errorf("final checkpoint completed %d files, want all %d", len(final.Completed), len(snap.Manifest.Files))
 	}
 
-	// Write after abort should fail
-	_, err = sw.Write([]byte("more data"))
-	if err == nil {
-		t.Fatal("expected error writing after abort")
+	if _, err := ds.ReadFrom(ctx, ReadCheckpoint{SnapshotID: snap.ID, Completed: []string{"other/file.jsonl"}}); !errors.Is(err, ErrFileNotInManifest) {
+		t.Errorf("expected ErrFileNotInManifest for unknown completed path, got: %v", err)
 	}
 }
 
-func TestDataset_StreamWrite_DoubleCommit_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+func TestDataset_OpenStream(t *testing.T) {
+	ctx := t.Context()
+	store := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	snap, err := ds.Write(ctx, R(
+		D{"day": "1", "n": 0}, D{"day": "2", "n": 1},
+		D{"day": "3", "n": 2}, D{"day": "1", "n": 3},
+	), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = sw.Write([]byte("data"))
-	if err != nil {
-		t.Fatal(err)
+	// Expected bytes: each file's records re-encoded, in manifest order.
+	var want bytes.Buffer
+	for _, f := range snap.Manifest.Files {
+		records, err := ds.ReadFile(ctx, snap.ID, f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := NewJSONLCodec().Encode(&want, records); err != nil {
+			t.Fatal(err)
+		}
 	}
 
-	_, err = sw.Commit(t.Context())
+	stream, err := ds.OpenStream(ctx, snap.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Second commit should fail
-	_, err = sw.Commit(t.Context())
-	if err == nil {
-		t.Fatal("expected error on double commit")
-	}
-}
-
-func TestDataset_StreamWrite_AbortAfterCommit_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	got, err := io.ReadAll(stream)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
-	if err != nil {
+	if err := stream.Close(); err != nil {
 		t.Fatal(err)
 	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("stream = %q, want %q", got, want.Bytes())
+	}
 
-	_, err = sw.Write([]byte("data"))
+	// A partial read opens only the first file; Close releases it.
+	store.Reset()
+	stream, err = ds.OpenStream(ctx, snap.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	_, err = sw.Commit(t.Context())
-	if err != nil {
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(stream, buf); err != nil {
 		t.Fatal(err)
 	}
-
-	// Abort after commit should fail
-	err = sw.Abort(t.Context())
-	if err == nil {
-		t.Fatal("expected error aborting after commit")
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var dataGets []string
+	for _, p := range store.GetCalls() {
+		if strings.Contains(p, "/data/") {
+			dataGets = append(dataGets, p)
+		}
+	}
+	if len(dataGets) != 1 || dataGets[0] != snap.Manifest.Files[0].Path {
+		t.Errorf("partial read fetched %v, want only the first data file", dataGets)
+	}
+	if _, err := stream.Read(buf); err == nil {
+		t.Error("expected error reading a closed stream")
 	}
-}
 
-func TestDataset_StreamWrite_ParentSnapshotLinked(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
-	if err != nil {
+	// A missing file fails mid-stream after the earlier files' bytes.
+	if err := store.Delete(ctx, snap.Manifest.Files[1].Path); err != nil {
 		t.Fatal(err)
 	}
-
-	// First write (regular Write)
-	firstSnap, err := ds.Write(t.Context(), []any{[]byte("first")}, Metadata{})
+	stream, err = ds.OpenStream(ctx, snap.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Second write (StreamWrite)
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	defer func() { _ = stream.Close() }()
+	got, err = io.ReadAll(stream)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound mid-stream, got: %v", err)
+	}
+	first, err := ds.ReadFile(ctx, snap.ID, snap.Manifest.Files[0].Path)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, _ = sw.Write([]byte("second"))
-	secondSnap, err := sw.Commit(t.Context())
-	if err != nil {
+	var firstBytes bytes.Buffer
+	if err := NewJSONLCodec().Encode(&firstBytes, first); err != nil {
 		t.Fatal(err)
 	}
+	if !bytes.Equal(got, firstBytes.Bytes()) {
+		t.Errorf("bytes before the failure = %q, want the first file %q", got, firstBytes.Bytes())
+	}
+}
 
-	if secondSnap.Manifest.ParentSnapshotID != firstSnap.ID {
-		t.Errorf("expected parent %s, got %s", firstSnap.ID, secondSnap.Manifest.ParentSnapshotID)
+func TestDataset_ReadFileRecords_Iterates(t *testing.T) {
+	records := R(D{"id": "1"}, D{"id": "2"}, D{"id": "3"})
+
+	tests := map[string]Codec{
+		"streaming decoder": NewJSONLCodec(),
+		"decode fallback":   struct{ Codec }{NewJSONLCodec()},
+	}
+	for name, codec := range tests {
+		t.Run(name, func(t *testing.T) {
+			ds, err := NewDataset("test-ds", NewMemoryFactory(),
+				WithCodec(codec),
+				WithCompressor(NewGzipCompressor()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			snap, err := ds.Write(t.Context(), records, Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			it, err := ds.ReadFileRecords(t.Context(), snap.ID, snap.Manifest.Files[0].Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []any
+			for it.Next() {
+				got = append(got, it.Record())
+			}
+			if err := it.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(records) || got[2].(map[string]any)["id"] != "3" {
+				t.Errorf("unexpected records: %v", got)
+			}
+
+			if err := it.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if it.Next() {
+				t.Error("Next must return false after Close")
+			}
+			if err := it.Close(); err != nil {
+				t.Errorf("Close must be idempotent, got: %v", err)
+			}
+		})
 	}
 }
 
-func TestDataset_StreamWrite_WithGzipCompression(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCompressor(NewGzipCompressor()))
+func TestDataset_OnDecodeError_SkipsBadLinesPerFile(t *testing.T) {
+	store := NewMemory()
+	writer, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithHiveLayout("day"),
+		WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	snap, err := writer.Write(t.Context(), R(D{"day": "a"}, D{"day": "b"}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	data := []byte("compressible data that should be gzipped")
-	_, err = sw.Write(data)
-	if err != nil {
-		t.Fatalf("Write failed: %v", err)
+	// Replace both data files with partially corrupt content.
+	corrupt := map[string]string{
+		"day=a": "{\"id\":1}\nnot json\n{\"id\":2}\n",
+		"day=b": "{\"id\":3}\n{bad\n",
 	}
-
-	snap, err := sw.Commit(t.Context())
-	if err != nil {
-		t.Fatalf("Commit failed: %v", err)
+	files := make(map[string]string)
+	for _, f := range snap.Manifest.Files {
+		partition := writer.(*dataset).layout.extractPartitionPath(f.Path)
+		files[partition] = f.Path
+		if err := store.Delete(t.Context(), f.Path); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Put(t.Context(), f.Path, strings.NewReader(corrupt[partition])); err != nil {
+			t.Fatal(err)
+		}
 	}
 
-	// Verify manifest indicates gzip compression
-	if snap.Manifest.Compressor != "gzip" {
-		t.Errorf("expected compressor 'gzip', got %q", snap.Manifest.Compressor)
+	if _, err := writer.Read(t.Context(), snap.ID); err == nil {
+		t.Fatal("expected DecodeErrorFail to fail the read")
 	}
 
-	// Verify file path ends with .gz
-	if len(snap.Manifest.Files) != 1 {
-		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
+	skipper, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithHiveLayout("day"),
+		WithCodec(NewJSONLCodec()),
+		WithOnDecodeError(DecodeErrorSkip, nil))
+	if err != nil {
+		t.Fatal(err)
 	}
-	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".gz") {
-		t.Errorf("expected .gz extension, got %s", snap.Manifest.Files[0].Path)
+	records, err := skipper.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read with DecodeErrorSkip failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("expected 3 good records, got %v", records)
 	}
 
-	// Verify data can be read back correctly
-	readData, err := ds.Read(t.Context(), snap.ID)
+	it, err := skipper.ReadSince(t.Context(), "")
 	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+		t.Fatal(err)
 	}
-	readBytes, ok := readData[0].([]byte)
-	if !ok {
-		t.Fatalf("expected []byte, got %T", readData[0])
+	n := 0
+	for it.Next() {
+		n++
 	}
-	if string(readBytes) != string(data) {
-		t.Errorf("expected %q, got %q", data, readBytes)
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || it.SkippedCount() != 2 {
+		t.Errorf("ReadSince: %d records, %d skipped; want 3 and 2", n, it.SkippedCount())
+	}
+	_ = it.Close()
+	if it.SkippedCount() != 2 {
+		t.Errorf("SkippedCount after Close = %d, want 2", it.SkippedCount())
 	}
-}
 
-func TestDataset_StreamWrite_WithZstdCompression(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCompressor(NewZstdCompressor()))
+	fileIt, err := skipper.ReadFileRecords(t.Context(), snap.ID, files["day=b"])
 	if err != nil {
 		t.Fatal(err)
 	}
+	for fileIt.Next() {
+	}
+	if fileIt.Err() != nil || fileIt.SkippedCount() != 1 {
+		t.Errorf("ReadFileRecords: err %v, %d skipped; want 1", fileIt.Err(), fileIt.SkippedCount())
+	}
+	_ = fileIt.Close()
 
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	var bad []*DecodeError
+	reporter, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithHiveLayout("day"),
+		WithCodec(NewJSONLCodec()),
+		WithOnDecodeError(DecodeErrorCallback, func(e *DecodeError) { bad = append(bad, e) }))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	data := []byte("compressible data that should be zstd compressed")
-	_, err = sw.Write(data)
-	if err != nil {
-		t.Fatalf("Write failed: %v", err)
+	if _, err := ReadTyped[map[string]int](t.Context(), reporter, snap.ID); err != nil {
+		t.Fatalf("ReadTyped with DecodeErrorCallback failed: %v", err)
 	}
-
-	snap, err := sw.Commit(t.Context())
-	if err != nil {
-		t.Fatalf("Commit failed: %v", err)
+	if _, err := reporter.Read(t.Context(), snap.ID); err != nil {
+		t.Fatal(err)
 	}
-
-	// Verify manifest indicates zstd compression
-	if snap.Manifest.Compressor != "zstd" {
-		t.Errorf("expected compressor 'zstd', got %q", snap.Manifest.Compressor)
+	if len(bad) != 4 {
+		t.Fatalf("expected 4 callbacks (2 per read), got %d", len(bad))
 	}
-
-	// Verify file path ends with .zst
-	if len(snap.Manifest.Files) != 1 {
-		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
+	for _, e := range bad[2:] {
+		if e.Line != 2 || !errors.Is(e, ErrInvalidFormat) {
+			t.Errorf("expected per-file line 2 matching ErrInvalidFormat, got %s line %d", e.Path, e.Line)
+		}
 	}
-	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".zst") {
-		t.Errorf("expected .zst extension, got %s", snap.Manifest.Files[0].Path)
+	if bad[2].Path == bad[3].Path {
+		t.Errorf("expected one callback per file, got %s twice", bad[2].Path)
 	}
+}
 
-	// Verify data can be read back correctly
-	readData, err := ds.Read(t.Context(), snap.ID)
-	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+func TestWithOnDecodeError_Validation(t *testing.T) {
+	tests := map[string]Option{
+		"callback without fn": WithOnDecodeError(DecodeErrorCallback, nil),
+		"fn with skip":        WithOnDecodeError(DecodeErrorSkip, func(*DecodeError) {}),
+		"unknown mode":        WithOnDecodeError(DecodeErrorMode(9), nil),
 	}
-	readBytes, ok := readData[0].([]byte)
-	if !ok {
-		t.Fatalf("expected []byte, got %T", readData[0])
+	for name, opt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewDataset("test-ds", NewMemoryFactory(), opt); err == nil {
+				t.Error("expected error")
+			}
+		})
 	}
-	if string(readBytes) != string(data) {
-		t.Errorf("expected %q, got %q", data, readBytes)
+
+	_, err := NewDatasetReader(NewMemoryFactory(), WithOnDecodeError(DecodeErrorSkip, nil))
+	if !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
 	}
 }
 
-func TestDataset_Write_WithZstdCompression(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+func TestDataset_Read_ContextCanceledMidRead_ReturnsPromptly(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
 		WithCodec(NewJSONLCodec()),
-		WithCompressor(NewZstdCompressor()))
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := []any{
-		D{"id": "1", "data": "compressible zstd content"},
-		D{"id": "2", "data": "more compressible zstd content"},
+	var records []any
+	for day := 1; day <= 10; day++ {
+		records = append(records, D{"id": day, "day": fmt.Sprintf("2024-01-%02d", day)})
 	}
-
 	snap, err := ds.Write(t.Context(), records, Metadata{})
 	if err != nil {
-		t.Fatalf("Write failed: %v", err)
+		t.Fatal(err)
 	}
 
-	// Verify manifest indicates zstd compression
-	if snap.Manifest.Compressor != "zstd" {
-		t.Errorf("expected compressor 'zstd', got %q", snap.Manifest.Compressor)
-	}
-	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".zst") {
-		t.Errorf("expected .zst extension, got %s", snap.Manifest.Files[0].Path)
+	// Cancel as soon as the first data file is fetched.
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	fs.Reset()
+	fs.SetBeforeGet(func(path string) {
+		if !strings.HasSuffix(path, "manifest.json") {
+			cancel()
+		}
+	})
+
+	_, err = ds.Read(ctx, snap.ID)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got: %v", err)
 	}
 
-	// Verify data can be read back
-	readData, err := ds.Read(t.Context(), snap.ID)
-	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+	dataGets := 0
+	for _, p := range fs.GetCalls() {
+		if !strings.HasSuffix(p, "manifest.json") {
+			dataGets++
+		}
 	}
-	if len(readData) != 2 {
-		t.Errorf("expected 2 records, got %d", len(readData))
+	if dataGets != 1 {
+		t.Errorf("expected read to stop after 1 data file, fetched %d", dataGets)
 	}
 }
 
-func TestDataset_StreamWriteRecords_WithZstdCompression(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(),
-		WithCodec(NewJSONLCodec()),
-		WithCompressor(NewZstdCompressor()))
+// -----------------------------------------------------------------------------
+// G2-15: Empty metadata explicitly valid and persisted
+// -----------------------------------------------------------------------------
+
+func TestDataset_Write_EmptyMetadata_ValidAndPersisted(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := []any{
-		D{"id": "1", "data": "some zstd compressible content"},
-		D{"id": "2", "data": "more zstd compressible content"},
-	}
-	iter := &sliceIterator{records: records}
-
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	// Write with explicitly empty metadata (not nil)
+	snap, err := ds.Write(t.Context(), []any{[]byte("data")}, Metadata{})
 	if err != nil {
-		t.Fatalf("StreamWriteRecords failed: %v", err)
+		t.Fatalf("expected empty metadata to be valid, got error: %v", err)
 	}
 
-	if snap.Manifest.Compressor != "zstd" {
-		t.Errorf("expected compressor 'zstd', got %q", snap.Manifest.Compressor)
+	// Verify metadata is persisted as empty object, not nil
+	if snap.Manifest.Metadata == nil {
+		t.Fatal("expected metadata to be non-nil empty map, got nil")
 	}
-	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".zst") {
-		t.Errorf("expected .zst extension, got %s", snap.Manifest.Files[0].Path)
+	if len(snap.Manifest.Metadata) != 0 {
+		t.Errorf("expected empty metadata, got %d keys", len(snap.Manifest.Metadata))
 	}
 
-	// Verify data can be read back
-	readData, err := ds.Read(t.Context(), snap.ID)
+	// Verify we can read it back and metadata is preserved
+	retrieved, err := ds.Snapshot(t.Context(), snap.ID)
 	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+		t.Fatalf("failed to retrieve snapshot: %v", err)
 	}
-	if len(readData) != 2 {
-		t.Errorf("expected 2 records, got %d", len(readData))
+	if retrieved.Manifest.Metadata == nil {
+		t.Fatal("retrieved metadata should be non-nil empty map, got nil")
+	}
+	if len(retrieved.Manifest.Metadata) != 0 {
+		t.Errorf("retrieved metadata should be empty, got %d keys", len(retrieved.Manifest.Metadata))
 	}
 }
 
-func TestDataset_StreamWrite_MultipleWrites(t *testing.T) {
+func TestDataset_StreamWrite_EmptyMetadata_ValidAndPersisted(t *testing.T) {
 	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatalf("expected empty metadata to be valid, got error: %v", err)
+	}
+
+	_, err = sw.Write([]byte("streaming data"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write in chunks
-	chunks := [][]byte{
-		[]byte("chunk1"),
-		[]byte("-chunk2"),
-		[]byte("-chunk3"),
+	snap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
 	}
-	for _, chunk := range chunks {
-		_, err = sw.Write(chunk)
-		if err != nil {
-			t.Fatalf("Write failed: %v", err)
-		}
+
+	// Verify metadata is persisted as empty object
+	if snap.Manifest.Metadata == nil {
+		t.Fatal("expected metadata to be non-nil empty map, got nil")
+	}
+	if len(snap.Manifest.Metadata) != 0 {
+		t.Errorf("expected empty metadata, got %d keys", len(snap.Manifest.Metadata))
 	}
+}
 
-	snap, err := sw.Commit(t.Context())
+func TestDataset_StreamWriteRecords_EmptyMetadata_ValidAndPersisted(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
 	if err != nil {
-		t.Fatalf("Commit failed: %v", err)
+		t.Fatal(err)
 	}
 
-	// Verify data can be read back as concatenated
-	readData, err := ds.Read(t.Context(), snap.ID)
+	iter := &sliceIterator{records: []any{D{"id": "1"}}}
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
 	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+		t.Fatalf("expected empty metadata to be valid, got error: %v", err)
 	}
-	readBytes, ok := readData[0].([]byte)
-	if !ok {
-		t.Fatalf("expected []byte, got %T", readData[0])
+
+	// Verify metadata is persisted as empty object
+	if snap.Manifest.Metadata == nil {
+		t.Fatal("expected metadata to be non-nil empty map, got nil")
 	}
-	expected := "chunk1-chunk2-chunk3"
-	if string(readBytes) != expected {
-		t.Errorf("expected %q, got %q", expected, readBytes)
+	if len(snap.Manifest.Metadata) != 0 {
+		t.Errorf("expected empty metadata, got %d keys", len(snap.Manifest.Metadata))
 	}
 }
 
 // -----------------------------------------------------------------------------
-// StreamWriteRecords tests
+// G3-1: Snapshot NOT visible before Commit
+// Per CONTRACT_WRITE_API.md: "A snapshot MUST NOT be visible before Commit writes the manifest."
 // -----------------------------------------------------------------------------
 
-func TestDataset_StreamWriteRecords_Success(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_StreamWrite_NotVisibleBeforeCommit(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := []any{
-		D{"id": "1", "value": "first"},
-		D{"id": "2", "value": "second"},
-		D{"id": "3", "value": "third"},
+	// Start streaming write
+	sw, err := ds.StreamWrite(t.Context(), Metadata{"test": "value"})
+	if err != nil {
+		t.Fatal(err)
 	}
-	iter := &sliceIterator{records: records}
 
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{"source": "stream"})
+	// Write data but don't commit yet
+	_, err = sw.Write([]byte("streaming data before commit"))
 	if err != nil {
-		t.Fatalf("StreamWriteRecords failed: %v", err)
+		t.Fatal(err)
 	}
 
-	if snap == nil {
-		t.Fatal("expected snapshot, got nil")
+	// Snapshot should NOT be visible before commit
+	_, err = ds.Latest(t.Context())
+	if !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots before commit, got: %v", err)
 	}
-	if snap.Manifest.RowCount != 3 {
-		t.Errorf("expected RowCount 3, got %d", snap.Manifest.RowCount)
+
+	snapshots, err := ds.Snapshots(t.Context())
+	if err != nil {
+		t.Fatalf("Snapshots() failed: %v", err)
 	}
-	if snap.Manifest.Codec != "jsonl" {
-		t.Errorf("expected codec 'jsonl', got %q", snap.Manifest.Codec)
+	if len(snapshots) != 0 {
+		t.Errorf("expected 0 snapshots before commit, got %d", len(snapshots))
 	}
-	if snap.Manifest.Metadata["source"] != "stream" {
-		t.Errorf("expected metadata source=stream, got %v", snap.Manifest.Metadata)
+
+	// Now commit
+	snap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
 	}
 
-	// Verify snapshot is visible via Latest
+	// Snapshot should now be visible
 	latest, err := ds.Latest(t.Context())
 	if err != nil {
-		t.Fatalf("Latest failed: %v", err)
+		t.Fatalf("Latest() failed after commit: %v", err)
 	}
 	if latest.ID != snap.ID {
 		t.Errorf("expected latest ID %s, got %s", snap.ID, latest.ID)
 	}
+}
 
-	// Verify data can be read back
-	readData, err := ds.Read(t.Context(), snap.ID)
+func TestDataset_StreamWrite_NotVisibleBeforeCommit_WithExistingSnapshot(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+		t.Fatal(err)
 	}
-	if len(readData) != 3 {
-		t.Fatalf("expected 3 records, got %d", len(readData))
-	}
-}
 
-func TestDataset_StreamWriteRecords_NonStreamingCodec_ReturnsError(t *testing.T) {
-	// testCodec does not implement StreamingRecordCodec
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(&testCodec{}))
+	// Create an existing snapshot first
+	existingSnap, err := ds.Write(t.Context(), []any{[]byte("existing data")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	iter := &sliceIterator{records: []any{D{"id": "1"}}}
-	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if !errors.Is(err, ErrCodecNotStreamable) {
-		t.Errorf("expected ErrCodecNotStreamable, got: %v", err)
-	}
-}
-
-func TestDataset_StreamWriteRecords_NoCodec_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	// Start streaming write for second snapshot
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	iter := &sliceIterator{records: []any{D{"id": "1"}}}
-	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if err == nil {
-		t.Fatal("expected error for no codec, got nil")
-	}
-	if !strings.Contains(err.Error(), "requires a codec") {
-		t.Errorf("expected 'requires a codec' error, got: %v", err)
-	}
-}
-
-func TestDataset_StreamWriteRecords_NilMetadata_CoalescesToEmpty(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	_, err = sw.Write([]byte("new streaming data"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	iter := &sliceIterator{records: []any{D{"id": "1"}}}
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, nil)
+	// Latest should still be the existing snapshot, not the uncommitted one
+	latest, err := ds.Latest(t.Context())
 	if err != nil {
-		t.Fatalf("expected nil metadata to succeed, got: %v", err)
-	}
-	if snap.Manifest.Metadata == nil {
-		t.Fatal("expected non-nil metadata in manifest after nil coalescing")
+		t.Fatalf("Latest() failed: %v", err)
 	}
-	if len(snap.Manifest.Metadata) != 0 {
-		t.Errorf("expected empty metadata, got %v", snap.Manifest.Metadata)
+	if latest.ID != existingSnap.ID {
+		t.Errorf("expected latest to be existing snapshot %s, got %s", existingSnap.ID, latest.ID)
 	}
-}
 
-func TestDataset_StreamWriteRecords_NilIterator_ReturnsError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	// Snapshots list should only have one entry
+	snapshots, err := ds.Snapshots(t.Context())
 	if err != nil {
-		t.Fatal(err)
-	}
-
-	_, err = ds.StreamWriteRecords(t.Context(), nil, Metadata{})
-	if err == nil {
-		t.Fatal("expected error for nil iterator, got nil")
+		t.Fatalf("Snapshots() failed: %v", err)
 	}
-	if !errors.Is(err, ErrNilIterator) {
-		t.Errorf("expected ErrNilIterator, got: %v", err)
+	if len(snapshots) != 1 {
+		t.Errorf("expected 1 snapshot before commit, got %d", len(snapshots))
 	}
-}
 
-func TestDataset_StreamWriteRecords_WithPartitioner_ReturnsError(t *testing.T) {
-	// StreamWriteRecords cannot partition since it's single-pass streaming
-	ds, err := NewDataset("test-ds", NewMemoryFactory(),
-		WithCodec(NewJSONLCodec()),
-		WithHiveLayout("day"))
+	// Commit and verify new snapshot is visible
+	newSnap, err := sw.Commit(t.Context())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	iter := &sliceIterator{records: []any{D{"id": "1", "day": "2024-01-01"}}}
-	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if err == nil {
-		t.Fatal("expected error for partitioning, got nil")
+	latest, err = ds.Latest(t.Context())
+	if err != nil {
+		t.Fatalf("Latest() failed after commit: %v", err)
 	}
-	if !errors.Is(err, ErrPartitioningNotSupported) {
-		t.Errorf("expected ErrPartitioningNotSupported, got: %v", err)
+	if latest.ID != newSnap.ID {
+		t.Errorf("expected latest to be new snapshot %s, got %s", newSnap.ID, latest.ID)
 	}
 }
 
-func TestDataset_StreamWriteRecords_TimestampedRecords_ComputesMinMax(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+// -----------------------------------------------------------------------------
+// G3-2, G3-3: Abort semantics tests
+// Per CONTRACT_WRITE_API.md:
+// - "StreamWriter.Abort(ctx) MUST ensure no manifest is written."
+// - "StreamWriter.Close() without Commit MUST behave as Abort."
+//
+// RISK NOTE: Context cancellation during streaming has nondeterministic outcomes.
+// If the background store.Put completes before cancellation takes effect, the
+// data object is written. However, no manifest is written unless Commit succeeds.
+// The Abort path is deterministic and is tested explicitly below.
+// -----------------------------------------------------------------------------
+
+func TestDataset_StreamWrite_AbortDuringWrite_NoManifest(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	ts1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
-	ts2 := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
-	ts3 := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
-
-	records := []any{
-		&timestampedRecord{ID: "a", Time: ts1},
-		&timestampedRecord{ID: "b", Time: ts2},
-		&timestampedRecord{ID: "c", Time: ts3},
-	}
-	iter := &sliceIterator{records: records}
-
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if snap.Manifest.MinTimestamp == nil {
-		t.Fatal("expected MinTimestamp to be set")
-	}
-	if snap.Manifest.MaxTimestamp == nil {
-		t.Fatal("expected MaxTimestamp to be set")
-	}
-	if !snap.Manifest.MinTimestamp.Equal(ts1) {
-		t.Errorf("expected MinTimestamp %v, got %v", ts1, *snap.Manifest.MinTimestamp)
-	}
-	if !snap.Manifest.MaxTimestamp.Equal(ts2) {
-		t.Errorf("expected MaxTimestamp %v, got %v", ts2, *snap.Manifest.MaxTimestamp)
-	}
-}
-
-func TestDataset_StreamWriteRecords_NonTimestampedRecords_OmitsMinMax(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	// Write some data
+	_, err = sw.Write([]byte("data that will be aborted"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := []any{
-		D{"id": "a", "value": 1},
-		D{"id": "b", "value": 2},
-	}
-	iter := &sliceIterator{records: records}
-
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	// Abort explicitly
+	err = sw.Abort(t.Context())
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("Abort failed: %v", err)
 	}
 
-	if snap.Manifest.MinTimestamp != nil {
-		t.Errorf("expected MinTimestamp to be nil, got %v", snap.Manifest.MinTimestamp)
-	}
-	if snap.Manifest.MaxTimestamp != nil {
-		t.Errorf("expected MaxTimestamp to be nil, got %v", snap.Manifest.MaxTimestamp)
+	// Verify no snapshot is visible
+	_, latestErr := ds.Latest(t.Context())
+	if !errors.Is(latestErr, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots after abort, got: %v", latestErr)
 	}
-}
 
-func TestDataset_StreamWriteRecords_EmptyIterator(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	// Verify no manifest in store
+	paths, err := store.List(t.Context(), "")
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	iter := &sliceIterator{records: []any{}}
-
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if err != nil {
-		t.Fatalf("StreamWriteRecords failed: %v", err)
-	}
-
-	if snap.Manifest.RowCount != 0 {
-		t.Errorf("expected RowCount 0, got %d", snap.Manifest.RowCount)
+	for _, p := range paths {
+		if strings.Contains(p, "manifest.json") {
+			t.Errorf("manifest should not exist after abort, found: %s", p)
+		}
 	}
 }
 
-func TestDataset_StreamWriteRecords_IteratorError(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_StreamWrite_CloseDuringWrite_NoManifest(t *testing.T) {
+	// Per CONTRACT_WRITE_API.md: "Close() without Commit MUST behave as Abort"
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	iterErr := errors.New("iterator failure")
-	iter := &errorIterator{err: iterErr}
-
-	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if err == nil {
-		t.Fatal("expected error from iterator, got nil")
-	}
-	if !strings.Contains(err.Error(), "iterator failure") {
-		t.Errorf("expected iterator failure error, got: %v", err)
-	}
-
-	// Verify no snapshot created
-	_, err = ds.Latest(t.Context())
-	if !errors.Is(err, ErrNoSnapshots) {
-		t.Errorf("expected ErrNoSnapshots after iterator error, got: %v", err)
-	}
-}
-
-func TestDataset_StreamWriteRecords_WithCompression(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(),
-		WithCodec(NewJSONLCodec()),
-		WithCompressor(NewGzipCompressor()))
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := []any{
-		D{"id": "1", "data": "some compressible content"},
-		D{"id": "2", "data": "more compressible content"},
+	_, err = sw.Write([]byte("data that will be abandoned"))
+	if err != nil {
+		t.Fatal(err)
 	}
-	iter := &sliceIterator{records: records}
 
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	// Close without commit (should behave as abort)
+	err = sw.Close()
 	if err != nil {
-		t.Fatalf("StreamWriteRecords failed: %v", err)
+		t.Fatalf("Close failed: %v", err)
 	}
 
-	if snap.Manifest.Compressor != "gzip" {
-		t.Errorf("expected compressor 'gzip', got %q", snap.Manifest.Compressor)
-	}
-	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".gz") {
-		t.Errorf("expected .gz extension, got %s", snap.Manifest.Files[0].Path)
+	// Verify no snapshot is visible
+	_, latestErr := ds.Latest(t.Context())
+	if !errors.Is(latestErr, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots after close-without-commit, got: %v", latestErr)
 	}
 
-	// Verify data can be read back
-	readData, err := ds.Read(t.Context(), snap.ID)
+	// Verify no manifest in store
+	paths, err := store.List(t.Context(), "")
 	if err != nil {
-		t.Fatalf("Read failed: %v", err)
+		t.Fatal(err)
 	}
-	if len(readData) != 2 {
-		t.Errorf("expected 2 records, got %d", len(readData))
+	for _, p := range paths {
+		if strings.Contains(p, "manifest.json") {
+			t.Errorf("manifest should not exist after close-without-commit, found: %s", p)
+		}
 	}
 }
 
-func TestDataset_StreamWriteRecords_ParentSnapshotLinked(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_StreamWrite_WriteError_NoManifest(t *testing.T) {
+	// Test that write errors prevent manifest creation
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// First write (regular Write)
-	firstSnap, err := ds.Write(t.Context(), []any{D{"id": "1"}}, Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Second write (StreamWriteRecords)
-	iter := &sliceIterator{records: []any{D{"id": "2"}}}
-	secondSnap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	// Write data then abort to simulate failure path
+	_, err = sw.Write([]byte("data"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if secondSnap.Manifest.ParentSnapshotID != firstSnap.ID {
-		t.Errorf("expected parent %s, got %s", firstSnap.ID, secondSnap.Manifest.ParentSnapshotID)
+	// Simulate failure by aborting
+	_ = sw.Abort(t.Context())
+
+	// Verify no manifest exists
+	paths, _ := store.List(t.Context(), "")
+	for _, p := range paths {
+		if strings.Contains(p, "manifest.json") {
+			t.Errorf("manifest should not exist after simulated failure, found: %s", p)
+		}
 	}
 }
 
+// RISK NOTE: Context cancellation during StreamWriteRecords
+//
+// StreamWriteRecords is an atomic operation from the caller's perspective.
+// If the context is cancelled mid-stream:
+// - The background store.Put may or may not have completed
+// - Partial data may remain in storage (best-effort cleanup attempted)
+// - No manifest will be written (the commit signal)
+//
+// The cleanup uses the caller's context for Delete, which may already be
+// cancelled. Per CONTRACT_STORAGE.md, cleanup SHOULD use an independent
+// context. Current implementation risk: cleanup may fail if context is
+// already cancelled.
+//
+// This is documented behavior, not a test failure condition.
+
+// -----------------------------------------------------------------------------
+// G3-4: Commit signal = manifest presence
+// Per CONTRACT_WRITE_API.md: "Manifest presence is the commit signal."
 // -----------------------------------------------------------------------------
-// Checksum tests
-// -----------------------------------------------------------------------------
 
-func TestDataset_Write_WithChecksum_RecordsChecksum(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
+func TestDataset_StreamWrite_ManifestPresenceIsCommitSignal(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	snap, err := ds.Write(t.Context(), []any{[]byte("hello world")}, Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Verify checksum algorithm recorded
-	if snap.Manifest.ChecksumAlgorithm != "md5" {
-		t.Errorf("expected ChecksumAlgorithm 'md5', got %q", snap.Manifest.ChecksumAlgorithm)
+	_, err = sw.Write([]byte("test data"))
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	// Verify file has checksum
-	if len(snap.Manifest.Files) != 1 {
-		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
-	}
-	if snap.Manifest.Files[0].Checksum == "" {
-		t.Error("expected non-empty checksum")
+	// Before commit: check that no manifest exists in store
+	paths, err := store.List(t.Context(), "")
+	if err != nil {
+		t.Fatal(err)
 	}
-	// MD5 produces 32 hex characters
-	if len(snap.Manifest.Files[0].Checksum) != 32 {
-		t.Errorf("expected 32 char MD5 checksum, got %d chars", len(snap.Manifest.Files[0].Checksum))
+	for _, p := range paths {
+		if strings.Contains(p, "manifest.json") {
+			t.Errorf("manifest should not exist before commit, found: %s", p)
+		}
 	}
-}
 
-func TestDataset_Write_WithoutChecksum_OmitsChecksum(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	// Commit
+	_, err = sw.Commit(t.Context())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	snap, err := ds.Write(t.Context(), []any{[]byte("hello world")}, Metadata{})
+	// After commit: manifest must exist
+	paths, err = store.List(t.Context(), "")
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Verify no checksum algorithm recorded
-	if snap.Manifest.ChecksumAlgorithm != "" {
-		t.Errorf("expected empty ChecksumAlgorithm, got %q", snap.Manifest.ChecksumAlgorithm)
+	hasManifest := false
+	for _, p := range paths {
+		if strings.Contains(p, "manifest.json") {
+			hasManifest = true
+			break
+		}
 	}
-
-	// Verify file has no checksum
-	if snap.Manifest.Files[0].Checksum != "" {
-		t.Errorf("expected empty checksum, got %q", snap.Manifest.Files[0].Checksum)
+	if !hasManifest {
+		t.Error("manifest should exist after commit")
 	}
 }
 
-func TestDataset_StreamWrite_WithChecksum_RecordsChecksum(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
+func TestDataset_StreamWriteRecords_ManifestPresenceIsCommitSignal(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	iter := &sliceIterator{records: []any{D{"id": "1"}, D{"id": "2"}}}
+	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = sw.Write([]byte("streaming data"))
+	// After successful StreamWriteRecords: manifest must exist
+	paths, err := store.List(t.Context(), "")
 	if err != nil {
 		t.Fatal(err)
 	}
+	hasManifest := false
+	for _, p := range paths {
+		if strings.Contains(p, "manifest.json") {
+			hasManifest = true
+			break
+		}
+	}
+	if !hasManifest {
+		t.Error("manifest should exist after successful StreamWriteRecords")
+	}
+}
 
-	snap, err := sw.Commit(t.Context())
+func TestDataset_StreamWriteRecords_IteratorError_NoManifestWritten(t *testing.T) {
+	// Explicitly verifies manifest is not written on iterator error
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Verify checksum algorithm recorded
-	if snap.Manifest.ChecksumAlgorithm != "md5" {
-		t.Errorf("expected ChecksumAlgorithm 'md5', got %q", snap.Manifest.ChecksumAlgorithm)
-	}
+	iterErr := errors.New("iterator failure")
+	iter := &errorIterator{err: iterErr}
 
-	// Verify file has checksum
-	if snap.Manifest.Files[0].Checksum == "" {
-		t.Error("expected non-empty checksum")
-	}
-	if len(snap.Manifest.Files[0].Checksum) != 32 {
-		t.Errorf("expected 32 char MD5 checksum, got %d chars", len(snap.Manifest.Files[0].Checksum))
+	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err == nil {
+		t.Fatal("expected error from iterator, got nil")
 	}
-}
 
-func TestDataset_StreamWriteRecords_WithChecksum_RecordsChecksum(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(),
-		WithCodec(NewJSONLCodec()),
-		WithChecksum(NewMD5Checksum()))
+	// Verify no manifest was written
+	paths, err := store.List(t.Context(), "")
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	iter := &sliceIterator{records: []any{D{"id": "1"}, D{"id": "2"}}}
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
-	if err != nil {
-		t.Fatal(err)
+	for _, p := range paths {
+		if strings.Contains(p, "manifest.json") {
+			t.Errorf("manifest should not exist after iterator error, found: %s", p)
+		}
 	}
+}
 
-	// Verify checksum algorithm recorded
-	if snap.Manifest.ChecksumAlgorithm != "md5" {
-		t.Errorf("expected ChecksumAlgorithm 'md5', got %q", snap.Manifest.ChecksumAlgorithm)
-	}
+// -----------------------------------------------------------------------------
+// Option validation tests
+// -----------------------------------------------------------------------------
 
-	// Verify file has checksum
-	if snap.Manifest.Files[0].Checksum == "" {
-		t.Error("expected non-empty checksum")
+func TestDatasetReader_WithCompressor_ReturnsError(t *testing.T) {
+	// WithCompressor is a dataset-only option
+	_, err := NewDatasetReader(NewMemoryFactory(), WithCompressor(NewNoOpCompressor()))
+	if err == nil {
+		t.Fatal("expected error for WithCompressor on reader, got nil")
 	}
-	if len(snap.Manifest.Files[0].Checksum) != 32 {
-		t.Errorf("expected 32 char MD5 checksum, got %d chars", len(snap.Manifest.Files[0].Checksum))
+	if !strings.Contains(err.Error(), "not valid for reader") {
+		t.Errorf("expected 'not valid for reader' error, got: %v", err)
 	}
 }
 
-func TestDataset_Checksum_ComputedOnCompressedData(t *testing.T) {
-	// Two datasets with same data but different compression
-	// should produce different checksums (since checksum is on stored bytes)
-	dsNoComp, err := NewDataset("test-no-comp", NewMemoryFactory(),
-		WithChecksum(NewMD5Checksum()))
-	if err != nil {
-		t.Fatal(err)
+func TestDatasetReader_WithCodec_ReturnsError(t *testing.T) {
+	// WithCodec is a dataset-only option
+	_, err := NewDatasetReader(NewMemoryFactory(), WithCodec(&testCodec{}))
+	if err == nil {
+		t.Fatal("expected error for WithCodec on reader, got nil")
 	}
-
-	dsGzip, err := NewDataset("test-gzip", NewMemoryFactory(),
-		WithCompressor(NewGzipCompressor()),
-		WithChecksum(NewMD5Checksum()))
-	if err != nil {
-		t.Fatal(err)
+	if !strings.Contains(err.Error(), "not valid for reader") {
+		t.Errorf("expected 'not valid for reader' error, got: %v", err)
 	}
+}
 
-	data := []byte("compressible data that should produce different checksums")
-
-	snapNoComp, err := dsNoComp.Write(t.Context(), []any{data}, Metadata{})
-	if err != nil {
-		t.Fatal(err)
+func TestDatasetReader_WithChecksum_ReturnsError(t *testing.T) {
+	// WithChecksum is a dataset-only option
+	_, err := NewDatasetReader(NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
+	if err == nil {
+		t.Fatal("expected error for WithChecksum on reader, got nil")
+	}
+	if !strings.Contains(err.Error(), "not valid for reader") {
+		t.Errorf("expected 'not valid for reader' error, got: %v", err)
 	}
+}
 
-	snapGzip, err := dsGzip.Write(t.Context(), []any{data}, Metadata{})
+// -----------------------------------------------------------------------------
+// Empty dataset behavior tests
+// -----------------------------------------------------------------------------
+
+func TestDataset_Latest_EmptyDataset_ReturnsErrNoSnapshots(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Both should have checksums
-	if snapNoComp.Manifest.Files[0].Checksum == "" {
-		t.Error("expected checksum for no-compression")
-	}
-	if snapGzip.Manifest.Files[0].Checksum == "" {
-		t.Error("expected checksum for gzip")
-	}
-
-	// Checksums should be different (stored bytes differ)
-	if snapNoComp.Manifest.Files[0].Checksum == snapGzip.Manifest.Files[0].Checksum {
-		t.Error("expected different checksums for different compression")
+	_, err = ds.Latest(t.Context())
+	if !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots, got: %v", err)
 	}
 }
 
-func TestDataset_Checksum_SameDataProducesSameChecksum(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
+func TestDataset_Snapshots_EmptyDataset_ReturnsEmptyList(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	data := []byte("consistent data")
-
-	snap1, err := ds.Write(t.Context(), []any{data}, Metadata{})
+	snapshots, err := ds.Snapshots(t.Context())
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Errorf("expected empty list, got %d snapshots", len(snapshots))
 	}
+}
 
-	snap2, err := ds.Write(t.Context(), []any{data}, Metadata{})
+func TestDataset_Snapshot_EmptyDataset_ReturnsErrNotFound(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Same data should produce same checksum
-	if snap1.Manifest.Files[0].Checksum != snap2.Manifest.Files[0].Checksum {
-		t.Errorf("expected same checksum for same data, got %s vs %s",
-			snap1.Manifest.Files[0].Checksum, snap2.Manifest.Files[0].Checksum)
+	_, err = ds.Snapshot(t.Context(), "nonexistent-id")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
 	}
 }
 
 // -----------------------------------------------------------------------------
-// Timestamped interface tests
+// Write validation tests
 // -----------------------------------------------------------------------------
 
-func TestDataset_Write_TimestampedRecords_ComputesMinMax(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_Write_NilMetadata_CoalescesToEmpty(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	ts1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
-	ts2 := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
-	ts3 := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
-
-	records := []any{
-		&timestampedRecord{ID: "a", Time: ts1},
-		&timestampedRecord{ID: "b", Time: ts2},
-		&timestampedRecord{ID: "c", Time: ts3},
-	}
-
-	snap, err := ds.Write(t.Context(), records, Metadata{})
+	snap, err := ds.Write(t.Context(), []any{[]byte("data")}, nil)
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("expected nil metadata to succeed, got: %v", err)
 	}
-
-	if snap.Manifest.MinTimestamp == nil {
-		t.Fatal("expected MinTimestamp to be set")
+	if snap.Manifest.Metadata == nil {
+		t.Fatal("expected non-nil metadata in manifest after nil coalescing")
 	}
-	if snap.Manifest.MaxTimestamp == nil {
-		t.Fatal("expected MaxTimestamp to be set")
-	}
-
-	if !snap.Manifest.MinTimestamp.Equal(ts1) {
-		t.Errorf("expected MinTimestamp %v, got %v", ts1, *snap.Manifest.MinTimestamp)
-	}
-	if !snap.Manifest.MaxTimestamp.Equal(ts2) {
-		t.Errorf("expected MaxTimestamp %v, got %v", ts2, *snap.Manifest.MaxTimestamp)
+	if len(snap.Manifest.Metadata) != 0 {
+		t.Errorf("expected empty metadata, got %v", snap.Manifest.Metadata)
 	}
 }
 
-func TestDataset_Write_NonTimestampedRecords_OmitsMinMax(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_RawBlobWrite_MultipleElements_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Plain maps don't implement Timestamped
-	records := []any{
-		D{"id": "a", "value": 1},
-		D{"id": "b", "value": 2},
+	// Raw blob mode requires exactly one []byte element
+	_, err = ds.Write(t.Context(), []any{[]byte("one"), []byte("two")}, Metadata{})
+	if err == nil {
+		t.Fatal("expected error for multiple elements in raw blob mode, got nil")
+	}
+	if !strings.Contains(err.Error(), "exactly one data element") {
+		t.Errorf("expected 'exactly one data element' error, got: %v", err)
 	}
+}
 
-	snap, err := ds.Write(t.Context(), records, Metadata{})
+func TestDataset_RawBlobWrite_WrongType_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if snap.Manifest.MinTimestamp != nil {
-		t.Errorf("expected MinTimestamp to be nil, got %v", snap.Manifest.MinTimestamp)
+	// Raw blob mode requires []byte, not string
+	_, err = ds.Write(t.Context(), []any{"not a byte slice"}, Metadata{})
+	if err == nil {
+		t.Fatal("expected error for wrong type in raw blob mode, got nil")
 	}
-	if snap.Manifest.MaxTimestamp != nil {
-		t.Errorf("expected MaxTimestamp to be nil, got %v", snap.Manifest.MaxTimestamp)
+	if !strings.Contains(err.Error(), "requires []byte") {
+		t.Errorf("expected '[]byte' error, got: %v", err)
 	}
 }
 
-func TestDataset_Write_RawBlob_OmitsTimestamps(t *testing.T) {
+// -----------------------------------------------------------------------------
+// StreamWrite tests
+// -----------------------------------------------------------------------------
+
+func TestDataset_StreamWrite_Success(t *testing.T) {
 	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	snap, err := ds.Write(t.Context(), []any{[]byte("blob data")}, Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), Metadata{"source": "test"})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if snap.Manifest.MinTimestamp != nil {
-		t.Errorf("expected MinTimestamp to be nil for raw blob, got %v", snap.Manifest.MinTimestamp)
+	// Write some data
+	data := []byte("hello stream world")
+	n, err := sw.Write(data)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
 	}
-	if snap.Manifest.MaxTimestamp != nil {
-		t.Errorf("expected MaxTimestamp to be nil for raw blob, got %v", snap.Manifest.MaxTimestamp)
+	if n != len(data) {
+		t.Errorf("expected %d bytes written, got %d", len(data), n)
 	}
-}
 
-func TestDataset_Write_SingleTimestampedRecord_SameMinMax(t *testing.T) {
-	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	// Commit and verify snapshot
+	snap, err := sw.Commit(t.Context())
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("Commit failed: %v", err)
 	}
 
-	ts := time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)
-	records := []any{&timestampedRecord{ID: "only", Time: ts}}
+	if snap == nil {
+		t.Fatal("expected snapshot, got nil")
+	}
+	if snap.Manifest.RowCount != 1 {
+		t.Errorf("expected RowCount 1, got %d", snap.Manifest.RowCount)
+	}
+	if len(snap.Manifest.Files) != 1 {
+		t.Errorf("expected 1 file, got %d", len(snap.Manifest.Files))
+	}
+	if snap.Manifest.Metadata["source"] != "test" {
+		t.Errorf("expected metadata source=test, got %v", snap.Manifest.Metadata)
+	}
 
-	snap, err := ds.Write(t.Context(), records, Metadata{})
+	// Verify snapshot is visible via Latest
+	latest, err := ds.Latest(t.Context())
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if latest.ID != snap.ID {
+		t.Errorf("expected latest ID %s, got %s", snap.ID, latest.ID)
 	}
 
-	if snap.Manifest.MinTimestamp == nil || snap.Manifest.MaxTimestamp == nil {
-		t.Fatal("expected both timestamps to be set")
+	// Verify data can be read back
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
 	}
-	if !snap.Manifest.MinTimestamp.Equal(ts) || !snap.Manifest.MaxTimestamp.Equal(ts) {
-		t.Errorf("expected both timestamps to be %v", ts)
+	if len(readData) != 1 {
+		t.Fatalf("expected 1 element, got %d", len(readData))
+	}
+	readBytes, ok := readData[0].([]byte)
+	if !ok {
+		t.Fatalf("expected []byte, got %T", readData[0])
+	}
+	if string(readBytes) != string(data) {
+		t.Errorf("expected %q, got %q", data, readBytes)
 	}
 }
 
-// -----------------------------------------------------------------------------
-// FileStats write-path tests
-// -----------------------------------------------------------------------------
-
-func TestDataset_Write_ParquetCodec_StatsPopulated(t *testing.T) {
-	schema := ParquetSchema{
-		Fields: []ParquetField{
-			{Name: "id", Type: ParquetInt64},
-			{Name: "name", Type: ParquetString},
-		},
-	}
-	codec, err := NewParquetCodec(schema)
+func TestDataset_StreamWrite_Abort_NoManifest(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	ds, err := NewDataset("stats-ds", NewMemoryFactory(), WithCodec(codec))
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := []any{
-		map[string]any{"id": int64(1), "name": "alice"},
-		map[string]any{"id": int64(2), "name": "bob"},
+	// Write some data
+	_, err = sw.Write([]byte("partial data"))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
 	}
 
-	snap, err := ds.Write(t.Context(), records, Metadata{})
+	// Abort
+	err = sw.Abort(t.Context())
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("Abort failed: %v", err)
 	}
 
-	if len(snap.Manifest.Files) != 1 {
-		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
+	// Verify no snapshot visible
+	_, err = ds.Latest(t.Context())
+	if !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots after abort, got: %v", err)
 	}
 
-	stats := snap.Manifest.Files[0].Stats
-	if stats == nil {
-		t.Fatal("expected Stats on FileRef, got nil")
-	}
-	if stats.RowCount != 2 {
-		t.Errorf("RowCount = %d, want 2", stats.RowCount)
-	}
-	if len(stats.Columns) != 2 {
-		t.Fatalf("len(Columns) = %d, want 2", len(stats.Columns))
+	snapshots, err := ds.Snapshots(t.Context())
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
 	}
-	if stats.Columns[0].Name != "id" {
-		t.Errorf("Columns[0].Name = %q, want %q", stats.Columns[0].Name, "id")
+	if len(snapshots) != 0 {
+		t.Errorf("expected 0 snapshots after abort, got %d", len(snapshots))
 	}
 }
 
-func TestDataset_Write_JSONLCodec_StatsNil(t *testing.T) {
-	ds, err := NewDataset("jsonl-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_StreamWrite_CloseWithoutCommit_BehavesAsAbort(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := []any{map[string]any{"key": "value"}}
-	snap, err := ds.Write(t.Context(), records, Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if snap.Manifest.Files[0].Stats != nil {
-		t.Errorf("expected nil Stats for JSONL codec, got %+v", snap.Manifest.Files[0].Stats)
+	// Write some data
+	_, err = sw.Write([]byte("will be abandoned"))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// Close without commit
+	err = sw.Close()
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Verify no snapshot visible
+	_, err = ds.Latest(t.Context())
+	if !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots after close without commit, got: %v", err)
 	}
 }
 
-func TestDataset_Write_RawBlob_StatsNil(t *testing.T) {
-	ds, err := NewDataset("blob-ds", NewMemoryFactory())
+func TestDataset_StreamWrite_NilMetadata_CoalescesToEmpty(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	snap, err := ds.Write(t.Context(), []any{[]byte("raw data")}, Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), nil)
+	if err != nil {
+		t.Fatalf("expected nil metadata to succeed, got: %v", err)
+	}
+	_, _ = sw.Write([]byte("data"))
+	snap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.Metadata == nil {
+		t.Fatal("expected non-nil metadata in manifest after nil coalescing")
+	}
+	if len(snap.Manifest.Metadata) != 0 {
+		t.Errorf("expected empty metadata, got %v", snap.Manifest.Metadata)
+	}
+}
+
+func TestDataset_StreamWrite_WithCodec_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if snap.Manifest.Files[0].Stats != nil {
-		t.Errorf("expected nil Stats for raw blob, got %+v", snap.Manifest.Files[0].Stats)
+	_, err = ds.StreamWrite(t.Context(), Metadata{})
+	if !errors.Is(err, ErrCodecConfigured) {
+		t.Errorf("expected ErrCodecConfigured, got: %v", err)
 	}
 }
 
-func TestDataset_StreamWriteRecords_StatsNil(t *testing.T) {
-	ds, err := NewDataset("stream-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+func TestDataset_StreamWrite_WriteAfterCommit_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	iter := &sliceIterator{records: []any{map[string]any{"key": "value"}}}
-	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if snap.Manifest.Files[0].Stats != nil {
-		t.Errorf("expected nil Stats for JSONL stream, got %+v", snap.Manifest.Files[0].Stats)
+	_, err = sw.Write([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
 	}
-}
 
-// -----------------------------------------------------------------------------
-// FileStats serialization tests
-// -----------------------------------------------------------------------------
+	_, err = sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
 
-func TestFileRef_Stats_JSONRoundTrip(t *testing.T) {
-	ref := FileRef{
-		Path:      "data/test.parquet",
-		SizeBytes: 1024,
-		Stats: &FileStats{
-			RowCount: 100,
-			Columns: []ColumnStats{
-				{Name: "id", Min: float64(1), Max: float64(100), NullCount: 0},
-				{Name: "name", Min: "alice", Max: "zara", NullCount: 5},
-			},
-		},
+	// Write after commit should fail
+	_, err = sw.Write([]byte("more data"))
+	if err == nil {
+		t.Fatal("expected error writing after commit")
 	}
+}
 
-	data, err := json.Marshal(ref)
+func TestDataset_StreamWrite_WriteAfterAbort_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	var decoded FileRef
-	if err := json.Unmarshal(data, &decoded); err != nil {
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	if decoded.Stats == nil {
-		t.Fatal("expected Stats after round-trip, got nil")
-	}
-	if decoded.Stats.RowCount != 100 {
-		t.Errorf("RowCount = %d, want 100", decoded.Stats.RowCount)
-	}
-	if len(decoded.Stats.Columns) != 2 {
-		t.Fatalf("len(Columns) = %d, want 2", len(decoded.Stats.Columns))
-	}
-	if decoded.Stats.Columns[0].Name != "id" {
-		t.Errorf("Columns[0].Name = %q, want %q", decoded.Stats.Columns[0].Name, "id")
-	}
-	// JSON round-trips numbers as float64
-	if decoded.Stats.Columns[0].Min != float64(1) {
-		t.Errorf("Columns[0].Min = %v, want 1", decoded.Stats.Columns[0].Min)
-	}
-	if decoded.Stats.Columns[1].NullCount != 5 {
-		t.Errorf("Columns[1].NullCount = %d, want 5", decoded.Stats.Columns[1].NullCount)
+	_, err = sw.Write([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
 	}
-}
-
-func TestFileRef_Stats_BackwardCompat(t *testing.T) {
-	// JSON without stats field should decode cleanly
-	jsonData := `{"path":"data/test.gz","size_bytes":512}`
 
-	var ref FileRef
-	if err := json.Unmarshal([]byte(jsonData), &ref); err != nil {
+	err = sw.Abort(t.Context())
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	if ref.Stats != nil {
-		t.Errorf("expected nil Stats for JSON without stats field, got %+v", ref.Stats)
-	}
-	if ref.Path != "data/test.gz" {
-		t.Errorf("Path = %q, want %q", ref.Path, "data/test.gz")
+	// Write after abort should fail
+	_, err = sw.Write([]byte("more data"))
+	if err == nil {
+		t.Fatal("expected error writing after abort")
 	}
 }
 
-func TestFileRef_Stats_OmittedWhenNil(t *testing.T) {
-	ref := FileRef{
-		Path:      "data/test.gz",
-		SizeBytes: 256,
+func TestDataset_StreamWrite_DoubleCommit_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	data, err := json.Marshal(ref)
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if strings.Contains(string(data), "stats") {
-		t.Errorf("expected no stats key in JSON when Stats is nil, got: %s", data)
+	_, err = sw.Write([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
 	}
-}
-
-// -----------------------------------------------------------------------------
-// Test helpers
-// -----------------------------------------------------------------------------
-
-// timestampedRecord implements Timestamped for testing.
-type timestampedRecord struct {
-	ID   string    `json:"id"`
-	Time time.Time `json:"time"`
-}
-
-func (r *timestampedRecord) Timestamp() time.Time {
-	return r.Time
-}
-
-// testCodec is a simple codec for testing.
-type testCodec struct{}
-
-func (c *testCodec) Name() string { return "test-codec" }
-
-func (c *testCodec) Encode(_ io.Writer, _ []any) error {
-	return nil
-}
-
-func (c *testCodec) Decode(_ io.Reader) ([]any, error) {
-	return nil, nil //nolint:nilnil // stub for testing
-}
-
-// sliceIterator implements RecordIterator for testing.
-type sliceIterator struct {
-	records []any
-	index   int
-	current any
-}
 
-func (s *sliceIterator) Next() bool {
-	if s.index >= len(s.records) {
-		return false
+	_, err = sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
 	}
-	s.current = s.records[s.index]
-	s.index++
-	return true
-}
 
-func (s *sliceIterator) Record() any {
-	return s.current
+	// Second commit should fail
+	_, err = sw.Commit(t.Context())
+	if err == nil {
+		t.Fatal("expected error on double commit")
+	}
 }
 
-func (s *sliceIterator) Err() error {
-	return nil
-}
+func TestDataset_StreamWrite_AbortAfterCommit_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
 
-// errorIterator implements RecordIterator that returns an error.
-type errorIterator struct {
-	err error
-}
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-func (e *errorIterator) Next() bool {
-	return false
-}
+	_, err = sw.Write([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
 
-func (e *errorIterator) Record() any {
-	return nil
-}
+	_, err = sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
 
-func (e *errorIterator) Err() error {
-	return e.err
+	// Abort after commit should fail
+	err = sw.Abort(t.Context())
+	if err == nil {
+		t.Fatal("expected error aborting after commit")
+	}
 }
 
-// -----------------------------------------------------------------------------
-// Latest pointer tests (issue #118 / #119)
-// -----------------------------------------------------------------------------
-
-func TestDataset_Write_LatestPointer_SkipsScan(t *testing.T) {
-	// After the first Write creates the pointer, subsequent writes must not
-	// call store.List to resolve the parent snapshot ID (O(1) via pointer).
-	fs := newFaultStore(NewMemory())
-	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
-		WithCodec(NewJSONLCodec()),
-	)
+func TestDataset_Write_ParentSnapshotLinked(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// First write: no pointer yet, falls back to scan (List)
-	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	first, err := ds.Write(t.Context(), []any{[]byte("first")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	listCallsAfterFirst := len(fs.ListCalls())
-	if listCallsAfterFirst == 0 {
-		t.Fatal("expected at least one List call on first write (no pointer)")
+	if first.Manifest.ParentSnapshotID != "" {
+		t.Errorf("expected no parent for first snapshot, got %s", first.Manifest.ParentSnapshotID)
 	}
-
-	// Second write: pointer exists, must NOT call List
-	snap2, err := ds.Write(t.Context(), R(D{"b": 2}), Metadata{})
+	second, err := ds.Write(t.Context(), []any{[]byte("second")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	listCallsAfterSecond := len(fs.ListCalls())
-	if listCallsAfterSecond != listCallsAfterFirst {
-		t.Errorf("expected no new List calls on second write, got %d new calls",
-			listCallsAfterSecond-listCallsAfterFirst)
-	}
 
-	// Verify parent chain is correct
-	if snap2.Manifest.ParentSnapshotID != snap1.ID {
-		t.Errorf("expected parent %s, got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+	// A new handle resolves the parent from storage.
+	fresh, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	// Third write: still pointer-based
-	snap3, err := ds.Write(t.Context(), R(D{"c": 3}), Metadata{})
+	third, err := fresh.Write(t.Context(), []any{[]byte("third")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	listCallsAfterThird := len(fs.ListCalls())
-	if listCallsAfterThird != listCallsAfterFirst {
-		t.Errorf("expected no new List calls on third write, got %d new calls",
-			listCallsAfterThird-listCallsAfterFirst)
+
+	if second.Manifest.ParentSnapshotID != first.ID {
+		t.Errorf("expected parent %s, got %s", first.ID, second.Manifest.ParentSnapshotID)
 	}
-	if snap3.Manifest.ParentSnapshotID != snap2.ID {
-		t.Errorf("expected parent %s, got %s", snap2.ID, snap3.Manifest.ParentSnapshotID)
+	if third.Manifest.ParentSnapshotID != second.ID {
+		t.Errorf("expected parent %s, got %s", second.ID, third.Manifest.ParentSnapshotID)
 	}
 }
 
-func TestDataset_StreamWrite_LatestPointer_SkipsScan(t *testing.T) {
-	// StreamWrite → Commit must write the pointer so that a subsequent
-	// Write uses O(1) parent resolution.
-	fs := newFaultStore(NewMemory())
-	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs))
+func TestDataset_StreamWrite_ParentSnapshotLinked(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// First write via StreamWrite
-	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	// First write (regular Write)
+	firstSnap, err := ds.Write(t.Context(), []any{[]byte("first")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, _ = sw.Write([]byte("payload-1"))
-	snap1, err := sw.Commit(t.Context())
+
+	// Second write (StreamWrite)
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	listCallsAfterFirst := len(fs.ListCalls())
-
-	// Second write via regular Write: pointer exists, no List
-	snap2, err := ds.Write(t.Context(), []any{[]byte("payload-2")}, Metadata{})
+	_, _ = sw.Write([]byte("second"))
+	secondSnap, err := sw.Commit(t.Context())
 	if err != nil {
 		t.Fatal(err)
 	}
-	listCallsAfterSecond := len(fs.ListCalls())
-	if listCallsAfterSecond != listCallsAfterFirst {
-		t.Errorf("expected no new List calls after StreamWrite wrote pointer, got %d new calls",
-			listCallsAfterSecond-listCallsAfterFirst)
-	}
-	if snap2.Manifest.ParentSnapshotID != snap1.ID {
-		t.Errorf("expected parent %s, got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+
+	if secondSnap.Manifest.ParentSnapshotID != firstSnap.ID {
+		t.Errorf("expected parent %s, got %s", firstSnap.ID, secondSnap.Manifest.ParentSnapshotID)
 	}
 }
 
-func TestDataset_StreamWriteRecords_LatestPointer_SkipsScan(t *testing.T) {
-	// StreamWriteRecords must write the pointer.
-	fs := newFaultStore(NewMemory())
-	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
-		WithCodec(NewJSONLCodec()),
-	)
+func TestDataset_StreamWrite_WithGzipCompression(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCompressor(NewGzipCompressor()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// First write
-	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	listCallsAfterFirst := len(fs.ListCalls())
 
-	// Second write via StreamWriteRecords: pointer exists
-	iter := &sliceIterator{records: R(D{"b": 2})}
-	snap2, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	data := []byte("compressible data that should be gzipped")
+	_, err = sw.Write(data)
 	if err != nil {
-		t.Fatal(err)
-	}
-	listCallsAfterSecond := len(fs.ListCalls())
-	if listCallsAfterSecond != listCallsAfterFirst {
-		t.Errorf("expected no new List calls on StreamWriteRecords, got %d new calls",
-			listCallsAfterSecond-listCallsAfterFirst)
-	}
-	if snap2.Manifest.ParentSnapshotID != snap1.ID {
-		t.Errorf("expected parent %s, got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+		t.Fatalf("Write failed: %v", err)
 	}
 
-	// Third write: verify chain continues via pointer
-	snap3, err := ds.Write(t.Context(), R(D{"c": 3}), Metadata{})
+	snap, err := sw.Commit(t.Context())
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("Commit failed: %v", err)
 	}
-	listCallsAfterThird := len(fs.ListCalls())
-	if listCallsAfterThird != listCallsAfterFirst {
-		t.Errorf("expected no new List calls on third write, got %d new calls",
-			listCallsAfterThird-listCallsAfterFirst)
+
+	// Verify manifest indicates gzip compression
+	if snap.Manifest.Compressor != "gzip" {
+		t.Errorf("expected compressor 'gzip', got %q", snap.Manifest.Compressor)
 	}
-	if snap3.Manifest.ParentSnapshotID != snap2.ID {
-		t.Errorf("expected parent %s, got %s", snap2.ID, snap3.Manifest.ParentSnapshotID)
+
+	// Verify file path ends with .gz
+	if len(snap.Manifest.Files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
+	}
+	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".gz") {
+		t.Errorf("expected .gz extension, got %s", snap.Manifest.Files[0].Path)
+	}
+
+	// Verify data can be read back correctly
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	readBytes, ok := readData[0].([]byte)
+	if !ok {
+		t.Fatalf("expected []byte, got %T", readData[0])
+	}
+	if string(readBytes) != string(data) {
+		t.Errorf("expected %q, got %q", data, readBytes)
+	}
+}
+
+func TestDataset_Read_GzipMultipleMembers(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"n": 0}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replace the data file with two concatenated gzip members, as written
+	// by pigz or `cat a.gz b.gz`.
+	var buf bytes.Buffer
+	for _, member := range []string{"{\"n\":1}\n{\"n\":2}\n", "{\"n\":3}\n"} {
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write([]byte(member)); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dataPath := snap.Manifest.Files[0].Path
+	if err := store.Delete(t.Context(), dataPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(t.Context(), dataPath, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ns []float64
+	for _, r := range records {
+		ns = append(ns, r.(map[string]any)["n"].(float64))
+	}
+	if want := []float64{1, 2, 3}; !reflect.DeepEqual(ns, want) {
+		t.Errorf("records n = %v, want %v from both gzip members", ns, want)
+	}
+}
+
+func TestDataset_StreamWrite_WithZstdCompression(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCompressor(NewZstdCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("compressible data that should be zstd compressed")
+	_, err = sw.Write(data)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	snap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Verify manifest indicates zstd compression
+	if snap.Manifest.Compressor != "zstd" {
+		t.Errorf("expected compressor 'zstd', got %q", snap.Manifest.Compressor)
+	}
+
+	// Verify file path ends with .zst
+	if len(snap.Manifest.Files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
+	}
+	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".zst") {
+		t.Errorf("expected .zst extension, got %s", snap.Manifest.Files[0].Path)
+	}
+
+	// Verify data can be read back correctly
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	readBytes, ok := readData[0].([]byte)
+	if !ok {
+		t.Fatalf("expected []byte, got %T", readData[0])
+	}
+	if string(readBytes) != string(data) {
+		t.Errorf("expected %q, got %q", data, readBytes)
+	}
+}
+
+func TestDataset_Write_WithZstdCompression(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewZstdCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{
+		D{"id": "1", "data": "compressible zstd content"},
+		D{"id": "2", "data": "more compressible zstd content"},
+	}
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// Verify manifest indicates zstd compression
+	if snap.Manifest.Compressor != "zstd" {
+		t.Errorf("expected compressor 'zstd', got %q", snap.Manifest.Compressor)
+	}
+	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".zst") {
+		t.Errorf("expected .zst extension, got %s", snap.Manifest.Files[0].Path)
+	}
+
+	// Verify data can be read back
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(readData) != 2 {
+		t.Errorf("expected 2 records, got %d", len(readData))
+	}
+}
+
+func TestDataset_StreamWriteRecords_WithZstdCompression(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewZstdCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{
+		D{"id": "1", "data": "some zstd compressible content"},
+		D{"id": "2", "data": "more zstd compressible content"},
+	}
+	iter := &sliceIterator{records: records}
+
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatalf("StreamWriteRecords failed: %v", err)
+	}
+
+	if snap.Manifest.Compressor != "zstd" {
+		t.Errorf("expected compressor 'zstd', got %q", snap.Manifest.Compressor)
+	}
+	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".zst") {
+		t.Errorf("expected .zst extension, got %s", snap.Manifest.Files[0].Path)
+	}
+
+	// Verify data can be read back
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(readData) != 2 {
+		t.Errorf("expected 2 records, got %d", len(readData))
+	}
+}
+
+func TestDataset_StreamWrite_MultipleWrites(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write in chunks
+	chunks := [][]byte{
+		[]byte("chunk1"),
+		[]byte("-chunk2"),
+		[]byte("-chunk3"),
+	}
+	for _, chunk := range chunks {
+		_, err = sw.Write(chunk)
+		if err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	snap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// Verify data can be read back as concatenated
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	readBytes, ok := readData[0].([]byte)
+	if !ok {
+		t.Fatalf("expected []byte, got %T", readData[0])
+	}
+	expected := "chunk1-chunk2-chunk3"
+	if string(readBytes) != expected {
+		t.Errorf("expected %q, got %q", expected, readBytes)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// StreamWriteRecords tests
+// -----------------------------------------------------------------------------
+
+func TestDataset_StreamWriteRecords_Success(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{
+		D{"id": "1", "value": "first"},
+		D{"id": "2", "value": "second"},
+		D{"id": "3", "value": "third"},
+	}
+	iter := &sliceIterator{records: records}
+
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{"source": "stream"})
+	if err != nil {
+		t.Fatalf("StreamWriteRecords failed: %v", err)
+	}
+
+	if snap == nil {
+		t.Fatal("expected snapshot, got nil")
+	}
+	if snap.Manifest.RowCount != 3 {
+		t.Errorf("expected RowCount 3, got %d", snap.Manifest.RowCount)
+	}
+	if snap.Manifest.Codec != "jsonl" {
+		t.Errorf("expected codec 'jsonl', got %q", snap.Manifest.Codec)
+	}
+	if snap.Manifest.Metadata["source"] != "stream" {
+		t.Errorf("expected metadata source=stream, got %v", snap.Manifest.Metadata)
+	}
+
+	// Verify snapshot is visible via Latest
+	latest, err := ds.Latest(t.Context())
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if latest.ID != snap.ID {
+		t.Errorf("expected latest ID %s, got %s", snap.ID, latest.ID)
+	}
+
+	// Verify data can be read back
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(readData) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(readData))
+	}
+}
+
+func TestDataset_StreamWriteRecords_NonStreamingCodec_ReturnsError(t *testing.T) {
+	// testCodec does not implement StreamingRecordCodec
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(&testCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: []any{D{"id": "1"}}}
+	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if !errors.Is(err, ErrCodecNotStreamable) {
+		t.Errorf("expected ErrCodecNotStreamable, got: %v", err)
+	}
+}
+
+func TestDataset_StreamWriteRecords_NoCodec_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: []any{D{"id": "1"}}}
+	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err == nil {
+		t.Fatal("expected error for no codec, got nil")
+	}
+	if !strings.Contains(err.Error(), "requires a codec") {
+		t.Errorf("expected 'requires a codec' error, got: %v", err)
+	}
+}
+
+func TestDataset_StreamWriteRecords_NilMetadata_CoalescesToEmpty(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: []any{D{"id": "1"}}}
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, nil)
+	if err != nil {
+		t.Fatalf("expected nil metadata to succeed, got: %v", err)
+	}
+	if snap.Manifest.Metadata == nil {
+		t.Fatal("expected non-nil metadata in manifest after nil coalescing")
+	}
+	if len(snap.Manifest.Metadata) != 0 {
+		t.Errorf("expected empty metadata, got %v", snap.Manifest.Metadata)
+	}
+}
+
+func TestDataset_StreamWriteRecords_NilIterator_ReturnsError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ds.StreamWriteRecords(t.Context(), nil, Metadata{})
+	if err == nil {
+		t.Fatal("expected error for nil iterator, got nil")
+	}
+	if !errors.Is(err, ErrNilIterator) {
+		t.Errorf("expected ErrNilIterator, got: %v", err)
+	}
+}
+
+func TestDataset_StreamWriteRecords_WithPartitioner_ReturnsError(t *testing.T) {
+	// StreamWriteRecords cannot partition since it's single-pass streaming
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: []any{D{"id": "1", "day": "2024-01-01"}}}
+	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err == nil {
+		t.Fatal("expected error for partitioning, got nil")
+	}
+	if !errors.Is(err, ErrPartitioningNotSupported) {
+		t.Errorf("expected ErrPartitioningNotSupported, got: %v", err)
+	}
+}
+
+func TestDataset_StreamWriteRecords_TimestampedRecords_ComputesMinMax(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	ts2 := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	ts3 := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	records := []any{
+		&timestampedRecord{ID: "a", Time: ts1},
+		&timestampedRecord{ID: "b", Time: ts2},
+		&timestampedRecord{ID: "c", Time: ts3},
+	}
+	iter := &sliceIterator{records: records}
+
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.MinTimestamp == nil {
+		t.Fatal("expected MinTimestamp to be set")
+	}
+	if snap.Manifest.MaxTimestamp == nil {
+		t.Fatal("expected MaxTimestamp to be set")
+	}
+	if !snap.Manifest.MinTimestamp.Equal(ts1) {
+		t.Errorf("expected MinTimestamp %v, got %v", ts1, *snap.Manifest.MinTimestamp)
+	}
+	if !snap.Manifest.MaxTimestamp.Equal(ts2) {
+		t.Errorf("expected MaxTimestamp %v, got %v", ts2, *snap.Manifest.MaxTimestamp)
+	}
+}
+
+func TestDataset_StreamWriteRecords_NonTimestampedRecords_OmitsMinMax(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{
+		D{"id": "a", "value": 1},
+		D{"id": "b", "value": 2},
+	}
+	iter := &sliceIterator{records: records}
+
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.MinTimestamp != nil {
+		t.Errorf("expected MinTimestamp to be nil, got %v", snap.Manifest.MinTimestamp)
+	}
+	if snap.Manifest.MaxTimestamp != nil {
+		t.Errorf("expected MaxTimestamp to be nil, got %v", snap.Manifest.MaxTimestamp)
+	}
+}
+
+func TestDataset_StreamWriteRecords_EmptyIterator(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: []any{}}
+
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatalf("StreamWriteRecords failed: %v", err)
+	}
+
+	if snap.Manifest.RowCount != 0 {
+		t.Errorf("expected RowCount 0, got %d", snap.Manifest.RowCount)
+	}
+}
+
+func TestDataset_StreamWriteRecords_IteratorError(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterErr := errors.New("iterator failure")
+	iter := &errorIterator{err: iterErr}
+
+	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err == nil {
+		t.Fatal("expected error from iterator, got nil")
+	}
+	if !strings.Contains(err.Error(), "iterator failure") {
+		t.Errorf("expected iterator failure error, got: %v", err)
+	}
+
+	// Verify no snapshot created
+	_, err = ds.Latest(t.Context())
+	if !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots after iterator error, got: %v", err)
+	}
+}
+
+func TestDataset_StreamWriteRecords_WithCompression(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{
+		D{"id": "1", "data": "some compressible content"},
+		D{"id": "2", "data": "more compressible content"},
+	}
+	iter := &sliceIterator{records: records}
+
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatalf("StreamWriteRecords failed: %v", err)
+	}
+
+	if snap.Manifest.Compressor != "gzip" {
+		t.Errorf("expected compressor 'gzip', got %q", snap.Manifest.Compressor)
+	}
+	if !strings.HasSuffix(snap.Manifest.Files[0].Path, ".gz") {
+		t.Errorf("expected .gz extension, got %s", snap.Manifest.Files[0].Path)
+	}
+
+	// Verify data can be read back
+	readData, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(readData) != 2 {
+		t.Errorf("expected 2 records, got %d", len(readData))
+	}
+}
+
+func TestDataset_StreamWriteRecords_ParentSnapshotLinked(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First write (regular Write)
+	firstSnap, err := ds.Write(t.Context(), []any{D{"id": "1"}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Second write (StreamWriteRecords)
+	iter := &sliceIterator{records: []any{D{"id": "2"}}}
+	secondSnap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secondSnap.Manifest.ParentSnapshotID != firstSnap.ID {
+		t.Errorf("expected parent %s, got %s", firstSnap.ID, secondSnap.Manifest.ParentSnapshotID)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Checksum tests
+// -----------------------------------------------------------------------------
+
+func TestDataset_Write_WithChecksum_RecordsChecksum(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), []any{[]byte("hello world")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify checksum algorithm recorded
+	if snap.Manifest.ChecksumAlgorithm != "md5" {
+		t.Errorf("expected ChecksumAlgorithm 'md5', got %q", snap.Manifest.ChecksumAlgorithm)
+	}
+
+	// Verify file has checksum
+	if len(snap.Manifest.Files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
+	}
+	if snap.Manifest.Files[0].Checksum == "" {
+		t.Error("expected non-empty checksum")
+	}
+	// MD5 produces 32 hex characters
+	if len(snap.Manifest.Files[0].Checksum) != 32 {
+		t.Errorf("expected 32 char MD5 checksum, got %d chars", len(snap.Manifest.Files[0].Checksum))
+	}
+}
+
+func TestDataset_Write_WithoutChecksum_OmitsChecksum(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), []any{[]byte("hello world")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify no checksum algorithm recorded
+	if snap.Manifest.ChecksumAlgorithm != "" {
+		t.Errorf("expected empty ChecksumAlgorithm, got %q", snap.Manifest.ChecksumAlgorithm)
+	}
+
+	// Verify file has no checksum
+	if snap.Manifest.Files[0].Checksum != "" {
+		t.Errorf("expected empty checksum, got %q", snap.Manifest.Files[0].Checksum)
+	}
+}
+
+func TestDataset_StreamWrite_WithChecksum_RecordsChecksum(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sw.Write([]byte("streaming data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify checksum algorithm recorded
+	if snap.Manifest.ChecksumAlgorithm != "md5" {
+		t.Errorf("expected ChecksumAlgorithm 'md5', got %q", snap.Manifest.ChecksumAlgorithm)
+	}
+
+	// Verify file has checksum
+	if snap.Manifest.Files[0].Checksum == "" {
+		t.Error("expected non-empty checksum")
+	}
+	if len(snap.Manifest.Files[0].Checksum) != 32 {
+		t.Errorf("expected 32 char MD5 checksum, got %d chars", len(snap.Manifest.Files[0].Checksum))
+	}
+}
+
+func TestDataset_StreamWriteRecords_WithChecksum_RecordsChecksum(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: []any{D{"id": "1"}, D{"id": "2"}}}
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify checksum algorithm recorded
+	if snap.Manifest.ChecksumAlgorithm != "md5" {
+		t.Errorf("expected ChecksumAlgorithm 'md5', got %q", snap.Manifest.ChecksumAlgorithm)
+	}
+
+	// Verify file has checksum
+	if snap.Manifest.Files[0].Checksum == "" {
+		t.Error("expected non-empty checksum")
+	}
+	if len(snap.Manifest.Files[0].Checksum) != 32 {
+		t.Errorf("expected 32 char MD5 checksum, got %d chars", len(snap.Manifest.Files[0].Checksum))
+	}
+}
+
+func TestDataset_Checksum_ComputedOnCompressedData(t *testing.T) {
+	// Two datasets with same data but different compression
+	// should produce different checksums (since checksum is on stored bytes)
+	dsNoComp, err := NewDataset("test-no-comp", NewMemoryFactory(),
+		WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsGzip, err := NewDataset("test-gzip", NewMemoryFactory(),
+		WithCompressor(NewGzipCompressor()),
+		WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("compressible data that should produce different checksums")
+
+	snapNoComp, err := dsNoComp.Write(t.Context(), []any{data}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapGzip, err := dsGzip.Write(t.Context(), []any{data}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both should have checksums
+	if snapNoComp.Manifest.Files[0].Checksum == "" {
+		t.Error("expected checksum for no-compression")
+	}
+	if snapGzip.Manifest.Files[0].Checksum == "" {
+		t.Error("expected checksum for gzip")
+	}
+
+	// Checksums should be different (stored bytes differ)
+	if snapNoComp.Manifest.Files[0].Checksum == snapGzip.Manifest.Files[0].Checksum {
+		t.Error("expected different checksums for different compression")
+	}
+}
+
+func TestDataset_Checksum_MatchesStoredObject(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var snaps []*DatasetSnapshot
+	snap, err := ds.Write(t.Context(), R(D{"id": 1}, D{"id": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snaps = append(snaps, snap)
+
+	snap, err = ds.StreamWriteRecords(t.Context(), &sliceIterator{records: R(D{"id": 3})}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snaps = append(snaps, snap)
+
+	blobs, err := NewDataset("test-blobs", NewMemoryFactoryFrom(store),
+		WithCompressor(NewGzipCompressor()),
+		WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw, err := blobs.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sw.Write([]byte("streamed blob")); err != nil {
+		t.Fatal(err)
+	}
+	snap, err = sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snaps = append(snaps, snap)
+
+	for _, snap := range snaps {
+		if snap.Manifest.ChecksumAlgorithm != "md5" {
+			t.Errorf("expected checksum algorithm md5, got %q", snap.Manifest.ChecksumAlgorithm)
+		}
+		for _, f := range snap.Manifest.Files {
+			rc, err := store.Get(t.Context(), f.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sum := md5.Sum(stored)
+			if want := hex.EncodeToString(sum[:]); f.Checksum != want {
+				t.Errorf("%s: recorded checksum %s, independent checksum of stored bytes %s", f.Path, f.Checksum, want)
+			}
+		}
+	}
+}
+
+func TestDataset_Checksum_SameDataProducesSameChecksum(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("consistent data")
+
+	snap1, err := ds.Write(t.Context(), []any{data}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap2, err := ds.Write(t.Context(), []any{data}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same data should produce same checksum
+	if snap1.Manifest.Files[0].Checksum != snap2.Manifest.Files[0].Checksum {
+		t.Errorf("expected same checksum for same data, got %s vs %s",
+			snap1.Manifest.Files[0].Checksum, snap2.Manifest.Files[0].Checksum)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Manifest formatting tests
+// -----------------------------------------------------------------------------
+
+func readStoredManifest(t *testing.T, store Store, id DatasetSnapshotID) []byte {
+	t.Helper()
+	rc, err := store.Get(t.Context(), "datasets/test-ds/snapshots/"+string(id)+"/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDataset_PrettyManifest_ReadsBackIdentically(t *testing.T) {
+	for _, pretty := range []bool{true, false} {
+		store := NewMemory()
+		ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+			WithCodec(NewJSONLCodec()), WithPrettyManifest(pretty))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		snap, err := ds.Write(t.Context(), []any{D{"id": "1"}}, Metadata{"source": "test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		raw := readStoredManifest(t, store, snap.ID)
+		if got := bytes.Contains(raw, []byte("\n")); got != pretty {
+			t.Errorf("pretty=%v: manifest contains newlines = %v", pretty, got)
+		}
+
+		got, err := ds.Snapshot(t.Context(), snap.ID)
+		if err != nil {
+			t.Fatalf("pretty=%v: Snapshot failed: %v", pretty, err)
+		}
+		want, _ := json.Marshal(snap.Manifest)
+		gotJSON, _ := json.Marshal(got.Manifest)
+		if !bytes.Equal(want, gotJSON) {
+			t.Errorf("pretty=%v: manifest round-trip mismatch:\n%s\n%s", pretty, want, gotJSON)
+		}
+	}
+}
+
+func TestManifestChecksum_IndependentOfPrettyPrinting(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), []any{D{"id": "1"}}, Metadata{"b": 2, "a": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pretty := readStoredManifest(t, store, snap.ID)
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, pretty); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewMD5Checksum().NewHasher()
+	_, _ = h.Write(compact.Bytes())
+
+	got, err := ManifestChecksum(snap.Manifest, NewMD5Checksum())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != h.Sum() {
+		t.Errorf("canonical checksum %s does not match compact form %s", got, h.Sum())
+	}
+
+	if _, err := ManifestChecksum(nil, NewMD5Checksum()); err == nil {
+		t.Error("expected error for nil manifest")
+	}
+}
+
+func TestDataset_ManifestCompressor_TransparentRead(t *testing.T) {
+	for _, tc := range []struct {
+		compressor Compressor
+		magic      []byte
+	}{
+		{NewGzipCompressor(), gzipMagic},
+		{NewZstdCompressor(), zstdMagic},
+	} {
+		t.Run(tc.compressor.Name(), func(t *testing.T) {
+			store := NewMemory()
+
+			// An uncompressed manifest from an earlier writer.
+			plain, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			old, err := plain.Write(t.Context(), R(D{"id": "1"}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+				WithCodec(NewJSONLCodec()),
+				WithManifestCompressor(tc.compressor))
+			if err != nil {
+				t.Fatal(err)
+			}
+			snap, err := ds.Write(t.Context(), R(D{"id": "2"}), Metadata{"source": "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if raw := readStoredManifest(t, store, snap.ID); !bytes.HasPrefix(raw, tc.magic) {
+				t.Errorf("stored manifest is not %s-compressed: % x", tc.compressor.Name(), raw[:4])
+			}
+
+			got, err := ds.Snapshot(t.Context(), snap.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := json.Marshal(snap.Manifest)
+			gotJSON, _ := json.Marshal(got.Manifest)
+			if !bytes.Equal(want, gotJSON) {
+				t.Errorf("manifest round-trip mismatch:\n%s\n%s", want, gotJSON)
+			}
+
+			// The reader validates both encodings.
+			reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs, err := reader.ListManifests(t.Context(), "test-ds", "", ManifestListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(refs) != 2 {
+				t.Errorf("expected 2 manifests, got %d", len(refs))
+			}
+			for _, id := range []DatasetSnapshotID{old.ID, snap.ID} {
+				if _, err := reader.GetManifest(t.Context(), "test-ds", ManifestRef{ID: id}); err != nil {
+					t.Errorf("GetManifest(%s): %v", id, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDataset_ManifestCompressor_InvalidCompressor(t *testing.T) {
+	if _, err := NewDataset("test-ds", NewMemoryFactory(), WithManifestCompressor(nil)); err == nil {
+		t.Error("expected error for nil compressor")
+	}
+	if _, err := NewDataset("test-ds", NewMemoryFactory(), WithManifestCompressor(struct{ Compressor }{NewGzipCompressor()})); err == nil {
+		t.Error("expected error for custom compressor")
+	}
+	_, err := NewDatasetReader(NewMemoryFactory(), WithManifestCompressor(NewGzipCompressor()))
+	if !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDatasetReader_WithPrettyManifest_ReturnsError(t *testing.T) {
+	_, err := NewDatasetReader(NewMemoryFactory(), WithPrettyManifest(false))
+	if !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_WithMaxPartitions(t *testing.T) {
+	ctx := t.Context()
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("id"),
+		WithMaxPartitions(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []any
+	for i := range 5 {
+		records = append(records, D{"id": i})
+	}
+	_, err = ds.Write(ctx, records, Metadata{})
+	var tooMany *TooManyPartitionsError
+	if !errors.As(err, &tooMany) || !errors.Is(err, ErrTooManyPartitions) {
+		t.Fatalf("expected *TooManyPartitionsError, got: %v", err)
+	}
+	if tooMany.Partitions != 5 || tooMany.Limit != 3 {
+		t.Errorf("error = %+v, want 5 partitions over limit 3", tooMany)
+	}
+	if want := "lode: write produces 5 partitions, limit is 3"; err.Error() != want {
+		t.Errorf("error message = %q, want %q", err.Error(), want)
+	}
+	if puts := fs.PutCalls(); len(puts) != 0 {
+		t.Errorf("expected no objects written, got %v", puts)
+	}
+	if _, err := ds.Latest(ctx); !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots, got: %v", err)
+	}
+
+	if _, err := ds.Write(ctx, records[:3], Metadata{}); err != nil {
+		t.Errorf("write at the limit: %v", err)
+	}
+
+	if _, err := NewDataset("events", NewMemoryFactory(), WithMaxPartitions(-1)); err == nil {
+		t.Error("expected error for negative limit")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithMaxPartitions(3)); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_WithSuccessMarker(t *testing.T) {
+	ctx := t.Context()
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"),
+		WithSuccessMarker(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"day": "1", "n": 1}, D{"day": "2", "n": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The marker is the last Put, after the canonical and partition manifests.
+	marker := "datasets/events/segments/" + string(snap.ID) + "/_SUCCESS"
+	puts := fs.PutCalls()
+	if len(puts) == 0 || puts[len(puts)-1] != marker {
+		t.Fatalf("expected %s as the final Put, got %v", marker, puts)
+	}
+	manifests := 0
+	for _, p := range puts[:len(puts)-1] {
+		if strings.HasSuffix(p, "/manifest.json") {
+			manifests++
+		}
+	}
+	if manifests != 3 {
+		t.Errorf("expected 3 manifest Puts before the marker, got %d in %v", manifests, puts)
+	}
+
+	// Discovery ignores the marker.
+	if _, err := ds.Write(ctx, R(D{"day": "1", "n": 3}), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	snaps, err := ds.Snapshots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Errorf("expected 2 snapshots, got %d", len(snaps))
+	}
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := reader.ListDatasets(ctx, DatasetListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []DatasetID{"events"}) {
+		t.Errorf("ListDatasets = %v, want [events]", ids)
+	}
+	report, err := reader.VerifySegment(ctx, "events", ManifestRef{ID: snap.ID}, VerifySegmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasDrift() {
+		t.Errorf("marker reported as drift: %+v", report)
+	}
+}
+
+func TestDataset_WithSuccessMarker_NotWrittenWhenManifestFails(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithSuccessMarker(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs.SetPutError(errors.New("manifest put failed"), "manifest.json")
+	if _, err := ds.Write(t.Context(), R(D{"n": 1}), Metadata{}); err == nil {
+		t.Fatal("expected error when the manifest Put fails")
+	}
+	for _, p := range fs.PutCalls() {
+		if strings.HasSuffix(p, "/_SUCCESS") {
+			t.Errorf("marker %s written despite failed manifest commit", p)
+		}
+	}
+}
+
+func TestDataset_WithSuccessMarker_PutFailureCarriesSnapshot(t *testing.T) {
+	ctx := t.Context()
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithSuccessMarker(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	putErr := errors.New("marker put failed")
+	fs.SetPutError(putErr, "_SUCCESS")
+
+	check := func(name string, ds Dataset, err error) {
+		t.Helper()
+		var markerErr *SuccessMarkerError
+		if !errors.As(err, &markerErr) || !errors.Is(err, putErr) {
+			t.Fatalf("%s: expected *SuccessMarkerError wrapping the Put error, got: %v", name, err)
+		}
+		if !strings.HasPrefix(err.Error(), "lode: ") {
+			t.Errorf("%s: error lacks lode prefix: %v", name, err)
+		}
+		// The carried snapshot is committed and its data kept.
+		records, err := ds.Read(ctx, markerErr.Snapshot.ID)
+		if err != nil {
+			t.Fatalf("%s: committed snapshot not readable: %v", name, err)
+		}
+		if len(records) != 1 {
+			t.Errorf("%s: read %d records, want 1", name, len(records))
+		}
+	}
+
+	_, err = ds.Write(ctx, R(D{"n": 1}), Metadata{})
+	check("Write", ds, err)
+
+	raw, err := NewDataset("blobs", newFaultStoreFactory(fs), WithSuccessMarker(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sw, err := raw.StreamWrite(ctx, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sw.Write([]byte(`{"n":2}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	_, err = sw.Commit(ctx)
+	check("StreamWrite", raw, err)
+}
+
+func TestDatasetReader_WithSuccessMarker_ReturnsError(t *testing.T) {
+	_, err := NewDatasetReader(NewMemoryFactory(), WithSuccessMarker(true))
+	if !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+// bzip2JSONLFixture is `printf '{"id":"a"}\n{"id":"b"}\n' | bzip2 -9`.
+var bzip2JSONLFixture = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x70, 0xf2, 0x32, 0x74, 0x00, 0x00,
+	0x09, 0x59, 0x80, 0x00, 0x10, 0x10, 0x00, 0x00, 0x10, 0x34, 0x20, 0x00, 0x0a, 0x20, 0x00, 0x31,
+	0x06, 0x4c, 0x40, 0x55, 0x4d, 0x0d, 0x36, 0xa4, 0x79, 0xd4, 0x66, 0x61, 0x14, 0x8a, 0x7c, 0x5d,
+	0xc9, 0x14, 0xe1, 0x42, 0x41, 0xc3, 0xc8, 0xc9, 0xd0,
+}
+
+func TestBzip2Compressor_DecompressesFixture(t *testing.T) {
+	rc, err := NewBzip2Compressor().Decompress(bytes.NewReader(bzip2JSONLFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"id\":\"a\"}\n{\"id\":\"b\"}\n"; string(data) != want {
+		t.Errorf("decompressed = %q, want %q", data, want)
+	}
+}
+
+func TestDataset_Bzip2_ReadsLegacySnapshotAndRejectsWrites(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	dataPath := "datasets/legacy/snapshots/snap-1/data/data.jsonl.bz2"
+	if err := store.Put(ctx, dataPath, bytes.NewReader(bzip2JSONLFixture)); err != nil {
+		t.Fatal(err)
+	}
+	writeManifest(ctx, t, store, &Manifest{
+		SchemaName:    "lode-manifest",
+		FormatVersion: "1.0.0",
+		DatasetID:     "legacy",
+		SnapshotID:    "snap-1",
+		CreatedAt:     time.Now().UTC(),
+		Metadata:      Metadata{},
+		Files:         []FileRef{{Path: dataPath, SizeBytes: int64(len(bzip2JSONLFixture))}},
+		RowCount:      2,
+		Codec:         "jsonl",
+		Compressor:    "bzip2",
+		Partitioner:   "noop",
+	})
+
+	ds, err := NewDataset("legacy", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewBzip2Compressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ds.Read(ctx, "snap-1")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(records) != 2 || records[1].(map[string]any)["id"] != "b" {
+		t.Errorf("unexpected records: %v", records)
+	}
+
+	_, err = ds.Write(ctx, R(D{"id": "c"}), Metadata{})
+	if !errors.Is(err, ErrCompressionWriteUnsupported) {
+		t.Errorf("expected ErrCompressionWriteUnsupported, got: %v", err)
+	}
+}
+
+func TestBrotliCompressor_RoundTripAndQuality(t *testing.T) {
+	for _, q := range []int{-1, 12} {
+		if _, err := NewBrotliCompressor(q); err == nil {
+			t.Errorf("quality %d: expected error", q)
+		}
+	}
+
+	c, err := NewBrotliCompressor(BrotliDefaultQuality)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name() != "brotli" || c.Extension() != ".br" {
+		t.Errorf("unexpected name/extension %q/%q", c.Name(), c.Extension())
+	}
+
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithCompressor(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"id": "a"}, D{"id": "b"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.Compressor != "brotli" || !strings.HasSuffix(snap.Manifest.Files[0].Path, ".jsonl.br") {
+		t.Errorf("unexpected manifest compressor %q or path %q", snap.Manifest.Compressor, snap.Manifest.Files[0].Path)
+	}
+	it, err := ds.ReadFileRecords(t.Context(), snap.ID, snap.Manifest.Files[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = it.Close() }()
+	var ids []any
+	for it.Next() {
+		ids = append(ids, it.Record().(map[string]any)["id"])
+	}
+	if it.Err() != nil || !reflect.DeepEqual(ids, []any{"a", "b"}) {
+		t.Errorf("streamed records = %v, err %v", ids, it.Err())
+	}
+}
+
+func TestBrotliCompressor_SmallerThanGzipOnJSON(t *testing.T) {
+	var buf bytes.Buffer
+	for i := range 500 {
+		fmt.Fprintf(&buf, `{"id":%d,"event":"page_view","path":"/products/%d","user_agent":"Mozilla/5.0 (X11; Linux x86_64)","ts":"2024-01-01T00:%02d:00Z"}`+"\n", i, i%37, i%60)
+	}
+	input := buf.Bytes()
+
+	compressed := func(c Compressor) int {
+		var out bytes.Buffer
+		w, err := c.Compress(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(input); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return out.Len()
+	}
+
+	brotli, err := NewBrotliCompressor(BrotliDefaultQuality)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipSize, brotliSize := compressed(NewGzipCompressor()), compressed(brotli)
+	t.Logf("%d bytes of JSONL: gzip %d, brotli(q=%d) %d", len(input), gzipSize, BrotliDefaultQuality, brotliSize)
+	if brotliSize >= gzipSize {
+		t.Errorf("expected brotli (%d bytes) to beat gzip (%d bytes)", brotliSize, gzipSize)
+	}
+}
+
+func TestLZ4Compressor_RoundTrip(t *testing.T) {
+	c := NewLZ4Compressor()
+	if c.Name() != "lz4" || c.Extension() != ".lz4" {
+		t.Errorf("unexpected name/extension %q/%q", c.Name(), c.Extension())
+	}
+
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()), WithCompressor(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"id": "a"}, D{"id": "b"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.Compressor != "lz4" || !strings.HasSuffix(snap.Manifest.Files[0].Path, ".jsonl.lz4") {
+		t.Errorf("unexpected manifest compressor %q or path %q", snap.Manifest.Compressor, snap.Manifest.Files[0].Path)
+	}
+	records, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].(map[string]any)["id"] != "a" {
+		t.Errorf("unexpected records: %v", records)
+	}
+
+	// A stored frame starts with the LZ4 frame magic number.
+	rc, err := store.Get(t.Context(), snap.Manifest.Files[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = rc.Close() }()
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(rc, header); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(header, []byte{0x04, 0x22, 0x4d, 0x18}) {
+		t.Errorf("expected LZ4 frame magic, got % x", header)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Timestamped interface tests
+// -----------------------------------------------------------------------------
+
+func TestDataset_Write_TimestampedRecords_ComputesMinMax(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	ts2 := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	ts3 := time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)
+
+	records := []any{
+		&timestampedRecord{ID: "a", Time: ts1},
+		&timestampedRecord{ID: "b", Time: ts2},
+		&timestampedRecord{ID: "c", Time: ts3},
+	}
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.MinTimestamp == nil {
+		t.Fatal("expected MinTimestamp to be set")
+	}
+	if snap.Manifest.MaxTimestamp == nil {
+		t.Fatal("expected MaxTimestamp to be set")
+	}
+
+	if !snap.Manifest.MinTimestamp.Equal(ts1) {
+		t.Errorf("expected MinTimestamp %v, got %v", ts1, *snap.Manifest.MinTimestamp)
+	}
+	if !snap.Manifest.MaxTimestamp.Equal(ts2) {
+		t.Errorf("expected MaxTimestamp %v, got %v", ts2, *snap.Manifest.MaxTimestamp)
+	}
+}
+
+func TestDataset_Write_NonTimestampedRecords_OmitsMinMax(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Plain maps don't implement Timestamped
+	records := []any{
+		D{"id": "a", "value": 1},
+		D{"id": "b", "value": 2},
+	}
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.MinTimestamp != nil {
+		t.Errorf("expected MinTimestamp to be nil, got %v", snap.Manifest.MinTimestamp)
+	}
+	if snap.Manifest.MaxTimestamp != nil {
+		t.Errorf("expected MaxTimestamp to be nil, got %v", snap.Manifest.MaxTimestamp)
+	}
+}
+
+func TestDataset_Write_RawBlob_OmitsTimestamps(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), []any{[]byte("blob data")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.MinTimestamp != nil {
+		t.Errorf("expected MinTimestamp to be nil for raw blob, got %v", snap.Manifest.MinTimestamp)
+	}
+	if snap.Manifest.MaxTimestamp != nil {
+		t.Errorf("expected MaxTimestamp to be nil for raw blob, got %v", snap.Manifest.MaxTimestamp)
+	}
+}
+
+func TestDataset_Write_SingleTimestampedRecord_SameMinMax(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)
+	records := []any{&timestampedRecord{ID: "only", Time: ts}}
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.MinTimestamp == nil || snap.Manifest.MaxTimestamp == nil {
+		t.Fatal("expected both timestamps to be set")
+	}
+	if !snap.Manifest.MinTimestamp.Equal(ts) || !snap.Manifest.MaxTimestamp.Equal(ts) {
+		t.Errorf("expected both timestamps to be %v", ts)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// FileStats write-path tests
+// -----------------------------------------------------------------------------
+
+func TestDataset_Write_ParquetCodec_StatsPopulated(t *testing.T) {
+	schema := ParquetSchema{
+		Fields: []ParquetField{
+			{Name: "id", Type: ParquetInt64},
+			{Name: "name", Type: ParquetString},
+		},
+	}
+	codec, err := NewParquetCodec(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds, err := NewDataset("stats-ds", NewMemoryFactory(), WithCodec(codec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{
+		map[string]any{"id": int64(1), "name": "alice"},
+		map[string]any{"id": int64(2), "name": "bob"},
+	}
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(snap.Manifest.Files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(snap.Manifest.Files))
+	}
+
+	stats := snap.Manifest.Files[0].Stats
+	if stats == nil {
+		t.Fatal("expected Stats on FileRef, got nil")
+	}
+	if stats.RowCount != 2 {
+		t.Errorf("RowCount = %d, want 2", stats.RowCount)
+	}
+	if len(stats.Columns) != 2 {
+		t.Fatalf("len(Columns) = %d, want 2", len(stats.Columns))
+	}
+	if stats.Columns[0].Name != "id" {
+		t.Errorf("Columns[0].Name = %q, want %q", stats.Columns[0].Name, "id")
+	}
+}
+
+func TestDataset_Write_JSONLCodec_StatsNil(t *testing.T) {
+	ds, err := NewDataset("jsonl-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []any{map[string]any{"key": "value"}}
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.Files[0].Stats != nil {
+		t.Errorf("expected nil Stats for JSONL codec, got %+v", snap.Manifest.Files[0].Stats)
+	}
+}
+
+func TestDataset_Write_RawBlob_StatsNil(t *testing.T) {
+	ds, err := NewDataset("blob-ds", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), []any{[]byte("raw data")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.Files[0].Stats != nil {
+		t.Errorf("expected nil Stats for raw blob, got %+v", snap.Manifest.Files[0].Stats)
+	}
+}
+
+func TestDataset_StreamWriteRecords_StatsNil(t *testing.T) {
+	ds, err := NewDataset("stream-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: []any{map[string]any{"key": "value"}}}
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Manifest.Files[0].Stats != nil {
+		t.Errorf("expected nil Stats for JSONL stream, got %+v", snap.Manifest.Files[0].Stats)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// FileStats serialization tests
+// -----------------------------------------------------------------------------
+
+func TestFileRef_Stats_JSONRoundTrip(t *testing.T) {
+	ref := FileRef{
+		Path:      "data/test.parquet",
+		SizeBytes: 1024,
+		Stats: &FileStats{
+			RowCount: 100,
+			Columns: []ColumnStats{
+				{Name: "id", Min: float64(1), Max: float64(100), NullCount: 0},
+				{Name: "name", Min: "alice", Max: "zara", NullCount: 5},
+			},
+		},
+	}
+
+	data, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded FileRef
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Stats == nil {
+		t.Fatal("expected Stats after round-trip, got nil")
+	}
+	if decoded.Stats.RowCount != 100 {
+		t.Errorf("RowCount = %d, want 100", decoded.Stats.RowCount)
+	}
+	if len(decoded.Stats.Columns) != 2 {
+		t.Fatalf("len(Columns) = %d, want 2", len(decoded.Stats.Columns))
+	}
+	if decoded.Stats.Columns[0].Name != "id" {
+		t.Errorf("Columns[0].Name = %q, want %q", decoded.Stats.Columns[0].Name, "id")
+	}
+	// JSON round-trips numbers as float64
+	if decoded.Stats.Columns[0].Min != float64(1) {
+		t.Errorf("Columns[0].Min = %v, want 1", decoded.Stats.Columns[0].Min)
+	}
+	if decoded.Stats.Columns[1].NullCount != 5 {
+		t.Errorf("Columns[1].NullCount = %d, want 5", decoded.Stats.Columns[1].NullCount)
+	}
+}
+
+func TestFileRef_Stats_BackwardCompat(t *testing.T) {
+	// JSON without stats field should decode cleanly
+	jsonData := `{"path":"data/test.gz","size_bytes":512}`
+
+	var ref FileRef
+	if err := json.Unmarshal([]byte(jsonData), &ref); err != nil {
+		t.Fatal(err)
+	}
+
+	if ref.Stats != nil {
+		t.Errorf("expected nil Stats for JSON without stats field, got %+v", ref.Stats)
+	}
+	if ref.Path != "data/test.gz" {
+		t.Errorf("Path = %q, want %q", ref.Path, "data/test.gz")
+	}
+}
+
+func TestFileRef_Stats_OmittedWhenNil(t *testing.T) {
+	ref := FileRef{
+		Path:      "data/test.gz",
+		SizeBytes: 256,
+	}
+
+	data, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "stats") {
+		t.Errorf("expected no stats key in JSON when Stats is nil, got: %s", data)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test helpers
+// -----------------------------------------------------------------------------
+
+// timestampedRecord implements Timestamped for testing.
+type timestampedRecord struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+}
+
+func (r *timestampedRecord) Timestamp() time.Time {
+	return r.Time
+}
+
+// testCodec is a simple codec for testing.
+type testCodec struct{}
+
+func (c *testCodec) Name() string { return "test-codec" }
+
+func (c *testCodec) Extension() string { return ".test" }
+
+func (c *testCodec) Encode(_ io.Writer, _ []any) error {
+	return nil
+}
+
+func (c *testCodec) Decode(_ io.Reader) ([]any, error) {
+	return nil, nil //nolint:nilnil // stub for testing
+}
+
+// sliceIterator implements RecordIterator for testing.
+type sliceIterator struct {
+	records []any
+	index   int
+	current any
+}
+
+func (s *sliceIterator) Next() bool {
+	if s.index >= len(s.records) {
+		return false
+	}
+	s.current = s.records[s.index]
+	s.index++
+	return true
+}
+
+func (s *sliceIterator) Record() any {
+	return s.current
+}
+
+func (s *sliceIterator) Err() error {
+	return nil
+}
+
+// errorIterator implements RecordIterator that returns an error.
+type errorIterator struct {
+	err error
+}
+
+func (e *errorIterator) Next() bool {
+	return false
+}
+
+func (e *errorIterator) Record() any {
+	return nil
+}
+
+func (e *errorIterator) Err() error {
+	return e.err
+}
+
+// -----------------------------------------------------------------------------
+// Latest pointer tests (issue #118 / #119)
+// -----------------------------------------------------------------------------
+
+func TestDataset_Write_LatestPointer_SkipsScan(t *testing.T) {
+	// After the first Write creates the pointer, subsequent writes must not
+	// call store.List to resolve the parent snapshot ID (O(1) via pointer).
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First write: no pointer yet, falls back to scan (List)
+	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterFirst := len(fs.ListCalls())
+	if listCallsAfterFirst == 0 {
+		t.Fatal("expected at least one List call on first write (no pointer)")
+	}
+
+	// Second write: pointer exists, must NOT call List
+	snap2, err := ds.Write(t.Context(), R(D{"b": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterSecond := len(fs.ListCalls())
+	if listCallsAfterSecond != listCallsAfterFirst {
+		t.Errorf("expected no new List calls on second write, got %d new calls",
+			listCallsAfterSecond-listCallsAfterFirst)
+	}
+
+	// Verify parent chain is correct
+	if snap2.Manifest.ParentSnapshotID != snap1.ID {
+		t.Errorf("expected parent %s, got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+	}
+
+	// Third write: still pointer-based
+	snap3, err := ds.Write(t.Context(), R(D{"c": 3}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterThird := len(fs.ListCalls())
+	if listCallsAfterThird != listCallsAfterFirst {
+		t.Errorf("expected no new List calls on third write, got %d new calls",
+			listCallsAfterThird-listCallsAfterFirst)
+	}
+	if snap3.Manifest.ParentSnapshotID != snap2.ID {
+		t.Errorf("expected parent %s, got %s", snap2.ID, snap3.Manifest.ParentSnapshotID)
+	}
+}
+
+func TestDataset_StreamWrite_LatestPointer_SkipsScan(t *testing.T) {
+	// StreamWrite → Commit must write the pointer so that a subsequent
+	// Write uses O(1) parent resolution.
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First write via StreamWrite
+	sw, err := ds.StreamWrite(t.Context(), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = sw.Write([]byte("payload-1"))
+	snap1, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterFirst := len(fs.ListCalls())
+
+	// Second write via regular Write: pointer exists, no List
+	snap2, err := ds.Write(t.Context(), []any{[]byte("payload-2")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterSecond := len(fs.ListCalls())
+	if listCallsAfterSecond != listCallsAfterFirst {
+		t.Errorf("expected no new List calls after StreamWrite wrote pointer, got %d new calls",
+			listCallsAfterSecond-listCallsAfterFirst)
+	}
+	if snap2.Manifest.ParentSnapshotID != snap1.ID {
+		t.Errorf("expected parent %s, got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+	}
+}
+
+func TestDataset_StreamWriteRecords_LatestPointer_SkipsScan(t *testing.T) {
+	// StreamWriteRecords must write the pointer.
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First write
+	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterFirst := len(fs.ListCalls())
+
+	// Second write via StreamWriteRecords: pointer exists
+	iter := &sliceIterator{records: R(D{"b": 2})}
+	snap2, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterSecond := len(fs.ListCalls())
+	if listCallsAfterSecond != listCallsAfterFirst {
+		t.Errorf("expected no new List calls on StreamWriteRecords, got %d new calls",
+			listCallsAfterSecond-listCallsAfterFirst)
+	}
+	if snap2.Manifest.ParentSnapshotID != snap1.ID {
+		t.Errorf("expected parent %s, got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+	}
+
+	// Third write: verify chain continues via pointer
+	snap3, err := ds.Write(t.Context(), R(D{"c": 3}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listCallsAfterThird := len(fs.ListCalls())
+	if listCallsAfterThird != listCallsAfterFirst {
+		t.Errorf("expected no new List calls on third write, got %d new calls",
+			listCallsAfterThird-listCallsAfterFirst)
+	}
+	if snap3.Manifest.ParentSnapshotID != snap2.ID {
+		t.Errorf("expected parent %s, got %s", snap2.ID, snap3.Manifest.ParentSnapshotID)
+	}
+}
+
+func TestDataset_LatestPointer_ReadAfterWrite(t *testing.T) {
+	// After a write, the pointer file must exist in the store and
+	// Latest() must resolve via pointer (no List calls).
+	store := NewMemory()
+	fs := newFaultStore(store)
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify pointer file exists in store.
+	pointerPath := "datasets/test-ds/latest"
+	rc, err := store.Get(t.Context(), pointerPath)
+	if err != nil {
+		t.Fatalf("expected pointer file at %s, got error: %v", pointerPath, err)
+	}
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(data) != string(snap.ID) {
+		t.Errorf("pointer file content: expected %q, got %q", snap.ID, string(data))
+	}
+
+	// Reset call tracking to verify Latest() uses pointer, not List.
+	fs.Reset()
+	latest, err := ds.Latest(t.Context())
+	if err != nil {
+		t.Fatalf("Latest() failed: %v", err)
+	}
+	if latest.ID != snap.ID {
+		t.Errorf("Latest() returned %s, expected %s", latest.ID, snap.ID)
+	}
+	if len(fs.ListCalls()) != 0 {
+		t.Errorf("Latest() should not call List when pointer exists, got %d List calls",
+			len(fs.ListCalls()))
+	}
+}
+
+func TestDataset_LatestPointer_UpdatesAcrossWrites(t *testing.T) {
+	// Pointer must always track the most recent snapshot.
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pointerPath := "datasets/test-ds/latest"
+
+	for i := range 3 {
+		snap, err := ds.Write(t.Context(), R(D{"i": i}), Metadata{})
+		if err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+
+		rc, err := store.Get(t.Context(), pointerPath)
+		if err != nil {
+			t.Fatalf("write %d: pointer file missing: %v", i, err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		if string(data) != string(snap.ID) {
+			t.Errorf("write %d: pointer %q, expected %q", i, string(data), snap.ID)
+		}
+	}
+}
+
+func TestDataset_LatestPointer_BackwardCompat(t *testing.T) {
+	// Pre-pointer datasets (manifests exist, no pointer) must fall back
+	// to scan and work correctly. After a write, the pointer is created.
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write a manifest directly without writing a pointer (simulates pre-pointer data).
+	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Delete the pointer to simulate pre-pointer state.
+	_ = store.Delete(t.Context(), "datasets/test-ds/latest")
+
+	// Latest() should still work via scan fallback.
+	latest, err := ds.Latest(t.Context())
+	if err != nil {
+		t.Fatalf("Latest() scan fallback failed: %v", err)
+	}
+	if latest.ID != snap1.ID {
+		t.Errorf("scan fallback returned %s, expected %s", latest.ID, snap1.ID)
+	}
+
+	// Next write should create the pointer.
+	snap2, err := ds.Write(t.Context(), R(D{"b": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := store.Get(t.Context(), "datasets/test-ds/latest")
+	if err != nil {
+		t.Fatalf("pointer should exist after write: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(data) != string(snap2.ID) {
+		t.Errorf("pointer %q, expected %q", string(data), snap2.ID)
+	}
+}
+
+func TestDataset_LatestPointer_CorruptPointer(t *testing.T) {
+	// If the pointer references a nonexistent snapshot, Latest() must
+	// fall back to scan and return the correct result.
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write a real snapshot.
+	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Overwrite the pointer with a nonexistent snapshot ID.
+	pointerPath := "datasets/test-ds/latest"
+	_ = store.Delete(t.Context(), pointerPath)
+	_ = store.Put(t.Context(), pointerPath, strings.NewReader("nonexistent-id"))
+
+	// Latest() should fall back to scan and find snap1.
+	latest, err := ds.Latest(t.Context())
+	if err != nil {
+		t.Fatalf("Latest() with corrupt pointer failed: %v", err)
+	}
+	if latest.ID != snap1.ID {
+		t.Errorf("expected %s from scan fallback, got %s", snap1.ID, latest.ID)
+	}
+}
+
+func TestDataset_LatestBy_StorageModTime(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.LatestBy(ctx, LatestByStorageModTime); !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots for empty dataset, got: %v", err)
+	}
+
+	snap1, err := ds.Write(ctx, R(D{"a": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap2, err := ds.Write(ctx, R(D{"b": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest, err := ds.LatestBy(ctx, LatestByStorageModTime); err != nil || latest.ID != snap2.ID {
+		t.Fatalf("LatestBy(mtime) = %v, %v; want %s", latest, err, snap2.ID)
+	}
+
+	// Rewriting snap1's manifest makes it the most recently written, even
+	// though its snapshot ID and CreatedAt are older.
+	paths, err := store.List(ctx, "datasets/test-ds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifestPath string
+	for _, p := range paths {
+		if strings.Contains(p, string(snap1.ID)) && strings.HasSuffix(p, "manifest.json") {
+			manifestPath = p
+		}
+	}
+	if manifestPath == "" {
+		t.Fatalf("no manifest for %s in %v", snap1.ID, paths)
+	}
+	rc, err := store.Get(ctx, manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	time.Sleep(10 * time.Millisecond)
+	if err := store.Delete(ctx, manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, manifestPath, bytes.NewReader(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := ds.LatestBy(ctx, LatestByStorageModTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != snap1.ID {
+		t.Errorf("LatestBy(mtime) = %s, want touched snapshot %s", latest.ID, snap1.ID)
+	}
+	if latest, err := ds.LatestBy(ctx, LatestByPointer); err != nil || latest.ID != snap2.ID {
+		t.Errorf("LatestBy(pointer) = %v, %v; want %s", latest, err, snap2.ID)
+	}
+
+	// Stores without modification times cannot order by them.
+	plain, err := NewDataset("test-ds", NewMemoryFactoryFrom(struct{ Store }{store}), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.LatestBy(ctx, LatestByStorageModTime); err == nil {
+		t.Error("expected error for a store without modification times")
+	}
+	if _, err := ds.LatestBy(ctx, LatestStrategy(99)); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestDataset_LatestPointer_AllLayouts(t *testing.T) {
+	// Verify pointer paths for all three layouts.
+	tests := []struct {
+		name         string
+		layout       layout
+		expectedPath string
+	}{
+		{
+			name:         "default",
+			layout:       NewDefaultLayout(),
+			expectedPath: "datasets/my-ds/latest",
+		},
+		{
+			name:         "flat",
+			layout:       NewFlatLayout(),
+			expectedPath: "my-ds/latest",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.layout.latestPointerPath("my-ds")
+			if got != tt.expectedPath {
+				t.Errorf("expected %q, got %q", tt.expectedPath, got)
+			}
+		})
+	}
+
+	// Hive layout requires NewHiveLayout with keys
+	hive, err := NewHiveLayout("day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := hive.latestPointerPath("my-ds")
+	if got != "datasets/my-ds/latest" {
+		t.Errorf("hive: expected %q, got %q", "datasets/my-ds/latest", got)
+	}
+}
+
+// TestDataset_HiveLayout_SnapshotByID_NoListCall verifies that Snapshot(ctx, id)
+// with HiveLayout does NOT require a List call. The canonical manifest is always
+// written alongside partition manifests, so a single Get resolves the snapshot.
+func TestDataset_HiveLayout_SnapshotByID_NoListCall(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	factory := newFaultStoreFactory(fs)
+
+	ds, err := NewDataset("test-ds", factory,
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := R(
+		D{"id": 1, "day": "2024-01-15"},
+		D{"id": 2, "day": "2024-01-16"},
+	)
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reset counters after the write.
+	fs.Reset()
+
+	// Snapshot by ID — should NOT trigger any List calls.
+	got, err := ds.Snapshot(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Snapshot(%s) failed: %v", snap.ID, err)
+	}
+	if got.ID != snap.ID {
+		t.Fatalf("expected snapshot ID %s, got %s", snap.ID, got.ID)
+	}
+
+	listCalls := fs.ListCalls()
+	if len(listCalls) != 0 {
+		t.Fatalf("expected 0 List calls for Snapshot(id), got %d: %v", len(listCalls), listCalls)
+	}
+}
+
+// TestDataset_HiveLayout_CanonicalManifestWritten verifies that writeManifests
+// writes the canonical manifest alongside partition manifests for HiveLayout.
+func TestDataset_HiveLayout_CanonicalManifestWritten(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	factory := newFaultStoreFactory(fs)
+
+	ds, err := NewDataset("test-ds", factory,
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := R(
+		D{"id": 1, "day": "2024-01-15"},
+		D{"id": 2, "day": "2024-01-16"},
+	)
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Canonical path must exist.
+	canonicalPath := "datasets/test-ds/segments/" + string(snap.ID) + "/manifest.json"
+	exists, err := fs.inner.Exists(t.Context(), canonicalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatalf("canonical manifest not found at %s", canonicalPath)
+	}
+
+	// Partition paths must also exist.
+	partPaths := []string{
+		"datasets/test-ds/partitions/day=2024-01-15/segments/" + string(snap.ID) + "/manifest.json",
+		"datasets/test-ds/partitions/day=2024-01-16/segments/" + string(snap.ID) + "/manifest.json",
+	}
+	for _, pp := range partPaths {
+		exists, err := fs.inner.Exists(t.Context(), pp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists {
+			t.Fatalf("partition manifest not found at %s", pp)
+		}
+	}
+}
+
+// TestDataset_HiveLayout_BackwardCompat_FallbackScan verifies that Snapshot(ctx, id)
+// falls back to findSnapshotByID when the canonical manifest is missing (pre-fix data).
+func TestDataset_HiveLayout_BackwardCompat_FallbackScan(t *testing.T) {
+	mem := NewMemory()
+	fs := newFaultStore(mem)
+	factory := newFaultStoreFactory(fs)
+
+	ds, err := NewDataset("test-ds", factory,
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := R(
+		D{"id": 1, "day": "2024-01-15"},
+	)
+
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate pre-fix data: delete the canonical manifest.
+	canonicalPath := "datasets/test-ds/segments/" + string(snap.ID) + "/manifest.json"
+	if err := mem.Delete(t.Context(), canonicalPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Snapshot should still work via findSnapshotByID fallback.
+	got, err := ds.Snapshot(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatalf("Snapshot(%s) failed after canonical delete: %v", snap.ID, err)
+	}
+	if got.ID != snap.ID {
+		t.Fatalf("expected snapshot ID %s, got %s", snap.ID, got.ID)
+	}
+}
+
+// TestDataset_Write_CorruptPointer_FallsBackToScan verifies that a corrupt
+// latest pointer (referencing a nonexistent snapshot) falls through to scan
+// in resolveParentID, preventing broken linear history.
+//
+// The store is wrapped so it does not implement ConditionalWriter: this test
+// covers the Delete+Put pointer protocol. With CAS, an out-of-band pointer
+// change is a conflict (see TestDataset_Write_ConditionalWriter_ExternalPointerChange_Conflicts).
+func TestDataset_Write_CorruptPointer_FallsBackToScan(t *testing.T) {
+	mem := NewMemory()
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(newFaultStore(mem)), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write first snapshot.
+	snap1, err := ds.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupt the pointer to reference a nonexistent snapshot.
+	pointerPath := "datasets/test-ds/latest"
+	if err := mem.Delete(t.Context(), pointerPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.Put(t.Context(), pointerPath, bytes.NewReader([]byte("nonexistent-snap-id"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second write should succeed — resolveParentID falls back to scan.
+	snap2, err := ds.Write(t.Context(), R(D{"i": 2}), Metadata{})
+	if err != nil {
+		t.Fatalf("Write with corrupt pointer should succeed: %v", err)
+	}
+
+	// Parent should be snap1 (from scan fallback), not the corrupt ID.
+	if snap2.Manifest.ParentSnapshotID != snap1.ID {
+		t.Errorf("expected parent %s (from scan), got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+	}
+}
+
+// TestDataset_Write_StaleButExistingPointer_UsesInMemoryCache verifies that
+// when the pointer references an older (but existing) snapshot — e.g., because
+// a pointer write failed — the in-memory cache prevents the stale pointer from
+// breaking linear history.
+//
+// The store is wrapped so it does not implement ConditionalWriter: this test
+// covers the Delete+Put pointer protocol.
+func TestDataset_Write_StaleButExistingPointer_UsesInMemoryCache(t *testing.T) {
+	mem := NewMemory()
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(newFaultStore(mem)), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write snap-1 and snap-2 normally.
+	snap1, err := ds.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap2, err := ds.Write(t.Context(), R(D{"i": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap2.Manifest.ParentSnapshotID != snap1.ID {
+		t.Fatalf("snap2 parent should be snap1")
+	}
+
+	// Corrupt the pointer to point back to snap-1 (which still exists).
+	// This simulates a pointer write failure after snap-2's commit.
+	pointerPath := "datasets/test-ds/latest"
+	if err := mem.Delete(t.Context(), pointerPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.Put(t.Context(), pointerPath, bytes.NewReader([]byte(snap1.ID))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write snap-3: must use snap-2 as parent (from in-memory cache),
+	// NOT snap-1 (from stale pointer).
+	snap3, err := ds.Write(t.Context(), R(D{"i": 3}), Metadata{})
+	if err != nil {
+		t.Fatalf("Write with stale pointer should succeed: %v", err)
+	}
+	if snap3.Manifest.ParentSnapshotID != snap2.ID {
+		t.Errorf("expected parent %s (from in-memory cache), got %s (stale pointer would give %s)",
+			snap2.ID, snap3.Manifest.ParentSnapshotID, snap1.ID)
+	}
+}
+
+// TestDataset_Write_ColdStart_ReadsPointerFromStore verifies that a new
+// Dataset instance (cold start with no in-memory cache) resolves the correct
+// parent by reading the persistent pointer from the store.
+func TestDataset_Write_ColdStart_ReadsPointerFromStore(t *testing.T) {
+	mem := NewMemory()
+
+	// Process A: write 3 snapshots.
+	dsA, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap1, err := dsA.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dsA.Write(t.Context(), R(D{"i": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap3, err := dsA.Write(t.Context(), R(D{"i": 3}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify pointer is on snap3 (protocol ensures this).
+	rc, err := mem.Get(t.Context(), "datasets/test-ds/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(data) != string(snap3.ID) {
+		t.Fatalf("pointer should track snap3, got %q", string(data))
+	}
+
+	// Process B: cold start (new Dataset instance, empty in-memory cache).
+	dsB, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap4, err := dsB.Write(t.Context(), R(D{"i": 4}), Metadata{})
+	if err != nil {
+		t.Fatalf("cold-start write should succeed: %v", err)
+	}
+
+	// snap4 must have snap3 as parent (pointer is correct).
+	if snap4.Manifest.ParentSnapshotID != snap3.ID {
+		t.Errorf("expected parent %s, got %s (snap1=%s)",
+			snap3.ID, snap4.Manifest.ParentSnapshotID, snap1.ID)
+	}
+}
+
+// TestDataset_Write_ColdStart_CorruptPointer_FallsBackToScan verifies that
+// a new Dataset instance (cold start) with a corrupt pointer (references
+// nonexistent snapshot) correctly falls back to scan.
+func TestDataset_Write_ColdStart_CorruptPointer_FallsBackToScan(t *testing.T) {
+	mem := NewMemory()
+
+	// Process A: write 2 snapshots.
+	dsA, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = dsA.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap2, err := dsA.Write(t.Context(), R(D{"i": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupt the pointer to reference a nonexistent snapshot.
+	pointerPath := "datasets/test-ds/latest"
+	if err := mem.Delete(t.Context(), pointerPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := mem.Put(t.Context(), pointerPath, bytes.NewReader([]byte("nonexistent-snap-id"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// Process B: cold start with corrupt pointer.
+	dsB, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap3, err := dsB.Write(t.Context(), R(D{"i": 3}), Metadata{})
+	if err != nil {
+		t.Fatalf("cold-start write with corrupt pointer should succeed: %v", err)
+	}
+
+	// snap3 must have snap2 as parent (from scan fallback).
+	if snap3.Manifest.ParentSnapshotID != snap2.ID {
+		t.Errorf("expected parent %s (from scan), got %s", snap2.ID, snap3.Manifest.ParentSnapshotID)
+	}
+}
+
+// TestDataset_Write_PointerWriteFailure_AbortsCommit verifies that when
+// writeLatestPointer fails, the commit is aborted and no manifest is written.
+// This is the core of the pointer-before-manifest protocol.
+func TestDataset_Write_PointerWriteFailure_AbortsCommit(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	factory := newFaultStoreFactory(fs)
+
+	ds, err := NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First write succeeds (creates snap-1).
+	snap1, err := ds.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject Put error only on the "latest" pointer path.
+	// writeLatestPointer does Delete + Put; the Put will fail.
+	fs.mu.Lock()
+	fs.putErr = errors.New("injected: pointer write failure")
+	fs.putErrMatch = "latest"
+	fs.mu.Unlock()
+
+	// Record Put calls before the failed write.
+	fs.Reset()
+
+	// Second write should fail because pointer write is required.
+	_, err = ds.Write(t.Context(), R(D{"i": 2}), Metadata{})
+	if err == nil {
+		t.Fatal("expected write to fail when pointer write fails")
+	}
+
+	// Verify no manifest was written (only Put calls should be for data file + pointer attempt).
+	putCalls := fs.PutCalls()
+	for _, p := range putCalls {
+		if strings.Contains(p, "manifest.json") {
+			t.Errorf("manifest should not be written when pointer fails, but saw Put(%s)", p)
+		}
+	}
+
+	// Clear the error and write again — should succeed with snap-1 as parent.
+	fs.mu.Lock()
+	fs.putErr = nil
+	fs.putErrMatch = ""
+	fs.mu.Unlock()
+
+	snap3, err := ds.Write(t.Context(), R(D{"i": 3}), Metadata{})
+	if err != nil {
+		t.Fatalf("write after clearing error should succeed: %v", err)
+	}
+	if snap3.Manifest.ParentSnapshotID != snap1.ID {
+		t.Errorf("expected parent %s, got %s", snap1.ID, snap3.Manifest.ParentSnapshotID)
 	}
 }
 
-func TestDataset_LatestPointer_ReadAfterWrite(t *testing.T) {
-	// After a write, the pointer file must exist in the store and
-	// Latest() must resolve via pointer (no List calls).
-	store := NewMemory()
-	fs := newFaultStore(store)
+func TestDataset_Write_ManifestPutFailure_DeletesDataObjects(t *testing.T) {
+	mem := NewMemory()
+	fs := newFaultStore(mem)
 	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
 		WithCodec(NewJSONLCodec()),
-	)
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	snap, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
-	if err != nil {
-		t.Fatal(err)
-	}
+	errManifest := errors.New("injected: manifest write failure")
+	fs.SetPutError(errManifest, "manifest.json")
 
-	// Verify pointer file exists in store.
-	pointerPath := "datasets/test-ds/latest"
-	rc, err := store.Get(t.Context(), pointerPath)
-	if err != nil {
-		t.Fatalf("expected pointer file at %s, got error: %v", pointerPath, err)
+	_, err = ds.Write(t.Context(), R(
+		D{"id": 1, "day": "2024-01-01"},
+		D{"id": 2, "day": "2024-01-02"},
+	), Metadata{})
+	if !errors.Is(err, errManifest) {
+		t.Fatalf("expected original error, got: %v", err)
 	}
-	data, _ := io.ReadAll(rc)
-	_ = rc.Close()
-	if string(data) != string(snap.ID) {
-		t.Errorf("pointer file content: expected %q, got %q", snap.ID, string(data))
+	if !strings.Contains(err.Error(), "cleaned up 2 partial objects") {
+		t.Errorf("expected cleanup note, got: %v", err)
 	}
 
-	// Reset call tracking to verify Latest() uses pointer, not List.
-	fs.Reset()
-	latest, err := ds.Latest(t.Context())
-	if err != nil {
-		t.Fatalf("Latest() failed: %v", err)
+	var dataPuts []string
+	for _, p := range fs.PutCalls() {
+		if strings.Contains(p, "/data/") {
+			dataPuts = append(dataPuts, p)
+		}
 	}
-	if latest.ID != snap.ID {
-		t.Errorf("Latest() returned %s, expected %s", latest.ID, snap.ID)
+	if len(dataPuts) != 2 {
+		t.Fatalf("expected 2 data Puts, got %v", dataPuts)
 	}
-	if len(fs.ListCalls()) != 0 {
-		t.Errorf("Latest() should not call List when pointer exists, got %d List calls",
-			len(fs.ListCalls()))
+	for _, p := range dataPuts {
+		if exists, _ := mem.Exists(t.Context(), p); exists {
+			t.Errorf("data object %s should have been deleted", p)
+		}
 	}
 }
 
-func TestDataset_LatestPointer_UpdatesAcrossWrites(t *testing.T) {
-	// Pointer must always track the most recent snapshot.
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
-		WithCodec(NewJSONLCodec()),
-	)
+func TestDataset_Write_CleanupFailure_ReportedWithOriginalError(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	pointerPath := "datasets/test-ds/latest"
-
-	for i := range 3 {
-		snap, err := ds.Write(t.Context(), R(D{"i": i}), Metadata{})
-		if err != nil {
-			t.Fatalf("write %d failed: %v", i, err)
-		}
+	errManifest := errors.New("injected: manifest write failure")
+	fs.SetPutError(errManifest, "manifest.json")
+	fs.SetDeleteError(errors.New("injected: delete failure"))
 
-		rc, err := store.Get(t.Context(), pointerPath)
-		if err != nil {
-			t.Fatalf("write %d: pointer file missing: %v", i, err)
-		}
-		data, _ := io.ReadAll(rc)
-		_ = rc.Close()
-		if string(data) != string(snap.ID) {
-			t.Errorf("write %d: pointer %q, expected %q", i, string(data), snap.ID)
-		}
+	_, err = ds.Write(t.Context(), R(D{"id": 1}), Metadata{})
+	if !errors.Is(err, errManifest) {
+		t.Fatalf("expected original error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "cleanup failed for 1 of 1 partial objects") {
+		t.Errorf("expected cleanup failure note, got: %v", err)
 	}
 }
 
-func TestDataset_LatestPointer_BackwardCompat(t *testing.T) {
-	// Pre-pointer datasets (manifests exist, no pointer) must fall back
-	// to scan and work correctly. After a write, the pointer is created.
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+func TestDataset_Write_StageFailure_DeletesEarlierDataObjects(t *testing.T) {
+	mem := NewMemory()
+	fs := newFaultStore(mem)
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
 		WithCodec(NewJSONLCodec()),
-	)
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write a manifest directly without writing a pointer (simulates pre-pointer data).
-	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
-	if err != nil {
-		t.Fatal(err)
-	}
+	// Fail the second partition's data file; the first must be removed.
+	errData := errors.New("injected: data write failure")
+	fs.SetPutError(errData, "day=2024-01-02")
 
-	// Delete the pointer to simulate pre-pointer state.
-	_ = store.Delete(t.Context(), "datasets/test-ds/latest")
+	_, err = ds.Write(t.Context(), R(
+		D{"id": 1, "day": "2024-01-01"},
+		D{"id": 2, "day": "2024-01-02"},
+	), Metadata{})
+	if !errors.Is(err, errData) {
+		t.Fatalf("expected original error, got: %v", err)
+	}
 
-	// Latest() should still work via scan fallback.
-	latest, err := ds.Latest(t.Context())
+	fs.SetPutError(nil)
+	paths, err := mem.List(t.Context(), "")
 	if err != nil {
-		t.Fatalf("Latest() scan fallback failed: %v", err)
+		t.Fatal(err)
 	}
-	if latest.ID != snap1.ID {
-		t.Errorf("scan fallback returned %s, expected %s", latest.ID, snap1.ID)
+	for _, p := range paths {
+		if strings.Contains(p, "/data/") {
+			t.Errorf("data object %s should have been deleted", p)
+		}
 	}
+}
 
-	// Next write should create the pointer.
-	snap2, err := ds.Write(t.Context(), R(D{"b": 2}), Metadata{})
+// -----------------------------------------------------------------------------
+// Append and optimistic concurrency
+// -----------------------------------------------------------------------------
+
+func TestDataset_Append_EmptyDataset_NoParent(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	rc, err := store.Get(t.Context(), "datasets/test-ds/latest")
+	snap, err := ds.Append(t.Context(), R(D{"i": 1}), Metadata{})
 	if err != nil {
-		t.Fatalf("pointer should exist after write: %v", err)
+		t.Fatal(err)
 	}
-	data, _ := io.ReadAll(rc)
-	_ = rc.Close()
-	if string(data) != string(snap2.ID) {
-		t.Errorf("pointer %q, expected %q", string(data), snap2.ID)
+	if snap.Manifest.ParentSnapshotID != "" {
+		t.Errorf("expected empty parent, got %s", snap.Manifest.ParentSnapshotID)
 	}
 }
 
-func TestDataset_LatestPointer_CorruptPointer(t *testing.T) {
-	// If the pointer references a nonexistent snapshot, Latest() must
-	// fall back to scan and return the correct result.
-	store := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
-		WithCodec(NewJSONLCodec()),
-	)
+func TestDataset_Append_LinksToOtherWritersSnapshot(t *testing.T) {
+	mem := NewMemory()
+	dsA, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Write a real snapshot.
-	snap1, err := ds.Write(t.Context(), R(D{"a": 1}), Metadata{})
+	dsB, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Overwrite the pointer with a nonexistent snapshot ID.
-	pointerPath := "datasets/test-ds/latest"
-	_ = store.Delete(t.Context(), pointerPath)
-	_ = store.Put(t.Context(), pointerPath, strings.NewReader("nonexistent-id"))
-
-	// Latest() should fall back to scan and find snap1.
-	latest, err := ds.Latest(t.Context())
+	snap1, err := dsA.Append(t.Context(), R(D{"i": 1}), Metadata{})
 	if err != nil {
-		t.Fatalf("Latest() with corrupt pointer failed: %v", err)
-	}
-	if latest.ID != snap1.ID {
-		t.Errorf("expected %s from scan fallback, got %s", snap1.ID, latest.ID)
-	}
-}
-
-func TestDataset_LatestPointer_AllLayouts(t *testing.T) {
-	// Verify pointer paths for all three layouts.
-	tests := []struct {
-		name         string
-		layout       layout
-		expectedPath string
-	}{
-		{
-			name:         "default",
-			layout:       NewDefaultLayout(),
-			expectedPath: "datasets/my-ds/latest",
-		},
-		{
-			name:         "flat",
-			layout:       NewFlatLayout(),
-			expectedPath: "my-ds/latest",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := tt.layout.latestPointerPath("my-ds")
-			if got != tt.expectedPath {
-				t.Errorf("expected %q, got %q", tt.expectedPath, got)
-			}
-		})
+		t.Fatal(err)
 	}
-
-	// Hive layout requires NewHiveLayout with keys
-	hive, err := NewHiveLayout("day")
+	snap2, err := dsB.Append(t.Context(), R(D{"i": 2}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	got := hive.latestPointerPath("my-ds")
-	if got != "datasets/my-ds/latest" {
-		t.Errorf("hive: expected %q, got %q", "datasets/my-ds/latest", got)
-	}
-}
-
-// TestDataset_HiveLayout_SnapshotByID_NoListCall verifies that Snapshot(ctx, id)
-// with HiveLayout does NOT require a List call. The canonical manifest is always
-// written alongside partition manifests, so a single Get resolves the snapshot.
-func TestDataset_HiveLayout_SnapshotByID_NoListCall(t *testing.T) {
-	fs := newFaultStore(NewMemory())
-	factory := newFaultStoreFactory(fs)
-
-	ds, err := NewDataset("test-ds", factory,
-		WithCodec(NewJSONLCodec()),
-		WithHiveLayout("day"))
+	// dsA's in-memory cache still holds snap1; Append must re-resolve.
+	snap3, err := dsA.Append(t.Context(), R(D{"i": 3}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records := R(
-		D{"id": 1, "day": "2024-01-15"},
-		D{"id": 2, "day": "2024-01-16"},
-	)
+	if snap2.Manifest.ParentSnapshotID != snap1.ID {
+		t.Errorf("snap2 parent: expected %s, got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+	}
+	if snap3.Manifest.ParentSnapshotID != snap2.ID {
+		t.Errorf("snap3 parent: expected %s, got %s", snap2.ID, snap3.Manifest.ParentSnapshotID)
+	}
+}
 
-	snap, err := ds.Write(t.Context(), records, Metadata{})
+func TestDataset_Write_ConditionalWriter_ExternalPointerChange_Conflicts(t *testing.T) {
+	mem := NewMemory()
+	dsA, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Reset counters after the write.
-	fs.Reset()
-
-	// Snapshot by ID — should NOT trigger any List calls.
-	got, err := ds.Snapshot(t.Context(), snap.ID)
+	dsB, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
 	if err != nil {
-		t.Fatalf("Snapshot(%s) failed: %v", snap.ID, err)
-	}
-	if got.ID != snap.ID {
-		t.Fatalf("expected snapshot ID %s, got %s", snap.ID, got.ID)
+		t.Fatal(err)
 	}
 
-	listCalls := fs.ListCalls()
-	if len(listCalls) != 0 {
-		t.Fatalf("expected 0 List calls for Snapshot(id), got %d: %v", len(listCalls), listCalls)
+	if _, err := dsA.Write(t.Context(), R(D{"i": 1}), Metadata{}); err != nil {
+		t.Fatal(err)
 	}
-}
-
-// TestDataset_HiveLayout_CanonicalManifestWritten verifies that writeManifests
-// writes the canonical manifest alongside partition manifests for HiveLayout.
-func TestDataset_HiveLayout_CanonicalManifestWritten(t *testing.T) {
-	fs := newFaultStore(NewMemory())
-	factory := newFaultStoreFactory(fs)
-
-	ds, err := NewDataset("test-ds", factory,
-		WithCodec(NewJSONLCodec()),
-		WithHiveLayout("day"))
-	if err != nil {
+	if _, err := dsB.Write(t.Context(), R(D{"i": 2}), Metadata{}); err != nil {
 		t.Fatal(err)
 	}
 
-	records := R(
-		D{"id": 1, "day": "2024-01-15"},
-		D{"id": 2, "day": "2024-01-16"},
-	)
-
-	snap, err := ds.Write(t.Context(), records, Metadata{})
-	if err != nil {
-		t.Fatal(err)
+	// dsA resolves its parent from the stale in-memory cache; the pointer
+	// swap detects dsB's commit instead of forking history.
+	_, err = dsA.Write(t.Context(), R(D{"i": 3}), Metadata{})
+	if !errors.Is(err, ErrSnapshotConflict) {
+		t.Fatalf("expected ErrSnapshotConflict, got: %v", err)
 	}
 
-	// Canonical path must exist.
-	canonicalPath := "datasets/test-ds/segments/" + string(snap.ID) + "/manifest.json"
-	exists, err := fs.inner.Exists(t.Context(), canonicalPath)
+	snaps, err := dsA.Snapshots(t.Context())
 	if err != nil {
 		t.Fatal(err)
 	}
-	if !exists {
-		t.Fatalf("canonical manifest not found at %s", canonicalPath)
+	if len(snaps) != 2 {
+		t.Errorf("conflicting write must not commit a manifest: got %d snapshots", len(snaps))
 	}
+}
 
-	// Partition paths must also exist.
-	partPaths := []string{
-		"datasets/test-ds/partitions/day=2024-01-15/segments/" + string(snap.ID) + "/manifest.json",
-		"datasets/test-ds/partitions/day=2024-01-16/segments/" + string(snap.ID) + "/manifest.json",
-	}
-	for _, pp := range partPaths {
-		exists, err := fs.inner.Exists(t.Context(), pp)
+func TestDataset_Append_Concurrent_SerializeOrConflict(t *testing.T) {
+	mem := NewMemory()
+	const writers = 8
+
+	var wg sync.WaitGroup
+	results := make([]*DatasetSnapshot, writers)
+	errs := make([]error, writers)
+	for i := range writers {
+		ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
 		if err != nil {
 			t.Fatal(err)
 		}
-		if !exists {
-			t.Fatalf("partition manifest not found at %s", pp)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = ds.Append(t.Context(), R(D{"i": i}), Metadata{})
+		}()
+	}
+	wg.Wait()
+
+	parents := make(map[DatasetSnapshotID]bool)
+	committed := 0
+	for i := range writers {
+		if errs[i] != nil {
+			if !errors.Is(errs[i], ErrSnapshotConflict) {
+				t.Errorf("writer %d: expected success or ErrSnapshotConflict, got: %v", i, errs[i])
+			}
+			continue
+		}
+		committed++
+		parent := results[i].Manifest.ParentSnapshotID
+		if parents[parent] {
+			t.Errorf("two commits share parent %q: history forked", parent)
 		}
+		parents[parent] = true
+	}
+	if committed == 0 {
+		t.Fatal("expected at least one append to commit")
 	}
 }
 
-// TestDataset_HiveLayout_BackwardCompat_FallbackScan verifies that Snapshot(ctx, id)
-// falls back to findSnapshotByID when the canonical manifest is missing (pre-fix data).
-func TestDataset_HiveLayout_BackwardCompat_FallbackScan(t *testing.T) {
-	mem := NewMemory()
-	fs := newFaultStore(mem)
-	factory := newFaultStoreFactory(fs)
+// -----------------------------------------------------------------------------
+// PlanWrite
+// -----------------------------------------------------------------------------
 
-	ds, err := NewDataset("test-ds", factory,
+func TestDataset_PlanWrite_WritesNothing(t *testing.T) {
+	layout, err := NewHiveLayout("day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithLayout(layout),
 		WithCodec(NewJSONLCodec()),
-		WithHiveLayout("day"))
+		WithCompressor(NewGzipCompressor()),
+		WithChecksum(NewMD5Checksum()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	records := R(
-		D{"id": 1, "day": "2024-01-15"},
+		D{"id": "1", "day": "2024-01-01"},
+		D{"id": "2", "day": "2024-01-02"},
+		D{"id": "3", "day": "2024-01-02"},
 	)
-
-	snap, err := ds.Write(t.Context(), records, Metadata{})
+	plan, err := ds.PlanWrite(t.Context(), records, Metadata{"source": "plan"})
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("PlanWrite failed: %v", err)
 	}
 
-	// Simulate pre-fix data: delete the canonical manifest.
-	canonicalPath := "datasets/test-ds/segments/" + string(snap.ID) + "/manifest.json"
-	if err := mem.Delete(t.Context(), canonicalPath); err != nil {
-		t.Fatal(err)
+	if calls := fs.PutCalls(); len(calls) != 0 {
+		t.Errorf("expected no Put calls, got %v", calls)
+	}
+	if calls := fs.DeleteCalls(); len(calls) != 0 {
+		t.Errorf("expected no Delete calls, got %v", calls)
+	}
+	if _, err := ds.Latest(t.Context()); !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected no snapshots after PlanWrite, got: %v", err)
 	}
 
-	// Snapshot should still work via findSnapshotByID fallback.
-	got, err := ds.Snapshot(t.Context(), snap.ID)
-	if err != nil {
-		t.Fatalf("Snapshot(%s) failed after canonical delete: %v", snap.ID, err)
+	if err := validateManifest(plan.Manifest); err != nil {
+		t.Errorf("planned manifest is invalid: %v", err)
 	}
-	if got.ID != snap.ID {
-		t.Fatalf("expected snapshot ID %s, got %s", snap.ID, got.ID)
+	if plan.Manifest.RowCount != 3 || len(plan.Manifest.Files) != 2 {
+		t.Errorf("expected 3 rows in 2 files, got %d rows in %d files", plan.Manifest.RowCount, len(plan.Manifest.Files))
+	}
+	for i, f := range plan.Manifest.Files {
+		if plan.DataPaths[i] != f.Path {
+			t.Errorf("DataPaths[%d] = %q, want %q", i, plan.DataPaths[i], f.Path)
+		}
+		if f.Checksum == "" {
+			t.Errorf("expected checksum for %s", f.Path)
+		}
+	}
+
+	canonical := layout.manifestPath("test-ds", plan.Manifest.SnapshotID)
+	if len(plan.ManifestPaths) != 3 || plan.ManifestPaths[0] != canonical {
+		t.Errorf("expected canonical path then 2 partition paths, got %v", plan.ManifestPaths)
 	}
 }
 
-// TestDataset_Write_CorruptPointer_FallsBackToScan verifies that a corrupt
-// latest pointer (referencing a nonexistent snapshot) falls through to scan
-// in resolveParentID, preventing broken linear history.
-func TestDataset_Write_CorruptPointer_FallsBackToScan(t *testing.T) {
-	mem := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+func TestDataset_PlanWrite_MatchesWrite(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithChecksum(NewMD5Checksum()))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write first snapshot.
-	snap1, err := ds.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	parent, err := ds.Write(t.Context(), R(D{"id": "0"}), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Corrupt the pointer to reference a nonexistent snapshot.
-	pointerPath := "datasets/test-ds/latest"
-	if err := mem.Delete(t.Context(), pointerPath); err != nil {
+	records := R(D{"id": "1"}, D{"id": "2"})
+	plan, err := ds.PlanWrite(t.Context(), records, Metadata{})
+	if err != nil {
 		t.Fatal(err)
 	}
-	if err := mem.Put(t.Context(), pointerPath, bytes.NewReader([]byte("nonexistent-snap-id"))); err != nil {
+
+	fs.Reset()
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Second write should succeed — resolveParentID falls back to scan.
-	snap2, err := ds.Write(t.Context(), R(D{"i": 2}), Metadata{})
-	if err != nil {
-		t.Fatalf("Write with corrupt pointer should succeed: %v", err)
+	if plan.Manifest.ParentSnapshotID != parent.ID {
+		t.Errorf("expected planned parent %s, got %s", parent.ID, plan.Manifest.ParentSnapshotID)
+	}
+	if len(plan.Manifest.Files) != len(snap.Manifest.Files) {
+		t.Fatalf("expected %d files, planned %d", len(snap.Manifest.Files), len(plan.Manifest.Files))
+	}
+	for i, want := range snap.Manifest.Files {
+		got := plan.Manifest.Files[i]
+		wantPath := strings.Replace(want.Path, string(snap.ID), string(plan.Manifest.SnapshotID), 1)
+		if got.Path != wantPath || got.SizeBytes != want.SizeBytes || got.Checksum != want.Checksum {
+			t.Errorf("file %d: planned %+v, wrote %+v", i, got, want)
+		}
 	}
 
-	// Parent should be snap1 (from scan fallback), not the corrupt ID.
-	if snap2.Manifest.ParentSnapshotID != snap1.ID {
-		t.Errorf("expected parent %s (from scan), got %s", snap1.ID, snap2.Manifest.ParentSnapshotID)
+	// Write stores exactly the planned objects, with the new snapshot ID.
+	var planned []string
+	for _, p := range append(plan.DataPaths, plan.ManifestPaths...) {
+		planned = append(planned, strings.Replace(p, string(plan.Manifest.SnapshotID), string(snap.ID), 1))
+	}
+	for _, p := range planned {
+		if !slices.Contains(fs.PutCalls(), p) {
+			t.Errorf("planned object %s was not written by Write (puts: %v)", p, fs.PutCalls())
+		}
 	}
 }
 
-// TestDataset_Write_StaleButExistingPointer_UsesInMemoryCache verifies that
-// when the pointer references an older (but existing) snapshot — e.g., because
-// a pointer write failed — the in-memory cache prevents the stale pointer from
-// breaking linear history.
-func TestDataset_Write_StaleButExistingPointer_UsesInMemoryCache(t *testing.T) {
-	mem := NewMemory()
-	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+func TestDataset_PlanWrite_StalePointer_NotRepaired(t *testing.T) {
+	store := NewMemory()
+	writer, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// Write snap-1 and snap-2 normally.
-	snap1, err := ds.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	snap, err := writer.Write(t.Context(), []any{[]byte("a")}, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	snap2, err := ds.Write(t.Context(), R(D{"i": 2}), Metadata{})
-	if err != nil {
+	if err := store.Delete(t.Context(), "datasets/test-ds/latest"); err != nil {
 		t.Fatal(err)
 	}
-	if snap2.Manifest.ParentSnapshotID != snap1.ID {
-		t.Fatalf("snap2 parent should be snap1")
-	}
 
-	// Corrupt the pointer to point back to snap-1 (which still exists).
-	// This simulates a pointer write failure after snap-2's commit.
-	pointerPath := "datasets/test-ds/latest"
-	if err := mem.Delete(t.Context(), pointerPath); err != nil {
+	fs := newFaultStore(store)
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs))
+	if err != nil {
 		t.Fatal(err)
 	}
-	if err := mem.Put(t.Context(), pointerPath, bytes.NewReader([]byte(snap1.ID))); err != nil {
+	plan, err := ds.PlanWrite(t.Context(), []any{[]byte("b")}, Metadata{})
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Write snap-3: must use snap-2 as parent (from in-memory cache),
-	// NOT snap-1 (from stale pointer).
-	snap3, err := ds.Write(t.Context(), R(D{"i": 3}), Metadata{})
-	if err != nil {
-		t.Fatalf("Write with stale pointer should succeed: %v", err)
+	if plan.Manifest.ParentSnapshotID != snap.ID {
+		t.Errorf("expected parent %s from scan, got %s", snap.ID, plan.Manifest.ParentSnapshotID)
 	}
-	if snap3.Manifest.ParentSnapshotID != snap2.ID {
-		t.Errorf("expected parent %s (from in-memory cache), got %s (stale pointer would give %s)",
-			snap2.ID, snap3.Manifest.ParentSnapshotID, snap1.ID)
+	if calls := fs.PutCalls(); len(calls) != 0 {
+		t.Errorf("expected pointer not to be repaired, got puts %v", calls)
 	}
 }
 
-// TestDataset_Write_ColdStart_ReadsPointerFromStore verifies that a new
-// Dataset instance (cold start with no in-memory cache) resolves the correct
-// parent by reading the persistent pointer from the store.
-func TestDataset_Write_ColdStart_ReadsPointerFromStore(t *testing.T) {
-	mem := NewMemory()
+func partNamer(_ string, index int) string {
+	return fmt.Sprintf("part-%05d", index+1)
+}
 
-	// Process A: write 3 snapshots.
-	dsA, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+func TestDataset_WithFileNamer_MultiFileSnapshot(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithFileNamer(partNamer))
 	if err != nil {
 		t.Fatal(err)
 	}
-	snap1, err := dsA.Write(t.Context(), R(D{"i": 1}), Metadata{})
+
+	data := make([]any, 100)
+	for i := range data {
+		data[i] = D{"id": i, "payload": strings.Repeat("x", 100)}
+	}
+	snap, err := ds.Write(t.Context(), data, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, err = dsA.Write(t.Context(), R(D{"i": 2}), Metadata{})
+	if got := path.Base(snap.Manifest.Files[0].Path); got != "part-00001.jsonl.gz" {
+		t.Errorf("Write file name = %q, want part-00001.jsonl.gz", got)
+	}
+
+	// Gzip shrinks the repetitive payload, so the target is small.
+	out, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap.ID}, CompactOptions{TargetFileBytes: 128})
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("Compact failed: %v", err)
 	}
-	snap3, err := dsA.Write(t.Context(), R(D{"i": 3}), Metadata{})
+	if len(out.Manifest.Files) < 2 {
+		t.Fatalf("expected multiple files, got %d", len(out.Manifest.Files))
+	}
+	for i, f := range out.Manifest.Files {
+		want := fmt.Sprintf("part-%05d.jsonl.gz", i+1)
+		if got := path.Base(f.Path); got != want {
+			t.Errorf("file %d name = %q, want %q", i, got, want)
+		}
+		if exists, err := store.Exists(t.Context(), f.Path); err != nil || !exists {
+			t.Errorf("manifest file %s not in storage: %v", f.Path, err)
+		}
+	}
+
+	records, err := ds.Read(t.Context(), out.ID)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(records) != 100 {
+		t.Errorf("expected 100 records, got %d", len(records))
+	}
+}
 
-	// Verify pointer is on snap3 (protocol ensures this).
-	rc, err := mem.Get(t.Context(), "datasets/test-ds/latest")
+func TestDataset_WithFileNamer_PartitionsAndBlobs(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"),
+		WithFileNamer(func(partition string, index int) string {
+			return strings.ReplaceAll(partition, "=", "-")
+		}))
 	if err != nil {
 		t.Fatal(err)
 	}
-	data, _ := io.ReadAll(rc)
-	_ = rc.Close()
-	if string(data) != string(snap3.ID) {
-		t.Fatalf("pointer should track snap3, got %q", string(data))
+	snap, err := ds.Write(t.Context(), R(D{"day": "a"}, D{"day": "b"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range snap.Manifest.Files {
+		names = append(names, path.Base(f.Path))
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"day-a.jsonl", "day-b.jsonl"}) {
+		t.Errorf("names = %v", names)
 	}
 
-	// Process B: cold start (new Dataset instance, empty in-memory cache).
-	dsB, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	blobs, err := NewDataset("blobs", NewMemoryFactory(), WithFileNamer(partNamer))
 	if err != nil {
 		t.Fatal(err)
 	}
-	snap4, err := dsB.Write(t.Context(), R(D{"i": 4}), Metadata{})
+	blobSnap, err := blobs.Write(t.Context(), []any{[]byte("x")}, Metadata{})
 	if err != nil {
-		t.Fatalf("cold-start write should succeed: %v", err)
+		t.Fatal(err)
 	}
-
-	// snap4 must have snap3 as parent (pointer is correct).
-	if snap4.Manifest.ParentSnapshotID != snap3.ID {
-		t.Errorf("expected parent %s, got %s (snap1=%s)",
-			snap3.ID, snap4.Manifest.ParentSnapshotID, snap1.ID)
+	if got := path.Base(blobSnap.Manifest.Files[0].Path); got != "part-00001" {
+		t.Errorf("blob name = %q, want part-00001", got)
 	}
 }
 
-// TestDataset_Write_ColdStart_CorruptPointer_FallsBackToScan verifies that
-// a new Dataset instance (cold start) with a corrupt pointer (references
-// nonexistent snapshot) correctly falls back to scan.
-func TestDataset_Write_ColdStart_CorruptPointer_FallsBackToScan(t *testing.T) {
-	mem := NewMemory()
+func TestDataset_WithFileNamer_InvalidNames(t *testing.T) {
+	for _, name := range []string{"", "..", "a/b"} {
+		fs := newFaultStore(NewMemory())
+		ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+			WithCodec(NewJSONLCodec()),
+			WithFileNamer(func(string, int) string { return name }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ds.Write(t.Context(), R(D{"id": 1}), Metadata{}); err == nil {
+			t.Errorf("%q: expected error for invalid name", name)
+		}
+		if calls := fs.PutCalls(); len(calls) != 0 {
+			t.Errorf("%q: expected no writes, got %v", name, calls)
+		}
+	}
 
-	// Process A: write 2 snapshots.
-	dsA, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithFileNamer(func(string, int) string { return "same" }))
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, err = dsA.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	data := make([]any, 50)
+	for i := range data {
+		data[i] = D{"id": i, "payload": strings.Repeat("x", 100)}
+	}
+	snap, err := ds.Write(t.Context(), data, Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	snap2, err := dsA.Write(t.Context(), R(D{"i": 2}), Metadata{})
+	if _, err := ds.Compact(t.Context(), []DatasetSnapshotID{snap.ID}, CompactOptions{TargetFileBytes: 512}); err == nil {
+		t.Error("expected error for duplicate names within a partition")
+	}
+
+	if _, err := NewDataset("test-ds", NewMemoryFactory(), WithFileNamer(nil)); err == nil {
+		t.Error("expected error for nil namer")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithFileNamer(partNamer)); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_Write_FileExtensions(t *testing.T) {
+	csv, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	parquet, err := NewParquetCodec(ParquetSchema{Fields: []ParquetField{{Name: "id", Type: ParquetString}}})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Corrupt the pointer to reference a nonexistent snapshot.
-	pointerPath := "datasets/test-ds/latest"
-	if err := mem.Delete(t.Context(), pointerPath); err != nil {
+	tests := []struct {
+		name       string
+		codec      Codec
+		compressor Compressor
+		want       string
+	}{
+		{"jsonl", NewJSONLCodec(), NewNoOpCompressor(), "/data.jsonl"},
+		{"jsonl gzip", NewJSONLCodec(), NewGzipCompressor(), "/data.jsonl.gz"},
+		{"csv zstd", csv, NewZstdCompressor(), "/data.csv.zst"},
+		{"parquet zstd", parquet, NewZstdCompressor(), "/data.parquet.zst"},
+		{"raw blob gzip", nil, NewGzipCompressor(), "/blob.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Option{WithCompressor(tt.compressor)}
+			data := R(D{"id": "1"})
+			if tt.codec != nil {
+				opts = append(opts, WithCodec(tt.codec))
+			} else {
+				data = []any{[]byte("blob")}
+			}
+			ds, err := NewDataset("test-ds", NewMemoryFactory(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			snap, err := ds.Write(t.Context(), data, Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := snap.Manifest.Files[0].Path; !strings.HasSuffix(got, tt.want) {
+				t.Errorf("Write path = %q, want suffix %q", got, tt.want)
+			}
+			if _, err := ds.Read(t.Context(), snap.ID); err != nil {
+				t.Errorf("Read failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestDataset_StreamWrite_FileExtensions(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()))
+	if err != nil {
 		t.Fatal(err)
 	}
-	if err := mem.Put(t.Context(), pointerPath, bytes.NewReader([]byte("nonexistent-snap-id"))); err != nil {
+	snap, err := ds.StreamWriteRecords(t.Context(), &sliceIterator{records: R(D{"id": "1"})}, Metadata{})
+	if err != nil {
 		t.Fatal(err)
 	}
+	if got := snap.Manifest.Files[0].Path; !strings.HasSuffix(got, "/data.jsonl.gz") {
+		t.Errorf("StreamWriteRecords path = %q, want suffix /data.jsonl.gz", got)
+	}
 
-	// Process B: cold start with corrupt pointer.
-	dsB, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	blobs, err := NewDataset("blobs", NewMemoryFactory(), WithCompressor(NewZstdCompressor()))
 	if err != nil {
 		t.Fatal(err)
 	}
-	snap3, err := dsB.Write(t.Context(), R(D{"i": 3}), Metadata{})
+	sw, err := blobs.StreamWrite(t.Context(), Metadata{})
 	if err != nil {
-		t.Fatalf("cold-start write with corrupt pointer should succeed: %v", err)
+		t.Fatal(err)
 	}
-
-	// snap3 must have snap2 as parent (from scan fallback).
-	if snap3.Manifest.ParentSnapshotID != snap2.ID {
-		t.Errorf("expected parent %s (from scan), got %s", snap2.ID, snap3.Manifest.ParentSnapshotID)
+	if _, err := sw.Write([]byte("payload")); err != nil {
+		t.Fatal(err)
+	}
+	blobSnap, err := sw.Commit(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := blobSnap.Manifest.Files[0].Path; !strings.HasSuffix(got, "/blob.zst") {
+		t.Errorf("StreamWrite path = %q, want suffix /blob.zst", got)
 	}
 }
 
-// TestDataset_Write_PointerWriteFailure_AbortsCommit verifies that when
-// writeLatestPointer fails, the commit is aborted and no manifest is written.
-// This is the core of the pointer-before-manifest protocol.
-func TestDataset_Write_PointerWriteFailure_AbortsCommit(t *testing.T) {
-	fs := newFaultStore(NewMemory())
-	factory := newFaultStoreFactory(fs)
+// -----------------------------------------------------------------------------
+// Snapshot accounting
+// -----------------------------------------------------------------------------
 
-	ds, err := NewDataset("test-ds", factory, WithCodec(NewJSONLCodec()))
+func TestDatasetSnapshot_Accounting_MultiPartition(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// First write succeeds (creates snap-1).
-	snap1, err := ds.Write(t.Context(), R(D{"i": 1}), Metadata{})
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": 1, "day": "2024-01-01"},
+		D{"id": 2, "day": "2024-01-01"},
+		D{"id": 3, "day": "2024-01-02"},
+		D{"id": 4, "day": "2024-01-03"},
+	), Metadata{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// Inject Put error only on the "latest" pointer path.
-	// writeLatestPointer does Delete + Put; the Put will fail.
-	fs.mu.Lock()
-	fs.putErr = errors.New("injected: pointer write failure")
-	fs.putErrMatch = "latest"
-	fs.mu.Unlock()
-
-	// Record Put calls before the failed write.
-	fs.Reset()
-
-	// Second write should fail because pointer write is required.
-	_, err = ds.Write(t.Context(), R(D{"i": 2}), Metadata{})
-	if err == nil {
-		t.Fatal("expected write to fail when pointer write fails")
+	var wantBytes int64
+	for _, f := range snap.Manifest.Files {
+		wantBytes += f.SizeBytes
 	}
+	wantCounts := map[string]int{"day=2024-01-01": 1, "day=2024-01-02": 1, "day=2024-01-03": 1}
 
-	// Verify no manifest was written (only Put calls should be for data file + pointer attempt).
-	putCalls := fs.PutCalls()
-	for _, p := range putCalls {
-		if strings.Contains(p, "manifest.json") {
-			t.Errorf("manifest should not be written when pointer fails, but saw Put(%s)", p)
+	// Snapshots returned by Write and loaded by Snapshot agree.
+	loaded, err := ds.Snapshot(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]*DatasetSnapshot{"write": snap, "load": loaded} {
+		if got := s.FileCount(); got != 3 {
+			t.Errorf("%s: FileCount = %d, want 3", name, got)
+		}
+		if got := s.TotalBytes(); got != wantBytes || got == 0 {
+			t.Errorf("%s: TotalBytes = %d, want %d", name, got, wantBytes)
+		}
+		if got := s.PartitionCounts(); !reflect.DeepEqual(got, wantCounts) {
+			t.Errorf("%s: PartitionCounts = %v, want %v", name, got, wantCounts)
 		}
 	}
+}
 
-	// Clear the error and write again — should succeed with snap-1 as parent.
-	fs.mu.Lock()
-	fs.putErr = nil
-	fs.putErrMatch = ""
-	fs.mu.Unlock()
-
-	snap3, err := ds.Write(t.Context(), R(D{"i": 3}), Metadata{})
-	if err != nil {
-		t.Fatalf("write after clearing error should succeed: %v", err)
+func TestDatasetSnapshot_Accounting_Unpartitioned(t *testing.T) {
+	snap := &DatasetSnapshot{Manifest: &Manifest{Files: []FileRef{
+		{Path: "a", SizeBytes: 10},
+		{Path: "b", SizeBytes: 5},
+	}}}
+	if snap.FileCount() != 2 || snap.TotalBytes() != 15 {
+		t.Errorf("FileCount/TotalBytes = %d/%d, want 2/15", snap.FileCount(), snap.TotalBytes())
 	}
-	if snap3.Manifest.ParentSnapshotID != snap1.ID {
-		t.Errorf("expected parent %s, got %s", snap1.ID, snap3.Manifest.ParentSnapshotID)
+	if got := snap.PartitionCounts(); !reflect.DeepEqual(got, map[string]int{"": 2}) {
+		t.Errorf("PartitionCounts = %v", got)
+	}
+
+	empty := &DatasetSnapshot{}
+	if empty.FileCount() != 0 || empty.TotalBytes() != 0 || len(empty.PartitionCounts()) != 0 {
+		t.Error("expected zero aggregates for snapshot without manifest")
 	}
 }
@@ -0,0 +1,387 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// -----------------------------------------------------------------------------
+// Listing Iterator
+// -----------------------------------------------------------------------------
+
+// listingIterator implements ObjectIterator over a materialized listing.
+// The listing is taken once, so iteration never observes later writes.
+type listingIterator struct {
+	refs    []ObjectRef
+	pos     int // index of the current ref plus one
+	current ObjectRef
+	closed  bool
+}
+
+func newListingIterator(refs []ObjectRef) *listingIterator {
+	return &listingIterator{refs: refs}
+}
+
+func (it *listingIterator) Next() bool {
+	if it.closed || it.pos >= len(it.refs) {
+		it.release()
+		return false
+	}
+	it.current = it.refs[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *listingIterator) Ref() ObjectRef {
+	return it.current
+}
+
+func (it *listingIterator) Err() error {
+	return nil
+}
+
+func (it *listingIterator) Close() error {
+	it.closed = true
+	it.release()
+	return nil
+}
+
+// release drops the listing once iteration can no longer advance.
+func (it *listingIterator) release() {
+	it.refs = nil
+	it.pos = 0
+}
+
+// statObjectIterator implements ObjectIterator over a materialized listing,
+// statting each object as it is reached to set SizeBytes. Objects deleted
+// since the listing was taken are skipped.
+type statObjectIterator struct {
+	ctx     context.Context
+	store   Store
+	refs    []ObjectRef
+	pos     int
+	current ObjectRef
+	err     error
+	closed  bool
+}
+
+func (it *statObjectIterator) Next() bool {
+	for !it.closed && it.err == nil && it.pos < len(it.refs) {
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			break
+		}
+		ref := it.refs[it.pos]
+		it.pos++
+		info, err := StatObject(it.ctx, it.store, ref.Path)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			it.err = err
+			break
+		}
+		ref.SizeBytes = info.SizeBytes
+		it.current = ref
+		return true
+	}
+	it.refs = nil
+	return false
+}
+
+func (it *statObjectIterator) Ref() ObjectRef {
+	return it.current
+}
+
+func (it *statObjectIterator) Err() error {
+	return it.err
+}
+
+func (it *statObjectIterator) Close() error {
+	it.closed = true
+	it.refs = nil
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// File Record Iterator
+// -----------------------------------------------------------------------------
+
+// skipCounter is implemented by record iterators that can drop
+// undecodable records (see WithOnDecodeError).
+type skipCounter interface {
+	SkippedCount() int
+}
+
+// fileRecordIterator implements FileRecordIterator by wrapping a record
+// iterator and the readers it consumes. Closers run in order on Close.
+type fileRecordIterator struct {
+	RecordIterator
+	closers []io.Closer
+	closed  bool
+}
+
+func newFileRecordIterator(it RecordIterator, closers ...io.Closer) *fileRecordIterator {
+	return &fileRecordIterator{RecordIterator: it, closers: closers}
+}
+
+func (it *fileRecordIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	return it.RecordIterator.Next()
+}
+
+func (it *fileRecordIterator) SkippedCount() int {
+	if sc, ok := it.RecordIterator.(skipCounter); ok {
+		return sc.SkippedCount()
+	}
+	return 0
+}
+
+func (it *fileRecordIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	var firstErr error
+	for _, c := range it.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	it.closers = nil
+	return firstErr
+}
+
+// chainedRecordIterator implements FileRecordIterator over a sequence of
+// files, opening each only when the previous one is exhausted.
+type chainedRecordIterator struct {
+	opens     []func() (FileRecordIterator, error)
+	current   FileRecordIterator
+	skipped   int // records skipped by files already closed
+	exhausted int // files read to the end without error
+	err       error
+	closed    bool
+}
+
+func newChainedRecordIterator(opens []func() (FileRecordIterator, error)) *chainedRecordIterator {
+	return &chainedRecordIterator{opens: opens}
+}
+
+func (it *chainedRecordIterator) Next() bool {
+	for !it.closed && it.err == nil {
+		if it.current != nil {
+			if it.current.Next() {
+				return true
+			}
+			it.err = it.current.Err()
+			it.skipped += it.current.SkippedCount()
+			if err := it.current.Close(); err != nil && it.err == nil {
+				it.err = err
+			}
+			if it.err == nil {
+				it.exhausted++
+			}
+			it.current = nil
+			continue
+		}
+		if len(it.opens) == 0 {
+			return false
+		}
+		open := it.opens[0]
+		it.opens = it.opens[1:]
+		it.current, it.err = open()
+	}
+	return false
+}
+
+func (it *chainedRecordIterator) Record() any {
+	if it.current == nil {
+		return nil
+	}
+	return it.current.Record()
+}
+
+func (it *chainedRecordIterator) Err() error {
+	return it.err
+}
+
+func (it *chainedRecordIterator) SkippedCount() int {
+	if it.current == nil {
+		return it.skipped
+	}
+	return it.skipped + it.current.SkippedCount()
+}
+
+func (it *chainedRecordIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.opens = nil
+	if it.current == nil {
+		return nil
+	}
+	it.skipped += it.current.SkippedCount()
+	err := it.current.Close()
+	it.current = nil
+	return err
+}
+
+// checkpointRecordIterator implements CheckpointIterator over the files of
+// one snapshot that a checkpoint has not yet completed.
+type checkpointRecordIterator struct {
+	*chainedRecordIterator
+	snapshotID DatasetSnapshotID
+	completed  []string // paths completed before this iterator started, in manifest order
+	paths      []string // paths of the chained files, in open order
+}
+
+func (it *checkpointRecordIterator) Checkpoint() ReadCheckpoint {
+	completed := make([]string, 0, len(it.completed)+it.exhausted)
+	completed = append(completed, it.completed...)
+	completed = append(completed, it.paths[:it.exhausted]...)
+	return ReadCheckpoint{SnapshotID: it.snapshotID, Completed: completed}
+}
+
+// limitRecordIterator implements FileRecordIterator by yielding at most n
+// records of another iterator.
+type limitRecordIterator struct {
+	inner     FileRecordIterator
+	remaining int
+	current   any
+	closeErr  error
+}
+
+// LimitRecords returns an iterator that yields at most n records of it.
+//
+// it is closed as soon as the n-th record is produced, so files still open
+// behind it are released without being read further; Record remains valid
+// until the next call to Next. A non-positive n yields no records. Closing
+// the returned iterator closes it.
+func LimitRecords(it FileRecordIterator, n int) FileRecordIterator {
+	l := &limitRecordIterator{inner: it, remaining: max(n, 0)}
+	if l.remaining == 0 {
+		l.closeErr = it.Close()
+	}
+	return l
+}
+
+func (it *limitRecordIterator) Next() bool {
+	it.current = nil
+	if it.remaining == 0 || !it.inner.Next() {
+		return false
+	}
+	it.current = it.inner.Record()
+	it.remaining--
+	if it.remaining == 0 {
+		it.closeErr = it.inner.Close()
+	}
+	return true
+}
+
+func (it *limitRecordIterator) Record() any {
+	return it.current
+}
+
+func (it *limitRecordIterator) Err() error {
+	if err := it.inner.Err(); err != nil {
+		return err
+	}
+	return it.closeErr
+}
+
+func (it *limitRecordIterator) SkippedCount() int {
+	return it.inner.SkippedCount()
+}
+
+func (it *limitRecordIterator) Close() error {
+	if err := it.inner.Close(); err != nil {
+		return err
+	}
+	return it.closeErr
+}
+
+// sliceRecordIterator implements RecordIterator over decoded records.
+type sliceRecordIterator struct {
+	records []any
+	pos     int
+	current any
+}
+
+func (it *sliceRecordIterator) Next() bool {
+	if it.pos >= len(it.records) {
+		return false
+	}
+	it.current = it.records[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *sliceRecordIterator) Record() any {
+	return it.current
+}
+
+func (it *sliceRecordIterator) Err() error {
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Concatenated Stream
+// -----------------------------------------------------------------------------
+
+// concatReader implements io.ReadCloser over a sequence of readers, opening
+// each only when the previous one is exhausted and closing it at its EOF.
+type concatReader struct {
+	opens   []func() (io.ReadCloser, error)
+	current io.ReadCloser
+	err     error // sticky; io.EOF once every reader is consumed
+}
+
+func newConcatReader(opens []func() (io.ReadCloser, error)) *concatReader {
+	return &concatReader{opens: opens}
+}
+
+func (r *concatReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.current == nil {
+			if len(r.opens) == 0 {
+				r.err = io.EOF
+				break
+			}
+			open := r.opens[0]
+			r.opens = r.opens[1:]
+			r.current, r.err = open()
+			continue
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			err = r.current.Close()
+			r.current = nil
+		}
+		r.err = err
+		if n > 0 {
+			return n, nil
+		}
+	}
+	return 0, r.err
+}
+
+// Close closes the open reader, if any. Readers not yet reached are never
+// opened, and later reads return fs.ErrClosed.
+func (r *concatReader) Close() error {
+	if r.err == fs.ErrClosed {
+		return nil
+	}
+	r.err = fs.ErrClosed
+	r.opens = nil
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -0,0 +1,107 @@
+package lode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// -----------------------------------------------------------------------------
+// JSON Output
+// -----------------------------------------------------------------------------
+
+// JSONOutputFormat selects how Dataset.WriteJSONTo frames records.
+type JSONOutputFormat int
+
+const (
+	// JSONOutputArray writes one JSON array: "[", the records separated by
+	// commas, then "]".
+	JSONOutputArray JSONOutputFormat = iota
+	// JSONOutputLines writes one record per line (NDJSON).
+	JSONOutputLines
+)
+
+// WriteJSONOptions configures Dataset.WriteJSONTo.
+type WriteJSONOptions struct {
+	// Format selects array or NDJSON output. Default: JSONOutputArray.
+	Format JSONOutputFormat
+}
+
+// WriteJSONTo streams the records of a snapshot to w as JSON, one record at
+// a time, in manifest file order. Each record is marshaled with
+// encoding/json and written as soon as it is decoded. When w has a Flush()
+// method, such as http.ResponseWriter, or a Flush() error method, such as
+// *bufio.Writer and *gzip.Writer, it is flushed after every write and a
+// flush error is returned.
+//
+// Output is not transactional: on a read, encode, or write error, or when
+// ctx is cancelled, w holds the records written so far and the array is
+// left unterminated.
+func (d *dataset) WriteJSONTo(ctx context.Context, id DatasetSnapshotID, w io.Writer, opts WriteJSONOptions) error {
+	if opts.Format != JSONOutputArray && opts.Format != JSONOutputLines {
+		return fmt.Errorf("lode: unknown JSON output format %d", opts.Format)
+	}
+	it, err := d.snapshotRecords(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = it.Close() }()
+
+	array := opts.Format == JSONOutputArray
+	var flush func() error
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		flush = f.Flush
+	case interface{ Flush() }:
+		flush = func() error { f.Flush(); return nil }
+	}
+	write := func(b []byte) error {
+		if _, err := w.Write(b); err != nil {
+			return fmt.Errorf("lode: failed to write JSON output: %w", err)
+		}
+		if flush != nil {
+			if err := flush(); err != nil {
+				return fmt.Errorf("lode: failed to flush JSON output: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if array {
+		if err := write([]byte("[")); err != nil {
+			return err
+		}
+	}
+	var buf []byte
+	for n := 0; it.Next(); n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := json.Marshal(it.Record())
+		if err != nil {
+			return fmt.Errorf("lode: failed to encode record %d as JSON: %w", n, err)
+		}
+		buf = buf[:0]
+		if array && n > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, data...)
+		if !array {
+			buf = append(buf, '\n')
+		}
+		if err := write(buf); err != nil {
+			return err
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if array {
+		return write([]byte("]"))
+	}
+	return nil
+}
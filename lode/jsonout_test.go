@@ -0,0 +1,118 @@
+package lode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDataset_WriteJSONTo(t *testing.T) {
+	ctx := t.Context()
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithHiveLayout("region"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(
+		D{"region": "us", "n": 1},
+		D{"region": "eu", "n": 2},
+		D{"region": "us", "n": 3},
+		D{"region": "eu", "n": 4},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Manifest.Files) != 2 {
+		t.Fatalf("expected 2 data files, got %d", len(snap.Manifest.Files))
+	}
+
+	tests := []struct {
+		format JSONOutputFormat
+		want   string
+	}{
+		{JSONOutputArray, `[{"n":2,"region":"eu"},{"n":4,"region":"eu"},{"n":1,"region":"us"},{"n":3,"region":"us"}]`},
+		{JSONOutputLines, "{\"n\":2,\"region\":\"eu\"}\n{\"n\":4,\"region\":\"eu\"}\n{\"n\":1,\"region\":\"us\"}\n{\"n\":3,\"region\":\"us\"}\n"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		if err := ds.WriteJSONTo(ctx, snap.ID, rec, WriteJSONOptions{Format: tt.format}); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.Body.String(); got != tt.want {
+			t.Errorf("format %d:\ngot  %s\nwant %s", tt.format, got, tt.want)
+		}
+		if !rec.Flushed {
+			t.Errorf("format %d: response was not flushed", tt.format)
+		}
+	}
+
+	// Flush() error writers are flushed too, and their errors returned.
+	var out bytes.Buffer
+	bw := bufio.NewWriterSize(&out, 4096)
+	if err := ds.WriteJSONTo(ctx, snap.ID, bw, WriteJSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != tests[0].want {
+		t.Errorf("bufio output:\ngot  %s\nwant %s", got, tests[0].want)
+	}
+	errFlush := errors.New("injected: flush failure")
+	if err := ds.WriteJSONTo(ctx, snap.ID, &failingFlushWriter{err: errFlush}, WriteJSONOptions{}); !errors.Is(err, errFlush) {
+		t.Errorf("expected flush error, got: %v", err)
+	}
+
+	var records []map[string]any
+	rec := httptest.NewRecorder()
+	if err := ds.WriteJSONTo(ctx, snap.ID, rec, WriteJSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil || len(records) != 4 {
+		t.Errorf("array output does not parse as 4 records: %d, %v", len(records), err)
+	}
+}
+
+// failingFlushWriter discards writes and fails every Flush.
+type failingFlushWriter struct {
+	err error
+}
+
+func (w *failingFlushWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *failingFlushWriter) Flush() error                { return w.err }
+
+func TestDataset_WriteJSONTo_EmptyAndErrors(t *testing.T) {
+	ctx := t.Context()
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty, err := ds.Write(ctx, []any{}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	if err := ds.WriteJSONTo(ctx, empty.ID, rec, WriteJSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Body.String(); got != "[]" {
+		t.Errorf("empty snapshot wrote %q, want []", got)
+	}
+
+	snap, err := ds.Write(ctx, R(D{"n": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.WriteJSONTo(ctx, snap.ID, httptest.NewRecorder(), WriteJSONOptions{Format: 7}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if err := ds.WriteJSONTo(ctx, "missing", httptest.NewRecorder(), WriteJSONOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := ds.WriteJSONTo(cancelled, snap.ID, httptest.NewRecorder(), WriteJSONOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
@@ -2,8 +2,10 @@ package lode
 
 import (
 	"errors"
+	"fmt"
 	"path"
 	"strings"
+	"time"
 )
 
 // layout is the internal interface that combines path topology with partitioning.
@@ -25,6 +27,10 @@ type layout interface {
 	dataFilePath(dataset DatasetID, segment DatasetSnapshotID, partition, filename string) string
 	latestPointerPath(dataset DatasetID) string
 
+	// withManifestName returns a copy of the layout whose manifest objects
+	// are named name instead of manifest.json.
+	withManifestName(name string) layout
+
 	// Partitioning (unified with layout)
 	partitioner() partitioner
 }
@@ -33,12 +39,124 @@ type layout interface {
 const (
 	datasetsDir   = "datasets"
 	snapshotsDir  = "snapshots"
-	manifestFile  = "manifest.json"
+	manifestFile  = "manifest.json" // default manifest object name
 	dataDir       = "data"
 	partitionsDir = "partitions"
 	segmentsDir   = "segments"
+
+	successMarkerFile = "_SUCCESS" // written beside the manifest by WithSuccessMarker
+	tagsDir           = "tags"     // holds Dataset.Tag pointers beside the latest pointer
 )
 
+// tagPath returns the key of a dataset's tag pointer. Tags live beside the
+// latest pointer, outside every layout's segment paths.
+func tagPath(l layout, dataset DatasetID, alias string) string {
+	return path.Join(path.Dir(l.latestPointerPath(dataset)), tagsDir, alias)
+}
+
+// successMarkerPath returns the _SUCCESS marker key for the segment
+// directory holding manifestPath.
+func successMarkerPath(manifestPath string) string {
+	return path.Join(path.Dir(manifestPath), successMarkerFile)
+}
+
+// splitManifestPath splits a listed key into path components for manifest
+// matching. Store.List implementations differ in slash conventions, so
+// leading and repeated slashes are dropped. A trailing slash denotes a
+// directory marker, not an object, and yields no components.
+func splitManifestPath(p string) []string {
+	if strings.HasSuffix(p, "/") {
+		return nil
+	}
+	parts := strings.Split(p, "/")
+	n := 0
+	for _, c := range parts {
+		if c != "" {
+			parts[n] = c
+			n++
+		}
+	}
+	return parts[:n]
+}
+
+// -----------------------------------------------------------------------------
+// Manifest Path Diagnostics
+// -----------------------------------------------------------------------------
+
+// ManifestPathReason explains why a path was or was not recognized as a manifest.
+type ManifestPathReason string
+
+// Manifest path reasons reported by DebugParseManifestPath.
+const (
+	// ManifestPathOK means the path is a manifest for the layout.
+	ManifestPathOK ManifestPathReason = "ok"
+	// ManifestPathTrailingSlash means the key is a directory marker.
+	ManifestPathTrailingSlash ManifestPathReason = "trailing slash"
+	// ManifestPathWrongPrefix means the path is outside the layout's manifest tree.
+	ManifestPathWrongPrefix ManifestPathReason = "wrong prefix"
+	// ManifestPathWrongDepth means the path has the wrong number of components.
+	ManifestPathWrongDepth ManifestPathReason = "wrong depth"
+	// ManifestPathWrongFilename means the final component is not the
+	// layout's manifest name (manifest.json by default).
+	ManifestPathWrongFilename ManifestPathReason = "wrong filename"
+	// ManifestPathUndiagnosed means the layout does not explain rejections.
+	ManifestPathUndiagnosed ManifestPathReason = "not diagnosable"
+)
+
+// ManifestPathDiagnosis is the result of DebugParseManifestPath.
+type ManifestPathDiagnosis struct {
+	// Path is the path as given.
+	Path string
+	// IsManifest reports whether the layout recognizes the path as a manifest.
+	IsManifest bool
+	// Reason classifies the outcome.
+	Reason ManifestPathReason
+	// Detail describes the mismatch in human-readable form. Empty when OK.
+	Detail string
+	// DatasetID and SnapshotID are the parsed identifiers when IsManifest.
+	DatasetID  DatasetID
+	SnapshotID DatasetSnapshotID
+}
+
+// manifestPathDiagnoser is implemented by layouts that can explain why a
+// path was rejected. It is optional; layouts without it report
+// ManifestPathUndiagnosed for rejected paths.
+type manifestPathDiagnoser interface {
+	diagnoseManifestPath(p string) ManifestPathDiagnosis
+}
+
+// DebugParseManifestPath explains how a layout classifies a path during
+// manifest discovery. It is a diagnostic aid for layout configuration and
+// is not used on the discovery path, which keeps its empty-string results.
+func DebugParseManifestPath(l layout, p string) ManifestPathDiagnosis {
+	if d, ok := l.(manifestPathDiagnoser); ok {
+		return d.diagnoseManifestPath(p)
+	}
+	if l.isManifest(p) {
+		return ManifestPathDiagnosis{
+			Path:       p,
+			IsManifest: true,
+			Reason:     ManifestPathOK,
+			DatasetID:  l.parseDatasetID(p),
+			SnapshotID: l.parseSegmentID(p),
+		}
+	}
+	return ManifestPathDiagnosis{Path: p, Reason: ManifestPathUndiagnosed}
+}
+
+// manifestNaming holds a layout's manifest object name; empty means
+// manifest.json.
+type manifestNaming struct {
+	manifest string
+}
+
+func (n manifestNaming) manifestName() string {
+	if n.manifest == "" {
+		return manifestFile
+	}
+	return n.manifest
+}
+
 // -----------------------------------------------------------------------------
 // Default Layout
 // -----------------------------------------------------------------------------
@@ -53,6 +171,7 @@ const (
 // This layout uses noop partitioning (flat, unpartitioned).
 type defaultLayout struct {
 	part partitioner
+	manifestNaming
 }
 
 // NewDefaultLayout creates the default novice-friendly layout.
@@ -78,7 +197,7 @@ func (l *defaultLayout) segmentsPrefixForPartition(dataset DatasetID, _ string)
 }
 
 func (l *defaultLayout) manifestPath(dataset DatasetID, segment DatasetSnapshotID) string {
-	return path.Join(datasetsDir, string(dataset), snapshotsDir, string(segment), manifestFile)
+	return path.Join(datasetsDir, string(dataset), snapshotsDir, string(segment), l.manifestName())
 }
 
 func (l *defaultLayout) manifestPathInPartition(dataset DatasetID, segment DatasetSnapshotID, _ string) string {
@@ -86,7 +205,7 @@ func (l *defaultLayout) manifestPathInPartition(dataset DatasetID, segment Datas
 }
 
 func (l *defaultLayout) isManifest(p string) bool {
-	parts := strings.Split(p, "/")
+	parts := splitManifestPath(p)
 	if len(parts) != 5 {
 		return false
 	}
@@ -94,14 +213,45 @@ func (l *defaultLayout) isManifest(p string) bool {
 		parts[1] != "" &&
 		parts[2] == snapshotsDir &&
 		parts[3] != "" &&
-		parts[4] == manifestFile
+		parts[4] == l.manifestName()
+}
+
+// diagnoseManifestPath mirrors isManifest, reporting the first check that fails.
+func (l *defaultLayout) diagnoseManifestPath(p string) ManifestPathDiagnosis {
+	d := ManifestPathDiagnosis{Path: p}
+	if strings.HasSuffix(p, "/") {
+		d.Reason = ManifestPathTrailingSlash
+		d.Detail = "key ends with '/' (directory marker)"
+		return d
+	}
+	parts := splitManifestPath(p)
+	switch {
+	case len(parts) == 0 || parts[0] != datasetsDir:
+		d.Reason = ManifestPathWrongPrefix
+		d.Detail = "expected path to start with " + datasetsDir + "/"
+	case len(parts) != 5:
+		d.Reason = ManifestPathWrongDepth
+		d.Detail = "expected " + datasetsDir + "/<dataset>/" + snapshotsDir + "/<snapshot>/" + l.manifestName()
+	case parts[2] != snapshotsDir:
+		d.Reason = ManifestPathWrongPrefix
+		d.Detail = "expected " + snapshotsDir + " directory, got " + parts[2]
+	case parts[4] != l.manifestName():
+		d.Reason = ManifestPathWrongFilename
+		d.Detail = "expected " + l.manifestName() + ", got " + parts[4]
+	default:
+		d.IsManifest = true
+		d.Reason = ManifestPathOK
+		d.DatasetID = DatasetID(parts[1])
+		d.SnapshotID = DatasetSnapshotID(parts[3])
+	}
+	return d
 }
 
 func (l *defaultLayout) parseDatasetID(manifestPath string) DatasetID {
 	if !l.isManifest(manifestPath) {
 		return ""
 	}
-	parts := strings.Split(manifestPath, "/")
+	parts := splitManifestPath(manifestPath)
 	return DatasetID(parts[1])
 }
 
@@ -109,7 +259,7 @@ func (l *defaultLayout) parseSegmentID(manifestPath string) DatasetSnapshotID {
 	if !l.isManifest(manifestPath) {
 		return ""
 	}
-	parts := strings.Split(manifestPath, "/")
+	parts := splitManifestPath(manifestPath)
 	return DatasetSnapshotID(parts[3])
 }
 
@@ -129,6 +279,12 @@ func (l *defaultLayout) latestPointerPath(dataset DatasetID) string {
 	return path.Join(datasetsDir, string(dataset), "latest")
 }
 
+func (l *defaultLayout) withManifestName(name string) layout {
+	c := *l
+	c.manifest = name
+	return &c
+}
+
 func (l *defaultLayout) partitioner() partitioner {
 	return l.part
 }
@@ -148,6 +304,7 @@ func (l *defaultLayout) partitioner() partitioner {
 // partition pruning at the storage layer.
 type hiveLayout struct {
 	part partitioner
+	manifestNaming
 }
 
 // NewHiveLayout creates a Hive (partition-first) layout with the specified partition keys.
@@ -156,6 +313,9 @@ type hiveLayout struct {
 //
 // The keys specify which record fields to use for partitioning.
 // Records must be map[string]any with the specified keys present.
+// Keys appear verbatim in paths and must not contain '=', '/', spaces, or
+// other reserved path characters. Values are percent-encoded, so values such
+// as "us/east" or "a=b" round-trip losslessly.
 //
 // Example:
 //
@@ -165,9 +325,74 @@ func NewHiveLayout(keys ...string) (layout, error) {
 	if len(keys) == 0 {
 		return nil, errors.New("NewHiveLayout requires at least one partition key; use NewDefaultLayout for unpartitioned data")
 	}
+	for _, key := range keys {
+		if !validPartitionKey(key) {
+			return nil, fmt.Errorf("NewHiveLayout: invalid partition key %q: keys must be non-empty and must not contain '=', '/', spaces, or other reserved path characters", key)
+		}
+	}
 	return &hiveLayout{part: newHivePartitioner(keys...)}, nil
 }
 
+// NewTimeRangeLayout creates a Hive (partition-first) layout that partitions
+// records into fixed-width time windows.
+//
+// The field is parsed as a time: time.Time, RFC3339 strings, and numeric Unix
+// epoch seconds are accepted. Records are written under window=<start>, where
+// start is the RFC3339 UTC start of the bucket-aligned window. Records whose
+// field is missing or unparseable go to window=__invalid__.
+//
+// Parsed times also populate manifest MinTimestamp/MaxTimestamp for records
+// that do not implement Timestamped. The manifest partitioner is "time-range".
+//
+// Example:
+//
+//	layout, err := NewTimeRangeLayout("event_time", time.Hour)
+//	// Records will be partitioned by window=2024-01-01T13:00:00Z
+func NewTimeRangeLayout(field string, bucket time.Duration) (layout, error) {
+	if field == "" {
+		return nil, errors.New("NewTimeRangeLayout requires a field name")
+	}
+	if bucket <= 0 {
+		return nil, errors.New("NewTimeRangeLayout requires a positive bucket duration")
+	}
+	return &hiveLayout{part: newTimeRangePartitioner(field, bucket)}, nil
+}
+
+// NewPartitionFuncLayout creates a Hive (partition-first) layout that routes
+// each record to the partition path returned by fn, for routing logic the
+// built-in layouts cannot express (e.g. a hash of several fields combined
+// with a lookup table).
+//
+// fn must return one or more "key=value" components joined by "/", with no
+// leading or trailing "/". Keys follow the NewHiveLayout rules; values must
+// be percent-encoded with url.PathEscape, with "=" encoded as %3D. A write
+// fails, committing nothing, if fn returns an error or an invalid path.
+// Reads parse partition values from paths, so a reader may use the same
+// layout or any Hive layout.
+//
+// name identifies the routing scheme and is recorded as the manifest
+// Partitioner. It must be non-empty and must not be a built-in partitioner
+// name ("hive", "time-range", "noop").
+//
+// Example:
+//
+//	layout, err := NewPartitionFuncLayout("tenant-shard", func(record any) (string, error) {
+//	    m := record.(map[string]any)
+//	    return fmt.Sprintf("shard=%02d", shardOf(m["tenant"], m["region"])), nil
+//	})
+func NewPartitionFuncLayout(name string, fn PartitionFunc) (layout, error) {
+	switch name {
+	case "":
+		return nil, errors.New("NewPartitionFuncLayout requires a name")
+	case "hive", "time-range", "noop":
+		return nil, fmt.Errorf("NewPartitionFuncLayout: name %q is reserved for a built-in partitioner", name)
+	}
+	if fn == nil {
+		return nil, errors.New("NewPartitionFuncLayout requires a partition function")
+	}
+	return &hiveLayout{part: newFuncPartitioner(name, fn)}, nil
+}
+
 func (l *hiveLayout) supportsDatasetEnumeration() bool { return true }
 func (l *hiveLayout) supportsPartitions() bool         { return true }
 func (l *hiveLayout) datasetsPrefix() string           { return datasetsDir + "/" }
@@ -184,29 +409,29 @@ func (l *hiveLayout) segmentsPrefixForPartition(dataset DatasetID, partition str
 }
 
 func (l *hiveLayout) manifestPath(dataset DatasetID, segment DatasetSnapshotID) string {
-	return path.Join(datasetsDir, string(dataset), segmentsDir, string(segment), manifestFile)
+	return path.Join(datasetsDir, string(dataset), segmentsDir, string(segment), l.manifestName())
 }
 
 func (l *hiveLayout) manifestPathInPartition(dataset DatasetID, segment DatasetSnapshotID, partition string) string {
 	if partition == "" {
 		return l.manifestPath(dataset, segment)
 	}
-	return path.Join(datasetsDir, string(dataset), partitionsDir, partition, segmentsDir, string(segment), manifestFile)
+	return path.Join(datasetsDir, string(dataset), partitionsDir, partition, segmentsDir, string(segment), l.manifestName())
 }
 
 func (l *hiveLayout) isManifest(p string) bool {
-	parts := strings.Split(p, "/")
+	parts := splitManifestPath(p)
 	if len(parts) < 4 {
 		return false
 	}
 	if parts[0] != datasetsDir || parts[1] == "" {
 		return false
 	}
-	if parts[len(parts)-1] != manifestFile {
+	if parts[len(parts)-1] != l.manifestName() {
 		return false
 	}
 	for i := 2; i < len(parts)-2; i++ {
-		if parts[i] == segmentsDir && parts[i+2] == manifestFile {
+		if parts[i] == segmentsDir && parts[i+2] == l.manifestName() {
 			return parts[i+1] != ""
 		}
 	}
@@ -217,7 +442,7 @@ func (l *hiveLayout) parseDatasetID(manifestPath string) DatasetID {
 	if !l.isManifest(manifestPath) {
 		return ""
 	}
-	parts := strings.Split(manifestPath, "/")
+	parts := splitManifestPath(manifestPath)
 	return DatasetID(parts[1])
 }
 
@@ -225,7 +450,7 @@ func (l *hiveLayout) parseSegmentID(manifestPath string) DatasetSnapshotID {
 	if !l.isManifest(manifestPath) {
 		return ""
 	}
-	parts := strings.Split(manifestPath, "/")
+	parts := splitManifestPath(manifestPath)
 	for i := 2; i < len(parts)-2; i++ {
 		if parts[i] == segmentsDir {
 			return DatasetSnapshotID(parts[i+1])
@@ -238,7 +463,7 @@ func (l *hiveLayout) parsePartitionFromManifest(manifestPath string) string {
 	if !l.isManifest(manifestPath) {
 		return ""
 	}
-	parts := strings.Split(manifestPath, "/")
+	parts := splitManifestPath(manifestPath)
 
 	partitionsIdx := -1
 	for i := 2; i < len(parts); i++ {
@@ -290,7 +515,27 @@ func (l *hiveLayout) extractPartitionPath(filePath string) string {
 		return ""
 	}
 
-	return strings.Join(parts[partitionsIdx+1:segmentsIdx], "/")
+	return partitionPrefix(parts[partitionsIdx+1 : segmentsIdx])
+}
+
+// partitionPrefix joins the leading key=value components of a path,
+// stopping at the first component that is not a partition.
+func partitionPrefix(components []string) string {
+	n := 0
+	for _, c := range components {
+		if !isPartitionComponent(c) {
+			break
+		}
+		n++
+	}
+	return strings.Join(components[:n], "/")
+}
+
+// isPartitionComponent reports whether c has the key=value shape with a
+// non-empty key.
+func isPartitionComponent(c string) bool {
+	key, _, ok := strings.Cut(c, "=")
+	return ok && key != ""
 }
 
 func (l *hiveLayout) dataFilePath(dataset DatasetID, segment DatasetSnapshotID, partition, filename string) string {
@@ -304,6 +549,12 @@ func (l *hiveLayout) latestPointerPath(dataset DatasetID) string {
 	return path.Join(datasetsDir, string(dataset), "latest")
 }
 
+func (l *hiveLayout) withManifestName(name string) layout {
+	c := *l
+	c.manifest = name
+	return &c
+}
+
 func (l *hiveLayout) partitioner() partitioner {
 	return l.part
 }
@@ -322,6 +573,7 @@ func (l *hiveLayout) partitioner() partitioner {
 // This layout has no partition support and no "datasets" prefix.
 type flatLayout struct {
 	part partitioner
+	manifestNaming
 }
 
 // NewFlatLayout creates a minimal flat layout for simple use cases.
@@ -347,7 +599,7 @@ func (l *flatLayout) segmentsPrefixForPartition(dataset DatasetID, _ string) str
 }
 
 func (l *flatLayout) manifestPath(dataset DatasetID, segment DatasetSnapshotID) string {
-	return path.Join(string(dataset), string(segment), manifestFile)
+	return path.Join(string(dataset), string(segment), l.manifestName())
 }
 
 func (l *flatLayout) manifestPathInPartition(dataset DatasetID, segment DatasetSnapshotID, _ string) string {
@@ -355,18 +607,18 @@ func (l *flatLayout) manifestPathInPartition(dataset DatasetID, segment DatasetS
 }
 
 func (l *flatLayout) isManifest(p string) bool {
-	parts := strings.Split(p, "/")
+	parts := splitManifestPath(p)
 	return len(parts) == 3 &&
 		parts[0] != "" &&
 		parts[1] != "" &&
-		parts[2] == manifestFile
+		parts[2] == l.manifestName()
 }
 
 func (l *flatLayout) parseDatasetID(manifestPath string) DatasetID {
 	if !l.isManifest(manifestPath) {
 		return ""
 	}
-	parts := strings.Split(manifestPath, "/")
+	parts := splitManifestPath(manifestPath)
 	return DatasetID(parts[0])
 }
 
@@ -374,7 +626,7 @@ func (l *flatLayout) parseSegmentID(manifestPath string) DatasetSnapshotID {
 	if !l.isManifest(manifestPath) {
 		return ""
 	}
-	parts := strings.Split(manifestPath, "/")
+	parts := splitManifestPath(manifestPath)
 	return DatasetSnapshotID(parts[1])
 }
 
@@ -394,6 +646,12 @@ func (l *flatLayout) latestPointerPath(dataset DatasetID) string {
 	return path.Join(string(dataset), "latest")
 }
 
+func (l *flatLayout) withManifestName(name string) layout {
+	c := *l
+	c.manifest = name
+	return &c
+}
+
 func (l *flatLayout) partitioner() partitioner {
 	return l.part
 }
@@ -0,0 +1,316 @@
+package lode
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestHiveLayout_ExtractPartitionPath(t *testing.T) {
+	l, err := NewHiveLayout("day")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		filePath string
+		want     string
+	}{
+		{
+			name:     "single partition",
+			filePath: "datasets/ds/partitions/day=2024-01-01/segments/s1/data/file.json",
+			want:     "day=2024-01-01",
+		},
+		{
+			name:     "nested partitions",
+			filePath: "datasets/ds/partitions/day=2024-01-01/region=us/segments/s1/data/file.json",
+			want:     "day=2024-01-01/region=us",
+		},
+		{
+			name:     "value containing equals",
+			filePath: "datasets/ds/partitions/q=a=b/segments/s1/data/file.json",
+			want:     "q=a=b",
+		},
+		{
+			name:     "non-partition component",
+			filePath: "datasets/ds/partitions/not-a-partition/segments/s1/data/file.json",
+			want:     "",
+		},
+		{
+			name:     "stops at first non-partition component",
+			filePath: "datasets/ds/partitions/day=2024-01-01/extra/segments/s1/data/file.json",
+			want:     "day=2024-01-01",
+		},
+		{
+			name:     "empty key",
+			filePath: "datasets/ds/partitions/=x/segments/s1/data/file.json",
+			want:     "",
+		},
+		{
+			name:     "unpartitioned",
+			filePath: "datasets/ds/segments/s1/data/file.json",
+			want:     "",
+		},
+		{
+			name:     "bare data path",
+			filePath: "data/not-a-partition/file.json",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.extractPartitionPath(tt.filePath); got != tt.want {
+				t.Errorf("extractPartitionPath(%q) = %q, want %q", tt.filePath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultLayout_ExtractPartitionPath_AlwaysEmpty(t *testing.T) {
+	l := NewDefaultLayout()
+	for _, p := range []string{
+		"data/not-a-partition/file.json",
+		"data/day=2024-01-01/extra/file.json",
+		"datasets/ds/snapshots/s1/data/file.json",
+	} {
+		if got := l.extractPartitionPath(p); got != "" {
+			t.Errorf("extractPartitionPath(%q) = %q, want empty", p, got)
+		}
+	}
+}
+
+func TestLayout_IsManifest_NormalizesSlashes(t *testing.T) {
+	hive, err := NewHiveLayout("day")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		layout layout
+		path   string
+		want   bool
+	}{
+		{"default canonical", NewDefaultLayout(), "datasets/foo/snapshots/bar/manifest.json", true},
+		{"default leading slashes", NewDefaultLayout(), "//datasets/foo/snapshots/bar/manifest.json", true},
+		{"default doubled slash", NewDefaultLayout(), "datasets//foo/snapshots/bar/manifest.json", true},
+		{"default trailing slash", NewDefaultLayout(), "datasets/foo/snapshots/bar/manifest.json/", false},
+		{"default empty segment", NewDefaultLayout(), "datasets/foo/snapshots//manifest.json", false},
+		{"hive leading slash", hive, "/datasets/foo/partitions/day=2024-01-01/segments/bar/manifest.json", true},
+		{"hive trailing slash", hive, "datasets/foo/segments/bar/manifest.json/", false},
+		{"flat leading slash", NewFlatLayout(), "/foo/bar/manifest.json", true},
+		{"flat trailing slash", NewFlatLayout(), "foo/bar/manifest.json/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.layout.isManifest(tt.path); got != tt.want {
+				t.Errorf("isManifest(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayout_ParseManifestPath_Normalized(t *testing.T) {
+	hive, err := NewHiveLayout("day")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewDefaultLayout()
+	p := "//datasets/foo/snapshots/bar/manifest.json"
+	if got := l.parseDatasetID(p); got != "foo" {
+		t.Errorf("parseDatasetID(%q) = %q, want foo", p, got)
+	}
+	if got := l.parseSegmentID(p); got != "bar" {
+		t.Errorf("parseSegmentID(%q) = %q, want bar", p, got)
+	}
+
+	p = "/datasets/foo/partitions/day=2024-01-01/segments/bar/manifest.json"
+	if got := hive.parseSegmentID(p); got != "bar" {
+		t.Errorf("parseSegmentID(%q) = %q, want bar", p, got)
+	}
+	if got := hive.parsePartitionFromManifest(p); got != "day=2024-01-01" {
+		t.Errorf("parsePartitionFromManifest(%q) = %q, want day=2024-01-01", p, got)
+	}
+}
+
+func TestDebugParseManifestPath_DefaultLayout(t *testing.T) {
+	l := NewDefaultLayout()
+
+	tests := []struct {
+		path   string
+		reason ManifestPathReason
+	}{
+		{"datasets/foo/snapshots/bar/manifest.json", ManifestPathOK},
+		{"//datasets/foo/snapshots/bar/manifest.json", ManifestPathOK},
+		{"datasets/foo/snapshots/bar/manifest.json/", ManifestPathTrailingSlash},
+		{"data/foo/snapshots/bar/manifest.json", ManifestPathWrongPrefix},
+		{"datasets/foo/segments/bar/manifest.json", ManifestPathWrongPrefix},
+		{"datasets/foo/snapshots/manifest.json", ManifestPathWrongDepth},
+		{"datasets/foo/snapshots/bar/data/manifest.json", ManifestPathWrongDepth},
+		{"datasets/foo/snapshots/bar/manifest.jsonl", ManifestPathWrongFilename},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			d := DebugParseManifestPath(l, tt.path)
+			if d.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q (detail: %s)", d.Reason, tt.reason, d.Detail)
+			}
+			// Diagnosis must agree with the discovery fast path.
+			if d.IsManifest != l.isManifest(tt.path) {
+				t.Errorf("IsManifest = %v, isManifest = %v", d.IsManifest, l.isManifest(tt.path))
+			}
+			if tt.reason != ManifestPathOK && d.Detail == "" {
+				t.Error("expected detail for rejected path")
+			}
+		})
+	}
+
+	d := DebugParseManifestPath(l, "datasets/foo/snapshots/bar/manifest.json")
+	if d.DatasetID != "foo" || d.SnapshotID != "bar" {
+		t.Errorf("unexpected parsed IDs: %q, %q", d.DatasetID, d.SnapshotID)
+	}
+}
+
+func TestDebugParseManifestPath_UndiagnosedLayout(t *testing.T) {
+	l := NewFlatLayout()
+
+	if d := DebugParseManifestPath(l, "foo/bar/manifest.json"); d.Reason != ManifestPathOK || d.SnapshotID != "bar" {
+		t.Errorf("unexpected diagnosis for valid path: %+v", d)
+	}
+	if d := DebugParseManifestPath(l, "foo/manifest.json"); d.Reason != ManifestPathUndiagnosed || d.IsManifest {
+		t.Errorf("unexpected diagnosis for rejected path: %+v", d)
+	}
+}
+
+func TestWithManifestFilename_WriterReaderRoundTrip(t *testing.T) {
+	ctx := t.Context()
+	for name, layout := range map[string]Option{
+		"default": WithLayout(NewDefaultLayout()),
+		"hive":    WithHiveLayout("day"),
+		"flat":    WithLayout(NewFlatLayout()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := NewMemory()
+			// Option order does not matter: the name applies to the final layout.
+			ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+				WithManifestFilename("meta.json"), layout, WithCodec(NewJSONLCodec()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			snap, err := ds.Write(ctx, R(D{"id": 1, "day": "a"}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			manifests, err := store.List(ctx, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range manifests {
+				if path.Base(p) == "manifest.json" {
+					t.Errorf("wrote default manifest name: %s", p)
+				}
+			}
+
+			reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), layout, WithManifestFilename("meta.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs, err := reader.ListManifests(ctx, "events", "", ManifestListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(refs) != 1 || refs[0].ID != snap.ID {
+				t.Fatalf("ListManifests = %v, want [%s]", refs, snap.ID)
+			}
+			if _, err := reader.GetManifest(ctx, "events", refs[0]); err != nil {
+				t.Errorf("GetManifest failed: %v", err)
+			}
+
+			// A fresh dataset handle reads back through the same naming.
+			cold, err := NewDataset("events", NewMemoryFactoryFrom(store),
+				layout, WithManifestFilename("meta.json"), WithCodec(NewJSONLCodec()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			records, err := cold.Read(ctx, snap.ID)
+			if err != nil || len(records) != 1 {
+				t.Errorf("Read = %v, %v; want 1 record", records, err)
+			}
+
+			// Readers expecting manifest.json do not see the dataset.
+			defaultReader, err := NewDatasetReader(NewMemoryFactoryFrom(store), layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := defaultReader.ListManifests(ctx, "events", "", ManifestListOptions{}); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound with default manifest name, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestWithManifestFilename_RejectsInvalidNames(t *testing.T) {
+	for _, name := range []string{"", ".", "..", "latest", "a/meta.json"} {
+		if _, err := NewDataset("events", NewMemoryFactory(), WithManifestFilename(name)); err == nil {
+			t.Errorf("NewDataset with manifest name %q: expected error", name)
+		}
+		if _, err := NewDatasetReader(NewMemoryFactory(), WithManifestFilename(name)); err == nil {
+			t.Errorf("NewDatasetReader with manifest name %q: expected error", name)
+		}
+	}
+}
+
+func TestLayout_FileRefPathIsFullStoreKey(t *testing.T) {
+	ctx := t.Context()
+	hive, err := NewHiveLayout("region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byRegion, err := NewPartitionFuncLayout("by-region", func(record any) (string, error) {
+		return "r=" + record.(map[string]any)["region"].(string), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		layout layout
+		prefix string
+	}{
+		{"default", NewDefaultLayout(), "datasets/events/snapshots/"},
+		{"flat", NewFlatLayout(), "events/"},
+		{"hive", hive, "datasets/events/partitions/region="},
+		{"partition func", byRegion, "datasets/events/partitions/r="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemory()
+			ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()), WithLayout(tt.layout))
+			if err != nil {
+				t.Fatal(err)
+			}
+			snap, err := ds.Write(ctx, R(D{"region": "us"}, D{"region": "eu"}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range snap.Manifest.Files {
+				if !strings.HasPrefix(f.Path, tt.prefix) {
+					t.Errorf("path %q does not start with %q", f.Path, tt.prefix)
+				}
+				// The path opens as-is, with no segment prefix joined on.
+				rc, err := store.Get(ctx, f.Path)
+				if err != nil {
+					t.Errorf("path %q is not a store key: %v", f.Path, err)
+					continue
+				}
+				_ = rc.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+// In-Process Write Locks
+// -----------------------------------------------------------------------------
+
+// Locker serializes commits to a dataset within one process.
+//
+// Lock blocks until the caller holds the lock for dataset or ctx is done,
+// and returns a function that releases it. Dataset handles sharing a Locker
+// run Write, Append, StreamWriteRecords, and Compact for the same dataset ID
+// one at a time, so they resolve the parent after the previous commit
+// instead of failing with ErrSnapshotConflict. With any Locker other than
+// NewNoOpLocker, Write and StreamWriteRecords read the parent from storage
+// rather than the handle's cached latest snapshot.
+//
+// A Locker does not coordinate processes; ConditionalWriter stores still
+// detect conflicting commits across them.
+type Locker interface {
+	Lock(ctx context.Context, dataset DatasetID) (unlock func(), err error)
+}
+
+// noopLocker implements Locker without locking.
+type noopLocker struct{}
+
+// NewNoOpLocker returns the default Locker, which never blocks.
+func NewNoOpLocker() Locker {
+	return noopLocker{}
+}
+
+func (noopLocker) Lock(context.Context, DatasetID) (func(), error) {
+	return func() {}, nil
+}
+
+// mutexLocker implements Locker with one lock per dataset ID.
+type mutexLocker struct {
+	mu    sync.Mutex
+	locks map[DatasetID]*datasetLock
+}
+
+// datasetLock is a context-aware mutex, with the number of callers holding
+// or waiting for it so unused entries can be dropped.
+type datasetLock struct {
+	held chan struct{}
+	refs int
+}
+
+// NewMutexLocker returns a Locker that holds one in-memory lock per dataset
+// ID. Share it between all dataset handles of a store; datasets with
+// different IDs never wait on each other. The lock is not reentrant.
+func NewMutexLocker() Locker {
+	return &mutexLocker{locks: make(map[DatasetID]*datasetLock)}
+}
+
+func (l *mutexLocker) Lock(ctx context.Context, dataset DatasetID) (func(), error) {
+	l.mu.Lock()
+	dl, ok := l.locks[dataset]
+	if !ok {
+		dl = &datasetLock{held: make(chan struct{}, 1)}
+		l.locks[dataset] = dl
+	}
+	dl.refs++
+	l.mu.Unlock()
+
+	select {
+	case dl.held <- struct{}{}:
+	case <-ctx.Done():
+		l.release(dataset, dl)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-dl.held
+			l.release(dataset, dl)
+		})
+	}, nil
+}
+
+// release drops a caller's reference, removing the entry once no caller
+// holds or waits for it.
+func (l *mutexLocker) release(dataset DatasetID, dl *datasetLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	dl.refs--
+	if dl.refs == 0 {
+		delete(l.locks, dataset)
+	}
+}
+
+// lockerOption implements Option for WithLocker (dataset-only).
+type lockerOption struct {
+	locker Locker
+}
+
+// WithLocker sets the in-process lock taken around each commit.
+// Default: NewNoOpLocker().
+// This option is only valid for NewDataset.
+//
+// The lock is held from parent resolution through the latest pointer
+// update of Write, Append, StreamWriteRecords, and Compact. StreamWrite is
+// not locked, because its commit happens in later caller code.
+func WithLocker(l Locker) Option {
+	return &lockerOption{locker: l}
+}
+
+func (o *lockerOption) applyDataset(cfg *datasetConfig) error {
+	if o.locker == nil {
+		return errors.New("WithLocker: locker must not be nil")
+	}
+	cfg.locker = o.locker
+	return nil
+}
+
+func (o *lockerOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithLocker: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// lock acquires the dataset's commit lock.
+func (d *dataset) lock(ctx context.Context) (func(), error) {
+	unlock, err := d.locker.Lock(ctx, d.id)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to acquire dataset lock: %w", err)
+	}
+	return unlock, nil
+}
@@ -0,0 +1,129 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithLocker_SerializesAppends(t *testing.T) {
+	ctx := t.Context()
+	// Put latency keeps unserialized appends overlapping; without
+	// ConditionalWriter they would silently share a parent.
+	store := &putLatencyStore{Store: NewMemory(), latency: time.Millisecond}
+	locker := NewMutexLocker()
+
+	const writers = 8
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := range writers {
+		// Separate handles, as separate workers would hold; only the
+		// locker is shared.
+		ds, err := NewDataset("events", func() (Store, error) { return store, nil }, WithCodec(NewJSONLCodec()), WithLocker(locker))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Go(func() {
+			_, errs[i] = ds.Append(ctx, R(D{"n": i}), Metadata{})
+		})
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("append %d: %v", i, err)
+		}
+	}
+
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store.Store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snaps, err := ds.Snapshots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != writers {
+		t.Fatalf("found %d snapshots, want %d", len(snaps), writers)
+	}
+	// Serialized appends form one linear history.
+	parents := make(map[DatasetSnapshotID]bool, writers)
+	for _, s := range snaps {
+		if parents[s.Manifest.ParentSnapshotID] {
+			t.Errorf("parent %q shared by more than one snapshot", s.Manifest.ParentSnapshotID)
+		}
+		parents[s.Manifest.ParentSnapshotID] = true
+	}
+}
+
+func TestWithLocker_WriteSeesOtherHandlesCommits(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	locker := NewMutexLocker()
+	newHandle := func() Dataset {
+		ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()), WithLocker(locker))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ds
+	}
+	a, b := newHandle(), newHandle()
+
+	// a's cached parent is stale by its second write.
+	var prev DatasetSnapshotID
+	for i, ds := range []Dataset{a, b, a} {
+		snap, err := ds.Write(ctx, R(D{"n": i}), Metadata{})
+		if err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+		if snap.Manifest.ParentSnapshotID != prev {
+			t.Errorf("write %d: parent %q, want %q", i, snap.Manifest.ParentSnapshotID, prev)
+		}
+		prev = snap.ID
+	}
+}
+
+func TestMutexLocker(t *testing.T) {
+	ctx := t.Context()
+	l := NewMutexLocker()
+
+	unlock, err := l.Lock(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Other datasets are not blocked.
+	unlockB, err := l.Lock(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockB()
+
+	// The same dataset waits until ctx is done.
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Lock(waitCtx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded while locked, got: %v", err)
+	}
+
+	unlock()
+	unlock() // releasing twice is harmless
+	unlock, err = l.Lock(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if n := len(l.(*mutexLocker).locks); n != 0 {
+		t.Errorf("%d lock entries left after release, want 0", n)
+	}
+}
+
+func TestWithLocker_InvalidConfiguration(t *testing.T) {
+	if _, err := NewDataset("events", NewMemoryFactory(), WithLocker(nil)); err == nil {
+		t.Error("expected error for nil locker")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithLocker(NewMutexLocker())); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+package lode
+
+import (
+	"cmp"
+	"container/list"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultManifestCacheEntries bounds a ManifestCache when MaxEntries is zero.
+const defaultManifestCacheEntries = 1024
+
+// defaultNotFoundTTL is the NotFoundTTL used when CacheNotFound is set and
+// NotFoundTTL is zero.
+const defaultNotFoundTTL = time.Second
+
+// -----------------------------------------------------------------------------
+// Manifest Cache
+// -----------------------------------------------------------------------------
+
+// ManifestCacheOptions configures a ManifestCache.
+type ManifestCacheOptions struct {
+	// MaxEntries is the maximum number of manifests retained. The least
+	// recently used entry is evicted when full.
+	// Zero means a default of 1024.
+	MaxEntries int
+
+	// TTL bounds how long an entry is served before it is re-fetched.
+	// Zero means entries do not expire.
+	TTL time.Duration
+
+	// CacheNotFound caches manifest lookups that fail with ErrNotFound, so
+	// polling for a snapshot that is not committed yet reaches the store at
+	// most once per NotFoundTTL. Default: false.
+	CacheNotFound bool
+
+	// NotFoundTTL bounds how long a not-found result is served; a snapshot
+	// committed meanwhile is seen once it expires. Requires CacheNotFound.
+	// Zero means a default of one second.
+	NotFoundTTL time.Duration
+}
+
+// ManifestCacheStats reports cache activity.
+type ManifestCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+
+	// NotFoundHits counts lookups answered from cached not-found results.
+	NotFoundHits uint64
+}
+
+// ManifestCache memoizes validated manifests keyed by dataset and snapshot ID.
+// An entry answers only lookups of manifest paths it was loaded from, so a
+// lookup of a partition the snapshot did not write still fails with
+// ErrNotFound.
+//
+// Committed manifests are immutable, so a cached manifest stays correct until
+// its snapshot is deleted. Lode never deletes manifests; callers that delete
+// snapshots out of band must call Invalidate or InvalidateDataset. TTL bounds
+// staleness when deletions happen in another process.
+//
+// With CacheNotFound, a lookup that failed with ErrNotFound is answered with
+// the same error until NotFoundTTL passes or the manifest is loaded by
+// another call (for example after ListManifests finds it).
+//
+// Cached manifests are shared between callers and must not be modified.
+// A ManifestCache is safe for concurrent use and may be shared by readers
+// over the same store and layout.
+type ManifestCache struct {
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // front is most recently used
+	entries map[manifestCacheKey]*list.Element
+	hits    uint64
+	misses  uint64
+
+	notFoundTTL  time.Duration // zero when not-found results are not cached
+	notFound     map[manifestCacheKey]notFoundEntry
+	notFoundHits uint64
+}
+
+type manifestCacheKey struct {
+	dataset DatasetID
+	id      DatasetSnapshotID
+}
+
+type manifestCacheEntry struct {
+	key      manifestCacheKey
+	manifest *Manifest
+	paths    map[string]bool // manifest paths confirmed to hold manifest
+	expires  time.Time       // zero when no TTL
+}
+
+// notFoundEntry is a cached not-found result for one manifest path.
+type notFoundEntry struct {
+	path    string
+	err     error
+	expires time.Time
+}
+
+// NewManifestCache creates an empty manifest cache.
+// Use with WithManifestCache to enable caching on a DatasetReader.
+func NewManifestCache(opts ManifestCacheOptions) (*ManifestCache, error) {
+	if opts.MaxEntries < 0 {
+		return nil, errors.New("lode: manifest cache MaxEntries must be non-negative")
+	}
+	if opts.TTL < 0 {
+		return nil, errors.New("lode: manifest cache TTL must be non-negative")
+	}
+	if opts.NotFoundTTL < 0 {
+		return nil, errors.New("lode: manifest cache NotFoundTTL must be non-negative")
+	}
+	if opts.NotFoundTTL > 0 && !opts.CacheNotFound {
+		return nil, errors.New("lode: manifest cache NotFoundTTL requires CacheNotFound")
+	}
+	maxEntries := opts.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = defaultManifestCacheEntries
+	}
+	var notFoundTTL time.Duration
+	if opts.CacheNotFound {
+		notFoundTTL = cmp.Or(opts.NotFoundTTL, defaultNotFoundTTL)
+	}
+	return &ManifestCache{
+		maxEntries:  maxEntries,
+		ttl:         opts.TTL,
+		now:         time.Now,
+		order:       list.New(),
+		entries:     make(map[manifestCacheKey]*list.Element),
+		notFoundTTL: notFoundTTL,
+		notFound:    make(map[manifestCacheKey]notFoundEntry),
+	}, nil
+}
+
+// Stats returns hit and miss counters and the current entry count.
+func (c *ManifestCache) Stats() ManifestCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ManifestCacheStats{
+		Hits:    c.hits,
+		Misses:  c.misses,
+		Entries: c.order.Len(),
+
+		NotFoundHits: c.notFoundHits,
+	}
+}
+
+// Invalidate removes the cached manifest or not-found result for a
+// snapshot, if any.
+func (c *ManifestCache) Invalidate(dataset DatasetID, id DatasetSnapshotID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := manifestCacheKey{dataset: dataset, id: id}
+	if el, ok := c.entries[key]; ok {
+		c.removeElement(el)
+	}
+	delete(c.notFound, key)
+}
+
+// InvalidateDataset removes all cached manifests and not-found results for
+// a dataset.
+func (c *ManifestCache) InvalidateDataset(dataset DatasetID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, el := range c.entries {
+		if key.dataset == dataset {
+			c.removeElement(el)
+		}
+	}
+	for key := range c.notFound {
+		if key.dataset == dataset {
+			delete(c.notFound, key)
+		}
+	}
+}
+
+// get returns a live cached manifest loaded from path and records a hit or
+// miss.
+func (c *ManifestCache) get(dataset DatasetID, id DatasetSnapshotID, path string) (*Manifest, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[manifestCacheKey{dataset: dataset, id: id}]
+	if ok {
+		entry := el.Value.(*manifestCacheEntry)
+		if !entry.paths[path] {
+			c.misses++
+			return nil, false
+		}
+		if entry.expires.IsZero() || c.now().Before(entry.expires) {
+			c.order.MoveToFront(el)
+			c.hits++
+			return entry.manifest, true
+		}
+		c.removeElement(el)
+	}
+	c.misses++
+	return nil, false
+}
+
+// put stores a validated manifest loaded from path, evicting the least
+// recently used entry when the cache is full.
+func (c *ManifestCache) put(dataset DatasetID, id DatasetSnapshotID, path string, m *Manifest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := manifestCacheKey{dataset: dataset, id: id}
+	delete(c.notFound, key)
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*manifestCacheEntry)
+		entry.manifest = m
+		entry.paths[path] = true
+		entry.expires = expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	entry := &manifestCacheEntry{key: key, manifest: m, paths: map[string]bool{path: true}, expires: expires}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// getNotFound returns the cached not-found error for a manifest path, or
+// nil when none is live. Results are keyed by snapshot; a lookup of another
+// path of the same snapshot (another partition) is not answered.
+func (c *ManifestCache) getNotFound(dataset DatasetID, id DatasetSnapshotID, path string) error {
+	if c.notFoundTTL == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := manifestCacheKey{dataset: dataset, id: id}
+	entry, ok := c.notFound[key]
+	if !ok || entry.path != path {
+		return nil
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.notFound, key)
+		return nil
+	}
+	c.notFoundHits++
+	return entry.err
+}
+
+// putNotFound caches a not-found result for a manifest path. When the
+// not-found results are at MaxEntries, expired ones are dropped first, then
+// an arbitrary one.
+func (c *ManifestCache) putNotFound(dataset DatasetID, id DatasetSnapshotID, path string, err error) {
+	if c.notFoundTTL == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := manifestCacheKey{dataset: dataset, id: id}
+	now := c.now()
+	if _, ok := c.notFound[key]; !ok && len(c.notFound) >= c.maxEntries {
+		for k, e := range c.notFound {
+			if !now.Before(e.expires) {
+				delete(c.notFound, k)
+			}
+		}
+		for k := range c.notFound {
+			if len(c.notFound) < c.maxEntries {
+				break
+			}
+			delete(c.notFound, k)
+		}
+	}
+	c.notFound[key] = notFoundEntry{path: path, err: err, expires: now.Add(c.notFoundTTL)}
+}
+
+func (c *ManifestCache) removeElement(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*manifestCacheEntry).key)
+}
+
+// -----------------------------------------------------------------------------
+// WithManifestCache Option
+// -----------------------------------------------------------------------------
+
+// manifestCacheOption implements Option for WithManifestCache (reader-only).
+type manifestCacheOption struct {
+	cache *ManifestCache
+}
+
+// WithManifestCache enables manifest caching on a DatasetReader.
+// Default: none (every manifest load is a store Get).
+// This option is only valid for NewDatasetReader.
+//
+// GetManifest, GetManifests, ListManifests, and ListPartitions serve
+// validated manifests from the cache and store newly loaded ones. With
+// CacheNotFound, GetManifest and GetManifestByPath also serve cached
+// ErrNotFound results.
+func WithManifestCache(c *ManifestCache) Option {
+	return &manifestCacheOption{cache: c}
+}
+
+func (o *manifestCacheOption) applyDataset(*datasetConfig) error {
+	return fmt.Errorf("WithManifestCache: %w", ErrOptionNotValidForDataset)
+}
+
+func (o *manifestCacheOption) applyReader(cfg *readerConfig) error {
+	if o.cache == nil {
+		return errors.New("WithManifestCache: cache must not be nil")
+	}
+	cfg.manifestCache = o.cache
+	return nil
+}
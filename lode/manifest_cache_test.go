@@ -0,0 +1,352 @@
+package lode
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeCacheTestSnapshots writes n snapshots to a dataset over fs and
+// returns their refs.
+func writeCacheTestSnapshots(t *testing.T, fs *faultStore, n int) []ManifestRef {
+	t.Helper()
+	ds, err := NewDataset("test-ds", newFaultStoreFactory(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := make([]ManifestRef, 0, n)
+	for range n {
+		snap, err := ds.Write(t.Context(), []any{[]byte("x")}, Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, ManifestRef{ID: snap.ID})
+	}
+	return refs
+}
+
+func TestManifestCache_GetManifest_HitSkipsStore(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	refs := writeCacheTestSnapshots(t, fs, 1)
+
+	cache, err := NewManifestCache(ManifestCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs.Reset()
+	for range 3 {
+		if _, err := reader.GetManifest(t.Context(), "test-ds", refs[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := len(fs.GetCalls()); got != 1 {
+		t.Errorf("expected 1 store Get, got %d", got)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestManifestCache_SharedAcrossListAndGet(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	writeCacheTestSnapshots(t, fs, 3)
+
+	cache, err := NewManifestCache(ManifestCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := reader.ListManifests(t.Context(), "test-ds", "", ManifestListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs.Reset()
+	if _, err := reader.GetManifests(t.Context(), "test-ds", refs, ManifestGetOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(fs.GetCalls()); got != 0 {
+		t.Errorf("expected manifests validated by ListManifests to be cached, got %d Gets", got)
+	}
+}
+
+func TestManifestCache_PartitionLookupsMatchUncached(t *testing.T) {
+	mem := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"day": "1"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewManifestCache(ManifestCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(mem), WithHiveLayout("day"), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.GetManifest(t.Context(), "test-ds", ManifestRef{ID: snap.ID, Partition: "day=1"}); err != nil {
+		t.Fatal(err)
+	}
+	// The snapshot wrote no day=2 manifest; a warm cache must not say otherwise.
+	if _, err := reader.GetManifest(t.Context(), "test-ds", ManifestRef{ID: snap.ID, Partition: "day=2"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unwritten partition, got: %v", err)
+	}
+	if _, err := reader.GetManifest(t.Context(), "test-ds", ManifestRef{ID: snap.ID, Partition: "day=1"}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Entries != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestManifestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	refs := writeCacheTestSnapshots(t, fs, 3)
+
+	cache, err := NewManifestCache(ManifestCacheOptions{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(ref ManifestRef) {
+		t.Helper()
+		if _, err := reader.GetManifest(t.Context(), "test-ds", ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get(refs[0])
+	get(refs[1])
+	get(refs[0]) // refs[1] is now least recently used
+	get(refs[2]) // evicts refs[1]
+
+	fs.Reset()
+	get(refs[0])
+	if got := len(fs.GetCalls()); got != 0 {
+		t.Errorf("expected refs[0] to be cached, got %d Gets", got)
+	}
+	get(refs[1])
+	if got := len(fs.GetCalls()); got != 1 {
+		t.Errorf("expected refs[1] to be evicted, got %d Gets", got)
+	}
+	if got := cache.Stats().Entries; got != 2 {
+		t.Errorf("expected 2 entries, got %d", got)
+	}
+}
+
+func TestManifestCache_TTLExpires(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	refs := writeCacheTestSnapshots(t, fs, 1)
+
+	cache, err := NewManifestCache(ManifestCacheOptions{TTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs.Reset()
+	for _, advance := range []time.Duration{0, 30 * time.Second, time.Minute} {
+		now = now.Add(advance)
+		if _, err := reader.GetManifest(t.Context(), "test-ds", refs[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Initial load, hit at +30s, re-fetch at +90s.
+	if got := len(fs.GetCalls()); got != 2 {
+		t.Errorf("expected 2 store Gets, got %d", got)
+	}
+}
+
+func TestManifestCache_InvalidateAfterDeletion(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	refs := writeCacheTestSnapshots(t, fs, 2)
+
+	cache, err := NewManifestCache(ManifestCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range refs {
+		if _, err := reader.GetManifest(t.Context(), "test-ds", ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := "datasets/test-ds/snapshots/" + string(refs[0].ID) + "/manifest.json"
+	if err := fs.Delete(t.Context(), path); err != nil {
+		t.Fatal(err)
+	}
+	cache.Invalidate("test-ds", refs[0].ID)
+
+	if _, err := reader.GetManifest(t.Context(), "test-ds", refs[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after invalidation, got: %v", err)
+	}
+
+	cache.InvalidateDataset("test-ds")
+	if got := cache.Stats().Entries; got != 0 {
+		t.Errorf("expected empty cache, got %d entries", got)
+	}
+}
+
+func TestManifestCache_ConcurrentAccess(t *testing.T) {
+	fs := newFaultStore(NewMemory())
+	refs := writeCacheTestSnapshots(t, fs, 4)
+
+	cache, err := NewManifestCache(ManifestCacheOptions{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 20 {
+				ref := refs[(i+j)%len(refs)]
+				if _, err := reader.GetManifest(t.Context(), "test-ds", ref); err != nil {
+					t.Error(err)
+					return
+				}
+				if j%7 == 0 {
+					cache.Invalidate("test-ds", ref.ID)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	if stats.Hits+stats.Misses != 16*20 {
+		t.Errorf("expected %d lookups, got %d", 16*20, stats.Hits+stats.Misses)
+	}
+}
+
+func TestManifestCache_InvalidOptions(t *testing.T) {
+	if _, err := NewManifestCache(ManifestCacheOptions{MaxEntries: -1}); err == nil {
+		t.Error("expected error for negative MaxEntries")
+	}
+	if _, err := NewManifestCache(ManifestCacheOptions{TTL: -time.Second}); err == nil {
+		t.Error("expected error for negative TTL")
+	}
+	if _, err := NewManifestCache(ManifestCacheOptions{CacheNotFound: true, NotFoundTTL: -time.Second}); err == nil {
+		t.Error("expected error for negative NotFoundTTL")
+	}
+	if _, err := NewManifestCache(ManifestCacheOptions{NotFoundTTL: time.Second}); err == nil {
+		t.Error("expected error for NotFoundTTL without CacheNotFound")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithManifestCache(nil)); err == nil {
+		t.Error("expected error for nil cache")
+	}
+
+	cache, err := NewManifestCache(ManifestCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewDataset("test-ds", NewMemoryFactory(), WithManifestCache(cache))
+	if !errors.Is(err, ErrOptionNotValidForDataset) {
+		t.Errorf("expected ErrOptionNotValidForDataset, got: %v", err)
+	}
+}
+
+func TestManifestCache_CacheNotFound(t *testing.T) {
+	ctx := t.Context()
+	fs := newFaultStore(NewMemory())
+	cache, err := NewManifestCache(ManifestCacheOptions{CacheNotFound: true, NotFoundTTL: 500 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(id DatasetSnapshotID) {
+		writeManifest(ctx, t, fs, &Manifest{
+			SchemaName:    manifestSchemaName,
+			FormatVersion: manifestFormatVersion,
+			DatasetID:     "test-ds",
+			SnapshotID:    id,
+			CreatedAt:     now,
+			Metadata:      Metadata{},
+			Files:         []FileRef{},
+			Compressor:    "noop",
+			Partitioner:   "noop",
+		})
+	}
+	next := ManifestRef{ID: "snap-next"}
+
+	// Poll every 100ms for a second: the store is reached once per TTL.
+	for i := range 10 {
+		if i > 0 {
+			now = now.Add(100 * time.Millisecond)
+		}
+		if _, err := reader.GetManifest(ctx, "test-ds", next); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("poll %d: expected ErrNotFound, got: %v", i, err)
+		}
+	}
+	if got := len(fs.GetCalls()); got != 2 {
+		t.Errorf("expected 2 store Gets for 10 polls, got %d", got)
+	}
+	if stats := cache.Stats(); stats.NotFoundHits != 8 || stats.Misses != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// A snapshot committed within the TTL is seen once it expires.
+	commit(next.ID)
+	if _, err := reader.GetManifest(ctx, "test-ds", next); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected cached ErrNotFound within TTL, got: %v", err)
+	}
+	now = now.Add(100 * time.Millisecond)
+	if _, err := reader.GetManifest(ctx, "test-ds", next); err != nil {
+		t.Errorf("expected snapshot after TTL, got: %v", err)
+	}
+
+	// Loading the manifest by another call drops the not-found result at once.
+	late := ManifestRef{ID: "snap-late"}
+	if _, err := reader.GetManifest(ctx, "test-ds", late); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+	commit(late.ID)
+	if _, err := reader.ListManifests(ctx, "test-ds", "", ManifestListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.GetManifest(ctx, "test-ds", late); err != nil {
+		t.Errorf("expected snapshot after ListManifests loaded it, got: %v", err)
+	}
+}
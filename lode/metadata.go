@@ -0,0 +1,131 @@
+package lode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Metadata Accessors
+// -----------------------------------------------------------------------------
+
+// Metadata values pass through JSON when a manifest is stored, so a value
+// read back from a snapshot may not have the type it was written with:
+// integers become float64 and times become RFC3339 strings. The accessors
+// below accept both forms so callers parse common fields consistently.
+
+// GetString returns the string value stored under key. ok is false when
+// the key is missing or its value is not a string.
+func (m Metadata) GetString(key string) (string, bool) {
+	s, ok := m[key].(string)
+	return s, ok
+}
+
+// GetInt returns the integer value stored under key. It accepts Go integer
+// types, integral float64 values (as decoded from JSON), json.Number, and
+// base-10 integer strings. ok is false when the key is missing or the value
+// is not an integer that fits in int64.
+func (m Metadata) GetInt(key string) (int64, bool) {
+	switch v := m[key].(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return uintToInt64(uint64(v))
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return uintToInt64(v)
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		return floatToInt64(v)
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// GetTime returns the time stored under key, in UTC. It accepts time.Time,
+// RFC3339 strings, and numeric Unix epoch seconds, like WithTimestampField.
+// ok is false when the key is missing or the value cannot be parsed.
+func (m Metadata) GetTime(key string) (time.Time, bool) {
+	return parseRecordTime(m[key])
+}
+
+// Set stores value under key after checking that it can be encoded as JSON,
+// which every manifest write requires. m must not be nil.
+func (m Metadata) Set(key string, value any) error {
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Errorf("lode: metadata %q: value is not JSON-serializable: %w", key, err)
+	}
+	m[key] = value
+	return nil
+}
+
+// SetString stores s under key. m must not be nil.
+func (m Metadata) SetString(key, s string) {
+	m[key] = s
+}
+
+// SetInt stores n under key. m must not be nil. Stored manifests carry
+// numbers as JSON, so integers beyond ±2^53 lose precision once read back;
+// store such values with SetString.
+func (m Metadata) SetInt(key string, n int64) {
+	m[key] = n
+}
+
+// SetTime stores t under key as a UTC RFC3339 string, the form it takes in
+// a stored manifest. m must not be nil.
+func (m Metadata) SetTime(key string, t time.Time) {
+	m[key] = t.UTC().Format(time.RFC3339Nano)
+}
+
+// Merge returns a new Metadata holding the entries of m and other. Entries
+// of other take precedence on conflicting keys. Neither input is modified;
+// values are copied shallowly.
+func (m Metadata) Merge(other Metadata) Metadata {
+	merged := make(Metadata, len(m)+len(other))
+	for k, v := range m {
+		merged[k] = v
+	}
+	for k, v := range other {
+		merged[k] = v
+	}
+	return merged
+}
+
+// floatToInt64 converts an integral float within int64 range.
+func floatToInt64(f float64) (int64, bool) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// uintToInt64 converts an unsigned integer within int64 range.
+func uintToInt64(u uint64) (int64, bool) {
+	if u > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(u), true
+}
@@ -0,0 +1,122 @@
+package lode
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMetadata_GetInt_Coercion(t *testing.T) {
+	m := Metadata{
+		"int":      42,
+		"int64":    int64(-7),
+		"uint64":   uint64(9),
+		"float":    float64(1e6),
+		"number":   json.Number("123"),
+		"string":   "456",
+		"fraction": 1.5,
+		"huge":     uint64(math.MaxUint64),
+		"inf":      math.Inf(1),
+		"word":     "many",
+		"bool":     true,
+	}
+	for key, want := range map[string]int64{"int": 42, "int64": -7, "uint64": 9, "float": 1e6, "number": 123, "string": 456} {
+		if got, ok := m.GetInt(key); !ok || got != want {
+			t.Errorf("GetInt(%q) = %d, %v; want %d, true", key, got, ok, want)
+		}
+	}
+	for _, key := range []string{"fraction", "huge", "inf", "word", "bool", "missing"} {
+		if got, ok := m.GetInt(key); ok {
+			t.Errorf("GetInt(%q) = %d, true; want false", key, got)
+		}
+	}
+}
+
+func TestMetadata_GetStringAndTime(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("X", 3600))
+	m := Metadata{"source": "crawler", "count": 3, "at": ts, "epoch": float64(1700000000)}
+
+	if s, ok := m.GetString("source"); !ok || s != "crawler" {
+		t.Errorf("GetString(source) = %q, %v", s, ok)
+	}
+	if _, ok := m.GetString("count"); ok {
+		t.Error("GetString(count) should not stringify numbers")
+	}
+	if _, ok := m.GetString("missing"); ok {
+		t.Error("GetString(missing) should report false")
+	}
+
+	if got, ok := m.GetTime("at"); !ok || !got.Equal(ts) || got.Location() != time.UTC {
+		t.Errorf("GetTime(at) = %v, %v", got, ok)
+	}
+	if got, ok := m.GetTime("epoch"); !ok || got.Unix() != 1700000000 {
+		t.Errorf("GetTime(epoch) = %v, %v", got, ok)
+	}
+	if _, ok := m.GetTime("source"); ok {
+		t.Error("GetTime(source) should report false for a non-time string")
+	}
+}
+
+func TestMetadata_SettersRoundTripThroughManifest(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 500, time.UTC)
+	m := Metadata{}
+	m.SetString("source", "crawler")
+	m.SetInt("rows", 1234)
+	m.SetTime("run_at", ts)
+	if err := m.Set("tags", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set("bad", make(chan int)); err == nil {
+		t.Error("expected error for a non-JSON value")
+	}
+	if _, ok := m["bad"]; ok {
+		t.Error("rejected value must not be stored")
+	}
+
+	snap, err := ds.Write(t.Context(), R(D{"id": 1}), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(ds.(*dataset).store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := reader.GetManifest(t.Context(), "test-ds", ManifestRef{ID: snap.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := stored.Metadata
+	if s, ok := got.GetString("source"); !ok || s != "crawler" {
+		t.Errorf("source = %q, %v", s, ok)
+	}
+	if n, ok := got.GetInt("rows"); !ok || n != 1234 {
+		t.Errorf("rows = %d, %v (stored as %T)", n, ok, got["rows"])
+	}
+	if at, ok := got.GetTime("run_at"); !ok || !at.Equal(ts) {
+		t.Errorf("run_at = %v, %v", at, ok)
+	}
+}
+
+func TestMetadata_Merge(t *testing.T) {
+	base := Metadata{"source": "a", "rows": 1}
+	override := Metadata{"rows": 2, "env": "prod"}
+
+	merged := base.Merge(override)
+	if len(merged) != 3 || merged["source"] != "a" || merged["rows"] != 2 || merged["env"] != "prod" {
+		t.Errorf("unexpected merge result: %v", merged)
+	}
+	if base["rows"] != 1 || len(base) != 2 || len(override) != 2 {
+		t.Error("Merge must not modify its inputs")
+	}
+
+	var empty Metadata
+	if got := empty.Merge(nil); got == nil || len(got) != 0 {
+		t.Errorf("merging nil metadata = %v, want empty non-nil", got)
+	}
+}
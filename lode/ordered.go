@@ -0,0 +1,222 @@
+package lode
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// WithOrdering Option
+// -----------------------------------------------------------------------------
+
+// orderingOption implements Option for WithOrdering (dataset-only).
+type orderingOption struct {
+	key string
+}
+
+// WithOrdering declares that snapshots written by Write and Append are
+// ordered, recording Manifest.Ordered and Manifest.OrderKey so that
+// Dataset.ReadOrdered can serve them in order.
+// Default: snapshots are unordered.
+// This option is only valid for NewDataset and requires a codec.
+//
+// Records are written as given. With a non-empty key, ReadOrdered stably
+// sorts the snapshot's records by the key field when reading. With an
+// empty key, the snapshot is ordered by partition: files in path order,
+// records in the order they were written.
+//
+// StreamWriteRecords and Compact do not declare ordering.
+func WithOrdering(key string) Option {
+	return &orderingOption{key: key}
+}
+
+func (o *orderingOption) applyDataset(cfg *datasetConfig) error {
+	cfg.ordered = true
+	cfg.orderKey = o.key
+	return nil
+}
+
+func (o *orderingOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithOrdering: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// -----------------------------------------------------------------------------
+// Ordered Reads
+// -----------------------------------------------------------------------------
+
+// ReadOrdered reads a snapshot that declares ordering. Files are read in
+// partition/path order; with an order key, the concatenated records are
+// then stably sorted by key, which merges partitions whose key ranges
+// overlap.
+func (d *dataset) ReadOrdered(ctx context.Context, id DatasetSnapshotID) ([]any, error) {
+	snapshot, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m := snapshot.Manifest
+	if !m.Ordered {
+		return nil, fmt.Errorf("lode: snapshot %s: %w", id, ErrNotOrdered)
+	}
+	codec, compressor, err := d.resolveReadCodec(m)
+	if err != nil {
+		return nil, err
+	}
+	if codec == nil {
+		return nil, fmt.Errorf("lode: snapshot %s: ordered reads require a codec", id)
+	}
+
+	files := slices.Clone(m.Files)
+	sortFileRefs(files)
+	records, err := d.readFiles(ctx, compressor, codec, files, d.timeBounds(m))
+	if err != nil {
+		return nil, err
+	}
+	if m.OrderKey == "" {
+		return records, nil
+	}
+	if err := sortByOrderKey(records, m.OrderKey); err != nil {
+		return nil, fmt.Errorf("lode: snapshot %s: %w", id, err)
+	}
+	return records, nil
+}
+
+// sortByOrderKey stably sorts map records in place by the value of key.
+func sortByOrderKey(records []any, key string) error {
+	var sortErr error
+	slices.SortStableFunc(records, func(a, b any) int {
+		c, ok := compareOrderValues(recordField(a, key), recordField(b, key))
+		if !ok && sortErr == nil {
+			sortErr = fmt.Errorf("order key %q: cannot compare %T and %T",
+				key, recordField(a, key), recordField(b, key))
+		}
+		return c
+	})
+	return sortErr
+}
+
+// compareOrderValues compares two order key values. nil sorts before any
+// value; numbers, strings, and times compare within their kind. ok is
+// false for values of different or unsupported kinds.
+func compareOrderValues(a, b any) (c int, ok bool) {
+	switch {
+	case a == nil && b == nil:
+		return 0, true
+	case a == nil:
+		return -1, true
+	case b == nil:
+		return 1, true
+	}
+	if c, isNum, ok := compareOrderNumbers(a, b); isNum {
+		return c, ok
+	}
+	switch av := a.(type) {
+	case string:
+		if bv, bok := b.(string); bok {
+			return strings.Compare(av, bv), true
+		}
+	case time.Time:
+		if bv, bok := b.(time.Time); bok {
+			return av.Compare(bv), true
+		}
+	}
+	return 0, false
+}
+
+// compareOrderNumbers compares two numeric order key values exactly, so
+// int64 keys above 2^53 (such as Unix nanosecond timestamps) do not collide
+// and json.Number keys are not rounded. As with cmp.Compare, NaN sorts
+// before any other number. isNum is false when a is not a number; ok is
+// false when b is not.
+func compareOrderNumbers(a, b any) (c int, isNum, ok bool) {
+	if ai, aok := orderInt(a); aok {
+		if bi, bok := orderInt(b); bok {
+			return cmp.Compare(ai, bi), true, true
+		}
+	}
+	af, afloat := orderFloat(a)
+	bf, bfloat := orderFloat(b)
+	if afloat && bfloat {
+		return cmp.Compare(af, bf), true, true
+	}
+	ar, aok := orderRat(a)
+	br, bok := orderRat(b)
+	switch {
+	case aok && bok:
+		return ar.Cmp(br), true, true
+	case afloat && bok:
+		return nonFiniteSign(af), true, true
+	case bfloat && aok:
+		return -nonFiniteSign(bf), true, true
+	}
+	return 0, aok || afloat, false
+}
+
+// nonFiniteSign compares a NaN or infinite float with a finite number.
+func nonFiniteSign(f float64) int {
+	if f > 0 {
+		return 1
+	}
+	return -1
+}
+
+// orderInt returns signed integer order key values as int64.
+func orderInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
+
+// orderFloat returns floating point order key values as float64. Decoded
+// JSONL records hold float64.
+func orderFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// orderRat returns the exact value of a numeric order key. Infinite and
+// NaN floats and malformed json.Number values have none.
+func orderRat(v any) (*big.Rat, bool) {
+	if i, ok := orderInt(v); ok {
+		return new(big.Rat).SetInt64(i), true
+	}
+	if f, ok := orderFloat(v); ok {
+		r := new(big.Rat).SetFloat64(f)
+		return r, r != nil
+	}
+	switch n := v.(type) {
+	case uint:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint64:
+		return new(big.Rat).SetUint64(n), true
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	}
+	return nil, false
+}
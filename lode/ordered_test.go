@@ -0,0 +1,187 @@
+package lode
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDataset_ReadOrdered_ByKey(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("region"),
+		WithOrdering("seq"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Partitions interleave in key order, so ordering must merge them.
+	input := R(
+		D{"region": "us", "seq": 4}, D{"region": "eu", "seq": 2},
+		D{"region": "us", "seq": 1}, D{"region": "eu", "seq": 5},
+		D{"region": "ap", "seq": 3}, D{"region": "us", "seq": 0},
+	)
+	snap, err := ds.Write(t.Context(), input, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.Manifest.Ordered || snap.Manifest.OrderKey != "seq" {
+		t.Fatalf("manifest ordered=%v key=%q, want ordered by seq", snap.Manifest.Ordered, snap.Manifest.OrderKey)
+	}
+
+	// Records are stored as written; only ReadOrdered sorts.
+	us, err := ds.ReadPartition(t.Context(), snap.ID, "region=us", ReadPartitionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seqValues(us, "seq"); !reflect.DeepEqual(got, []float64{4, 1, 0}) {
+		t.Errorf("stored us partition seq = %v, want [4 1 0] as written", got)
+	}
+
+	records, err := ds.ReadOrdered(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := seqValues(records, "seq"), []float64{0, 1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadOrdered seq = %v, want %v", got, want)
+	}
+
+	bad, err := ds.Write(t.Context(), R(D{"region": "us", "seq": 1}, D{"region": "us", "seq": "x"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.ReadOrdered(t.Context(), bad.ID); err == nil {
+		t.Error("expected error reading incomparable order key values")
+	}
+}
+
+func TestDataset_ReadOrdered_ByPartition(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"),
+		WithOrdering(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(
+		D{"day": "2", "n": 0}, D{"day": "1", "n": 1},
+		D{"day": "2", "n": 2}, D{"day": "1", "n": 3},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.Manifest.Ordered || snap.Manifest.OrderKey != "" {
+		t.Fatalf("manifest ordered=%v key=%q, want ordered by partition", snap.Manifest.Ordered, snap.Manifest.OrderKey)
+	}
+	records, err := ds.ReadOrdered(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := seqValues(records, "n"), []float64{1, 3, 0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadOrdered n = %v, want partition order %v", got, want)
+	}
+}
+
+func TestDataset_ReadOrdered_Unordered(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"n": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.Ordered || snap.Manifest.OrderKey != "" {
+		t.Errorf("default manifest declares ordering: ordered=%v key=%q", snap.Manifest.Ordered, snap.Manifest.OrderKey)
+	}
+	if _, err := ds.ReadOrdered(t.Context(), snap.ID); !errors.Is(err, ErrNotOrdered) {
+		t.Errorf("expected ErrNotOrdered, got: %v", err)
+	}
+	m := *snap.Manifest
+	m.OrderKey = "n"
+	if err := validateManifest(&m); !errors.Is(err, ErrManifestInvalid) {
+		t.Errorf("expected ErrManifestInvalid for order_key without ordered, got: %v", err)
+	}
+
+	if _, err := NewDataset("blobs", NewMemoryFactory(), WithOrdering("")); err == nil {
+		t.Error("expected error for WithOrdering without a codec")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithOrdering("seq")); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_ReadOrdered_LargeIntKeys(t *testing.T) {
+	codec, err := NewGobCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(codec),
+		WithOrdering("ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both keys round to the same float64.
+	const base = int64(1) << 60
+	snap, err := ds.Write(t.Context(), R(D{"ts": base + 1}, D{"ts": base}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ds.ReadOrdered(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, r := range records {
+		got = append(got, r.(map[string]any)["ts"].(int64))
+	}
+	if want := []int64{base, base + 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadOrdered ts = %v, want %v", got, want)
+	}
+}
+
+func TestCompareOrderValues(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		a, b any
+		want int
+		ok   bool
+	}{
+		{nil, nil, 0, true},
+		{nil, 1, -1, true},
+		{"b", nil, 1, true},
+		{int64(2), 1.5, 1, true},
+		{1, float64(1), 0, true},
+		{int64(1<<53 + 1), int64(1 << 53), 1, true},
+		{uint64(math.MaxUint64), int64(-1), 1, true},
+		{uint8(3), 2.5, 1, true},
+		{json.Number("9007199254740993"), json.Number("9007199254740992"), 1, true},
+		{json.Number("0.1"), 0.1, -1, true},
+		{math.Inf(1), uint64(math.MaxUint64), 1, true},
+		{int16(-7), math.Inf(-1), 1, true},
+		{math.NaN(), 0, -1, true},
+		{"a", "b", -1, true},
+		{t0, t0.Add(time.Second), -1, true},
+		{1, "1", 0, false},
+		{"1", 1, 0, false},
+		{true, false, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := compareOrderValues(tt.a, tt.b)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("compareOrderValues(%v, %v) = %d, %v; want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// seqValues returns the float64 key field of each decoded record.
+func seqValues(records []any, key string) []float64 {
+	var values []float64
+	for _, r := range records {
+		values = append(values, r.(map[string]any)[key].(float64))
+	}
+	return values
+}
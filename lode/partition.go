@@ -1,6 +1,7 @@
 package lode
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -54,6 +55,9 @@ func (h *hivePartitioner) isNoop() bool {
 	return false
 }
 
+// escapeValue formats a partition value as one path component. Reserved
+// characters are percent-encoded, including "=" (which url.PathEscape keeps),
+// so the first "=" of a component always separates key from value.
 func escapeValue(v any) string {
 	var s string
 	switch val := v.(type) {
@@ -64,7 +68,121 @@ func escapeValue(v any) string {
 	default:
 		s = fmt.Sprintf("%v", val)
 	}
-	return url.PathEscape(s)
+	return strings.ReplaceAll(url.PathEscape(s), "=", "%3D")
+}
+
+// validPartitionKey reports whether key can be written unescaped as the key
+// of a key=value path component.
+func validPartitionKey(key string) bool {
+	return key != "" && !strings.Contains(key, "=") && url.PathEscape(key) == key
+}
+
+// -----------------------------------------------------------------------------
+// Time-Range Partitioner (internal)
+// -----------------------------------------------------------------------------
+
+const (
+	// timeRangeKey is the partition key written by the time-range partitioner.
+	timeRangeKey = "window"
+	// timeRangeInvalid is the window value for records without a parseable time.
+	timeRangeInvalid = "__invalid__"
+)
+
+// timeRangePartitioner routes records to fixed-width time windows.
+type timeRangePartitioner struct {
+	field  string
+	bucket time.Duration
+}
+
+func newTimeRangePartitioner(field string, bucket time.Duration) partitioner {
+	return &timeRangePartitioner{field: field, bucket: bucket}
+}
+
+func (p *timeRangePartitioner) name() string {
+	return "time-range"
+}
+
+func (p *timeRangePartitioner) partitionKey(record any) (string, error) {
+	if _, ok := record.(map[string]any); !ok {
+		return "", fmt.Errorf("time-range partitioner: record must be map[string]any, got %T", record)
+	}
+	t, ok := p.recordTime(record)
+	if !ok {
+		return timeRangeKey + "=" + timeRangeInvalid, nil
+	}
+	// Truncate aligns to multiples of bucket since the zero time, which is
+	// UTC midnight, so hourly and daily windows start on UTC boundaries.
+	start := t.Truncate(p.bucket)
+	return timeRangeKey + "=" + escapeValue(start.Format(time.RFC3339)), nil
+}
+
+func (p *timeRangePartitioner) isNoop() bool {
+	return false
+}
+
+// recordTime parses the configured field as a time.
+func (p *timeRangePartitioner) recordTime(record any) (time.Time, bool) {
+	return timestampField(p.field).recordTime(record)
+}
+
+// -----------------------------------------------------------------------------
+// Func Partitioner (internal)
+// -----------------------------------------------------------------------------
+
+// PartitionFunc returns the partition path of a record, as one or more
+// "key=value" components joined by "/" (e.g. "shard=07/region=us").
+type PartitionFunc func(record any) (string, error)
+
+// funcPartitioner routes records with a caller-supplied PartitionFunc.
+type funcPartitioner struct {
+	label string
+	fn    PartitionFunc
+}
+
+func newFuncPartitioner(name string, fn PartitionFunc) partitioner {
+	return &funcPartitioner{label: name, fn: fn}
+}
+
+func (p *funcPartitioner) name() string {
+	return p.label
+}
+
+func (p *funcPartitioner) partitionKey(record any) (string, error) {
+	partPath, err := p.fn(record)
+	if err != nil {
+		return "", fmt.Errorf("%s partitioner: %w", p.label, err)
+	}
+	if err := validatePartitionPath(partPath); err != nil {
+		return "", fmt.Errorf("%s partitioner: %w", p.label, err)
+	}
+	return partPath, nil
+}
+
+func (p *funcPartitioner) isNoop() bool {
+	return false
+}
+
+// validatePartitionPath checks that partPath is a layout-safe partition
+// path: non-empty "key=value" components joined by "/", with keys accepted
+// by NewHiveLayout and values in the escaped form escapeValue produces.
+func validatePartitionPath(partPath string) error {
+	if partPath == "" {
+		return errors.New("partition path must not be empty")
+	}
+	for _, component := range strings.Split(partPath, "/") {
+		key, raw, ok := strings.Cut(component, "=")
+		if !ok {
+			return fmt.Errorf("invalid partition path %q: component %q is not key=value", partPath, component)
+		}
+		if !validPartitionKey(key) {
+			return fmt.Errorf("invalid partition path %q: invalid key %q", partPath, key)
+		}
+		val, err := url.PathUnescape(raw)
+		if err != nil || escapeValue(val) != raw {
+			return fmt.Errorf("invalid partition path %q: value %q of key %q must be escaped with url.PathEscape (and \"=\" as %%3D)", partPath, raw, key)
+		}
+	}
+	return nil
 }
 
 // -----------------------------------------------------------------------------
@@ -89,3 +207,29 @@ func (n *noopPartitioner) partitionKey(_ any) (string, error) {
 func (n *noopPartitioner) isNoop() bool {
 	return true
 }
+
+// -----------------------------------------------------------------------------
+// Partition Path Parsing (internal)
+// -----------------------------------------------------------------------------
+
+// parsePartitionValues parses a partition path ("k1=v1/k2=v2") into its
+// key/value pairs, unescaping values encoded by escapeValue.
+// An empty path yields an empty map.
+func parsePartitionValues(partPath string) (map[string]string, error) {
+	values := make(map[string]string)
+	if partPath == "" {
+		return values, nil
+	}
+	for _, component := range strings.Split(partPath, "/") {
+		key, raw, ok := strings.Cut(component, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid partition component %q", component)
+		}
+		val, err := url.PathUnescape(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition value %q: %w", raw, err)
+		}
+		values[key] = val
+	}
+	return values, nil
+}
@@ -0,0 +1,289 @@
+package lode
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeRangePartitioner_Buckets(t *testing.T) {
+	at := time.Date(2024, 3, 5, 13, 47, 12, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		bucket time.Duration
+		value  any
+		want   string
+	}{
+		{"hourly time.Time", time.Hour, at, "window=2024-03-05T13:00:00Z"},
+		{"hourly RFC3339 offset", time.Hour, "2024-03-05T14:47:12+01:00", "window=2024-03-05T13:00:00Z"},
+		{"hourly epoch int64", time.Hour, at.Unix(), "window=2024-03-05T13:00:00Z"},
+		{"hourly epoch float64", time.Hour, float64(at.Unix()) + 0.5, "window=2024-03-05T13:00:00Z"},
+		{"hourly epoch json.Number", time.Hour, json.Number("1709646432"), "window=2024-03-05T13:00:00Z"},
+		{"daily", 24 * time.Hour, at, "window=2024-03-05T00:00:00Z"},
+		{"daily just before midnight", 24 * time.Hour, "2024-03-05T23:59:59.999Z", "window=2024-03-05T00:00:00Z"},
+		{"fifteen minutes", 15 * time.Minute, at, "window=2024-03-05T13:45:00Z"},
+		{"unparseable string", time.Hour, "yesterday", "window=__invalid__"},
+		{"unsupported type", time.Hour, true, "window=__invalid__"},
+		{"nil", time.Hour, nil, "window=__invalid__"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTimeRangePartitioner("ts", tt.bucket)
+			got, err := p.partitionKey(D{"ts": tt.value})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("partitionKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeRangePartitioner_MissingFieldAndNonMap(t *testing.T) {
+	p := newTimeRangePartitioner("ts", time.Hour)
+	if got, err := p.partitionKey(D{"other": 1}); err != nil || got != "window=__invalid__" {
+		t.Errorf("missing field: got %q, %v", got, err)
+	}
+	if _, err := p.partitionKey("not a map"); err == nil {
+		t.Error("expected error for non-map record")
+	}
+	if p.name() != "time-range" {
+		t.Errorf("name = %q, want time-range", p.name())
+	}
+}
+
+func TestNewTimeRangeLayout_InvalidArguments(t *testing.T) {
+	if _, err := NewTimeRangeLayout("", time.Hour); err == nil {
+		t.Error("expected error for empty field")
+	}
+	if _, err := NewTimeRangeLayout("ts", 0); err == nil {
+		t.Error("expected error for zero bucket")
+	}
+}
+
+func TestDataset_Write_TimeRangeLayout_MixedTimestamps(t *testing.T) {
+	layout, err := NewTimeRangeLayout("ts", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := NewDataset("events", NewMemoryFactory(), WithLayout(layout), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": "1", "ts": "2024-01-01T10:15:00Z"},
+		D{"id": "2", "ts": "2024-01-01T10:45:00Z"},
+		D{"id": "3", "ts": "2024-01-01T12:05:00Z"},
+		D{"id": "4", "ts": "not a time"},
+		D{"id": "5"},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := snap.Manifest
+	if m.Partitioner != "time-range" {
+		t.Errorf("Partitioner = %q, want time-range", m.Partitioner)
+	}
+	var windows []string
+	for _, f := range m.Files {
+		for _, c := range strings.Split(f.Path, "/") {
+			if strings.HasPrefix(c, "window=") {
+				windows = append(windows, c)
+			}
+		}
+	}
+	slices.Sort(windows)
+	want := []string{"window=2024-01-01T10:00:00Z", "window=2024-01-01T12:00:00Z", "window=__invalid__"}
+	if !slices.Equal(windows, want) {
+		t.Errorf("windows = %v, want %v", windows, want)
+	}
+
+	wantMin := time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)
+	wantMax := time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)
+	if m.MinTimestamp == nil || !m.MinTimestamp.Equal(wantMin) {
+		t.Errorf("MinTimestamp = %v, want %v", m.MinTimestamp, wantMin)
+	}
+	if m.MaxTimestamp == nil || !m.MaxTimestamp.Equal(wantMax) {
+		t.Errorf("MaxTimestamp = %v, want %v", m.MaxTimestamp, wantMax)
+	}
+
+	invalid, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, func(p map[string]string) bool {
+		return p["window"] == "__invalid__"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invalid) != 2 {
+		t.Errorf("expected 2 records in invalid window, got %d", len(invalid))
+	}
+}
+
+func TestDataset_Write_TimeRangeLayout_NoParseableTimes_OmitsMinMax(t *testing.T) {
+	layout, err := NewTimeRangeLayout("ts", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := NewDataset("events", NewMemoryFactory(), WithLayout(layout), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), R(D{"ts": "bad"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.MinTimestamp != nil || snap.Manifest.MaxTimestamp != nil {
+		t.Errorf("expected nil min/max, got %v/%v", snap.Manifest.MinTimestamp, snap.Manifest.MaxTimestamp)
+	}
+}
+
+func TestHivePartitioner_EscapesReservedValues(t *testing.T) {
+	p := newHivePartitioner("region", "cat")
+
+	tests := []struct {
+		name  string
+		value D
+		want  string
+	}{
+		{"slash", D{"region": "us/east", "cat": "x"}, "region=us%2Feast/cat=x"},
+		{"equals", D{"region": "eu", "cat": "a=b"}, "region=eu/cat=a%3Db"},
+		{"space", D{"region": "us east", "cat": "x"}, "region=us%20east/cat=x"},
+		{"percent", D{"region": "100%", "cat": "x"}, "region=100%25/cat=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.partitionKey(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("partitionKey = %q, want %q", got, tt.want)
+			}
+			values, err := parsePartitionValues(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if values["region"] != tt.value["region"] || values["cat"] != tt.value["cat"] {
+				t.Errorf("parsePartitionValues(%q) = %v, want %v", got, values, tt.value)
+			}
+		})
+	}
+}
+
+func TestNewHiveLayout_RejectsReservedKeys(t *testing.T) {
+	for _, key := range []string{"", "a=b", "us/east", "my key"} {
+		if _, err := NewHiveLayout(key); err == nil {
+			t.Errorf("NewHiveLayout(%q): expected error", key)
+		}
+	}
+	if _, err := NewHiveLayout("event_day", "region-1"); err != nil {
+		t.Errorf("NewHiveLayout with plain keys failed: %v", err)
+	}
+}
+
+func TestDataset_Write_HiveLayout_ReservedValuesRoundTrip(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("region", "cat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": 1, "region": "us/east", "cat": "a=b"},
+		D{"id": 2, "region": "eu", "cat": "c"},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, f := range snap.Manifest.Files {
+		paths = append(paths, f.Path)
+	}
+	if !slices.ContainsFunc(paths, func(p string) bool {
+		return strings.Contains(p, "/partitions/region=us%2Feast/cat=a%3Db/segments/")
+	}) {
+		t.Errorf("expected escaped partition directory, got %v", paths)
+	}
+
+	got, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, func(p map[string]string) bool {
+		return p["region"] == "us/east" && p["cat"] == "a=b"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].(map[string]any)["id"] != float64(1) {
+		t.Errorf("ReadPartitionsWhere = %v, want record 1", got)
+	}
+}
+
+func TestNewPartitionFuncLayout_RoutesAndValidates(t *testing.T) {
+	shardOf := map[string]int{"acme": 7, "globex": 3}
+	l, err := NewPartitionFuncLayout("tenant-shard", func(record any) (string, error) {
+		m, ok := record.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("unexpected record %T", record)
+		}
+		tenant, _ := m["tenant"].(string)
+		if custom, ok := m["path"].(string); ok {
+			return custom, nil
+		}
+		return fmt.Sprintf("shard=%02d/region=%s", shardOf[tenant], url.PathEscape(m["region"].(string))), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemory()
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()), WithLayout(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(
+		D{"tenant": "acme", "region": "us/east"},
+		D{"tenant": "globex", "region": "eu"},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.Partitioner != "tenant-shard" {
+		t.Errorf("Partitioner = %q, want tenant-shard", snap.Manifest.Partitioner)
+	}
+	got, err := ds.ReadPartitionsWhere(t.Context(), snap.ID, func(p map[string]string) bool {
+		return p["shard"] == "07" && p["region"] == "us/east"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].(map[string]any)["tenant"] != "acme" {
+		t.Errorf("ReadPartitionsWhere = %v, want the acme record", got)
+	}
+
+	for _, bad := range []string{"", "/shard=1", "shard=1/", "shard", "a b=1", "shard=us/east", "shard=a b", "shard=a=b"} {
+		_, err := ds.Write(t.Context(), R(D{"path": bad}), Metadata{})
+		if err == nil || !strings.Contains(err.Error(), "tenant-shard partitioner") {
+			t.Errorf("path %q: expected partitioner error, got: %v", bad, err)
+		}
+	}
+	if _, err := ds.Write(t.Context(), R(D{"path": "shard=a%3Db"}), Metadata{}); err != nil {
+		t.Errorf("escaped value rejected: %v", err)
+	}
+
+	if _, err := NewPartitionFuncLayout("", func(any) (string, error) { return "", nil }); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if _, err := NewPartitionFuncLayout("hive", func(any) (string, error) { return "", nil }); err == nil {
+		t.Error("expected error for built-in name")
+	}
+	if _, err := NewPartitionFuncLayout("custom", nil); err == nil {
+		t.Error("expected error for nil function")
+	}
+}
@@ -1,21 +1,33 @@
 package lode
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+// defaultManifestConcurrency bounds GetManifests when no concurrency is set.
+const defaultManifestConcurrency = 8
+
 // -----------------------------------------------------------------------------
 // Reader Configuration
 // -----------------------------------------------------------------------------
 
 // readerConfig holds the resolved configuration for a reader.
 type readerConfig struct {
-	layout layout
+	layout        layout
+	manifestCache *ManifestCache
+	manifestName  string
+	strictCodecs  *CodecRegistry // non-nil enables strict component checks
 }
 
 // -----------------------------------------------------------------------------
@@ -26,6 +38,8 @@ type readerConfig struct {
 type reader struct {
 	store  Store
 	layout layout
+	cache  *ManifestCache // nil when caching is disabled
+	strict *CodecRegistry // nil when component names are not checked
 }
 
 // NewDatasetReader creates a DatasetReader with documented defaults.
@@ -35,6 +49,8 @@ type reader struct {
 //
 // Use option functions to override defaults:
 //   - WithLayout(l) to use a different layout
+//   - WithManifestCache(c) to cache validated manifests
+//   - WithStrictComponents(codecs) to reject manifests it cannot decode
 func NewDatasetReader(factory StoreFactory, opts ...Option) (DatasetReader, error) {
 	if factory == nil {
 		return nil, errors.New("lode: store factory is required")
@@ -61,10 +77,15 @@ func NewDatasetReader(factory StoreFactory, opts ...Option) (DatasetReader, erro
 	if cfg.layout == nil {
 		return nil, errors.New("lode: layout must not be nil")
 	}
+	if cfg.manifestName != "" {
+		cfg.layout = cfg.layout.withManifestName(cfg.manifestName)
+	}
 
 	return &reader{
 		store:  store,
 		layout: cfg.layout,
+		cache:  cfg.manifestCache,
+		strict: cfg.strictCodecs,
 	}, nil
 }
 
@@ -72,40 +93,140 @@ func (r *reader) ListDatasets(ctx context.Context, opts DatasetListOptions) ([]D
 	if !r.layout.supportsDatasetEnumeration() {
 		return nil, ErrDatasetsNotModeled
 	}
-
-	paths, err := r.store.List(ctx, r.layout.datasetsPrefix())
+	if dl, ok := r.store.(DelimitedLister); ok {
+		return r.listDatasetDirs(ctx, dl, opts)
+	}
+	paths, err := r.store.List(ctx, r.layout.datasetsPrefix()+opts.Prefix)
 	if err != nil {
 		return nil, err
 	}
 
+	// Many manifests map to one dataset: deduplicate, then sort so pages
+	// are stable across calls.
 	seen := make(map[DatasetID]bool)
 	var datasets []DatasetID
+	hasAnyManifest := false
 
 	for _, p := range paths {
 		if !r.layout.isManifest(p) {
 			continue
 		}
+		hasAnyManifest = true
 
 		datasetID := r.layout.parseDatasetID(p)
-		if datasetID == "" || seen[datasetID] {
+		if datasetID == "" || seen[datasetID] || !strings.HasPrefix(string(datasetID), opts.Prefix) {
 			continue
 		}
 		seen[datasetID] = true
+		if opts.PageToken != "" && string(datasetID) <= opts.PageToken {
+			continue
+		}
 		datasets = append(datasets, datasetID)
+	}
+
+	// Contract: empty list means storage truly empty, not "no manifests"
+	if !hasAnyManifest && len(paths) > 0 {
+		return nil, ErrNoManifests
+	}
+
+	slices.Sort(datasets)
+	if opts.Limit > 0 && len(datasets) > opts.Limit {
+		datasets = datasets[:opts.Limit]
+	}
+	return datasets, nil
+}
+
+// listDatasetDirs enumerates candidate datasets as the directories one level
+// below the datasets prefix, then confirms each with datasetHasManifest, so
+// it reports the same datasets as the flat listing. Confirmation stops once
+// Limit datasets are found. No dataset's keys are listed in full.
+func (r *reader) listDatasetDirs(ctx context.Context, dl DelimitedLister, opts DatasetListOptions) ([]DatasetID, error) {
+	root := r.layout.datasetsPrefix()
+	keys, dirs, err := dl.ListPrefixes(ctx, root+opts.Prefix, "/")
+	if err != nil {
+		return nil, err
+	}
 
-		if opts.Limit > 0 && len(datasets) >= opts.Limit {
+	var ids []DatasetID
+	for _, dir := range dirs {
+		id, ok := strings.CutPrefix(strings.TrimSuffix(dir, "/"), root)
+		if !ok || id == "" || strings.Contains(id, "/") {
+			continue
+		}
+		ids = append(ids, DatasetID(id))
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var datasets []DatasetID
+	for _, id := range ids {
+		if opts.PageToken != "" && string(id) <= opts.PageToken {
+			continue
+		}
+		if opts.Limit > 0 && len(datasets) == opts.Limit {
 			break
 		}
+		ok, err := r.datasetHasManifest(ctx, dl, id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			datasets = append(datasets, id)
+		}
 	}
 
-	// Contract: empty list means storage truly empty, not "no manifests"
-	if len(datasets) == 0 && len(paths) > 0 {
+	// Contract: empty list means storage truly empty, not "no manifests".
+	// Datasets at or before the page token were skipped above, so they are
+	// checked before reporting ErrNoManifests.
+	if len(datasets) == 0 && (len(keys) > 0 || len(dirs) > 0) {
+		for _, id := range ids {
+			if opts.PageToken == "" || string(id) > opts.PageToken {
+				break
+			}
+			ok, err := r.datasetHasManifest(ctx, dl, id)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return nil, nil
+			}
+		}
 		return nil, ErrNoManifests
 	}
-
 	return datasets, nil
 }
 
+// datasetHasManifest reports whether any snapshot directory of the dataset
+// holds a canonical manifest. Every committed snapshot writes its canonical
+// manifest first, so this matches a flat listing of the dataset. The
+// directories are listed one level deep and probed with Exists until the
+// first manifest is found.
+func (r *reader) datasetHasManifest(ctx context.Context, dl DelimitedLister, dataset DatasetID) (bool, error) {
+	// The canonical manifest sits at <snapshots dir>/<id>/<manifest name>.
+	snapshotsPrefix := path.Dir(path.Dir(r.layout.manifestPath(dataset, "_"))) + "/"
+	_, dirs, err := dl.ListPrefixes(ctx, snapshotsPrefix, "/")
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, dir := range dirs {
+		id := path.Base(strings.TrimSuffix(dir, "/"))
+		if id == "" || id == "." {
+			continue
+		}
+		exists, err := r.store.Exists(ctx, r.layout.manifestPath(dataset, DatasetSnapshotID(id)))
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (r *reader) ListPartitions(ctx context.Context, dataset DatasetID, opts PartitionListOptions) ([]PartitionRef, error) {
 	// Single-pass: list paths, load each manifest once, extract partitions.
 	// Eliminates the double-deserialization of ListManifests + GetManifest (CX-3).
@@ -141,7 +262,7 @@ func (r *reader) ListPartitions(ctx context.Context, dataset DatasetID, opts Par
 		seenSnap[snapshotID] = true
 
 		// Load each manifest once — the only Get per manifest.
-		manifest, err := r.loadManifest(ctx, p)
+		manifest, err := r.cachedManifest(ctx, dataset, snapshotID, p)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load manifest %s: %w", p, err)
 		}
@@ -168,13 +289,21 @@ func (r *reader) ListPartitions(ctx context.Context, dataset DatasetID, opts Par
 }
 
 func (r *reader) ListManifests(ctx context.Context, dataset DatasetID, partition string, opts ManifestListOptions) ([]ManifestRef, error) {
+	refs, _, err := r.listManifests(ctx, dataset, partition, opts)
+	return refs, err
+}
+
+// listManifests implements ListManifests, also returning the loaded
+// manifest of each ref.
+func (r *reader) listManifests(ctx context.Context, dataset DatasetID, partition string, opts ManifestListOptions) ([]ManifestRef, []*Manifest, error) {
 	prefix := r.layout.segmentsPrefixForPartition(dataset, partition)
 	paths, err := r.store.List(ctx, prefix)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var refs []ManifestRef
+	var manifests []*Manifest
 	seen := make(map[DatasetSnapshotID]bool)
 	hasAnyManifest := false
 
@@ -200,9 +329,9 @@ func (r *reader) ListManifests(ctx context.Context, dataset DatasetID, partition
 		}
 
 		// Always validate manifest per CONTRACT_READ_API.md
-		manifest, err := r.loadManifest(ctx, p)
+		manifest, err := r.cachedManifest(ctx, dataset, snapshotID, p)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load manifest %s: %w", p, err)
+			return nil, nil, fmt.Errorf("failed to load manifest %s: %w", p, err)
 		}
 
 		// Apply partition filter if specified
@@ -212,30 +341,573 @@ func (r *reader) ListManifests(ctx context.Context, dataset DatasetID, partition
 			}
 		}
 
+		if !metadataMatches(manifest.Metadata, opts.MetadataMatch) {
+			continue
+		}
+
 		seen[snapshotID] = true
 		refs = append(refs, ManifestRef{
 			ID:        snapshotID,
 			Partition: manifestPartition,
 		})
+		manifests = append(manifests, manifest)
 
-		if opts.Limit > 0 && len(refs) >= opts.Limit {
+		if !opts.Sort && opts.Limit > 0 && len(refs) >= opts.Limit {
 			break
 		}
 	}
 
 	if !hasAnyManifest {
-		return nil, ErrNotFound
+		return nil, nil, ErrNotFound
+	}
+
+	if opts.Sort {
+		order := make([]int, len(refs))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int {
+			if c := strings.Compare(string(refs[a].ID), string(refs[b].ID)); c != 0 {
+				return c
+			}
+			return strings.Compare(refs[a].Partition, refs[b].Partition)
+		})
+		if opts.Limit > 0 && len(order) > opts.Limit {
+			order = order[:opts.Limit]
+		}
+		sortedRefs := make([]ManifestRef, len(order))
+		sortedManifests := make([]*Manifest, len(order))
+		for i, j := range order {
+			sortedRefs[i], sortedManifests[i] = refs[j], manifests[j]
+		}
+		refs, manifests = sortedRefs, sortedManifests
+	}
+	return refs, manifests, nil
+}
+
+// metadataMatches reports whether metadata contains every key in match with
+// an equal string value.
+func metadataMatches(metadata Metadata, match map[string]string) bool {
+	for k, want := range match {
+		got, ok := metadata[k].(string)
+		if !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *reader) DatasetExists(ctx context.Context, dataset DatasetID) (bool, error) {
+	if dataset == "" {
+		return false, fmt.Errorf("lode: %w: dataset is required", ErrInvalidPath)
+	}
+	_, err := r.ListManifests(ctx, dataset, "", ManifestListOptions{Limit: 1})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *reader) DatasetState(ctx context.Context, dataset DatasetID) (DatasetState, error) {
+	exists, err := r.DatasetExists(ctx, dataset)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return DatasetCommitted, nil
+	}
+	paths, err := r.store.List(ctx, r.datasetRoot(dataset))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return DatasetAbsent, nil
+	}
+	return DatasetEmpty, nil
+}
+
+func (r *reader) SnapshotExists(ctx context.Context, dataset DatasetID, snapshot DatasetSnapshotID) (bool, error) {
+	if dataset == "" || snapshot == "" {
+		return false, fmt.Errorf("lode: %w: dataset and snapshot are required", ErrInvalidPath)
+	}
+	return r.store.Exists(ctx, r.layout.manifestPath(dataset, snapshot))
+}
+
+func (r *reader) DiffSnapshots(ctx context.Context, dataset DatasetID, a, b ManifestRef) (*SnapshotDiff, error) {
+	manifests, err := r.GetManifests(ctx, dataset, []ManifestRef{a, b}, ManifestGetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ma, mb := manifests[0], manifests[1]
+
+	inA := make(map[string]bool, len(ma.Files))
+	var bytesA, bytesB int64
+	for _, f := range ma.Files {
+		inA[f.Path] = true
+		bytesA += f.SizeBytes
+	}
+
+	diff := &SnapshotDiff{
+		Dataset:       dataset,
+		A:             a,
+		B:             b,
+		RowCountDelta: mb.RowCount - ma.RowCount,
+	}
+	inB := make(map[string]bool, len(mb.Files))
+	for _, f := range mb.Files {
+		inB[f.Path] = true
+		bytesB += f.SizeBytes
+		if inA[f.Path] {
+			diff.InBoth = append(diff.InBoth, f.Path)
+		} else {
+			diff.OnlyInB = append(diff.OnlyInB, f.Path)
+		}
+	}
+	for _, f := range ma.Files {
+		if !inB[f.Path] {
+			diff.OnlyInA = append(diff.OnlyInA, f.Path)
+		}
+	}
+	diff.BytesDelta = bytesB - bytesA
+
+	slices.Sort(diff.OnlyInA)
+	slices.Sort(diff.OnlyInB)
+	slices.Sort(diff.InBoth)
+	return diff, nil
+}
+
+func (r *reader) SnapshotsSince(ctx context.Context, dataset DatasetID, since DatasetSnapshotID) ([]ManifestRef, error) {
+	refs, manifests, err := r.listManifests(ctx, dataset, "", ManifestListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[DatasetSnapshotID]ManifestRef, len(refs))
+	for _, ref := range refs {
+		byID[ref.ID] = ref
+	}
+
+	descendants, err := snapshotsSince(manifests, since)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ManifestRef, len(descendants))
+	for i, m := range descendants {
+		result[i] = byID[m.SnapshotID]
+	}
+	return result, nil
+}
+
+// snapshotsSince returns the manifests that descend from since through
+// ParentSnapshotID, ordered by CreatedAt (then ID). An empty since selects
+// every manifest. Returns ErrNotFound if since is not among manifests.
+func snapshotsSince(manifests []*Manifest, since DatasetSnapshotID) ([]*Manifest, error) {
+	children := make(map[DatasetSnapshotID][]*Manifest)
+	found := since == ""
+	for _, m := range manifests {
+		children[m.ParentSnapshotID] = append(children[m.ParentSnapshotID], m)
+		if m.SnapshotID == since {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("lode: snapshot %s: %w", since, ErrNotFound)
+	}
+
+	var result []*Manifest
+	if since == "" {
+		result = slices.Clone(manifests)
+	} else {
+		visited := map[DatasetSnapshotID]bool{since: true}
+		queue := []DatasetSnapshotID{since}
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, child := range children[id] {
+				if visited[child.SnapshotID] {
+					continue
+				}
+				visited[child.SnapshotID] = true
+				result = append(result, child)
+				queue = append(queue, child.SnapshotID)
+			}
+		}
 	}
 
-	return refs, nil
+	slices.SortFunc(result, func(a, b *Manifest) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.SnapshotID), string(b.SnapshotID))
+	})
+	return result, nil
 }
 
 func (r *reader) GetManifest(ctx context.Context, dataset DatasetID, ref ManifestRef) (*Manifest, error) {
 	manifestPath := r.layout.manifestPathInPartition(dataset, ref.ID, ref.Partition)
-	return r.loadManifest(ctx, manifestPath)
+	m, err := r.lookupManifest(ctx, dataset, ref.ID, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkComponents(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NotManifestPathError reports a path passed to GetManifestByPath that the
+// reader's layout does not recognize as a manifest. It matches
+// ErrNotManifestPath.
+type NotManifestPathError struct {
+	// Path is the path as given.
+	Path string
+	// Reason and Detail explain the rejection, as DebugParseManifestPath
+	// reports them.
+	Reason ManifestPathReason
+	Detail string
+}
+
+func (e *NotManifestPathError) Error() string {
+	msg := fmt.Sprintf("lode: %q is not a manifest path (%s)", e.Path, e.Reason)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+func (e *NotManifestPathError) Unwrap() error {
+	return ErrNotManifestPath
+}
+
+// GetManifestByPath parses the dataset and snapshot IDs from the path, so
+// manifests share the reader's cache with GetManifest.
+func (r *reader) GetManifestByPath(ctx context.Context, manifestPath string) (*Manifest, error) {
+	if !r.layout.isManifest(manifestPath) {
+		d := DebugParseManifestPath(r.layout, manifestPath)
+		return nil, &NotManifestPathError{Path: manifestPath, Reason: d.Reason, Detail: d.Detail}
+	}
+	dataset := r.layout.parseDatasetID(manifestPath)
+	id := r.layout.parseSegmentID(manifestPath)
+	m, err := r.lookupManifest(ctx, dataset, id, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkComponents(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *reader) GetManifests(ctx context.Context, dataset DatasetID, refs []ManifestRef, opts ManifestGetOptions) ([]*Manifest, error) {
+	if opts.Concurrency < 0 {
+		return nil, errors.New("lode: concurrency must be non-negative")
+	}
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultManifestConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	manifests := make([]*Manifest, len(refs))
+	sem := make(chan struct{}, concurrency)
+
+dispatch:
+	for i, ref := range refs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Each goroutine writes only its own slot; order follows input.
+			var m *Manifest
+			var err error
+			if opts.SkipValidation {
+				m, err = r.getManifestUnvalidated(ctx, dataset, ref)
+			} else {
+				m, err = r.GetManifest(ctx, dataset, ref)
+			}
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to load manifest %s: %w", ref.ID, err)
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			manifests[i] = m
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// Cancellation by the caller may stop dispatch before any fetch fails.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+func (r *reader) ListSegmentPartitions(ctx context.Context, dataset DatasetID, ref ManifestRef) ([]PartitionRef, error) {
+	m, err := r.GetManifest(ctx, dataset, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if len(m.Partitions) > 0 {
+		for _, p := range m.Partitions {
+			paths = append(paths, p.Path)
+		}
+	} else {
+		for _, f := range m.Files {
+			paths = append(paths, r.layout.extractPartitionPath(f.Path))
+		}
+	}
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+
+	partitions := []PartitionRef{}
+	for _, p := range paths {
+		if p != "" {
+			partitions = append(partitions, PartitionRef{Path: p})
+		}
+	}
+	return partitions, nil
+}
+
+func (r *reader) ListSegmentObjects(ctx context.Context, dataset DatasetID, ref ManifestRef, opts SegmentObjectListOptions) (ObjectIterator, error) {
+	if dataset == "" || ref.ID == "" {
+		return nil, fmt.Errorf("lode: %w: dataset and segment are required", ErrInvalidPath)
+	}
+
+	paths, err := r.store.List(ctx, r.segmentDataPrefix(dataset, ref.ID, ref.Partition))
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []ObjectRef
+	for _, p := range paths {
+		if r.layout.isManifest(p) {
+			continue
+		}
+		refs = append(refs, ObjectRef{Dataset: dataset, Manifest: ref, Path: p})
+		if opts.Limit > 0 && len(refs) >= opts.Limit {
+			break
+		}
+	}
+	return newListingIterator(refs), nil
+}
+
+func (r *reader) ListAllObjects(ctx context.Context, dataset DatasetID, opts AllObjectListOptions) (ObjectIterator, error) {
+	if dataset == "" {
+		return nil, fmt.Errorf("lode: %w: dataset is required", ErrInvalidPath)
+	}
+
+	// A SizedLister reports sizes with the listing, so no Stat is needed.
+	var infos []ObjectInfo
+	var err error
+	_, sized := r.store.(SizedLister)
+	if opts.Stat && sized {
+		infos, err = ListSized(ctx, r.store, r.datasetRoot(dataset))
+	} else {
+		var paths []string
+		paths, err = r.store.List(ctx, r.datasetRoot(dataset))
+		for _, p := range paths {
+			infos = append(infos, ObjectInfo{Path: p})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]ObjectRef, 0, len(infos))
+	for _, info := range infos {
+		p := info.Path
+		ref := ObjectRef{Dataset: dataset, Path: p, SizeBytes: info.SizeBytes}
+		if r.layout.isManifest(p) {
+			ref.IsManifest = true
+			ref.Manifest = ManifestRef{
+				ID:        r.layout.parseSegmentID(p),
+				Partition: r.layout.parsePartitionFromManifest(p),
+			}
+		}
+		refs = append(refs, ref)
+		if opts.Limit > 0 && len(refs) >= opts.Limit {
+			break
+		}
+	}
+	if !opts.Stat || sized {
+		return newListingIterator(refs), nil
+	}
+	return &statObjectIterator{ctx: ctx, store: r.store, refs: refs}, nil
+}
+
+func (r *reader) DatasetSize(ctx context.Context, dataset DatasetID) (int64, error) {
+	if dataset == "" {
+		return 0, fmt.Errorf("lode: %w: dataset is required", ErrInvalidPath)
+	}
+	infos, err := ListSized(ctx, r.store, r.datasetRoot(dataset))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+	var total int64
+	for _, info := range infos {
+		total += info.SizeBytes
+	}
+	return total, nil
+}
+
+// datasetRoot returns the prefix holding every object of dataset. Every
+// layout keeps the latest pointer at the dataset root.
+func (r *reader) datasetRoot(dataset DatasetID) string {
+	return path.Dir(r.layout.latestPointerPath(dataset)) + "/"
+}
+
+func (r *reader) VerifySegment(ctx context.Context, dataset DatasetID, ref ManifestRef, opts VerifySegmentOptions) (*DriftReport, error) {
+	m, err := r.GetManifest(ctx, dataset, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files of one segment may span several partition data prefixes.
+	expected := make(map[string]int64, len(m.Files))
+	partitions := []string{ref.Partition}
+	for _, f := range m.Files {
+		expected[f.Path] = f.SizeBytes
+		if p := r.layout.extractPartitionPath(f.Path); !slices.Contains(partitions, p) {
+			partitions = append(partitions, p)
+		}
+	}
+
+	stored := make(map[string]bool)
+	// Sizes reported by a SizedLister listing spare a Stat per file.
+	var listed map[string]int64
+	if _, ok := r.store.(SizedLister); ok && opts.CheckSizes {
+		listed = make(map[string]int64)
+	}
+	for _, p := range partitions {
+		if listed != nil {
+			infos, err := ListSized(ctx, r.store, r.segmentDataPrefix(dataset, ref.ID, p))
+			if err != nil {
+				return nil, err
+			}
+			for _, info := range infos {
+				if !r.layout.isManifest(info.Path) {
+					stored[info.Path] = true
+					listed[info.Path] = info.SizeBytes
+				}
+			}
+			continue
+		}
+		iter, err := r.ListSegmentObjects(ctx, dataset, ManifestRef{ID: ref.ID, Partition: p}, SegmentObjectListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for iter.Next() {
+			stored[iter.Ref().Path] = true
+		}
+		err = iter.Err()
+		_ = iter.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Files shared from an earlier segment (see WithDedup) live outside
+	// this segment's data prefixes and are checked in one batch.
+	var shared []string
+	for p := range expected {
+		if !stored[p] && !r.inSegmentData(dataset, ref.ID, p) {
+			shared = append(shared, p)
+		}
+	}
+	if len(shared) > 0 {
+		exists, err := ExistsMany(ctx, r.store, shared)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range shared {
+			stored[p] = exists[p]
+		}
+	}
+
+	report := &DriftReport{Dataset: dataset, Segment: ref}
+	for p, size := range expected {
+		if !stored[p] {
+			report.Missing = append(report.Missing, p)
+			continue
+		}
+		if !opts.CheckSizes {
+			continue
+		}
+		if actual, ok := listed[p]; ok {
+			if actual != size {
+				report.SizeMismatches = append(report.SizeMismatches, SizeMismatch{Path: p, Expected: size, Actual: actual})
+			}
+			continue
+		}
+		info, err := StatObject(ctx, r.store, p)
+		if errors.Is(err, ErrNotFound) {
+			// Deleted between listing and stat.
+			report.Missing = append(report.Missing, p)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.SizeBytes != size {
+			report.SizeMismatches = append(report.SizeMismatches, SizeMismatch{Path: p, Expected: size, Actual: info.SizeBytes})
+		}
+	}
+	for p := range stored {
+		if _, ok := expected[p]; !ok {
+			report.Extra = append(report.Extra, p)
+		}
+	}
+
+	slices.Sort(report.Missing)
+	slices.Sort(report.Extra)
+	slices.SortFunc(report.SizeMismatches, func(a, b SizeMismatch) int { return strings.Compare(a.Path, b.Path) })
+	return report, nil
+}
+
+// segmentDataPrefix returns the data prefix of segment id in partition: the
+// directory of any data file the segment writes there.
+func (r *reader) segmentDataPrefix(dataset DatasetID, id DatasetSnapshotID, partition string) string {
+	return path.Dir(r.layout.dataFilePath(dataset, id, partition, "_")) + "/"
+}
+
+// inSegmentData reports whether filePath lies under a data prefix of
+// segment id.
+func (r *reader) inSegmentData(dataset DatasetID, id DatasetSnapshotID, filePath string) bool {
+	partition := r.layout.extractPartitionPath(filePath)
+	return strings.HasPrefix(filePath, r.segmentDataPrefix(dataset, id, partition))
 }
 
 func (r *reader) OpenObject(ctx context.Context, obj ObjectRef) (io.ReadCloser, error) {
+	if obj.Path == "" {
+		return nil, fmt.Errorf("lode: %w: object path is required", ErrInvalidPath)
+	}
 	return r.store.Get(ctx, obj.Path)
 }
 
@@ -243,22 +915,115 @@ func (r *reader) ReaderAt(ctx context.Context, obj ObjectRef) (io.ReaderAt, erro
 	return r.store.ReaderAt(ctx, obj.Path)
 }
 
+// lookupManifest is cachedManifest for a snapshot the caller names, which
+// may not exist: with CacheNotFound, a load failing with ErrNotFound is
+// cached and served until it expires. Listings load manifests they have
+// just found through cachedManifest, bypassing not-found results.
+func (r *reader) lookupManifest(ctx context.Context, dataset DatasetID, id DatasetSnapshotID, manifestPath string) (*Manifest, error) {
+	if r.cache == nil {
+		return r.loadManifest(ctx, manifestPath)
+	}
+	if err := r.cache.getNotFound(dataset, id, manifestPath); err != nil {
+		return nil, err
+	}
+	m, err := r.cachedManifest(ctx, dataset, id, manifestPath)
+	if errors.Is(err, ErrNotFound) {
+		r.cache.putNotFound(dataset, id, manifestPath, err)
+	}
+	return m, err
+}
+
+// cachedManifest serves a manifest from the cache when one is configured,
+// loading and caching it on a miss. Only validated manifests are cached.
+func (r *reader) cachedManifest(ctx context.Context, dataset DatasetID, id DatasetSnapshotID, manifestPath string) (*Manifest, error) {
+	if r.cache == nil {
+		return r.loadManifest(ctx, manifestPath)
+	}
+	if m, ok := r.cache.get(dataset, id, manifestPath); ok {
+		return m, nil
+	}
+	m, err := r.loadManifest(ctx, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.put(dataset, id, manifestPath, m)
+	return m, nil
+}
+
+// getManifestUnvalidated serves a cached manifest when present and
+// otherwise fetches and decodes one without validating or caching it.
+func (r *reader) getManifestUnvalidated(ctx context.Context, dataset DatasetID, ref ManifestRef) (*Manifest, error) {
+	manifestPath := r.layout.manifestPathInPartition(dataset, ref.ID, ref.Partition)
+	if r.cache != nil {
+		if m, ok := r.cache.get(dataset, ref.ID, manifestPath); ok {
+			return m, nil
+		}
+	}
+	return r.fetchManifest(ctx, manifestPath)
+}
+
 func (r *reader) loadManifest(ctx context.Context, manifestPath string) (*Manifest, error) {
+	manifest, err := r.fetchManifest(ctx, manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateManifest(manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// fetchManifest reads and decodes the manifest at manifestPath.
+func (r *reader) fetchManifest(ctx context.Context, manifestPath string) (*Manifest, error) {
 	rc, err := r.store.Get(ctx, manifestPath)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rc.Close() }()
 
-	var manifest Manifest
-	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+	manifest, err := decodeManifest(rc)
+	if err != nil {
 		return nil, fmt.Errorf("failed to decode manifest: %w", err)
 	}
+	return manifest, nil
+}
 
-	if err := validateManifest(&manifest); err != nil {
-		return nil, err
+// Magic headers of compressed manifest objects (see WithManifestCompressor).
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decodeManifest decodes a manifest object, first decompressing it when it
+// starts with a gzip or zstd header. JSON cannot start with either header,
+// so plain manifests are decoded unchanged.
+func decodeManifest(r io.Reader) (*Manifest, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(len(zstdMagic))
+
+	var src io.Reader = br
+	var c Compressor
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		c = NewGzipCompressor()
+	case bytes.HasPrefix(header, zstdMagic):
+		c = NewZstdCompressor()
+	}
+	if c != nil {
+		dr, err := c.Decompress(br)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = dr.Close() }()
+		src = dr
 	}
 
+	var manifest Manifest
+	if err := json.NewDecoder(src).Decode(&manifest); err != nil {
+		return nil, err
+	}
 	return &manifest, nil
 }
 
@@ -272,6 +1037,337 @@ func (r *reader) manifestContainsPartition(m *Manifest, partition string) bool {
 	return false
 }
 
+// -----------------------------------------------------------------------------
+// Row Count Audit
+// -----------------------------------------------------------------------------
+
+// RowCountMismatchError reports a manifest whose RowCount differs from the
+// number of records decoded from its files. It matches ErrRowCountMismatch.
+type RowCountMismatchError struct {
+	// Dataset and SnapshotID identify the audited segment.
+	Dataset    DatasetID
+	SnapshotID DatasetSnapshotID
+	// Recorded is the manifest's RowCount.
+	Recorded int64
+	// Actual is the number of records decoded.
+	Actual int64
+}
+
+func (e *RowCountMismatchError) Error() string {
+	return fmt.Sprintf("lode: dataset %s snapshot %s: manifest row_count %d, decoded %d records",
+		e.Dataset, e.SnapshotID, e.Recorded, e.Actual)
+}
+
+func (e *RowCountMismatchError) Unwrap() error {
+	return ErrRowCountMismatch
+}
+
+// AuditRowCount streams the records of each file through the manifest's
+// compressor and codec, holding at most one file's records for codecs that
+// cannot stream. Raw blob files count as one record each.
+func (r *reader) AuditRowCount(ctx context.Context, dataset DatasetID, ref ManifestRef) error {
+	m, err := r.GetManifest(ctx, dataset, ref)
+	if err != nil {
+		return err
+	}
+	compressor, err := builtinCompressor(m.Compressor)
+	if err != nil {
+		return err
+	}
+	var codec Codec
+	if m.Codec != "" {
+		codecs := r.strict
+		if codecs == nil {
+			codecs = NewCodecRegistry()
+		}
+		if codec, err = codecs.Get(m.Codec); err != nil {
+			return err
+		}
+	}
+
+	var actual int64
+	for _, f := range m.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := r.countRecords(ctx, compressor, codec, f.Path)
+		if err != nil {
+			return fmt.Errorf("lode: audit data file %s: %w", f.Path, err)
+		}
+		actual += n
+	}
+	if actual != m.RowCount {
+		return &RowCountMismatchError{Dataset: dataset, SnapshotID: m.SnapshotID, Recorded: m.RowCount, Actual: actual}
+	}
+	return nil
+}
+
+// countRecords counts the records of one data file. A nil codec marks a
+// raw blob, which is one record.
+func (r *reader) countRecords(ctx context.Context, compressor Compressor, codec Codec, filePath string) (int64, error) {
+	if codec == nil {
+		exists, err := r.store.Exists(ctx, filePath)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, ErrNotFound
+		}
+		return 1, nil
+	}
+
+	rc, err := r.store.Get(ctx, filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = rc.Close() }()
+	decompReader, err := compressor.Decompress(rc)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = decompReader.Close() }()
+
+	var it RecordIterator
+	if streaming, ok := codec.(StreamingDecodeCodec); ok {
+		if it, err = streaming.NewStreamDecoder(decompReader); err != nil {
+			return 0, err
+		}
+	} else {
+		records, err := codec.Decode(decompReader)
+		if err != nil {
+			return 0, err
+		}
+		it = &sliceRecordIterator{records: records}
+	}
+
+	var n int64
+	for it.Next() {
+		n++
+	}
+	return n, it.Err()
+}
+
+// -----------------------------------------------------------------------------
+// Checksum Verification
+// -----------------------------------------------------------------------------
+
+// ChecksumMismatchError reports the data files of a segment whose stored
+// bytes do not match their manifest checksums. It matches ErrChecksumMismatch.
+type ChecksumMismatchError struct {
+	// Dataset and SnapshotID identify the verified segment.
+	Dataset    DatasetID
+	SnapshotID DatasetSnapshotID
+	// Mismatches lists each bad file, sorted by path.
+	Mismatches []ChecksumMismatch
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "lode: dataset %s snapshot %s: %d checksum mismatches:",
+		e.Dataset, e.SnapshotID, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		fmt.Fprintf(&b, " %s (manifest %s, stored %s);", m.Path, m.Expected, m.Actual)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+func (e *ChecksumMismatchError) Unwrap() error {
+	return ErrChecksumMismatch
+}
+
+// VerifyChecksums hashes files on a bounded pool of goroutines. Mismatches
+// are collected; the first store error cancels the remaining work. Files
+// without a recorded checksum are skipped.
+func (r *reader) VerifyChecksums(ctx context.Context, dataset DatasetID, ref ManifestRef, opts ChecksumVerifyOptions) error {
+	if opts.Concurrency < 0 {
+		return errors.New("lode: concurrency must be non-negative")
+	}
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultManifestConcurrency
+	}
+	m, err := r.GetManifest(ctx, dataset, ref)
+	if err != nil {
+		return err
+	}
+	if m.ChecksumAlgorithm == "" {
+		return fmt.Errorf("lode: snapshot %s does not record checksums", m.SnapshotID)
+	}
+	checksum := opts.Checksum
+	if checksum == nil {
+		if checksum, err = builtinChecksum(m.ChecksumAlgorithm); err != nil {
+			return err
+		}
+	} else if checksum.Name() != m.ChecksumAlgorithm {
+		return fmt.Errorf("lode: snapshot %s uses checksum %q, options provide %q",
+			m.SnapshotID, m.ChecksumAlgorithm, checksum.Name())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		firstErr   error
+		mismatches []ChecksumMismatch
+	)
+	sem := make(chan struct{}, concurrency)
+
+dispatch:
+	for _, f := range m.Files {
+		if f.Checksum == "" {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			actual, err := r.hashObject(ctx, checksum, f.Path)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				if firstErr == nil {
+					firstErr = fmt.Errorf("lode: verify checksum %s: %w", f.Path, err)
+					cancel()
+				}
+			case actual != f.Checksum:
+				mismatches = append(mismatches, ChecksumMismatch{Path: f.Path, Expected: f.Checksum, Actual: actual})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		slices.SortFunc(mismatches, func(a, b ChecksumMismatch) int { return strings.Compare(a.Path, b.Path) })
+		return &ChecksumMismatchError{Dataset: dataset, SnapshotID: m.SnapshotID, Mismatches: mismatches}
+	}
+	return nil
+}
+
+// hashObject streams one stored object through a fresh hasher, checking
+// ctx between reads so cancellation stops large files promptly.
+func (r *reader) hashObject(ctx context.Context, checksum Checksum, filePath string) (string, error) {
+	rc, err := r.store.Get(ctx, filePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rc.Close() }()
+
+	hasher := checksum.NewHasher()
+	buf := make([]byte, 32*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		n, err := rc.Read(buf)
+		if n > 0 {
+			_, _ = hasher.Write(buf[:n])
+		}
+		if err == io.EOF {
+			return hasher.Sum(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Strict Component Validation
+// -----------------------------------------------------------------------------
+
+// builtinPartitioners lists the partitioner names written by the built-in
+// layouts. PartitionFunc layouts record a caller-chosen name.
+var builtinPartitioners = []string{"noop", "hive", "time-range"}
+
+// UnknownComponentError reports a manifest component name that a strict
+// reader cannot resolve. It matches ErrUnknownComponent, and ErrUnknownCodec
+// when the component is the codec.
+type UnknownComponentError struct {
+	// Component is "codec", "compressor", or "partitioner".
+	Component string
+	// Name is the name recorded in the manifest.
+	Name string
+	// SnapshotID is the snapshot whose manifest names the component.
+	SnapshotID DatasetSnapshotID
+}
+
+func (e *UnknownComponentError) Error() string {
+	return fmt.Sprintf("lode: snapshot %s: unknown %s %q", e.SnapshotID, e.Component, e.Name)
+}
+
+func (e *UnknownComponentError) Unwrap() error {
+	return ErrUnknownComponent
+}
+
+func (e *UnknownComponentError) Is(target error) bool {
+	return target == ErrUnknownCodec && e.Component == "codec"
+}
+
+// checkComponents rejects manifests whose codec, compressor, or partitioner
+// this reader could not resolve. It is a no-op unless strict mode is on.
+// An omitted codec (raw blob snapshot) is always accepted.
+func (r *reader) checkComponents(m *Manifest) error {
+	if r.strict == nil {
+		return nil
+	}
+	if m.Codec != "" && !r.strict.has(m.Codec) {
+		return &UnknownComponentError{Component: "codec", Name: m.Codec, SnapshotID: m.SnapshotID}
+	}
+	if _, err := builtinCompressor(m.Compressor); err != nil {
+		return &UnknownComponentError{Component: "compressor", Name: m.Compressor, SnapshotID: m.SnapshotID}
+	}
+	if !slices.Contains(builtinPartitioners, m.Partitioner) && m.Partitioner != r.layout.partitioner().name() {
+		return &UnknownComponentError{Component: "partitioner", Name: m.Partitioner, SnapshotID: m.SnapshotID}
+	}
+	return nil
+}
+
+// strictComponentsOption implements Option for WithStrictComponents (reader-only).
+type strictComponentsOption struct {
+	codecs *CodecRegistry
+}
+
+// WithStrictComponents makes GetManifest and GetManifests reject manifests
+// naming a codec, compressor, or partitioner the reader cannot resolve.
+// Default: none (any non-empty name is accepted, so metadata-only tooling
+// can read manifests of formats it cannot decode).
+// This option is only valid for NewDatasetReader.
+//
+// Codec names are checked against codecs; pass NewCodecRegistry() for the
+// built-in codecs and register any others. Compressor names must be
+// built-in. Partitioner names must be built-in or match the configured
+// layout. Failures return an *UnknownComponentError.
+func WithStrictComponents(codecs *CodecRegistry) Option {
+	return &strictComponentsOption{codecs: codecs}
+}
+
+func (o *strictComponentsOption) applyDataset(*datasetConfig) error {
+	return fmt.Errorf("WithStrictComponents: %w", ErrOptionNotValidForDataset)
+}
+
+func (o *strictComponentsOption) applyReader(cfg *readerConfig) error {
+	if o.codecs == nil {
+		return errors.New("WithStrictComponents: registry must not be nil")
+	}
+	cfg.strictCodecs = o.codecs
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // Manifest Validation
 // -----------------------------------------------------------------------------
@@ -293,6 +1389,42 @@ func (e *manifestValidationError) Unwrap() error {
 	return ErrManifestInvalid
 }
 
+// checkFormatVersion accepts manifest format versions up to and including
+// supported, which is the version this library writes. Versions are
+// MAJOR.MINOR.PATCH; older versions are read as-is. An empty version is
+// left to the caller's required-field check.
+func checkFormatVersion(version, supported string) error {
+	if version == "" {
+		return nil
+	}
+	v, ok := parseFormatVersion(version)
+	if !ok {
+		return &manifestValidationError{Field: "format_version", Message: fmt.Sprintf("%q is not MAJOR.MINOR.PATCH", version)}
+	}
+	newest, _ := parseFormatVersion(supported)
+	if slices.Compare(v[:], newest[:]) > 0 {
+		return fmt.Errorf("%w: %s (newest supported is %s)", ErrUnsupportedFormatVersion, version, supported)
+	}
+	return nil
+}
+
+// parseFormatVersion parses a MAJOR.MINOR.PATCH version.
+func parseFormatVersion(version string) ([3]int, bool) {
+	var v [3]int
+	parts := strings.Split(version, ".")
+	if len(parts) != len(v) {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
 // validateManifest checks that a manifest contains all required fields
 // per CONTRACT_CORE.md and CONTRACT_READ_API.md.
 func validateManifest(m *Manifest) error {
@@ -306,6 +1438,9 @@ func validateManifest(m *Manifest) error {
 	if m.FormatVersion == "" {
 		return &manifestValidationError{Field: "format_version", Message: "is required"}
 	}
+	if err := checkFormatVersion(m.FormatVersion, manifestFormatVersion); err != nil {
+		return err
+	}
 	if m.DatasetID == "" {
 		return &manifestValidationError{Field: "dataset_id", Message: "is required"}
 	}
@@ -331,14 +1466,17 @@ func validateManifest(m *Manifest) error {
 	if m.Partitioner == "" {
 		return &manifestValidationError{Field: "partitioner", Message: "is required"}
 	}
+	if m.OrderKey != "" && !m.Ordered {
+		return &manifestValidationError{Field: "order_key", Message: "requires ordered"}
+	}
 
+	if err := checkFilePaths(m); err != nil {
+		return err
+	}
+	if err := checkFileChecksums(m); err != nil {
+		return err
+	}
 	for i, f := range m.Files {
-		if f.Path == "" {
-			return &manifestValidationError{
-				Field:   fmt.Sprintf("files[%d].path", i),
-				Message: "is required",
-			}
-		}
 		if f.SizeBytes < 0 {
 			return &manifestValidationError{
 				Field:   fmt.Sprintf("files[%d].size_bytes", i),
@@ -347,5 +1485,100 @@ func validateManifest(m *Manifest) error {
 		}
 	}
 
+	return validatePartitionSummaries(m)
+}
+
+// checkFilePaths checks that every FileRef path is a clean, relative store
+// key, so a manifest cannot direct reads outside the store root (for
+// example "../../etc/passwd" or "/etc/passwd"). Files are not required to
+// lie under the manifest's own segment: WithDedup references files stored
+// by earlier snapshots. Paths must be unique; a file listed twice would be
+// read twice.
+func checkFilePaths(m *Manifest) error {
+	seen := make(map[string]int, len(m.Files))
+	for i, f := range m.Files {
+		var msg string
+		first, dup := seen[f.Path]
+		switch {
+		case f.Path == "":
+			msg = "is required"
+		case strings.HasPrefix(f.Path, "/"):
+			msg = fmt.Sprintf("%q must be relative", f.Path)
+		case slices.Contains(strings.Split(f.Path, "/"), ".."):
+			msg = fmt.Sprintf("%q must not contain .. segments", f.Path)
+		case path.Clean(f.Path) != f.Path || f.Path == ".":
+			msg = fmt.Sprintf("%q is not a clean path", f.Path)
+		case dup:
+			msg = fmt.Sprintf("duplicates files[%d] path %q", first, f.Path)
+		default:
+			seen[f.Path] = i
+			continue
+		}
+		return &manifestValidationError{Field: fmt.Sprintf("files[%d].path", i), Message: msg}
+	}
+	return nil
+}
+
+// checkFileChecksums checks that file checksums agree with the manifest's
+// declared checksum_algorithm: a checksum requires a declared algorithm, and
+// digests of built-in algorithms must use that algorithm's encoding. Files
+// without a checksum are allowed.
+func checkFileChecksums(m *Manifest) error {
+	for i, f := range m.Files {
+		if f.Checksum == "" {
+			continue
+		}
+		field := fmt.Sprintf("files[%d].checksum", i)
+		if m.ChecksumAlgorithm == "" {
+			return &manifestValidationError{Field: field, Message: "is set but checksum_algorithm is not"}
+		}
+		if err := checkDigestEncoding(m.ChecksumAlgorithm, f.Checksum); err != nil {
+			return &manifestValidationError{Field: field, Message: fmt.Sprintf("%q %v", f.Checksum, err)}
+		}
+	}
+	return nil
+}
+
+// validatePartitionSummaries checks that an optional Partitions summary is
+// consistent with the manifest totals.
+func validatePartitionSummaries(m *Manifest) error {
+	if len(m.Partitions) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(m.Partitions))
+	var rows, size int64
+	for i, p := range m.Partitions {
+		if seen[p.Path] {
+			return &manifestValidationError{
+				Field:   fmt.Sprintf("partitions[%d].path", i),
+				Message: fmt.Sprintf("duplicates partition %q", p.Path),
+			}
+		}
+		seen[p.Path] = true
+		if p.RowCount < 0 || p.SizeBytes < 0 {
+			return &manifestValidationError{
+				Field:   fmt.Sprintf("partitions[%d]", i),
+				Message: "row_count and size_bytes must be non-negative",
+			}
+		}
+		rows += p.RowCount
+		size += p.SizeBytes
+	}
+	if rows != m.RowCount {
+		return &manifestValidationError{
+			Field:   "partitions",
+			Message: fmt.Sprintf("row counts sum to %d, manifest row_count is %d", rows, m.RowCount),
+		}
+	}
+	var fileBytes int64
+	for _, f := range m.Files {
+		fileBytes += f.SizeBytes
+	}
+	if size != fileBytes {
+		return &manifestValidationError{
+			Field:   "partitions",
+			Message: fmt.Sprintf("sizes sum to %d, files total %d bytes", size, fileBytes),
+		}
+	}
 	return nil
 }
@@ -5,8 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -341,6 +348,229 @@ func TestDatasetReader_GetManifest_InvalidManifest_EmptyFilePath(t *testing.T) {
 	}
 }
 
+func TestDatasetReader_GetManifest_InvalidManifest_UnsafeFilePath(t *testing.T) {
+	ctx := t.Context()
+
+	for _, p := range []string{
+		"../../etc/passwd",
+		"datasets/test-ds/../../etc/passwd",
+		"datasets/test-ds/snapshots/snap-1/data/..",
+		"/etc/passwd",
+		"datasets//test-ds/data.jsonl",
+		"./datasets/test-ds/data.jsonl",
+		".",
+	} {
+		t.Run(p, func(t *testing.T) {
+			store := NewMemory()
+			manifest := &Manifest{
+				SchemaName:    "lode-manifest",
+				FormatVersion: "1.0.0",
+				DatasetID:     "test-ds",
+				SnapshotID:    "snap-1",
+				CreatedAt:     time.Now().UTC(),
+				Metadata:      Metadata{},
+				Files:         []FileRef{{Path: "datasets/test-ds/snapshots/snap-1/data/ok.jsonl"}, {Path: p}},
+				Compressor:    "noop",
+				Partitioner:   "noop",
+			}
+			writeManifest(ctx, t, store, manifest)
+
+			reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = reader.GetManifest(ctx, "test-ds", ManifestRef{ID: "snap-1"})
+			if !errors.Is(err, ErrManifestInvalid) || !strings.Contains(err.Error(), "files[1].path") {
+				t.Errorf("expected ErrManifestInvalid on files[1].path, got: %v", err)
+			}
+
+			// Dataset reads refuse the manifest before fetching any file.
+			ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ds.Read(ctx, "snap-1"); !errors.Is(err, ErrManifestInvalid) {
+				t.Errorf("Read: expected ErrManifestInvalid, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDatasetReader_GetManifest_InvalidManifest_DuplicateFilePath(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	const a = "datasets/test-ds/snapshots/snap-1/data/a.jsonl"
+	manifest := &Manifest{
+		SchemaName:    "lode-manifest",
+		FormatVersion: "1.0.0",
+		DatasetID:     "test-ds",
+		SnapshotID:    "snap-1",
+		CreatedAt:     time.Now().UTC(),
+		Metadata:      Metadata{},
+		Files: []FileRef{
+			{Path: a},
+			{Path: "datasets/test-ds/snapshots/snap-1/data/b.jsonl"},
+			{Path: a},
+		},
+		Compressor:  "noop",
+		Partitioner: "noop",
+	}
+	writeManifest(ctx, t, store, manifest)
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = reader.GetManifest(ctx, "test-ds", ManifestRef{ID: "snap-1"})
+	var verr *manifestValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrManifestInvalid) {
+		t.Fatalf("expected manifest validation error, got: %v", err)
+	}
+	if verr.Field != "files[2].path" || !strings.Contains(verr.Message, "files[0]") {
+		t.Errorf("got %s: %s; want files[2].path duplicating files[0]", verr.Field, verr.Message)
+	}
+}
+
+func TestDatasetReader_GetManifest_InvalidManifest_ChecksumAlgorithm(t *testing.T) {
+	ctx := t.Context()
+	const filePath = "datasets/test-ds/snapshots/snap-1/data/a.jsonl"
+
+	tests := []struct {
+		name      string
+		algorithm string
+		checksum  string
+		valid     bool
+	}{
+		{"md5", "md5", "5d41402abc4b2a76b9719d911017c592", true},
+		{"crc32c", "crc32c", "yZRlqg==", true},
+		{"custom algorithm unchecked", "sha256", "anything", true},
+		{"no checksum", "md5", "", true},
+		{"checksum without algorithm", "", "5d41402abc4b2a76b9719d911017c592", false},
+		{"legacy prefixed md5", "md5", "md5:5d41402abc4b2a76b9719d911017c592", false},
+		{"uppercase md5", "md5", "5D41402ABC4B2A76B9719D911017C592", false},
+		{"crc32c digest under md5", "md5", "yZRlqg==", false},
+		{"md5 digest under crc32c", "crc32c", "5d41402abc4b2a76b9719d911017c592", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemory()
+			writeManifest(ctx, t, store, &Manifest{
+				SchemaName:        "lode-manifest",
+				FormatVersion:     "1.0.0",
+				DatasetID:         "test-ds",
+				SnapshotID:        "snap-1",
+				CreatedAt:         time.Now().UTC(),
+				Metadata:          Metadata{},
+				Files:             []FileRef{{Path: filePath, Checksum: tt.checksum}},
+				Compressor:        "noop",
+				Partitioner:       "noop",
+				ChecksumAlgorithm: tt.algorithm,
+			})
+
+			reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = reader.GetManifest(ctx, "test-ds", ManifestRef{ID: "snap-1"})
+			if tt.valid {
+				if err != nil {
+					t.Errorf("expected valid manifest, got: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrManifestInvalid) || !strings.Contains(err.Error(), "files[0].checksum") {
+				t.Errorf("expected ErrManifestInvalid on files[0].checksum, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDatasetReader_GetManifest_StrictComponents(t *testing.T) {
+	ctx := t.Context()
+	funcLayout, err := NewPartitionFuncLayout("shard", func(any) (string, error) { return "shard=0", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		codec       string
+		compressor  string
+		partitioner string
+		layout      layout
+		unknown     string // component rejected in strict mode; empty when accepted
+	}{
+		{"built-in components", "jsonl", "gzip", "hive", NewDefaultLayout(), ""},
+		{"raw blob", "", "lz4", "noop", NewDefaultLayout(), ""},
+		{"unknown codec", "avro", "gzip", "noop", NewDefaultLayout(), "codec"},
+		{"unknown compressor", "jsonl", "snappy", "noop", NewDefaultLayout(), "compressor"},
+		{"unknown partitioner", "jsonl", "noop", "shard", NewDefaultLayout(), "partitioner"},
+		{"partitioner of configured layout", "jsonl", "noop", "shard", funcLayout, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemory()
+			data, err := json.Marshal(&Manifest{
+				SchemaName:    "lode-manifest",
+				FormatVersion: "1.0.0",
+				DatasetID:     "test-ds",
+				SnapshotID:    "snap-1",
+				CreatedAt:     time.Now().UTC(),
+				Metadata:      Metadata{},
+				Files:         []FileRef{},
+				Codec:         tt.codec,
+				Compressor:    tt.compressor,
+				Partitioner:   tt.partitioner,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Put(ctx, tt.layout.manifestPathInPartition("test-ds", "snap-1", ""), bytes.NewReader(data)); err != nil {
+				t.Fatal(err)
+			}
+			ref := ManifestRef{ID: "snap-1"}
+
+			lenient, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithLayout(tt.layout))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := lenient.GetManifest(ctx, "test-ds", ref); err != nil {
+				t.Errorf("lenient reader: expected manifest to load, got: %v", err)
+			}
+
+			strict, err := NewDatasetReader(NewMemoryFactoryFrom(store),
+				WithLayout(tt.layout), WithStrictComponents(NewCodecRegistry()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = strict.GetManifest(ctx, "test-ds", ref)
+			if tt.unknown == "" {
+				if err != nil {
+					t.Errorf("strict reader: expected manifest to load, got: %v", err)
+				}
+				return
+			}
+			var compErr *UnknownComponentError
+			if !errors.As(err, &compErr) || compErr.Component != tt.unknown || compErr.SnapshotID != "snap-1" {
+				t.Fatalf("strict reader: expected UnknownComponentError for %s, got: %v", tt.unknown, err)
+			}
+			if !errors.Is(err, ErrUnknownComponent) {
+				t.Errorf("expected ErrUnknownComponent, got: %v", err)
+			}
+			if got := errors.Is(err, ErrUnknownCodec); got != (tt.unknown == "codec") {
+				t.Errorf("errors.Is(err, ErrUnknownCodec) = %v for unknown %s", got, tt.unknown)
+			}
+		})
+	}
+
+	if _, err := NewDataset("test-ds", NewMemoryFactory(), WithStrictComponents(NewCodecRegistry())); !errors.Is(err, ErrOptionNotValidForDataset) {
+		t.Errorf("expected ErrOptionNotValidForDataset, got: %v", err)
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithStrictComponents(nil)); err == nil {
+		t.Error("expected error for nil registry")
+	}
+}
+
 func TestDatasetReader_GetManifest_InvalidManifest_NegativeFileSize(t *testing.T) {
 	ctx := t.Context()
 	store := NewMemory()
@@ -372,6 +602,115 @@ func TestDatasetReader_GetManifest_InvalidManifest_NegativeFileSize(t *testing.T
 	}
 }
 
+func TestDatasetReader_GetManifest_PartitionSummaries(t *testing.T) {
+	files := []FileRef{
+		{Path: "datasets/test-ds/partitions/day=a/segments/snap-1/data/data.jsonl", SizeBytes: 10},
+		{Path: "datasets/test-ds/partitions/day=b/segments/snap-1/data/data.jsonl", SizeBytes: 5},
+	}
+	tests := map[string]struct {
+		partitions []PartitionSummary
+		valid      bool
+	}{
+		"absent":     {partitions: nil, valid: true},
+		"consistent": {partitions: []PartitionSummary{{Path: "day=a", RowCount: 2, SizeBytes: 10}, {Path: "day=b", RowCount: 1, SizeBytes: 5}}, valid: true},
+		"row sum":    {partitions: []PartitionSummary{{Path: "day=a", RowCount: 2, SizeBytes: 10}, {Path: "day=b", RowCount: 2, SizeBytes: 5}}},
+		"size sum":   {partitions: []PartitionSummary{{Path: "day=a", RowCount: 2, SizeBytes: 10}, {Path: "day=b", RowCount: 1, SizeBytes: 6}}},
+		"duplicate":  {partitions: []PartitionSummary{{Path: "day=a", RowCount: 2, SizeBytes: 10}, {Path: "day=a", RowCount: 1, SizeBytes: 5}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := NewMemory()
+			writeManifest(t.Context(), t, store, &Manifest{
+				SchemaName:    "lode-manifest",
+				FormatVersion: "1.0.0",
+				DatasetID:     "test-ds",
+				SnapshotID:    "snap-1",
+				CreatedAt:     time.Now().UTC(),
+				Metadata:      Metadata{},
+				Files:         files,
+				RowCount:      3,
+				Compressor:    "noop",
+				Partitioner:   "hive",
+				Partitions:    tt.partitions,
+			})
+			reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = reader.GetManifest(t.Context(), "test-ds", ManifestRef{ID: "snap-1"})
+			if tt.valid && err != nil {
+				t.Errorf("expected valid manifest, got: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrManifestInvalid) {
+				t.Errorf("expected ErrManifestInvalid, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDatasetReader_GetManifest_FormatVersion(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		version string
+		wantErr error
+	}{
+		{version: manifestFormatVersion},
+		{version: "0.9.0"},
+		{version: "1.0.1", wantErr: ErrUnsupportedFormatVersion},
+		{version: "1.1.0", wantErr: ErrUnsupportedFormatVersion},
+		{version: "2.0.0", wantErr: ErrUnsupportedFormatVersion},
+		{version: "1.0", wantErr: ErrManifestInvalid},
+		{version: "v1.0.0", wantErr: ErrManifestInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			store := NewMemory()
+			writeManifest(ctx, t, store, &Manifest{
+				SchemaName:    "lode-manifest",
+				FormatVersion: tt.version,
+				DatasetID:     "test-ds",
+				SnapshotID:    "snap-1",
+				CreatedAt:     time.Now().UTC(),
+				Metadata:      Metadata{},
+				Files:         []FileRef{},
+				Compressor:    "noop",
+				Partitioner:   "noop",
+			})
+			reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = reader.GetManifest(ctx, "test-ds", ManifestRef{ID: "snap-1"})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("expected version %s to be accepted, got: %v", tt.version, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got: %v", tt.wantErr, err)
+			}
+			if !strings.Contains(err.Error(), tt.version) {
+				t.Errorf("error should name version %s: %v", tt.version, err)
+			}
+			if tt.wantErr == ErrUnsupportedFormatVersion && errors.Is(err, ErrManifestInvalid) {
+				t.Errorf("unsupported version must not match ErrManifestInvalid: %v", err)
+			}
+
+			// Dataset reads apply the same check.
+			ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ds.Snapshot(ctx, "snap-1"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Snapshot: expected %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestDatasetReader_ListManifests_InvalidManifest_WithPartitionFilter_ReturnsError(t *testing.T) {
 	ctx := t.Context()
 	store := NewMemory()
@@ -460,6 +799,74 @@ func TestDatasetReader_ListManifests_HiveLayout_SkipsCanonicalManifest(t *testin
 	}
 }
 
+func TestDatasetReader_ListManifests_MetadataMatch(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := make(map[DatasetSnapshotID]bool)
+	for i, md := range []Metadata{
+		{"source": "etl-v2", "env": "prod"},
+		{"source": "etl-v1", "env": "prod"},
+		{"source": "etl-v2", "env": "dev"},
+		{"env": "prod"},
+		{"source": 2},
+	} {
+		snap, err := ds.Write(t.Context(), R(D{"id": i}), md)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if md["source"] == "etl-v2" {
+			want[snap.ID] = true
+		}
+	}
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := reader.ListManifests(t.Context(), "test-ds", "", ManifestListOptions{
+		MetadataMatch: map[string]string{"source": "etl-v2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != len(want) {
+		t.Fatalf("expected %d manifests, got %d", len(want), len(refs))
+	}
+	for _, ref := range refs {
+		if !want[ref.ID] {
+			t.Errorf("unexpected manifest %s", ref.ID)
+		}
+	}
+
+	// All pairs must match.
+	refs, err = reader.ListManifests(t.Context(), "test-ds", "", ManifestListOptions{
+		MetadataMatch: map[string]string{"source": "etl-v2", "env": "prod"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 {
+		t.Errorf("expected 1 manifest matching both pairs, got %d", len(refs))
+	}
+
+	// Limit counts only matching manifests.
+	refs, err = reader.ListManifests(t.Context(), "test-ds", "", ManifestListOptions{
+		Limit:         1,
+		MetadataMatch: map[string]string{"env": "dev"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 {
+		t.Errorf("expected 1 manifest with Limit, got %d", len(refs))
+	}
+}
+
 // -----------------------------------------------------------------------------
 // G3: ErrNoManifests test
 // -----------------------------------------------------------------------------
@@ -535,130 +942,1564 @@ func TestDatasetReader_ListDatasets_WithValidManifest(t *testing.T) {
 	}
 }
 
-// -----------------------------------------------------------------------------
-// G4: Layout-specific tests
-// -----------------------------------------------------------------------------
+func TestDatasetReader_ListDatasets_PrefixAndPagination(t *testing.T) {
+	tmpDir := t.TempDir()
+	fs := newFaultStore(NewMemory())
+	for name, factory := range map[string]StoreFactory{
+		"memory": newFaultStoreFactory(fs),
+		"fs":     NewFSFactory(tmpDir),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Several snapshots per dataset: results must be deduplicated.
+			for _, id := range []DatasetID{"tenant-c", "tenant-a", "other", "tenant-b", "tenant-d"} {
+				ds, err := NewDataset(id, factory, WithCodec(NewJSONLCodec()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for i := range 2 {
+					if _, err := ds.Write(t.Context(), R(D{"i": i}), Metadata{}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			reader, err := NewDatasetReader(factory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fs.Reset()
+
+			var pages [][]DatasetID
+			token := ""
+			for {
+				page, err := reader.ListDatasets(t.Context(), DatasetListOptions{
+					Prefix:    "tenant-",
+					Limit:     3,
+					PageToken: token,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(page) == 0 {
+					break
+				}
+				pages = append(pages, page)
+				token = string(page[len(page)-1])
+			}
+
+			want := [][]DatasetID{{"tenant-a", "tenant-b", "tenant-c"}, {"tenant-d"}}
+			if !reflect.DeepEqual(pages, want) {
+				t.Errorf("pages = %v, want %v", pages, want)
+			}
+			if name == "memory" {
+				for _, prefix := range fs.ListCalls() {
+					if prefix != "datasets/tenant-" {
+						t.Errorf("expected List with dataset prefix, got %q", prefix)
+					}
+				}
+			}
+		})
+	}
+}
 
-func TestDatasetReader_ListDatasets_FlatLayout_ReturnsErrDatasetsNotModeled(t *testing.T) {
-	ctx := t.Context()
-	store := NewMemory()
+// delimitedStore is a mock DelimitedLister that records its calls.
+type delimitedStore struct {
+	Store
+	prefixCalls []string
+	listCalls   []string
+}
 
-	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithLayout(NewFlatLayout()))
+func (s *delimitedStore) List(ctx context.Context, prefix string) ([]string, error) {
+	s.listCalls = append(s.listCalls, prefix)
+	return s.Store.List(ctx, prefix)
+}
+
+func (s *delimitedStore) ListPrefixes(ctx context.Context, prefix, delimiter string) ([]string, []string, error) {
+	s.prefixCalls = append(s.prefixCalls, prefix+" "+delimiter)
+	return ListPrefixes(ctx, s.Store, prefix, delimiter)
+}
+
+func TestDatasetReader_ListDatasets_DelimitedLister(t *testing.T) {
+	ctx := t.Context()
+	inner := NewMemory()
+	for _, id := range []DatasetID{"tenant-b", "tenant-a", "other"} {
+		ds, err := NewDataset(id, NewMemoryFactoryFrom(inner), WithCodec(NewJSONLCodec()), WithHiveLayout("day"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, day := range []string{"1", "2"} {
+			if _, err := ds.Write(ctx, R(D{"day": day}), Metadata{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	store := &delimitedStore{Store: inner}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithHiveLayout("day"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	_, err = reader.ListDatasets(ctx, DatasetListOptions{})
-	if !errors.Is(err, ErrDatasetsNotModeled) {
-		t.Errorf("expected ErrDatasetsNotModeled, got: %v", err)
+	all, err := reader.ListDatasets(ctx, DatasetListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []DatasetID{"other", "tenant-a", "tenant-b"}; !slices.Equal(all, want) {
+		t.Errorf("ListDatasets = %v, want %v", all, want)
+	}
+	// Each directory is confirmed by listing one level of its snapshot
+	// directories, never by listing the dataset's keys.
+	if want := []string{"datasets/ /", "datasets/other/segments/ /", "datasets/tenant-a/segments/ /", "datasets/tenant-b/segments/ /"}; !slices.Equal(store.prefixCalls, want) {
+		t.Errorf("ListPrefixes calls = %q, want %q", store.prefixCalls, want)
+	}
+	if len(store.listCalls) != 0 {
+		t.Errorf("List calls = %q, want none", store.listCalls)
 	}
-}
-
-func TestDatasetReader_ListDatasets_FSStore_EmptyStorage_ReturnsEmptyList(t *testing.T) {
-	ctx := t.Context()
 
-	tmpDir, err := os.MkdirTemp("", "lode-test-*")
+	store.prefixCalls = nil
+	page, err := reader.ListDatasets(ctx, DatasetListOptions{Prefix: "tenant-", PageToken: "tenant-a", Limit: 1})
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer testutil.RemoveAll(tmpDir)
+	if want := []DatasetID{"tenant-b"}; !slices.Equal(page, want) {
+		t.Errorf("paged ListDatasets = %v, want %v", page, want)
+	}
+	if want := []string{"datasets/tenant- /", "datasets/tenant-b/segments/ /"}; !slices.Equal(store.prefixCalls, want) {
+		t.Errorf("paged ListPrefixes calls = %q, want %q", store.prefixCalls, want)
+	}
 
-	reader, err := NewDatasetReader(NewFSFactory(tmpDir))
+	// A directory without a manifest is not a dataset, as with a flat
+	// listing.
+	if err := inner.Put(ctx, "datasets/orphan/segments/x/data.jsonl", strings.NewReader("x")); err != nil {
+		t.Fatal(err)
+	}
+	delimited, err := reader.ListDatasets(ctx, DatasetListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	flatReader, err := NewDatasetReader(NewMemoryFactoryFrom(inner), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat, err := flatReader.ListDatasets(ctx, DatasetListOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if !slices.Equal(delimited, flat) || slices.Contains(delimited, "orphan") {
+		t.Errorf("delimited ListDatasets = %v, flat = %v", delimited, flat)
+	}
 
-	datasets, err := reader.ListDatasets(ctx, DatasetListOptions{})
+	// Objects directly under the datasets prefix, or in directories without
+	// a manifest, are not datasets.
+	for _, key := range []string{"datasets/README", "datasets/orphan/snapshots/x/data.jsonl"} {
+		loose := &delimitedStore{Store: NewMemory()}
+		if err := loose.Put(ctx, key, strings.NewReader("x")); err != nil {
+			t.Fatal(err)
+		}
+		reader, err = NewDatasetReader(NewMemoryFactoryFrom(loose))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := reader.ListDatasets(ctx, DatasetListOptions{}); !errors.Is(err, ErrNoManifests) {
+			t.Errorf("%s: expected ErrNoManifests, got: %v", key, err)
+		}
+	}
+
+	// Past the last page, earlier datasets still count as manifests.
+	reader, err = NewDatasetReader(NewMemoryFactoryFrom(store), WithHiveLayout("day"))
 	if err != nil {
-		t.Fatalf("expected no error for empty FS storage, got: %v", err)
+		t.Fatal(err)
 	}
-	if len(datasets) != 0 {
-		t.Errorf("expected empty list, got: %v", datasets)
+	if got, err := reader.ListDatasets(ctx, DatasetListOptions{PageToken: "tenant-b"}); err != nil || len(got) != 0 {
+		t.Errorf("ListDatasets past last page = %v, %v; want empty", got, err)
 	}
 }
 
-// -----------------------------------------------------------------------------
-// G5: Complexity verification tests
-// -----------------------------------------------------------------------------
+// shuffledStore returns List results in reverse order to expose reliance on
+// store listing order.
+type shuffledStore struct {
+	Store
+}
 
-// TestDatasetReader_ListPartitions_SingleManifestLoad verifies that ListPartitions
-// loads each manifest exactly once (M Gets), not twice (2M Gets via ListManifests + GetManifest).
-func TestDatasetReader_ListPartitions_SingleManifestLoad(t *testing.T) {
-	inner := NewMemory()
-	fs := newFaultStore(inner)
-	factory := newFaultStoreFactory(fs)
+func (s shuffledStore) List(ctx context.Context, prefix string) ([]string, error) {
+	paths, err := s.Store.List(ctx, prefix)
+	slices.Sort(paths)
+	slices.Reverse(paths)
+	return paths, err
+}
 
-	// Write two snapshots with hive-partitioned data.
-	ds, err := NewDataset("test-ds", factory,
-		WithCodec(NewJSONLCodec()),
-		WithHiveLayout("day"))
+func TestDatasetReader_ListSorted_IgnoresStoreOrder(t *testing.T) {
+	mem := NewMemory()
+	factory := func() (Store, error) { return shuffledStore{mem}, nil }
+
+	var ids []DatasetSnapshotID
+	for _, dataset := range []DatasetID{"b", "c", "a"} {
+		ds, err := NewDataset(dataset, factory, WithCodec(NewJSONLCodec()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range 3 {
+			snap, err := ds.Write(t.Context(), R(D{"i": i}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dataset == "a" {
+				ids = append(ids, snap.ID)
+			}
+		}
+	}
+
+	reader, err := NewDatasetReader(factory)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	records1 := R(D{"id": 1, "day": "2024-01-15"})
-	if _, err := ds.Write(t.Context(), records1, Metadata{}); err != nil {
+	datasets, err := reader.ListDatasets(t.Context(), DatasetListOptions{})
+	if err != nil {
 		t.Fatal(err)
 	}
+	if !slices.Equal(datasets, []DatasetID{"a", "b", "c"}) {
+		t.Errorf("ListDatasets = %v, want sorted", datasets)
+	}
 
-	records2 := R(D{"id": 2, "day": "2024-01-16"})
-	if _, err := ds.Write(t.Context(), records2, Metadata{}); err != nil {
+	refs, err := reader.ListManifests(t.Context(), "a", "", ManifestListOptions{Sort: true, Limit: 2})
+	if err != nil {
 		t.Fatal(err)
 	}
+	slices.Sort(ids)
+	if len(refs) != 2 || refs[0].ID != ids[0] || refs[1].ID != ids[1] {
+		t.Errorf("ListManifests(Sort) = %v, want first two of %v", refs, ids)
+	}
 
-	reader, err := NewDatasetReader(factory, WithHiveLayout("day"))
+	unsorted, err := reader.ListManifests(t.Context(), "a", "", ManifestListOptions{})
 	if err != nil {
 		t.Fatal(err)
 	}
+	if len(unsorted) != 3 || unsorted[0].ID != ids[2] {
+		t.Errorf("ListManifests without Sort should follow store order, got %v", unsorted)
+	}
+}
 
-	// Reset call tracking before the operation under test.
-	fs.Reset()
+// markerStore adds directory-marker keys to List results, as some S3
+// consoles and sync tools leave behind.
+type markerStore struct {
+	Store
+}
 
-	partitions, err := reader.ListPartitions(t.Context(), "test-ds", PartitionListOptions{})
+func (s markerStore) List(ctx context.Context, prefix string) ([]string, error) {
+	paths, err := s.Store.List(ctx, prefix)
+	for _, marker := range []string{"datasets/", "datasets/foo/", "datasets/foo/snapshots/", "tenant/datasets/foo/"} {
+		if strings.HasPrefix(marker, prefix) {
+			paths = append(paths, marker)
+		}
+	}
+	return paths, err
+}
+
+func TestDatasetReader_IgnoresDirectoryMarkers(t *testing.T) {
+	mem := NewMemory()
+	factory := func() (Store, error) { return markerStore{mem}, nil }
+	ds, err := NewDataset("foo", factory, WithCodec(NewJSONLCodec()))
 	if err != nil {
-		t.Fatalf("ListPartitions failed: %v", err)
+		t.Fatal(err)
 	}
-	if len(partitions) == 0 {
-		t.Fatal("expected at least one partition")
+	snap, err := ds.Write(t.Context(), R(D{"n": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	// Count manifest Get calls (paths ending in manifest.json).
-	getCalls := fs.GetCalls()
-	manifestGets := 0
-	for _, p := range getCalls {
-		if strings.HasSuffix(p, "manifest.json") {
-			manifestGets++
-		}
+	reader, err := NewDatasetReader(factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasets, err := reader.ListDatasets(t.Context(), DatasetListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(datasets, []DatasetID{"foo"}) {
+		t.Errorf("ListDatasets = %v, want [foo]", datasets)
+	}
+	refs, err := reader.ListManifests(t.Context(), "foo", "", ManifestListOptions{})
+	if err != nil {
+		t.Fatal(err)
 	}
+	if len(refs) != 1 || refs[0].ID != snap.ID {
+		t.Errorf("ListManifests = %v, want [%s]", refs, snap.ID)
+	}
+	latest, err := ds.Latest(t.Context())
+	if err != nil || latest.ID != snap.ID {
+		t.Errorf("Latest = %v, %v; want %s", latest, err, snap.ID)
+	}
+}
 
-	// With single-pass, we expect exactly 2 Gets (one per snapshot manifest),
-	// not 4 (which the old ListManifests + GetManifest path would produce).
-	// There are 2 snapshots × 2 partition manifests each = 4 partition manifests,
-	// but deduplicated by snapshot ID = 2 manifests loaded.
-	if manifestGets != 2 {
-		t.Errorf("expected exactly 2 manifest Gets (single-pass), got %d", manifestGets)
+func TestDatasetReader_DatasetState(t *testing.T) {
+	ctx := t.Context()
+	for name, layout := range map[string]Option{
+		"default": WithLayout(NewDefaultLayout()),
+		"hive":    WithHiveLayout("day"),
+		"flat":    WithLayout(NewFlatLayout()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := NewMemory()
+			ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()), layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ds.Write(ctx, R(D{"day": "1"}), Metadata{}); err != nil {
+				t.Fatal(err)
+			}
+			// A stray object under a dataset's root, but no manifest.
+			stray := path.Join(path.Dir(ds.(*dataset).layout.latestPointerPath("pending")), "README")
+			if err := store.Put(ctx, stray, strings.NewReader("x")); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for dataset, want := range map[DatasetID]DatasetState{
+				"events":  DatasetCommitted,
+				"pending": DatasetEmpty,
+				"missing": DatasetAbsent,
+				"event":   DatasetAbsent, // prefix of "events"
+			} {
+				got, err := reader.DatasetState(ctx, dataset)
+				if err != nil {
+					t.Fatalf("DatasetState(%s) failed: %v", dataset, err)
+				}
+				if got != want {
+					t.Errorf("DatasetState(%s) = %q, want %q", dataset, got, want)
+				}
+			}
+		})
 	}
 }
 
-// -----------------------------------------------------------------------------
-// Test helpers
-// -----------------------------------------------------------------------------
+func TestDatasetReader_DatasetAndSnapshotExists(t *testing.T) {
+	ctx := t.Context()
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"day": "1"}, D{"day": "2"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Data objects without a manifest do not make a dataset exist.
+	if err := fs.Put(ctx, "datasets/orphan/segments/s1/data/data.jsonl", strings.NewReader("{}")); err != nil {
+		t.Fatal(err)
+	}
 
-// NewMemoryFactoryFrom creates a StoreFactory that returns an existing store.
-func NewMemoryFactoryFrom(store Store) StoreFactory {
-	return func() (Store, error) {
-		return store, nil
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for dataset, want := range map[DatasetID]bool{"events": true, "orphan": false, "missing": false} {
+		got, err := reader.DatasetExists(ctx, dataset)
+		if err != nil {
+			t.Fatalf("DatasetExists(%s) failed: %v", dataset, err)
+		}
+		if got != want {
+			t.Errorf("DatasetExists(%s) = %v, want %v", dataset, got, want)
+		}
+	}
+
+	fs.Reset()
+	exists, err := reader.SnapshotExists(ctx, "events", snap.ID)
+	if err != nil || !exists {
+		t.Errorf("SnapshotExists(committed) = %v, %v; want true", exists, err)
+	}
+	if exists, err := reader.SnapshotExists(ctx, "events", "missing"); err != nil || exists {
+		t.Errorf("SnapshotExists(missing) = %v, %v; want false", exists, err)
+	}
+	if calls := fs.ListCalls(); len(calls) != 0 {
+		t.Errorf("SnapshotExists must not list, got %v", calls)
+	}
+	if calls := fs.GetCalls(); len(calls) != 0 {
+		t.Errorf("SnapshotExists must not read manifests, got %v", calls)
+	}
+
+	if _, err := reader.SnapshotExists(ctx, "", snap.ID); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for empty dataset, got: %v", err)
 	}
 }
 
-func writeManifest(ctx context.Context, t *testing.T, store Store, m *Manifest) {
-	t.Helper()
-	data, err := json.Marshal(m)
+func TestDatasetReader_DiffSnapshots(t *testing.T) {
+	ctx := t.Context()
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs), WithCodec(NewJSONLCodec()))
 	if err != nil {
 		t.Fatal(err)
 	}
-	path := "datasets/" + string(m.DatasetID) + "/snapshots/" + string(m.SnapshotID) + "/manifest.json"
-	if err := store.Put(ctx, path, bytes.NewReader(data)); err != nil {
+	base, err := ds.Write(ctx, R(D{"id": 1}, D{"id": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appended, err := ds.Append(ctx, R(D{"id": 3}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs))
+	if err != nil {
 		t.Fatal(err)
 	}
+	fs.Reset()
+
+	diff, err := reader.DiffSnapshots(ctx, "events", ManifestRef{ID: base.ID}, ManifestRef{ID: appended.ID})
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	if !slices.Equal(diff.OnlyInA, []string{base.Manifest.Files[0].Path}) {
+		t.Errorf("OnlyInA = %v, want base file", diff.OnlyInA)
+	}
+	if !slices.Equal(diff.OnlyInB, []string{appended.Manifest.Files[0].Path}) {
+		t.Errorf("OnlyInB = %v, want appended file", diff.OnlyInB)
+	}
+	if len(diff.InBoth) != 0 {
+		t.Errorf("InBoth = %v, want empty", diff.InBoth)
+	}
+	if diff.RowCountDelta != -1 {
+		t.Errorf("RowCountDelta = %d, want -1", diff.RowCountDelta)
+	}
+	if want := appended.TotalBytes() - base.TotalBytes(); diff.BytesDelta != want {
+		t.Errorf("BytesDelta = %d, want %d", diff.BytesDelta, want)
+	}
+	for _, p := range fs.GetCalls() {
+		if !strings.HasSuffix(p, "manifest.json") {
+			t.Errorf("DiffSnapshots read data object %s", p)
+		}
+	}
+
+	self, err := reader.DiffSnapshots(ctx, "events", ManifestRef{ID: base.ID}, ManifestRef{ID: base.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(self.OnlyInA) != 0 || len(self.OnlyInB) != 0 || len(self.InBoth) != 1 || self.BytesDelta != 0 || self.RowCountDelta != 0 {
+		t.Errorf("self diff = %+v, want all files in both and zero deltas", self)
+	}
+
+	if _, err := reader.DiffSnapshots(ctx, "events", ManifestRef{ID: base.ID}, ManifestRef{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDatasetReader_SnapshotsSince(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []DatasetSnapshotID
+	for i := range 3 {
+		snap, err := ds.Write(t.Context(), R(D{"i": i}), Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, snap.ID)
+	}
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refIDs := func(since DatasetSnapshotID) []DatasetSnapshotID {
+		t.Helper()
+		refs, err := reader.SnapshotsSince(t.Context(), "events", since)
+		if err != nil {
+			t.Fatalf("SnapshotsSince(%q) failed: %v", since, err)
+		}
+		got := []DatasetSnapshotID{}
+		for _, ref := range refs {
+			got = append(got, ref.ID)
+		}
+		return got
+	}
+
+	if got := refIDs(ids[0]); !slices.Equal(got, ids[1:]) {
+		t.Errorf("SnapshotsSince(first) = %v, want %v", got, ids[1:])
+	}
+	if got := refIDs(ids[2]); len(got) != 0 {
+		t.Errorf("SnapshotsSince(latest) = %v, want empty", got)
+	}
+	if got := refIDs(""); !slices.Equal(got, ids) {
+		t.Errorf("SnapshotsSince(\"\") = %v, want %v", got, ids)
+	}
+
+	_, err = reader.SnapshotsSince(t.Context(), "events", "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown snapshot, got: %v", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// G4: Layout-specific tests
+// -----------------------------------------------------------------------------
+
+func TestDatasetReader_ListDatasets_FlatLayout_ReturnsErrDatasetsNotModeled(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithLayout(NewFlatLayout()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = reader.ListDatasets(ctx, DatasetListOptions{})
+	if !errors.Is(err, ErrDatasetsNotModeled) {
+		t.Errorf("expected ErrDatasetsNotModeled, got: %v", err)
+	}
+}
+
+func TestDatasetReader_ListDatasets_FSStore_EmptyStorage_ReturnsEmptyList(t *testing.T) {
+	ctx := t.Context()
+
+	tmpDir, err := os.MkdirTemp("", "lode-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testutil.RemoveAll(tmpDir)
+
+	reader, err := NewDatasetReader(NewFSFactory(tmpDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	datasets, err := reader.ListDatasets(ctx, DatasetListOptions{})
+	if err != nil {
+		t.Fatalf("expected no error for empty FS storage, got: %v", err)
+	}
+	if len(datasets) != 0 {
+		t.Errorf("expected empty list, got: %v", datasets)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// G5: Complexity verification tests
+// -----------------------------------------------------------------------------
+
+// TestDatasetReader_ListPartitions_SingleManifestLoad verifies that ListPartitions
+// loads each manifest exactly once (M Gets), not twice (2M Gets via ListManifests + GetManifest).
+func TestDatasetReader_ListPartitions_SingleManifestLoad(t *testing.T) {
+	inner := NewMemory()
+	fs := newFaultStore(inner)
+	factory := newFaultStoreFactory(fs)
+
+	// Write two snapshots with hive-partitioned data.
+	ds, err := NewDataset("test-ds", factory,
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records1 := R(D{"id": 1, "day": "2024-01-15"})
+	if _, err := ds.Write(t.Context(), records1, Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	records2 := R(D{"id": 2, "day": "2024-01-16"})
+	if _, err := ds.Write(t.Context(), records2, Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewDatasetReader(factory, WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reset call tracking before the operation under test.
+	fs.Reset()
+
+	partitions, err := reader.ListPartitions(t.Context(), "test-ds", PartitionListOptions{})
+	if err != nil {
+		t.Fatalf("ListPartitions failed: %v", err)
+	}
+	if len(partitions) == 0 {
+		t.Fatal("expected at least one partition")
+	}
+
+	// Count manifest Get calls (paths ending in manifest.json).
+	getCalls := fs.GetCalls()
+	manifestGets := 0
+	for _, p := range getCalls {
+		if strings.HasSuffix(p, "manifest.json") {
+			manifestGets++
+		}
+	}
+
+	// With single-pass, we expect exactly 2 Gets (one per snapshot manifest),
+	// not 4 (which the old ListManifests + GetManifest path would produce).
+	// There are 2 snapshots × 2 partition manifests each = 4 partition manifests,
+	// but deduplicated by snapshot ID = 2 manifests loaded.
+	if manifestGets != 2 {
+		t.Errorf("expected exactly 2 manifest Gets (single-pass), got %d", manifestGets)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// GetManifests
+// -----------------------------------------------------------------------------
+
+// concurrencyStore records the peak number of concurrent Get calls.
+type concurrencyStore struct {
+	Store
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *concurrencyStore) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+
+	// Hold the slot briefly so overlapping fetches are observable.
+	time.Sleep(time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return c.Store.Get(ctx, path)
+}
+
+func TestDatasetReader_GetManifestByPath(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"n": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifestPath := "datasets/test-ds/snapshots/" + string(snap.ID) + "/manifest.json"
+	m, err := reader.GetManifestByPath(ctx, manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SnapshotID != snap.ID || m.DatasetID != "test-ds" {
+		t.Errorf("manifest = %s/%s, want test-ds/%s", m.DatasetID, m.SnapshotID, snap.ID)
+	}
+
+	// A stray data file is rejected before storage is read.
+	_, err = reader.GetManifestByPath(ctx, snap.Manifest.Files[0].Path)
+	var notManifest *NotManifestPathError
+	if !errors.As(err, &notManifest) || !errors.Is(err, ErrNotManifestPath) {
+		t.Fatalf("expected *NotManifestPathError, got: %v", err)
+	}
+	if notManifest.Path != snap.Manifest.Files[0].Path || notManifest.Reason == ManifestPathOK {
+		t.Errorf("error = %+v, want rejection of %s", notManifest, snap.Manifest.Files[0].Path)
+	}
+
+	missing := "datasets/test-ds/snapshots/missing/manifest.json"
+	if _, err := reader.GetManifestByPath(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing manifest, got: %v", err)
+	}
+}
+
+func TestDatasetReader_GetManifests_PreservesOrderAndBoundsConcurrency(t *testing.T) {
+	store := &concurrencyStore{Store: NewMemory()}
+	factory := func() (Store, error) { return store, nil }
+
+	ds, err := NewDataset("test-ds", factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var refs []ManifestRef
+	for i := range 20 {
+		snap, err := ds.Write(t.Context(), []any{[]byte{byte(i)}}, Metadata{"i": i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, ManifestRef{ID: snap.ID})
+	}
+	// Reverse so input order differs from storage order.
+	slices.Reverse(refs)
+
+	reader, err := NewDatasetReader(factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.peak = 0
+
+	manifests, err := reader.GetManifests(t.Context(), "test-ds", refs, ManifestGetOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("GetManifests failed: %v", err)
+	}
+	if len(manifests) != len(refs) {
+		t.Fatalf("expected %d manifests, got %d", len(refs), len(manifests))
+	}
+	for i, m := range manifests {
+		if m.SnapshotID != refs[i].ID {
+			t.Errorf("manifest %d: expected %s, got %s", i, refs[i].ID, m.SnapshotID)
+		}
+	}
+	if store.peak > 4 {
+		t.Errorf("expected at most 4 concurrent fetches, observed %d", store.peak)
+	}
+}
+
+func TestDatasetReader_GetManifests_IdentifiesInvalidManifest(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+
+	valid := &Manifest{
+		SchemaName:    "lode-manifest",
+		FormatVersion: "1.0.0",
+		DatasetID:     "test-ds",
+		SnapshotID:    "snap-1",
+		CreatedAt:     time.Now().UTC(),
+		Metadata:      Metadata{},
+		Files:         []FileRef{},
+		Compressor:    "noop",
+		Partitioner:   "noop",
+	}
+	writeManifest(ctx, t, store, valid)
+
+	invalid := *valid
+	invalid.SnapshotID = "snap-bad"
+	invalid.SchemaName = ""
+	writeManifest(ctx, t, store, &invalid)
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = reader.GetManifests(ctx, "test-ds", []ManifestRef{{ID: "snap-1"}, {ID: "snap-bad"}}, ManifestGetOptions{})
+	if !errors.Is(err, ErrManifestInvalid) {
+		t.Fatalf("expected ErrManifestInvalid, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "snap-bad") {
+		t.Errorf("expected error to identify snap-bad, got: %v", err)
+	}
+
+	_, err = reader.GetManifests(ctx, "test-ds", []ManifestRef{{ID: "missing"}}, ManifestGetOptions{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDatasetReader_GetManifests_SkipValidation(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+
+	invalid := &Manifest{
+		SchemaName:    "lode-manifest",
+		FormatVersion: "9.0.0",
+		DatasetID:     "test-ds",
+		SnapshotID:    "snap-bad",
+		CreatedAt:     time.Now().UTC(),
+		Files:         []FileRef{},
+	}
+	writeManifest(ctx, t, store, invalid)
+	if err := store.Put(ctx, "datasets/test-ds/snapshots/snap-garbage/manifest.json", strings.NewReader("{not json")); err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewManifestCache(ManifestCacheOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := []ManifestRef{{ID: "snap-bad"}}
+
+	if _, err := reader.GetManifests(ctx, "test-ds", refs, ManifestGetOptions{}); !errors.Is(err, ErrUnsupportedFormatVersion) {
+		t.Fatalf("expected validation error by default, got: %v", err)
+	}
+
+	got, err := reader.GetManifests(ctx, "test-ds", refs, ManifestGetOptions{SkipValidation: true})
+	if err != nil {
+		t.Fatalf("SkipValidation: unexpected error: %v", err)
+	}
+	if got[0].SnapshotID != "snap-bad" || got[0].FormatVersion != "9.0.0" {
+		t.Errorf("unexpected manifest: %+v", got[0])
+	}
+	if entries := cache.Stats().Entries; entries != 0 {
+		t.Errorf("unvalidated manifests must not be cached, got %d entries", entries)
+	}
+
+	// Decoding still runs.
+	_, err = reader.GetManifests(ctx, "test-ds", []ManifestRef{{ID: "snap-garbage"}}, ManifestGetOptions{SkipValidation: true})
+	if err == nil || !strings.Contains(err.Error(), "decode") {
+		t.Errorf("expected decode error, got: %v", err)
+	}
+}
+
+// BenchmarkDatasetReader_GetManifests_Validation measures the validation
+// share of loading a large manifest (10,000 files) from memory. JSON decoding
+// dominates; validation adds a few percent at most, so SkipValidation pays
+// off only when manifests are loaded in very large numbers.
+func BenchmarkDatasetReader_GetManifests_Validation(b *testing.B) {
+	ctx := b.Context()
+	store := NewMemory()
+	files := make([]FileRef, 10000)
+	for i := range files {
+		files[i] = FileRef{Path: fmt.Sprintf("datasets/bench/snapshots/snap-1/data/part-%05d.jsonl", i), SizeBytes: 1024}
+	}
+	m := &Manifest{
+		SchemaName:    "lode-manifest",
+		FormatVersion: "1.0.0",
+		DatasetID:     "bench",
+		SnapshotID:    "snap-1",
+		CreatedAt:     time.Now().UTC(),
+		Metadata:      Metadata{},
+		Files:         files,
+		Compressor:    "noop",
+		Partitioner:   "noop",
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := store.Put(ctx, "datasets/bench/snapshots/snap-1/manifest.json", bytes.NewReader(data)); err != nil {
+		b.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		b.Fatal(err)
+	}
+	refs := []ManifestRef{{ID: "snap-1"}}
+
+	for _, skip := range []bool{false, true} {
+		b.Run(fmt.Sprintf("SkipValidation=%t", skip), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := reader.GetManifests(ctx, "bench", refs, ManifestGetOptions{SkipValidation: skip}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestDatasetReader_GetManifests_ContextCanceled(t *testing.T) {
+	reader, err := NewDatasetReader(NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err = reader.GetManifests(ctx, "test-ds", []ManifestRef{{ID: "a"}, {ID: "b"}}, ManifestGetOptions{Concurrency: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+
+	if _, err := reader.GetManifests(t.Context(), "test-ds", nil, ManifestGetOptions{Concurrency: -1}); err == nil {
+		t.Error("expected error for negative concurrency")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Test helpers
+// -----------------------------------------------------------------------------
+
+// NewMemoryFactoryFrom creates a StoreFactory that returns an existing store.
+func NewMemoryFactoryFrom(store Store) StoreFactory {
+	return func() (Store, error) {
+		return store, nil
+	}
+}
+
+func writeManifest(ctx context.Context, t *testing.T, store Store, m *Manifest) {
+	t.Helper()
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "datasets/" + string(m.DatasetID) + "/snapshots/" + string(m.SnapshotID) + "/manifest.json"
+	if err := store.Put(ctx, path, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// ListSegmentPartitions
+// -----------------------------------------------------------------------------
+
+func TestDatasetReader_ListSegmentPartitions(t *testing.T) {
+	ctx := t.Context()
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("events", newFaultStoreFactory(fs), WithCodec(NewJSONLCodec()), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	multi, err := ds.Write(ctx, R(D{"id": 1, "day": "b"}, D{"id": 2, "day": "a"}, D{"id": 3, "day": "b"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	single, err := ds.Write(ctx, R(D{"id": 4, "day": "c"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewDatasetReader(newFaultStoreFactory(fs), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs.Reset()
+	got, err := reader.ListSegmentPartitions(ctx, "events", ManifestRef{ID: multi.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []PartitionRef{{Path: "day=a"}, {Path: "day=b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("multi-partition snapshot: got %v, want %v", got, want)
+	}
+	if gets := fs.GetCalls(); len(gets) != 1 {
+		t.Errorf("expected only the manifest to be read, got %v", gets)
+	}
+
+	got, err = reader.ListSegmentPartitions(ctx, "events", ManifestRef{ID: single.ID, Partition: "day=c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []PartitionRef{{Path: "day=c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("single-partition snapshot: got %v, want %v", got, want)
+	}
+
+	// Without summaries, partitions come from the file paths.
+	m := *multi.Manifest
+	m.SnapshotID = "legacy"
+	m.Partitions = nil
+	data, err := json.Marshal(&m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Put(ctx, "datasets/events/segments/legacy/manifest.json", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	got, err = reader.ListSegmentPartitions(ctx, "events", ManifestRef{ID: "legacy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []PartitionRef{{Path: "day=a"}, {Path: "day=b"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("summary-less snapshot: got %v, want %v", got, want)
+	}
+
+	if _, err := reader.ListSegmentPartitions(ctx, "events", ManifestRef{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDatasetReader_ListSegmentPartitions_Unpartitioned(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"id": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reader.ListSegmentPartitions(ctx, "events", ManifestRef{ID: snap.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil list, got %#v", got)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// ListSegmentObjects
+// -----------------------------------------------------------------------------
+
+func collectObjectPaths(t *testing.T, it ObjectIterator) []string {
+	t.Helper()
+	defer func() { _ = it.Close() }()
+	var paths []string
+	for it.Next() {
+		paths = append(paths, it.Ref().Path)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestDatasetReader_ListSegmentObjects_MatchesManifestFiles(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"id": "1"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := ManifestRef{ID: snap.ID}
+	it, err := reader.ListSegmentObjects(t.Context(), "test-ds", ref, SegmentObjectListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collectObjectPaths(t, it)
+	if len(got) != 1 || got[0] != snap.Manifest.Files[0].Path {
+		t.Errorf("expected manifest file %s, got %v", snap.Manifest.Files[0].Path, got)
+	}
+
+	// Drift: a file missing from storage no longer appears.
+	if err := store.Delete(t.Context(), snap.Manifest.Files[0].Path); err != nil {
+		t.Fatal(err)
+	}
+	it, err = reader.ListSegmentObjects(t.Context(), "test-ds", ref, SegmentObjectListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := collectObjectPaths(t, it); len(got) != 0 {
+		t.Errorf("expected no objects after deletion, got %v", got)
+	}
+}
+
+func TestDatasetReader_ListSegmentObjects_Partition(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithHiveLayout("day"),
+		WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"day": "a"}, D{"day": "b"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := ManifestRef{ID: snap.ID, Partition: "day=a"}
+	it, err := reader.ListSegmentObjects(t.Context(), "test-ds", ref, SegmentObjectListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collectObjectPaths(t, it)
+	want := "datasets/test-ds/partitions/day=a/segments/" + string(snap.ID) + "/data/data.jsonl"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("expected [%s], got %v", want, got)
+	}
+}
+
+func TestDatasetReader_ListAllObjects(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]int64)
+	manifests := make(map[string]DatasetSnapshotID)
+	for i := range 2 {
+		snap, err := ds.Write(ctx, R(D{"i": i}), Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range snap.Manifest.Files {
+			files[f.Path] = f.SizeBytes
+		}
+		manifests["datasets/test-ds/snapshots/"+string(snap.ID)+"/manifest.json"] = snap.ID
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter, err := reader.ListAllObjects(ctx, "test-ds", AllObjectListOptions{Stat: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seenManifests, seenFiles, other := 0, 0, 0
+	for iter.Next() {
+		ref := iter.Ref()
+		if ref.SizeBytes <= 0 {
+			t.Errorf("%s: SizeBytes = %d, want stat size", ref.Path, ref.SizeBytes)
+		}
+		switch {
+		case ref.IsManifest:
+			if id, ok := manifests[ref.Path]; !ok || ref.Manifest.ID != id {
+				t.Errorf("unexpected manifest ref %+v", ref)
+			}
+			seenManifests++
+		case files[ref.Path] != 0:
+			if ref.SizeBytes != files[ref.Path] {
+				t.Errorf("%s: SizeBytes = %d, want %d", ref.Path, ref.SizeBytes, files[ref.Path])
+			}
+			seenFiles++
+		default:
+			other++ // latest pointer
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	}
+	_ = iter.Close()
+	if seenManifests != 2 || seenFiles != 2 || other != 1 {
+		t.Errorf("got %d manifests, %d data files, %d other; want 2, 2, 1", seenManifests, seenFiles, other)
+	}
+
+	limited, err := reader.ListAllObjects(ctx, "test-ds", AllObjectListOptions{Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for limited.Next() {
+		if limited.Ref().SizeBytes != 0 {
+			t.Error("SizeBytes set without Stat")
+		}
+		n++
+	}
+	if n != 3 {
+		t.Errorf("Limit 3 returned %d refs", n)
+	}
+
+	// Without SizedLister, objects are statted lazily during iteration.
+	statReader, err := NewDatasetReader(func() (Store, error) { return shuffledStore{store}, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter, err = statReader.ListAllObjects(ctx, "test-ds", AllObjectListOptions{Stat: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for iter.Next() {
+		if want, ok := files[iter.Ref().Path]; ok && iter.Ref().SizeBytes != want {
+			t.Errorf("%s: stat SizeBytes = %d, want %d", iter.Ref().Path, iter.Ref().SizeBytes, want)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	// The memory store lists regardless of ctx; iteration must still stop.
+	iter, err = statReader.ListAllObjects(cancelled, "test-ds", AllObjectListOptions{Stat: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iter.Next() || !errors.Is(iter.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled from a cancelled stat listing, got: %v", iter.Err())
+	}
+
+	if _, err := reader.ListAllObjects(ctx, "", AllObjectListOptions{}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for empty dataset, got: %v", err)
+	}
+}
+
+// sizedListerStore implements SizedLister over an inner store and counts
+// its calls.
+type sizedListerStore struct {
+	Store
+	calls atomic.Int32
+}
+
+func (s *sizedListerStore) ListSized(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.calls.Add(1)
+	return ListSized(ctx, s.Store, prefix)
+}
+
+func TestDatasetReader_DatasetSize(t *testing.T) {
+	ctx := t.Context()
+	mem := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(mem), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 2 {
+		if _, err := ds.Write(ctx, R(D{"i": i}), Metadata{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths, err := mem.List(ctx, "datasets/test-ds/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want int64
+	for _, p := range paths {
+		info, err := StatObject(ctx, mem, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want += info.SizeBytes
+	}
+
+	sized := &sizedListerStore{Store: shuffledStore{mem}}
+	fs := newFaultStore(mem)
+	for name, store := range map[string]Store{"sized": sized, "fallback": fs} {
+		reader, err := NewDatasetReader(func() (Store, error) { return store, nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := reader.DatasetSize(ctx, "test-ds")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("%s: DatasetSize = %d, want %d", name, got, want)
+		}
+		if size, err := reader.DatasetSize(ctx, "absent"); err != nil || size != 0 {
+			t.Errorf("%s: absent dataset size = %d, %v; want 0", name, size, err)
+		}
+	}
+	if n := sized.calls.Load(); n != 2 {
+		t.Errorf("ListSized calls = %d, want one per DatasetSize", n)
+	}
+	if gets := len(fs.GetCalls()); gets != len(paths) {
+		t.Errorf("fallback Gets = %d, want one per object (%d)", gets, len(paths))
+	}
+}
+
+func TestDatasetReader_ListSegmentObjects_IteratorLifecycle(t *testing.T) {
+	store := NewMemory()
+	for _, p := range []string{"a", "b", "c"} {
+		if err := store.Put(t.Context(), "datasets/test-ds/snapshots/s1/data/"+p, strings.NewReader(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := reader.ListSegmentObjects(t.Context(), "test-ds", ManifestRef{ID: "s1"}, SegmentObjectListOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.Next() {
+		t.Fatal("expected first object")
+	}
+	ref := it.Ref()
+	if ref.Dataset != "test-ds" || ref.Manifest.ID != "s1" {
+		t.Errorf("unexpected ref: %+v", ref)
+	}
+	if err := it.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if it.Next() {
+		t.Error("Next must return false after Close")
+	}
+	if err := it.Close(); err != nil {
+		t.Errorf("Close must be idempotent, got: %v", err)
+	}
+	if it.Err() != nil {
+		t.Errorf("Err after Close: %v", it.Err())
+	}
+
+	it, err = reader.ListSegmentObjects(t.Context(), "test-ds", ManifestRef{ID: "s1"}, SegmentObjectListOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := collectObjectPaths(t, it); len(got) != 2 {
+		t.Errorf("expected Limit to cap results at 2, got %v", got)
+	}
+
+	if _, err := reader.ListSegmentObjects(t.Context(), "test-ds", ManifestRef{}, SegmentObjectListOptions{}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for empty segment, got: %v", err)
+	}
+}
+
+func TestDatasetReader_OpenObject_ReadsManifestFiles(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithHiveLayout("day"),
+		WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"day": "a", "v": 1}, D{"day": "b", "v": 2}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codec := NewJSONLCodec()
+	opened := 0
+	for _, f := range snap.Manifest.Files {
+		ref := ManifestRef{ID: snap.ID, Partition: ds.(*dataset).layout.extractPartitionPath(f.Path)}
+		it, err := reader.ListSegmentObjects(t.Context(), "test-ds", ref, SegmentObjectListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for it.Next() {
+			rc, err := reader.OpenObject(t.Context(), it.Ref())
+			if err != nil {
+				t.Fatalf("OpenObject(%s) failed: %v", it.Ref().Path, err)
+			}
+			records, err := codec.Decode(rc)
+			_ = rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 1 {
+				t.Errorf("expected 1 record in %s, got %d", it.Ref().Path, len(records))
+			}
+			opened++
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		_ = it.Close()
+	}
+	if opened != len(snap.Manifest.Files) {
+		t.Errorf("opened %d objects, want %d", opened, len(snap.Manifest.Files))
+	}
+
+	missing := ObjectRef{Dataset: "test-ds", Manifest: ManifestRef{ID: snap.ID}, Path: snap.Manifest.Files[0].Path + ".missing"}
+	if _, err := reader.OpenObject(t.Context(), missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing object, got: %v", err)
+	}
+	if _, err := reader.OpenObject(t.Context(), ObjectRef{}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for empty path, got: %v", err)
+	}
+}
+
+func TestDatasetReader_VerifySegment_ReportsDrift(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithHiveLayout("day"),
+		WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"day": "a"}, D{"day": "b"}, D{"day": "c"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := ManifestRef{ID: snap.ID}
+
+	report, err := reader.VerifySegment(t.Context(), "test-ds", ref, VerifySegmentOptions{CheckSizes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasDrift() {
+		t.Fatalf("expected no drift for a fresh segment, got %+v", report)
+	}
+
+	files := snap.Manifest.Files
+	slices.SortFunc(files, func(a, b FileRef) int { return strings.Compare(a.Path, b.Path) })
+	missing, resized := files[0].Path, files[1].Path
+	extra := path.Dir(files[2].Path) + "/orphan"
+	if err := store.Delete(t.Context(), missing); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(t.Context(), resized); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(t.Context(), resized, strings.NewReader("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(t.Context(), extra, strings.NewReader("orphan")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = reader.VerifySegment(t.Context(), "test-ds", ref, VerifySegmentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.Missing, []string{missing}) {
+		t.Errorf("Missing = %v, want [%s]", report.Missing, missing)
+	}
+	if !slices.Equal(report.Extra, []string{extra}) {
+		t.Errorf("Extra = %v, want [%s]", report.Extra, extra)
+	}
+	if len(report.SizeMismatches) != 0 {
+		t.Errorf("sizes must not be checked by default, got %v", report.SizeMismatches)
+	}
+
+	report, err = reader.VerifySegment(t.Context(), "test-ds", ref, VerifySegmentOptions{CheckSizes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SizeMismatch{{Path: resized, Expected: files[1].SizeBytes, Actual: 1}}
+	if !slices.Equal(report.SizeMismatches, want) {
+		t.Errorf("SizeMismatches = %v, want %v", report.SizeMismatches, want)
+	}
+
+	// Without SizedLister, sizes come from a Stat per file.
+	statReader, err := NewDatasetReader(func() (Store, error) { return shuffledStore{store}, nil }, WithHiveLayout("day"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = statReader.VerifySegment(t.Context(), "test-ds", ref, VerifySegmentOptions{CheckSizes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.SizeMismatches, want) || !slices.Equal(report.Missing, []string{missing}) {
+		t.Errorf("Stat fallback report = %+v, want size mismatches %v", report, want)
+	}
+}
+
+func TestDatasetReader_AuditRowCount(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"n": 1}, D{"n": 2}, D{"n": 3}, D{"n": 4}, D{"n": 5}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := NewDataset("blobs", NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := blobs.Write(ctx, []any{[]byte("blob")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reader.AuditRowCount(ctx, "test-ds", ManifestRef{ID: snap.ID}); err != nil {
+		t.Errorf("audit of a correct manifest: %v", err)
+	}
+	if err := reader.AuditRowCount(ctx, "blobs", ManifestRef{ID: blob.ID}); err != nil {
+		t.Errorf("audit of a raw blob snapshot: %v", err)
+	}
+	if err := reader.AuditRowCount(ctx, "test-ds", ManifestRef{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing manifest, got: %v", err)
+	}
+
+	// Rewrite the manifest with a wrong row count.
+	wrong := *snap.Manifest
+	wrong.RowCount = 7
+	if err := store.Delete(ctx, "datasets/test-ds/snapshots/"+string(snap.ID)+"/manifest.json"); err != nil {
+		t.Fatal(err)
+	}
+	writeManifest(ctx, t, store, &wrong)
+
+	err = reader.AuditRowCount(ctx, "test-ds", ManifestRef{ID: snap.ID})
+	var mismatch *RowCountMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrRowCountMismatch) {
+		t.Fatalf("expected *RowCountMismatchError, got: %v", err)
+	}
+	if mismatch.Recorded != 7 || mismatch.Actual != 5 || mismatch.SnapshotID != snap.ID {
+		t.Errorf("mismatch = %+v, want recorded 7, actual 5 for %s", mismatch, snap.ID)
+	}
+}
+
+func TestDatasetReader_VerifyChecksums(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("test-ds", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithHiveLayout("region"),
+		WithChecksum(NewMD5Checksum()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(
+		D{"region": "ap", "n": 1}, D{"region": "eu", "n": 2},
+		D{"region": "us", "n": 3}, D{"region": "sa", "n": 4},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewDatasetReader(NewMemoryFactoryFrom(store), WithHiveLayout("region"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := ManifestRef{ID: snap.ID}
+
+	if err := reader.VerifyChecksums(ctx, "test-ds", ref, ChecksumVerifyOptions{}); err != nil {
+		t.Errorf("verify of intact snapshot: %v", err)
+	}
+	if err := reader.VerifyChecksums(ctx, "test-ds", ManifestRef{ID: "missing"}, ChecksumVerifyOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing manifest, got: %v", err)
+	}
+
+	// Corrupt one file among four; only it is reported.
+	bad := snap.Manifest.Files[2].Path
+	if err := store.Delete(ctx, bad); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, bad, bytes.NewReader([]byte(`{"region":"tampered"}`+"\n"))); err != nil {
+		t.Fatal(err)
+	}
+	for _, concurrency := range []int{0, 1} {
+		err = reader.VerifyChecksums(ctx, "test-ds", ref, ChecksumVerifyOptions{Concurrency: concurrency})
+		var mismatch *ChecksumMismatchError
+		if !errors.As(err, &mismatch) || !errors.Is(err, ErrChecksumMismatch) {
+			t.Fatalf("concurrency %d: expected *ChecksumMismatchError, got: %v", concurrency, err)
+		}
+		if len(mismatch.Mismatches) != 1 || mismatch.Mismatches[0].Path != bad ||
+			mismatch.Mismatches[0].Expected != snap.Manifest.Files[2].Checksum {
+			t.Errorf("concurrency %d: mismatches = %+v, want only %s", concurrency, mismatch.Mismatches, bad)
+		}
+		if !strings.Contains(err.Error(), bad) {
+			t.Errorf("error %q does not name %s", err, bad)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := reader.VerifyChecksums(cancelled, "test-ds", ref, ChecksumVerifyOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	if err := reader.VerifyChecksums(ctx, "test-ds", ref, ChecksumVerifyOptions{Checksum: NewCRC32CChecksum()}); err == nil {
+		t.Error("expected error for checksum algorithm mismatch")
+	}
+	if err := reader.VerifyChecksums(ctx, "test-ds", ref, ChecksumVerifyOptions{Concurrency: -1}); err == nil {
+		t.Error("expected error for negative concurrency")
+	}
+
+	plain, err := NewDataset("plain", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainSnap, err := plain.Write(ctx, R(D{"n": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reader.VerifyChecksums(ctx, "plain", ManifestRef{ID: plainSnap.ID}, ChecksumVerifyOptions{}); err == nil {
+		t.Error("expected error for snapshot without checksums")
+	}
+}
+
+func TestDatasetReader_VerifySegment_ManifestNotFound(t *testing.T) {
+	reader, err := NewDatasetReader(NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = reader.VerifySegment(t.Context(), "test-ds", ManifestRef{ID: "missing"}, VerifySegmentOptions{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
 }
@@ -0,0 +1,202 @@
+package lode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// WithRecordDedup Option
+// -----------------------------------------------------------------------------
+
+// recordDedupOption implements Option for WithRecordDedup (dataset-only).
+type recordDedupOption struct {
+	key string
+}
+
+// WithRecordDedup makes Write and Append drop records that repeat the value
+// of the key field of an earlier record in the same write, keeping the
+// first occurrence in input order. The number of dropped records is
+// reported in WriteStats.DuplicatesDropped when WithWriteStats is enabled.
+// Default: records are written as given.
+// This option is only valid for NewDataset and requires a codec.
+//
+// Deduplication is scoped to one write; it never consults earlier
+// snapshots (see WithDedup for sharing identical files across snapshots).
+// Key values compare as strings, bools, time.Time instants, or numbers.
+// Numbers compare exactly: integer types and json.Number compare by value
+// at any magnitude, and a float equals an integer only when it holds that
+// integer exactly (int 1 equals float64 1). Records that are not
+// map[string]any, or that lack the key or hold nil, are always kept. Other
+// value types fail the write.
+//
+// The write holds a set of every distinct key value it has seen, so memory
+// grows with the number of distinct keys in the batch. StreamWriteRecords
+// and Compact do not deduplicate.
+func WithRecordDedup(key string) Option {
+	return &recordDedupOption{key: key}
+}
+
+func (o *recordDedupOption) applyDataset(cfg *datasetConfig) error {
+	if o.key == "" {
+		return errors.New("WithRecordDedup: key must not be empty")
+	}
+	cfg.uniqueKey = o.key
+	return nil
+}
+
+func (o *recordDedupOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithRecordDedup: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// -----------------------------------------------------------------------------
+// Record Deduplication
+// -----------------------------------------------------------------------------
+
+// recordDedupKey is the comparable form of a dedup key value. Numbers are
+// kept exact: integers that fit int64 or uint64 use those kinds, and other
+// numbers use their exact rational text.
+type recordDedupKey struct {
+	kind byte // 'i' int64, 'u' uint64 above MaxInt64, 'r' rational, 's' string, 'b' bool, 't' time
+	i    int64
+	u    uint64
+	str  string
+}
+
+// dropDuplicateRecords returns records without those repeating an earlier
+// record's key value, and how many were dropped. The input slice is not
+// modified; it is returned as-is when nothing is dropped.
+func dropDuplicateRecords(records []any, key string) ([]any, int, error) {
+	seen := make(map[recordDedupKey]struct{})
+	var kept []any
+	for i, record := range records {
+		v := recordField(record, key)
+		if v == nil {
+			if kept != nil {
+				kept = append(kept, record)
+			}
+			continue
+		}
+		k, err := newRecordDedupKey(v)
+		if err != nil {
+			return nil, 0, fmt.Errorf("record dedup key %q: %w", key, err)
+		}
+		if _, dup := seen[k]; !dup {
+			seen[k] = struct{}{}
+			if kept != nil {
+				kept = append(kept, record)
+			}
+			continue
+		}
+		if kept == nil {
+			// First duplicate: copy the records kept so far.
+			kept = make([]any, i, len(records))
+			copy(kept, records[:i])
+		}
+	}
+	if kept == nil {
+		return records, 0, nil
+	}
+	return kept, len(records) - len(kept), nil
+}
+
+// recordField returns the key field of a map record, or nil.
+func recordField(record any, key string) any {
+	m, ok := record.(map[string]any)
+	if !ok {
+		return nil
+	}
+	return m[key]
+}
+
+// newRecordDedupKey converts a key value to its comparable form.
+func newRecordDedupKey(v any) (recordDedupKey, error) {
+	switch val := v.(type) {
+	case int:
+		return intDedupKey(int64(val)), nil
+	case int8:
+		return intDedupKey(int64(val)), nil
+	case int16:
+		return intDedupKey(int64(val)), nil
+	case int32:
+		return intDedupKey(int64(val)), nil
+	case int64:
+		return intDedupKey(val), nil
+	case uint:
+		return uintDedupKey(uint64(val)), nil
+	case uint8:
+		return uintDedupKey(uint64(val)), nil
+	case uint16:
+		return uintDedupKey(uint64(val)), nil
+	case uint32:
+		return uintDedupKey(uint64(val)), nil
+	case uint64:
+		return uintDedupKey(val), nil
+	case float32:
+		return floatDedupKey(float64(val))
+	case float64:
+		return floatDedupKey(val)
+	case json.Number:
+		return numberDedupKey(val)
+	case string:
+		return recordDedupKey{kind: 's', str: val}, nil
+	case bool:
+		if val {
+			return recordDedupKey{kind: 'b', i: 1}, nil
+		}
+		return recordDedupKey{kind: 'b'}, nil
+	case time.Time:
+		return recordDedupKey{kind: 't', str: val.UTC().Format(time.RFC3339Nano)}, nil
+	}
+	return recordDedupKey{}, fmt.Errorf("unsupported value type %T", v)
+}
+
+func intDedupKey(n int64) recordDedupKey {
+	return recordDedupKey{kind: 'i', i: n}
+}
+
+func uintDedupKey(n uint64) recordDedupKey {
+	if n <= math.MaxInt64 {
+		return intDedupKey(int64(n))
+	}
+	return recordDedupKey{kind: 'u', u: n}
+}
+
+// ratDedupKey keys a number that does not fit int64 or uint64.
+func ratDedupKey(r *big.Rat) recordDedupKey {
+	if r.IsInt() {
+		if n := r.Num(); n.IsInt64() {
+			return intDedupKey(n.Int64())
+		} else if n.IsUint64() {
+			return uintDedupKey(n.Uint64())
+		}
+	}
+	return recordDedupKey{kind: 'r', str: r.RatString()}
+}
+
+// floatDedupKey keys a float by its exact value, so an integral float
+// equals the integer it holds. NaN and infinities are rejected.
+func floatDedupKey(f float64) (recordDedupKey, error) {
+	r := new(big.Rat).SetFloat64(f)
+	if r == nil {
+		return recordDedupKey{}, fmt.Errorf("unsupported number %v", f)
+	}
+	return ratDedupKey(r), nil
+}
+
+// numberDedupKey keys a json.Number by its exact decimal value.
+func numberDedupKey(n json.Number) (recordDedupKey, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return intDedupKey(i), nil
+	}
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return recordDedupKey{}, fmt.Errorf("invalid number %q", n)
+	}
+	return ratDedupKey(r), nil
+}
@@ -0,0 +1,142 @@
+package lode
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDataset_Write_WithRecordDedup(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithRecordDedup("id"),
+		WithWriteStats(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := R(
+		D{"id": "a", "n": 0}, D{"id": "b", "n": 1},
+		D{"id": "a", "n": 2}, D{"n": 3},
+		D{"id": 7, "n": 4}, D{"n": 5},
+		D{"id": 7.0, "n": 6}, D{"id": "b", "n": 7},
+	)
+	snap, err := ds.Write(t.Context(), input, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.RowCount != 5 {
+		t.Errorf("RowCount = %d, want 5 after deduplication", snap.Manifest.RowCount)
+	}
+	if snap.WriteStats.DuplicatesDropped != 3 {
+		t.Errorf("DuplicatesDropped = %d, want 3", snap.WriteStats.DuplicatesDropped)
+	}
+	if len(input) != 8 {
+		t.Error("Write modified the caller's slice")
+	}
+
+	records, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ns []float64
+	for _, r := range records {
+		ns = append(ns, r.(map[string]any)["n"].(float64))
+	}
+	// First occurrences win; records without the key are kept.
+	if want := []float64{0, 1, 3, 4, 5}; !reflect.DeepEqual(ns, want) {
+		t.Errorf("records n = %v, want %v", ns, want)
+	}
+
+	// Deduplication is scoped to one write.
+	next, err := ds.Write(t.Context(), R(D{"id": "a", "n": 8}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Manifest.RowCount != 1 || next.WriteStats.DuplicatesDropped != 0 {
+		t.Errorf("second write RowCount %d dropped %d, want 1 and 0", next.Manifest.RowCount, next.WriteStats.DuplicatesDropped)
+	}
+
+	if _, err := ds.Write(t.Context(), R(D{"id": []any{1}}), Metadata{}); err == nil {
+		t.Error("expected error for unsupported key value type")
+	}
+}
+
+func TestDropDuplicateRecords_NoDuplicates(t *testing.T) {
+	records := R(D{"id": 1}, D{"id": 2}, D{"other": 1})
+	got, dropped, err := dropDuplicateRecords(records, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dropped != 0 || &got[0] != &records[0] {
+		t.Errorf("dropped %d, want 0 and the input slice returned as-is", dropped)
+	}
+}
+
+func TestWithRecordDedup_InvalidConfiguration(t *testing.T) {
+	if _, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithRecordDedup("")); err == nil {
+		t.Error("expected error for empty key")
+	}
+	if _, err := NewDataset("blobs", NewMemoryFactory(), WithRecordDedup("id")); err == nil {
+		t.Error("expected error for WithRecordDedup without a codec")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithRecordDedup("id")); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_Write_WithRecordDedup_ExactIntegers(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithRecordDedup("id"),
+		WithWriteStats(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const big = int64(1) << 53
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": big}, D{"id": big + 1},
+		D{"id": uint64(1) << 63}, D{"id": uint64(1)<<63 + 1},
+		D{"id": json.Number("9007199254740993")}, // big + 1 again
+		D{"id": float64(big)},                    // holds big exactly
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.RowCount != 4 || snap.WriteStats.DuplicatesDropped != 2 {
+		t.Errorf("RowCount %d dropped %d, want 4 and 2", snap.Manifest.RowCount, snap.WriteStats.DuplicatesDropped)
+	}
+}
+
+func TestNewRecordDedupKey_Numbers(t *testing.T) {
+	tests := []struct {
+		a, b  any
+		equal bool
+	}{
+		{1, 1.0, true},
+		{int64(1) << 53, int64(1)<<53 + 1, false},
+		{int64(1)<<53 + 1, float64(int64(1) << 53), false},
+		{uint64(5), int8(5), true},
+		{json.Number("12345678901234567890123"), json.Number("12345678901234567890124"), false},
+		{json.Number("1.5"), 1.5, true},
+		{json.Number("0.1"), 0.1, false}, // float64 0.1 is not exactly 1/10
+		{1.5, 2, false},
+	}
+	for _, tt := range tests {
+		ka, err := newRecordDedupKey(tt.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kb, err := newRecordDedupKey(tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (ka == kb) != tt.equal {
+			t.Errorf("%v (%T) == %v (%T): got %v, want %v", tt.a, tt.a, tt.b, tt.b, ka == kb, tt.equal)
+		}
+	}
+	if _, err := newRecordDedupKey(math.NaN()); err == nil {
+		t.Error("expected error for NaN")
+	}
+}
@@ -161,6 +161,43 @@ func TestIntegration_WriteListRead(t *testing.T) {
 	}
 }
 
+func TestIntegration_MultipartThreshold(t *testing.T) {
+	for _, backend := range s3Backends {
+		t.Run(backend.name, func(t *testing.T) {
+			base := setupTestBucket(t, backend)
+			store, err := New(base.client, Config{Bucket: base.bucket, MultipartThreshold: minPartSize})
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			ctx := t.Context()
+
+			// 12MB with a 5MB threshold: three parts, the last one short.
+			content := make([]byte, 12*1024*1024)
+			for i := range content {
+				content[i] = byte(i % 251)
+			}
+			key := "large/multipart.bin"
+
+			if err := store.Put(ctx, key, bytes.NewReader(content)); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+
+			rc, err := store.Get(ctx, key)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			data, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				t.Fatalf("reading body failed: %v", err)
+			}
+			if !bytes.Equal(data, content) {
+				t.Errorf("content mismatch: got %d bytes, want %d", len(data), len(content))
+			}
+		})
+	}
+}
+
 func TestIntegration_RangeRead(t *testing.T) {
 	for _, backend := range s3Backends {
 		t.Run(backend.name, func(t *testing.T) {
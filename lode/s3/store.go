@@ -6,18 +6,26 @@
 //
 // This adapter implements CONTRACT_STORAGE.md obligations:
 //   - Put: Uses atomic or multipart path based on payload size.
-//     Atomic (≤5GB): Spools to temp file, then PutObject with If-None-Match.
-//     Provides atomic no-overwrite guarantee with O(1) memory usage.
-//     Multipart (>5GB): Uses CompleteMultipartUpload with If-None-Match for
+//     Atomic (≤5GB, or Config.MultipartThreshold): Spools to temp file, then
+//     PutObject with If-None-Match. Provides atomic no-overwrite guarantee
+//     with O(1) memory usage.
+//     Multipart (above the threshold): Uses CompleteMultipartUpload with If-None-Match for
 //     atomic no-overwrite guarantee. Preflight check optimizes fail-fast behavior.
 //   - Get/Exists/Delete: Standard ErrNotFound semantics
+//   - ExistsMany: Concurrent HeadObject requests (lode.BatchExistsStore)
+//   - Stat: HeadObject content length (lode.StatStore)
+//   - Copy: Server-side CopyObject with If-None-Match (lode.CopyStore);
+//     single-request copies are limited to 5GB by S3
 //   - List: Full pagination support, returns all matching keys
+//   - ListPrefixes: ListObjectsV2 with Delimiter, returning CommonPrefixes
+//     (lode.DelimitedLister)
 //   - ReadRange: True range reads via HTTP Range header
 //   - ReaderAt: Concurrent-safe random access reads
 //
 // # S3-Specific Limits
 //
-//   - Atomic path threshold: 5GB (S3 PutObject limit)
+//   - Atomic path threshold: 5GB (S3 PutObject limit); lower it with
+//     Config.MultipartThreshold (minimum 5MB)
 //   - Maximum object size: 5TB (S3 multipart limit)
 //   - Part size: 5MB minimum, adaptive for objects >50GB to stay under 10,000 parts
 //
@@ -53,6 +61,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"net/url"
 	"os"
 	"path"
 	"strings"
@@ -89,6 +98,9 @@ const (
 // Set to 5GB (the S3 PutObject limit) to maximize atomic upload coverage.
 const maxAtomicPutSize = 5 * 1024 * 1024 * 1024 // 5GB
 
+// existsManyConcurrency bounds concurrent HeadObject requests in ExistsMany.
+const existsManyConcurrency = 16
+
 // maxReadRangeLength is the maximum length for ReadRange to prevent overflow
 // when converting int64 to int on 32-bit platforms.
 const maxReadRangeLength = int64(math.MaxInt)
@@ -99,6 +111,7 @@ type API interface {
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
 	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
 	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
 	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
@@ -115,6 +128,11 @@ type Config struct {
 	// Prefix is an optional key prefix for all operations.
 	// If set, all keys are prefixed with this value (with a trailing slash added if missing).
 	Prefix string
+
+	// MultipartThreshold is the payload size above which Put switches from
+	// a single PutObject to a multipart upload. Zero uses the S3 PutObject
+	// limit (5GB). Must be between 5MB (the minimum part size) and 5GB.
+	MultipartThreshold int64
 }
 
 // Store implements lode.Store using an S3-compatible backend.
@@ -123,6 +141,8 @@ type Store struct {
 	bucket     string
 	prefix     string
 	createTemp func() (*os.File, error) // temp file factory for Put spooling
+
+	multipartThreshold int64 // payloads larger than this use multipart upload
 }
 
 // New creates a new S3 store with the given client and configuration.
@@ -143,6 +163,14 @@ func New(client API, cfg Config) (*Store, error) {
 		return nil, errors.New("s3: bucket is required")
 	}
 
+	threshold := cfg.MultipartThreshold
+	if threshold == 0 {
+		threshold = maxAtomicPutSize
+	}
+	if threshold < minPartSize || threshold > maxAtomicPutSize {
+		return nil, fmt.Errorf("s3: multipart threshold %d out of range [%d, %d]", cfg.MultipartThreshold, minPartSize, maxAtomicPutSize)
+	}
+
 	prefix := cfg.Prefix
 	if prefix != "" && !strings.HasSuffix(prefix, "/") {
 		prefix += "/"
@@ -153,13 +181,15 @@ func New(client API, cfg Config) (*Store, error) {
 		bucket:     cfg.Bucket,
 		prefix:     prefix,
 		createTemp: func() (*os.File, error) { return os.CreateTemp("", "lode-s3-*") },
+
+		multipartThreshold: threshold,
 	}, nil
 }
 
 // shouldUseAtomicPath returns true if the given size should use the atomic Put path.
 // This is a pure function for routing decisions, testable without large files.
-func shouldUseAtomicPath(size int64) bool {
-	return size <= maxAtomicPutSize
+func shouldUseAtomicPath(size, threshold int64) bool {
+	return size <= threshold
 }
 
 // Put writes data to the given path.
@@ -168,11 +198,12 @@ func shouldUseAtomicPath(size int64) bool {
 //
 // # Routing and Atomicity (per CONTRACT_STORAGE.md)
 //
-// Atomic path (≤5GB): Spools to temp file, then uses PutObject with
-// If-None-Match for atomic no-overwrite protection. O(1) memory usage.
-// Duplicate writes return ErrPathExists.
+// Atomic path (≤ Config.MultipartThreshold, default 5GB): Spools to temp
+// file, then uses PutObject with If-None-Match for atomic no-overwrite
+// protection. O(1) memory usage. Duplicate writes return ErrPathExists.
 //
-// Multipart path (>5GB): Uses preflight HeadObject check then multipart
+// Multipart path (> threshold): Streams parts from the spooled temp file;
+// a failed part or completion aborts the upload. Uses preflight HeadObject check then multipart
 // upload. Provides best-effort no-overwrite protection with a TOCTOU window.
 // Single-writer or external coordination is required for guaranteed
 // no-overwrite semantics on this path.
@@ -205,7 +236,7 @@ func (s *Store) Put(ctx context.Context, key string, r io.Reader) error {
 	}
 
 	// Route based on size
-	if shouldUseAtomicPath(size) {
+	if shouldUseAtomicPath(size, s.multipartThreshold) {
 		return s.putAtomicFromFile(ctx, fullKey, tmpFile, size)
 	}
 	return s.putMultipartFromFile(ctx, fullKey, tmpFile, size)
@@ -236,7 +267,8 @@ func (s *Store) putAtomicFromFile(ctx context.Context, fullKey string, file io.R
 	return nil
 }
 
-// putMultipartFromFile implements multipart upload for objects > 5GB.
+// putMultipartFromFile implements multipart upload for objects above the
+// multipart threshold.
 // Reads parts directly from file using io.SectionReader for memory efficiency.
 //
 // Uses conditional completion (If-None-Match) for atomic no-overwrite guarantee,
@@ -392,18 +424,166 @@ func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
 	return s.exists(ctx, fullKey)
 }
 
+// ExistsMany checks the existence of many paths with concurrent HeadObject
+// requests (at most 16 in flight). Implements lode.BatchExistsStore.
+// Returns ErrInvalidPath if any path is empty or escaping; no requests are
+// made in that case. On request failure, the first error is returned.
+func (s *Store) ExistsMany(ctx context.Context, keys []string) (map[string]bool, error) {
+	fullKeys := make(map[string]string, len(keys))
+	for _, key := range keys {
+		fullKey, err := s.validateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		fullKeys[key] = fullKey
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	result := make(map[string]bool, len(fullKeys))
+	sem := make(chan struct{}, existsManyConcurrency)
+
+	for key, fullKey := range fullKeys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			exists, err := s.exists(ctx, fullKey)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("s3: head object %s: %w", key, err)
+					cancel()
+				}
+				return
+			}
+			result[key] = exists
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// Stat returns object metadata with a HeadObject request.
+// Implements lode.StatStore. Returns ErrNotFound if the path does not exist.
+func (s *Store) Stat(ctx context.Context, key string) (lode.ObjectInfo, error) {
+	fullKey, err := s.validateKey(key)
+	if err != nil {
+		return lode.ObjectInfo{}, err
+	}
+
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(fullKey),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return lode.ObjectInfo{}, lode.ErrNotFound
+		}
+		return lode.ObjectInfo{}, fmt.Errorf("s3: head object: %w", err)
+	}
+	return lode.ObjectInfo{SizeBytes: aws.ToInt64(out.ContentLength), ModTime: aws.ToTime(out.LastModified)}, nil
+}
+
+// Copy copies src to dst server-side with a single CopyObject request, so
+// no object bytes pass through the client. Implements lode.CopyStore.
+// Returns ErrNotFound if src does not exist and ErrPathExists if dst exists
+// (If-None-Match). S3 limits single-request copies to 5GB; larger objects
+// return the service error.
+func (s *Store) Copy(ctx context.Context, src, dst string) error {
+	srcKey, err := s.validateKey(src)
+	if err != nil {
+		return err
+	}
+	dstKey, err := s.validateKey(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(dstKey),
+		CopySource:  aws.String(copySource(s.bucket, srcKey)),
+		IfNoneMatch: aws.String("*"),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return lode.ErrNotFound
+		}
+		if isPreconditionFailed(err) {
+			return lode.ErrPathExists
+		}
+		return fmt.Errorf("s3: copy object: %w", err)
+	}
+	return nil
+}
+
+// copySource formats the URL-encoded "bucket/key" CopySource value.
+func copySource(bucket, key string) string {
+	segments := strings.Split(bucket+"/"+key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
 // List returns all paths under the given prefix.
 // Pagination is handled automatically; all matching keys are returned.
+// Directory markers (keys ending in "/", as created by consoles and some
+// sync tools) are not objects and are omitted.
 // Returns ErrInvalidPath for escaping prefixes.
 func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
-	fullPrefix, err := s.validatePrefix(prefix)
+	var keys []string
+	err := s.listObjects(ctx, prefix, func(relKey string, _ types.Object) {
+		keys = append(keys, relKey)
+	})
 	if err != nil {
 		return nil, err
 	}
+	return keys, nil
+}
 
-	var keys []string
-	var continuationToken *string
+// ListSized returns the keys under prefix with their sizes and modification
+// times, taken from the ListObjectsV2 pages List already fetches.
+// Implements lode.SizedLister. Returns ErrInvalidPath for escaping prefixes.
+func (s *Store) ListSized(ctx context.Context, prefix string) ([]lode.ObjectInfo, error) {
+	var infos []lode.ObjectInfo
+	err := s.listObjects(ctx, prefix, func(relKey string, obj types.Object) {
+		infos = append(infos, lode.ObjectInfo{
+			Path:      relKey,
+			SizeBytes: aws.ToInt64(obj.Size),
+			ModTime:   aws.ToTime(obj.LastModified),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// listObjects pages through ListObjectsV2 under prefix, calling fn with the
+// store-relative key of each object. Directory markers are skipped.
+func (s *Store) listObjects(ctx context.Context, prefix string, fn func(relKey string, obj types.Object)) error {
+	fullPrefix, err := s.validatePrefix(prefix)
+	if err != nil {
+		return err
+	}
 
+	var continuationToken *string
 	for {
 		out, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
 			Bucket:            aws.String(s.bucket),
@@ -411,14 +591,72 @@ func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 			ContinuationToken: continuationToken,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("s3: list objects: %w", err)
+			return fmt.Errorf("s3: list objects: %w", err)
 		}
 
 		for _, obj := range out.Contents {
 			if obj.Key != nil {
 				// Strip the store prefix to return relative keys
 				relKey := strings.TrimPrefix(*obj.Key, s.prefix)
-				keys = append(keys, relKey)
+				if !isDirectoryMarker(relKey) {
+					fn(relKey, obj)
+				}
+			}
+		}
+
+		if !aws.ToBool(out.IsTruncated) {
+			return nil
+		}
+		continuationToken = out.NextContinuationToken
+	}
+}
+
+// isDirectoryMarker reports whether a relative key is a directory
+// placeholder (or the store prefix itself) rather than an object.
+func isDirectoryMarker(relKey string) bool {
+	return relKey == "" || strings.HasSuffix(relKey, "/")
+}
+
+// ListPrefixes lists one level of keys under prefix with ListObjectsV2
+// Delimiter, so keys below each common prefix are not enumerated.
+// Implements lode.DelimitedLister. Returns ErrInvalidPath for escaping
+// prefixes.
+func (s *Store) ListPrefixes(ctx context.Context, prefix, delimiter string) ([]string, []string, error) {
+	fullPrefix, err := s.validatePrefix(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	// validatePrefix cleans away a trailing slash, which selects the level.
+	if strings.HasSuffix(prefix, "/") && !strings.HasSuffix(fullPrefix, "/") {
+		fullPrefix += "/"
+	}
+
+	var keys, commonPrefixes []string
+	var continuationToken *string
+	for {
+		input := &s3.ListObjectsV2Input{
+			Bucket:            aws.String(s.bucket),
+			Prefix:            aws.String(fullPrefix),
+			ContinuationToken: continuationToken,
+		}
+		if delimiter != "" {
+			input.Delimiter = aws.String(delimiter)
+		}
+		out, err := s.client.ListObjectsV2(ctx, input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("s3: list objects: %w", err)
+		}
+
+		for _, obj := range out.Contents {
+			if obj.Key != nil {
+				if relKey := strings.TrimPrefix(*obj.Key, s.prefix); !isDirectoryMarker(relKey) {
+					keys = append(keys, relKey)
+				}
+			}
+		}
+		for _, cp := range out.CommonPrefixes {
+			if cp.Prefix != nil {
+				commonPrefixes = append(commonPrefixes, strings.TrimPrefix(*cp.Prefix, s.prefix))
 			}
 		}
 
@@ -428,7 +666,7 @@ func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 		continuationToken = out.NextContinuationToken
 	}
 
-	return keys, nil
+	return keys, commonPrefixes, nil
 }
 
 // Delete removes the path if it exists.
@@ -454,17 +692,18 @@ func (s *Store) Delete(ctx context.Context, key string) error {
 
 // ReadRange reads a byte range from the given path.
 // Returns ErrNotFound if the path does not exist.
-// Returns ErrInvalidPath for negative offset/length, overflow, or invalid paths.
+// Returns ErrInvalidRange for negative offset/length or overflow.
+// Returns ErrInvalidPath for invalid paths.
 // If offset is beyond EOF, returns empty slice.
 // If range extends beyond EOF, returns available bytes.
 // If length is 0, returns empty slice without making a request.
 func (s *Store) ReadRange(ctx context.Context, key string, offset, length int64) ([]byte, error) {
 	// Validate offset and length per CONTRACT_STORAGE.md
 	if offset < 0 || length < 0 || length > maxReadRangeLength {
-		return nil, lode.ErrInvalidPath
+		return nil, lode.ErrInvalidRange
 	}
 	if offset > math.MaxInt64-length {
-		return nil, lode.ErrInvalidPath
+		return nil, lode.ErrInvalidRange
 	}
 
 	fullKey, err := s.validateKey(key)
@@ -662,6 +901,18 @@ func isNotFound(err error) bool {
 	return false
 }
 
+// isPreconditionFailed reports whether err is a failed If-None-Match
+// condition (the destination object already exists).
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		return code == "PreconditionFailed" || code == "412" ||
+			code == "ConditionalRequestConflict" || code == "409"
+	}
+	return false
+}
+
 // -----------------------------------------------------------------------------
 // Mock S3 Client for Testing
 // -----------------------------------------------------------------------------
@@ -675,11 +926,14 @@ type multipartUpload struct {
 type MockS3Client struct {
 	mu       sync.RWMutex
 	objects  map[string][]byte
+	modTimes map[string]time.Time        // key -> LastModified
 	uploads  map[string]*multipartUpload // uploadID -> upload
 	uploadID int
 
 	// Call counters for test assertions
 	PutObjectCalls             int
+	HeadObjectCalls            int
+	CopyObjectCalls            int
 	CreateMultipartUploadCalls int
 	AbortMultipartUploadCalls  int
 
@@ -692,8 +946,9 @@ type MockS3Client struct {
 // NewMockS3Client creates a new mock S3 client for testing.
 func NewMockS3Client() *MockS3Client {
 	return &MockS3Client{
-		objects: make(map[string][]byte),
-		uploads: make(map[string]*multipartUpload),
+		objects:  make(map[string][]byte),
+		modTimes: make(map[string]time.Time),
+		uploads:  make(map[string]*multipartUpload),
 	}
 }
 
@@ -702,6 +957,8 @@ func (m *MockS3Client) ResetCounts() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.PutObjectCalls = 0
+	m.HeadObjectCalls = 0
+	m.CopyObjectCalls = 0
 	m.CreateMultipartUploadCalls = 0
 	m.AbortMultipartUploadCalls = 0
 	m.uploadPartCalls = 0
@@ -728,6 +985,7 @@ func (m *MockS3Client) PutObject(_ context.Context, params *s3.PutObjectInput, _
 	}
 
 	m.objects[key] = data
+	m.modTimes[key] = time.Now()
 	return &s3.PutObjectOutput{}, nil
 }
 
@@ -769,15 +1027,49 @@ func (m *MockS3Client) GetObject(_ context.Context, params *s3.GetObjectInput, _
 func (m *MockS3Client) HeadObject(_ context.Context, params *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
 	key := aws.ToString(params.Key)
 
-	m.mu.RLock()
-	_, exists := m.objects[key]
-	m.mu.RUnlock()
+	m.mu.Lock()
+	m.HeadObjectCalls++
+	data, exists := m.objects[key]
+	modTime := m.modTimes[key]
+	m.mu.Unlock()
+
+	if !exists {
+		return nil, &types.NoSuchKey{}
+	}
+
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(data))),
+		LastModified:  aws.Time(modTime),
+	}, nil
+}
+
+// CopyObject implements API.CopyObject for testing.
+func (m *MockS3Client) CopyObject(_ context.Context, params *s3.CopyObjectInput, _ ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	source, err := url.PathUnescape(aws.ToString(params.CopySource))
+	if err != nil {
+		return nil, err
+	}
+	_, srcKey, _ := strings.Cut(source, "/")
+	key := aws.ToString(params.Key)
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CopyObjectCalls++
+
+	data, exists := m.objects[srcKey]
 	if !exists {
 		return nil, &types.NoSuchKey{}
 	}
+	if aws.ToString(params.IfNoneMatch) == "*" {
+		if _, exists := m.objects[key]; exists {
+			return nil, &smithyAPIError{code: "PreconditionFailed", message: "object already exists"}
+		}
+	}
 
-	return &s3.HeadObjectOutput{}, nil
+	m.objects[key] = data
+	m.modTimes[key] = time.Now()
+	return &s3.CopyObjectOutput{}, nil
 }
 
 // CreateMultipartUpload implements API.CreateMultipartUpload for testing.
@@ -858,6 +1150,7 @@ func (m *MockS3Client) CompleteMultipartUpload(_ context.Context, params *s3.Com
 	}
 
 	m.objects[key] = assembled
+	m.modTimes[key] = time.Now()
 	delete(m.uploads, uploadID)
 
 	return &s3.CompleteMultipartUploadOutput{}, nil
@@ -881,6 +1174,7 @@ func (m *MockS3Client) DeleteObject(_ context.Context, params *s3.DeleteObjectIn
 
 	m.mu.Lock()
 	delete(m.objects, key)
+	delete(m.modTimes, key)
 	m.mu.Unlock()
 
 	return &s3.DeleteObjectOutput{}, nil
@@ -889,21 +1183,38 @@ func (m *MockS3Client) DeleteObject(_ context.Context, params *s3.DeleteObjectIn
 // ListObjectsV2 implements API.ListObjectsV2 for testing.
 func (m *MockS3Client) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
 	prefix := aws.ToString(params.Prefix)
+	delimiter := aws.ToString(params.Delimiter)
 
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var contents []types.Object
+	var commonPrefixes []types.CommonPrefix
+	seen := make(map[string]bool)
 	for key := range m.objects {
-		if strings.HasPrefix(key, prefix) {
-			k := key
-			contents = append(contents, types.Object{Key: &k})
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+			if cp := prefix + rest[:i+len(delimiter)]; !seen[cp] {
+				seen[cp] = true
+				commonPrefixes = append(commonPrefixes, types.CommonPrefix{Prefix: aws.String(cp)})
+			}
+			continue
 		}
+		k := key
+		contents = append(contents, types.Object{
+			Key:          &k,
+			Size:         aws.Int64(int64(len(m.objects[key]))),
+			LastModified: aws.Time(m.modTimes[key]),
+		})
 	}
 
 	return &s3.ListObjectsV2Output{
-		Contents:    contents,
-		IsTruncated: aws.Bool(false),
+		Contents:       contents,
+		CommonPrefixes: commonPrefixes,
+		IsTruncated:    aws.Bool(false),
 	}, nil
 }
 
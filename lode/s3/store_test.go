@@ -9,9 +9,11 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	s3api "github.com/aws/aws-sdk-go-v2/service/s3"
@@ -151,7 +153,7 @@ func TestShouldUseAtomicPath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := shouldUseAtomicPath(tt.size)
+			got := shouldUseAtomicPath(tt.size, maxAtomicPutSize)
 			if got != tt.expected {
 				t.Errorf("shouldUseAtomicPath(%d) = %v, want %v", tt.size, got, tt.expected)
 			}
@@ -159,6 +161,58 @@ func TestShouldUseAtomicPath(t *testing.T) {
 	}
 }
 
+func TestNew_MultipartThresholdValidation(t *testing.T) {
+	mock := NewMockS3Client()
+	for _, threshold := range []int64{-1, minPartSize - 1, maxAtomicPutSize + 1} {
+		if _, err := New(mock, Config{Bucket: "test", MultipartThreshold: threshold}); err == nil {
+			t.Errorf("threshold %d: expected error", threshold)
+		}
+	}
+	for _, threshold := range []int64{0, minPartSize, maxAtomicPutSize} {
+		if _, err := New(mock, Config{Bucket: "test", MultipartThreshold: threshold}); err != nil {
+			t.Errorf("threshold %d: unexpected error: %v", threshold, err)
+		}
+	}
+}
+
+func TestStore_Put_MultipartThreshold_Routes(t *testing.T) {
+	ctx := t.Context()
+	mock := NewMockS3Client()
+	store, err := New(mock, Config{Bucket: "test", MultipartThreshold: minPartSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	small := bytes.Repeat([]byte("a"), minPartSize)
+	if err := store.Put(ctx, "small.bin", bytes.NewReader(small)); err != nil {
+		t.Fatalf("Put small failed: %v", err)
+	}
+
+	large := make([]byte, 2*minPartSize+1)
+	for i := range large {
+		large[i] = byte(i % 251)
+	}
+	if err := store.Put(ctx, "large.bin", bytes.NewReader(large)); err != nil {
+		t.Fatalf("Put large failed: %v", err)
+	}
+
+	mock.mu.RLock()
+	putCalls := mock.PutObjectCalls
+	createCalls := mock.CreateMultipartUploadCalls
+	stored := mock.objects["large.bin"]
+	mock.mu.RUnlock()
+
+	if putCalls != 1 {
+		t.Errorf("expected 1 PutObject call (payload at threshold), got %d", putCalls)
+	}
+	if createCalls != 1 {
+		t.Errorf("expected 1 CreateMultipartUpload call (payload above threshold), got %d", createCalls)
+	}
+	if !bytes.Equal(large, stored) {
+		t.Error("multipart payload does not match original")
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Internal path tests - verify atomic and multipart paths work correctly
 // by calling internal methods directly with small test data.
@@ -708,6 +762,132 @@ func TestStore_Exists_ErrInvalidPath(t *testing.T) {
 	}
 }
 
+// -----------------------------------------------------------------------------
+// ExistsMany tests
+// -----------------------------------------------------------------------------
+
+func TestStore_ExistsMany(t *testing.T) {
+	ctx := t.Context()
+	client := NewMockS3Client()
+	store, _ := New(client, Config{Bucket: "test", Prefix: "pfx"})
+
+	_ = store.Put(ctx, "a.txt", bytes.NewReader([]byte("a")))
+	_ = store.Put(ctx, "dir/b.txt", bytes.NewReader([]byte("b")))
+
+	paths := []string{"a.txt", "dir/b.txt", "missing.txt", "a.txt"}
+	client.ResetCounts()
+
+	got, err := store.ExistsMany(ctx, paths)
+	if err != nil {
+		t.Fatalf("ExistsMany failed: %v", err)
+	}
+	want := map[string]bool{"a.txt": true, "dir/b.txt": true, "missing.txt": false}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %v", len(want), got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, got[k])
+		}
+	}
+	if client.HeadObjectCalls != len(want) {
+		t.Errorf("expected %d HeadObject calls (deduplicated), got %d", len(want), client.HeadObjectCalls)
+	}
+
+	// The per-path fallback must agree with the batch path.
+	fallback, err := lode.ExistsMany(ctx, struct{ lode.Store }{store}, paths)
+	if err != nil {
+		t.Fatalf("fallback ExistsMany failed: %v", err)
+	}
+	for k, v := range want {
+		if fallback[k] != v {
+			t.Errorf("fallback %s: expected %v, got %v", k, v, fallback[k])
+		}
+	}
+}
+
+func TestStore_ExistsMany_ErrInvalidPath(t *testing.T) {
+	ctx := t.Context()
+	client := NewMockS3Client()
+	store, _ := New(client, Config{Bucket: "test"})
+
+	_, err := store.ExistsMany(ctx, []string{"ok.txt", ""})
+	if !errors.Is(err, lode.ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got: %v", err)
+	}
+	if client.HeadObjectCalls != 0 {
+		t.Errorf("expected no HeadObject calls for invalid input, got %d", client.HeadObjectCalls)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Stat tests
+// -----------------------------------------------------------------------------
+
+func TestStore_Stat(t *testing.T) {
+	ctx := t.Context()
+	store, _ := New(NewMockS3Client(), Config{Bucket: "test", Prefix: "pfx"})
+
+	before := time.Now()
+	_ = store.Put(ctx, "a.txt", bytes.NewReader([]byte("hello")))
+
+	info, err := store.Stat(ctx, "a.txt")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.SizeBytes != 5 {
+		t.Errorf("SizeBytes = %d, want 5", info.SizeBytes)
+	}
+	if info.ModTime.Before(before) || info.ModTime.After(time.Now()) {
+		t.Errorf("ModTime = %v, want LastModified at Put (%v)", info.ModTime, before)
+	}
+	if _, err := store.Stat(ctx, "missing.txt"); !errors.Is(err, lode.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if _, err := store.Stat(ctx, ""); !errors.Is(err, lode.ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got: %v", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Copy tests
+// -----------------------------------------------------------------------------
+
+func TestStore_Copy(t *testing.T) {
+	ctx := t.Context()
+	client := NewMockS3Client()
+	store, _ := New(client, Config{Bucket: "test", Prefix: "pfx"})
+
+	_ = store.Put(ctx, "a dir/src.txt", bytes.NewReader([]byte("hello")))
+	client.ResetCounts()
+
+	if err := lode.CopyObject(ctx, store, "a dir/src.txt", "dst.txt"); err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	if client.CopyObjectCalls != 1 || client.PutObjectCalls != 0 {
+		t.Errorf("expected 1 CopyObject and no PutObject, got %d and %d", client.CopyObjectCalls, client.PutObjectCalls)
+	}
+	rc, err := store.Get(ctx, "dst.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(data) != "hello" {
+		t.Errorf("copied content = %q, want hello", data)
+	}
+
+	if err := store.Copy(ctx, "a dir/src.txt", "dst.txt"); !errors.Is(err, lode.ErrPathExists) {
+		t.Errorf("expected ErrPathExists, got: %v", err)
+	}
+	if err := store.Copy(ctx, "missing.txt", "other.txt"); !errors.Is(err, lode.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if err := store.Copy(ctx, "../escape", "other.txt"); !errors.Is(err, lode.ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got: %v", err)
+	}
+}
+
 // -----------------------------------------------------------------------------
 // Delete tests
 // -----------------------------------------------------------------------------
@@ -807,6 +987,97 @@ func TestStore_List_WithStorePrefix(t *testing.T) {
 	}
 }
 
+func TestStore_ListPrefixes(t *testing.T) {
+	ctx := t.Context()
+	store, _ := New(NewMockS3Client(), Config{Bucket: "test", Prefix: "root/"})
+
+	for _, key := range []string{"datasets/a/snapshots/1/manifest.json", "datasets/a/snapshots/2/manifest.json", "datasets/b/x", "datasets/README"} {
+		if err := store.Put(ctx, key, bytes.NewReader([]byte("1"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, prefixes, err := store.ListPrefixes(ctx, "datasets/", "/")
+	if err != nil {
+		t.Fatalf("ListPrefixes failed: %v", err)
+	}
+	slices.Sort(prefixes)
+	if !slices.Equal(keys, []string{"datasets/README"}) {
+		t.Errorf("keys = %v, want [datasets/README]", keys)
+	}
+	if !slices.Equal(prefixes, []string{"datasets/a/", "datasets/b/"}) {
+		t.Errorf("common prefixes = %v, want [datasets/a/ datasets/b/]", prefixes)
+	}
+
+	if _, _, err := store.ListPrefixes(ctx, "../", "/"); !errors.Is(err, lode.ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got: %v", err)
+	}
+}
+
+func TestStore_ListSized(t *testing.T) {
+	ctx := t.Context()
+	client := NewMockS3Client()
+	store, _ := New(client, Config{Bucket: "test", Prefix: "root/"})
+	var _ lode.SizedLister = store
+
+	before := time.Now()
+	_ = store.Put(ctx, "a/1.txt", bytes.NewReader([]byte("1")))
+	_ = store.Put(ctx, "a/2.txt", bytes.NewReader([]byte("22")))
+	_ = store.Put(ctx, "b/3.txt", bytes.NewReader([]byte("333")))
+	client.ResetCounts()
+
+	infos, err := store.ListSized(ctx, "a/")
+	if err != nil {
+		t.Fatalf("ListSized failed: %v", err)
+	}
+	slices.SortFunc(infos, func(a, b lode.ObjectInfo) int { return strings.Compare(a.Path, b.Path) })
+	if len(infos) != 2 || infos[0].Path != "a/1.txt" || infos[0].SizeBytes != 1 || infos[1].Path != "a/2.txt" || infos[1].SizeBytes != 2 {
+		t.Fatalf("ListSized = %+v, want a/1.txt (1 byte) and a/2.txt (2 bytes)", infos)
+	}
+	if infos[0].ModTime.Before(before) {
+		t.Errorf("ModTime = %v, want the time of Put", infos[0].ModTime)
+	}
+	if heads := client.HeadObjectCalls; heads != 0 {
+		t.Errorf("ListSized issued %d HeadObject calls, want 0", heads)
+	}
+}
+
+func TestStore_List_SkipsDirectoryMarkers(t *testing.T) {
+	ctx := t.Context()
+	client := NewMockS3Client()
+	store, _ := New(client, Config{Bucket: "test", Prefix: "root/"})
+
+	if err := store.Put(ctx, "datasets/foo/snapshots/1/manifest.json", bytes.NewReader([]byte("{}"))); err != nil {
+		t.Fatal(err)
+	}
+	// Zero-byte directory placeholders, as created by consoles and sync tools.
+	for _, key := range []string{"root/", "root/datasets/", "root/datasets/foo/", "root/datasets/foo/snapshots/"} {
+		if _, err := client.PutObject(ctx, &s3api.PutObjectInput{
+			Bucket: aws.String("test"),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(nil),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := store.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !slices.Equal(keys, []string{"datasets/foo/snapshots/1/manifest.json"}) {
+		t.Errorf("List = %v, want only the manifest", keys)
+	}
+
+	keys, prefixes, err := store.ListPrefixes(ctx, "datasets/foo/", "/")
+	if err != nil {
+		t.Fatalf("ListPrefixes failed: %v", err)
+	}
+	if len(keys) != 0 || !slices.Equal(prefixes, []string{"datasets/foo/snapshots/"}) {
+		t.Errorf("ListPrefixes = %v, %v; want no keys and [datasets/foo/snapshots/]", keys, prefixes)
+	}
+}
+
 func TestStore_List_ErrInvalidPath(t *testing.T) {
 	ctx := t.Context()
 	store, _ := New(NewMockS3Client(), Config{Bucket: "test"})
@@ -887,8 +1158,8 @@ func TestStore_ReadRange_NegativeOffset(t *testing.T) {
 	store, _ := New(NewMockS3Client(), Config{Bucket: "test"})
 
 	_, err := store.ReadRange(ctx, "test.txt", -1, 10)
-	if !errors.Is(err, lode.ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for negative offset, got: %v", err)
+	if !errors.Is(err, lode.ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for negative offset, got: %v", err)
 	}
 }
 
@@ -897,8 +1168,8 @@ func TestStore_ReadRange_NegativeLength(t *testing.T) {
 	store, _ := New(NewMockS3Client(), Config{Bucket: "test"})
 
 	_, err := store.ReadRange(ctx, "test.txt", 0, -1)
-	if !errors.Is(err, lode.ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for negative length, got: %v", err)
+	if !errors.Is(err, lode.ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for negative length, got: %v", err)
 	}
 }
 
@@ -912,8 +1183,8 @@ func TestStore_ReadRange_LengthOverflow(t *testing.T) {
 	store, _ := New(NewMockS3Client(), Config{Bucket: "test"})
 
 	_, err := store.ReadRange(ctx, "test.txt", 0, math.MaxInt64)
-	if !errors.Is(err, lode.ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for length overflow, got: %v", err)
+	if !errors.Is(err, lode.ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for length overflow, got: %v", err)
 	}
 }
 
@@ -923,8 +1194,8 @@ func TestStore_ReadRange_OffsetPlusLengthOverflow(t *testing.T) {
 
 	// offset + length would overflow int64
 	_, err := store.ReadRange(ctx, "test.txt", math.MaxInt64-10, 20)
-	if !errors.Is(err, lode.ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for offset+length overflow, got: %v", err)
+	if !errors.Is(err, lode.ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for offset+length overflow, got: %v", err)
 	}
 }
 
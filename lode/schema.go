@@ -0,0 +1,219 @@
+package lode
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Record Schema
+// -----------------------------------------------------------------------------
+
+// FieldType is the expected type of a record field in a Schema.
+type FieldType string
+
+// Field types supported by Schema validation.
+const (
+	// FieldString accepts Go strings.
+	FieldString FieldType = "string"
+	// FieldNumber accepts any Go integer or floating-point value.
+	FieldNumber FieldType = "number"
+	// FieldBool accepts Go bools.
+	FieldBool FieldType = "bool"
+	// FieldTime accepts time.Time and RFC3339 strings.
+	FieldTime FieldType = "time"
+	// FieldAny accepts any value; use it to declare a field without a type.
+	FieldAny FieldType = "any"
+)
+
+// SchemaField declares one record field.
+type SchemaField struct {
+	// Name is the record key.
+	Name string
+	// Type is the expected value type.
+	Type FieldType
+	// Required rejects records where the field is missing or nil.
+	Required bool
+}
+
+// Schema declares the fields of a dataset's records for write-time validation.
+//
+// Records must be map[string]any. Optional fields are type-checked only
+// when present and non-nil. When Strict is set, keys not declared in Fields
+// are rejected.
+//
+// Name and Version are recorded in manifests as record_schema_name and
+// record_schema_version. They are distinct from Manifest.SchemaName, which
+// identifies the manifest format itself.
+type Schema struct {
+	Name    string
+	Version string
+	Fields  []SchemaField
+	Strict  bool
+}
+
+// SchemaError reports the first record that failed schema validation.
+// It matches ErrSchemaViolation via errors.Is.
+type SchemaError struct {
+	// Index is the position of the record in the write (0-based).
+	Index int
+	// Field is the offending field. Empty when the record itself is invalid.
+	Field string
+	// Reason describes the violation.
+	Reason string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("schema violation: record %d: %s", e.Index, e.Reason)
+	}
+	return fmt.Sprintf("schema violation: record %d: field %q: %s", e.Index, e.Field, e.Reason)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaViolation
+}
+
+// validateDefinition checks the schema itself at configuration time.
+func (s *Schema) validateDefinition() error {
+	if s.Name == "" {
+		return errors.New("schema name must not be empty")
+	}
+	seen := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		if f.Name == "" {
+			return errors.New("schema field name must not be empty")
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("duplicate schema field %q", f.Name)
+		}
+		seen[f.Name] = true
+		switch f.Type {
+		case FieldString, FieldNumber, FieldBool, FieldTime, FieldAny:
+		default:
+			return fmt.Errorf("schema field %q: unknown type %q", f.Name, f.Type)
+		}
+	}
+	return nil
+}
+
+// validateRecords checks records in order and returns the first violation.
+func (s *Schema) validateRecords(records []any) error {
+	for i, record := range records {
+		if err := s.validateRecord(i, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRecord checks a single record at the given index.
+func (s *Schema) validateRecord(index int, record any) error {
+	m, ok := record.(map[string]any)
+	if !ok {
+		return &SchemaError{Index: index, Reason: fmt.Sprintf("record must be map[string]any, got %T", record)}
+	}
+
+	for _, f := range s.Fields {
+		val, present := m[f.Name]
+		if !present || val == nil {
+			if f.Required {
+				return &SchemaError{Index: index, Field: f.Name, Reason: "required field missing"}
+			}
+			continue
+		}
+		if !fieldTypeMatches(f.Type, val) {
+			return &SchemaError{Index: index, Field: f.Name, Reason: fmt.Sprintf("expected %s, got %T", f.Type, val)}
+		}
+	}
+
+	if s.Strict {
+		// Report the unknown key deterministically when several are present.
+		var unknown string
+		for key := range m {
+			if !s.declares(key) && (unknown == "" || key < unknown) {
+				unknown = key
+			}
+		}
+		if unknown != "" {
+			return &SchemaError{Index: index, Field: unknown, Reason: "field not declared in schema"}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) declares(name string) bool {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldTypeMatches(t FieldType, val any) bool {
+	switch t {
+	case FieldString:
+		_, ok := val.(string)
+		return ok
+	case FieldNumber:
+		switch val.(type) {
+		case int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32, uint64,
+			float32, float64:
+			return true
+		}
+		return false
+	case FieldBool:
+		_, ok := val.(bool)
+		return ok
+	case FieldTime:
+		switch v := val.(type) {
+		case time.Time:
+			return true
+		case string:
+			_, err := time.Parse(time.RFC3339Nano, v)
+			return err == nil
+		}
+		return false
+	case FieldAny:
+		return true
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
+// WithSchema Option
+// -----------------------------------------------------------------------------
+
+// schemaOption implements Option for WithSchema (dataset-only).
+type schemaOption struct {
+	schema *Schema
+}
+
+// WithSchema validates records against a schema before they are encoded.
+// Default: none (records are not validated).
+// This option is only valid for NewDataset and requires a codec.
+//
+// Write, Append, and StreamWriteRecords fail with a *SchemaError naming the
+// first invalid record and field; nothing is committed. Manifests record
+// the schema name and version.
+func WithSchema(s *Schema) Option {
+	return &schemaOption{schema: s}
+}
+
+func (o *schemaOption) applyDataset(cfg *datasetConfig) error {
+	if o.schema == nil {
+		return errors.New("WithSchema: schema must not be nil")
+	}
+	if err := o.schema.validateDefinition(); err != nil {
+		return fmt.Errorf("WithSchema: %w", err)
+	}
+	cfg.schema = o.schema
+	return nil
+}
+
+func (o *schemaOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithSchema: %w", ErrOptionNotValidForDatasetReader)
+}
@@ -0,0 +1,127 @@
+package lode
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func eventSchema(strict bool) *Schema {
+	return &Schema{
+		Name:    "events",
+		Version: "2",
+		Strict:  strict,
+		Fields: []SchemaField{
+			{Name: "id", Type: FieldString, Required: true},
+			{Name: "count", Type: FieldNumber},
+			{Name: "at", Type: FieldTime},
+		},
+	}
+}
+
+func TestDataset_Write_WithSchema_RecordsSchemaInManifest(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithSchema(eventSchema(false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": "a", "count": 1, "at": time.Now()},
+		D{"id": "b", "at": "2024-01-01T00:00:00Z", "extra": true},
+	), Metadata{})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if snap.Manifest.RecordSchemaName != "events" || snap.Manifest.RecordSchemaVersion != "2" {
+		t.Errorf("unexpected record schema: %q %q", snap.Manifest.RecordSchemaName, snap.Manifest.RecordSchemaVersion)
+	}
+	if snap.Manifest.SchemaName != "lode-manifest" {
+		t.Errorf("manifest schema name must be unchanged, got %q", snap.Manifest.SchemaName)
+	}
+}
+
+func TestDataset_Write_WithSchema_Violations(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		record D
+		field  string
+	}{
+		{"missing required", false, D{"count": 1}, "id"},
+		{"nil required", false, D{"id": nil}, "id"},
+		{"wrong type", false, D{"id": "a", "count": "one"}, "count"},
+		{"bad time string", false, D{"id": "a", "at": "yesterday"}, "at"},
+		{"unknown field strict", true, D{"id": "a", "idd": "typo"}, "idd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newFaultStore(NewMemory())
+			ds, err := NewDataset("test-ds", newFaultStoreFactory(fs),
+				WithCodec(NewJSONLCodec()),
+				WithSchema(eventSchema(tt.strict)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = ds.Write(t.Context(), R(D{"id": "ok"}, tt.record), Metadata{})
+			if !errors.Is(err, ErrSchemaViolation) {
+				t.Fatalf("expected ErrSchemaViolation, got: %v", err)
+			}
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected *SchemaError, got: %T", err)
+			}
+			if schemaErr.Index != 1 || schemaErr.Field != tt.field {
+				t.Errorf("expected record 1 field %q, got record %d field %q", tt.field, schemaErr.Index, schemaErr.Field)
+			}
+			if calls := fs.PutCalls(); len(calls) != 0 {
+				t.Errorf("expected no writes, got %v", calls)
+			}
+		})
+	}
+}
+
+func TestDataset_StreamWriteRecords_WithSchema_Violation(t *testing.T) {
+	ds, err := NewDataset("test-ds", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithSchema(eventSchema(false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: R(D{"id": "a"}, D{"id": "b"}, D{"count": 3})}
+	_, err = ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || schemaErr.Index != 2 || schemaErr.Field != "id" {
+		t.Fatalf("expected schema error at record 2 field id, got: %v", err)
+	}
+
+	if _, err := ds.Latest(t.Context()); !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected no snapshot after violation, got: %v", err)
+	}
+}
+
+func TestWithSchema_InvalidConfiguration(t *testing.T) {
+	cases := map[string]*Schema{
+		"nil":             nil,
+		"empty name":      {Fields: []SchemaField{{Name: "id", Type: FieldString}}},
+		"empty field":     {Name: "s", Fields: []SchemaField{{Type: FieldString}}},
+		"duplicate field": {Name: "s", Fields: []SchemaField{{Name: "id", Type: FieldString}, {Name: "id", Type: FieldAny}}},
+		"unknown type":    {Name: "s", Fields: []SchemaField{{Name: "id", Type: "uuid"}}},
+	}
+	for name, s := range cases {
+		if _, err := NewDataset("test-ds", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithSchema(s)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := NewDataset("test-ds", NewMemoryFactory(), WithSchema(eventSchema(false))); err == nil {
+		t.Error("expected error for schema without codec")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithSchema(eventSchema(false))); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 )
 
 // ErrInvalidPath indicates a path that would escape the storage root.
@@ -110,39 +113,148 @@ func (f *fsStore) Exists(_ context.Context, path string) (bool, error) {
 	return false, err
 }
 
+func (f *fsStore) Stat(_ context.Context, path string) (ObjectInfo, error) {
+	fullPath, err := f.safePathForFile(path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ObjectInfo{}, ErrNotFound
+		}
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{SizeBytes: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// Copy hard-links dst to src, falling back to copying the file contents
+// when linking is not possible (e.g. across devices). Objects are immutable,
+// so sharing the inode is safe.
+func (f *fsStore) Copy(ctx context.Context, src, dst string) error {
+	srcPath, err := f.safePathForFile(src)
+	if err != nil {
+		return err
+	}
+	dstPath, err := f.safePathForFile(dst)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if info.IsDir() {
+		return ErrNotFound
+	}
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
+		return err
+	}
+
+	err = os.Link(srcPath, dstPath)
+	if err == nil {
+		return nil
+	}
+	if os.IsExist(err) {
+		return ErrPathExists
+	}
+
+	file, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	return f.Put(ctx, dst, file)
+}
+
+// List returns all files whose slash-separated relative path starts with
+// prefix. As with the memory and S3 stores, the prefix need not end at a
+// directory boundary ("datasets/ten" matches "datasets/tenant-a/...").
 func (f *fsStore) List(_ context.Context, prefix string) ([]string, error) {
-	searchPath, err := f.safePathForPrefix(prefix)
+	var paths []string
+	err := f.walk(prefix, func(relPath string, _ fs.DirEntry) error {
+		paths = append(paths, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// ListSized returns the files under prefix with their sizes and
+// modification times from the same directory walk as List.
+// Implements SizedLister.
+func (f *fsStore) ListSized(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	var infos []ObjectInfo
+	err := f.walk(prefix, func(relPath string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if os.IsNotExist(err) {
+			return nil // removed during the walk
+		}
+		if err != nil {
+			return err
+		}
+		infos = append(infos, ObjectInfo{Path: relPath, SizeBytes: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return infos, nil
+}
+
+// walk calls fn for each file whose slash-separated relative path starts
+// with prefix.
+func (f *fsStore) walk(prefix string, fn func(relPath string, d fs.DirEntry) error) error {
+	if _, err := f.safePathForPrefix(prefix); err != nil {
+		return err
+	}
+
+	// Walk the deepest directory named by the prefix and filter the rest.
+	dir, match := prefix, ""
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		dir, match = filepath.Dir(filepath.FromSlash(prefix)), filepath.ToSlash(filepath.Clean(prefix))
+	}
+	searchPath, err := f.safePathForPrefix(dir)
+	if err != nil {
+		return err
+	}
 
 	// Return empty list if prefix directory doesn't exist (empty dataset/prefix semantics)
 	if _, err := os.Stat(searchPath); os.IsNotExist(err) {
-		return nil, nil
+		return nil
 	}
 
-	var paths []string
-
-	err = filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
+	return filepath.WalkDir(searchPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			if os.IsNotExist(err) {
 				return nil
 			}
 			return err
 		}
-		if !d.IsDir() {
-			relPath, err := filepath.Rel(f.root, path)
-			if err != nil {
-				return err
+		relPath, err := filepath.Rel(f.root, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if d.IsDir() {
+			// Skip directories that cannot contain a match.
+			if match != "" && path != searchPath &&
+				!strings.HasPrefix(relPath, match) && !strings.HasPrefix(match, relPath+"/") {
+				return filepath.SkipDir
 			}
-			paths = append(paths, filepath.ToSlash(relPath))
+			return nil
+		}
+		if match == "" || strings.HasPrefix(relPath, match) {
+			return fn(relPath, d)
 		}
 		return nil
 	})
-	if err != nil {
-		return nil, err
-	}
-	return paths, nil
 }
 
 func (f *fsStore) Delete(_ context.Context, path string) error {
@@ -159,11 +271,11 @@ func (f *fsStore) Delete(_ context.Context, path string) error {
 
 func (f *fsStore) ReadRange(_ context.Context, path string, offset, length int64) ([]byte, error) {
 	if offset < 0 || length < 0 || length > maxReadRangeLength {
-		return nil, ErrInvalidPath
+		return nil, ErrInvalidRange
 	}
 	// Check for offset+length overflow
 	if offset > math.MaxInt64-length {
-		return nil, ErrInvalidPath
+		return nil, ErrInvalidRange
 	}
 
 	fullPath, err := f.safePathForFile(path)
@@ -180,6 +292,17 @@ func (f *fsStore) ReadRange(_ context.Context, path string, offset, length int64
 	}
 	defer func() { _ = file.Close() }()
 
+	// Clamp to the file size before allocating so oversized lengths
+	// cost only what is available.
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if offset >= info.Size() {
+		return []byte{}, nil
+	}
+	length = min(length, info.Size()-offset)
+
 	data := make([]byte, int(length))
 	n, err := file.ReadAt(data, offset)
 	if err != nil && !errors.Is(err, io.EOF) {
@@ -257,14 +380,255 @@ func (f *fsStore) safePathForPrefix(path string) (string, error) {
 	return filepath.Join(f.root, cleaned), nil
 }
 
+// ExistsMany reports the existence of each path in the store.
+//
+// Uses the store's BatchExistsStore implementation when available and
+// falls back to one Exists call per path otherwise. The returned map has
+// an entry for every requested path.
+func ExistsMany(ctx context.Context, store Store, paths []string) (map[string]bool, error) {
+	if batch, ok := store.(BatchExistsStore); ok {
+		return batch.ExistsMany(ctx, paths)
+	}
+
+	result := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if _, seen := result[p]; seen {
+			continue
+		}
+		exists, err := store.Exists(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("lode: exists %s: %w", p, err)
+		}
+		result[p] = exists
+	}
+	return result, nil
+}
+
+// ListPrefixes lists one level of the keys under prefix, grouping every
+// key that contains delimiter after prefix under its common prefix.
+//
+// Uses the store's DelimitedLister implementation when available and falls
+// back to grouping a full List otherwise, returning both lists sorted. An
+// empty delimiter returns every key under prefix and no common prefixes.
+func ListPrefixes(ctx context.Context, store Store, prefix, delimiter string) (keys, commonPrefixes []string, err error) {
+	if dl, ok := store.(DelimitedLister); ok {
+		return dl.ListPrefixes(ctx, prefix, delimiter)
+	}
+
+	paths, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		i := strings.Index(rest, delimiter)
+		if delimiter == "" || i < 0 {
+			keys = append(keys, p)
+			continue
+		}
+		common := prefix + rest[:i+len(delimiter)]
+		if !seen[common] {
+			seen[common] = true
+			commonPrefixes = append(commonPrefixes, common)
+		}
+	}
+	slices.Sort(keys)
+	slices.Sort(commonPrefixes)
+	return keys, commonPrefixes, nil
+}
+
+// ListSized returns the objects under prefix with their sizes and
+// modification times.
+//
+// Uses the store's SizedLister implementation when available and falls back
+// to List plus one StatObject per key otherwise, skipping keys deleted in
+// between. Ordering is unspecified.
+func ListSized(ctx context.Context, store Store, prefix string) ([]ObjectInfo, error) {
+	if sl, ok := store.(SizedLister); ok {
+		return sl.ListSized(ctx, prefix)
+	}
+
+	paths, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]ObjectInfo, 0, len(paths))
+	for _, p := range paths {
+		info, err := StatObject(ctx, store, p)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		info.Path = p
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// CopyObject copies the object at src to dst.
+//
+// Uses the store's CopyStore implementation when available and falls back
+// to streaming the object through Get and Put otherwise. Returns ErrNotFound
+// if src does not exist and ErrPathExists if dst already exists.
+func CopyObject(ctx context.Context, store Store, src, dst string) error {
+	if c, ok := store.(CopyStore); ok {
+		return c.Copy(ctx, src, dst)
+	}
+
+	rc, err := store.Get(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	return store.Put(ctx, dst, rc)
+}
+
+// StatObject returns metadata for the object at path.
+//
+// Uses the store's StatStore implementation when available and falls back
+// to reading the object through Get otherwise. Returns ErrNotFound if the
+// path does not exist.
+func StatObject(ctx context.Context, store Store, path string) (ObjectInfo, error) {
+	if s, ok := store.(StatStore); ok {
+		return s.Stat(ctx, path)
+	}
+
+	rc, err := store.Get(ctx, path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer func() { _ = rc.Close() }()
+	n, err := io.Copy(io.Discard, rc)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("lode: stat %s: %w", path, err)
+	}
+	return ObjectInfo{SizeBytes: n}, nil
+}
+
+// ReadTail returns the last n bytes of the object at path, such as the
+// footer of a format with a trailer.
+//
+// The object size comes from StatObject, so on stores without StatStore the
+// object is read once in full to size it. Returns ErrInvalidRange if n is
+// negative or exceeds the object size, ErrNotFound if the path does not
+// exist, and ErrRangeReadNotSupported if the store does not support range
+// reads.
+func ReadTail(ctx context.Context, store Store, path string, n int64) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("lode: read tail %s: negative length %d: %w", path, n, ErrInvalidRange)
+	}
+	info, err := StatObject(ctx, store, path)
+	if err != nil {
+		return nil, err
+	}
+	if n > info.SizeBytes {
+		return nil, fmt.Errorf("lode: read tail %s: %d bytes exceeds object size %d: %w", path, n, info.SizeBytes, ErrInvalidRange)
+	}
+	data, err := store.ReadRange(ctx, path, info.SizeBytes-n, n)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != n {
+		// The object shrank between Stat and ReadRange.
+		return nil, fmt.Errorf("lode: read tail %s: got %d of %d bytes: %w", path, len(data), n, io.ErrUnexpectedEOF)
+	}
+	return data, nil
+}
+
+// OpenSection returns random access to the object at path together with its
+// size, the pair consumed by RandomAccessCodec.DecodeRange and by Parquet
+// readers that seek to a footer and then to column chunks.
+//
+// Reads go through the store's ReaderAt; the size comes from StatObject.
+// Returns ErrNotFound if the path does not exist and ErrRangeReadNotSupported
+// if the store does not support range reads.
+func OpenSection(ctx context.Context, store Store, path string) (*io.SectionReader, error) {
+	ra, err := store.ReaderAt(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := StatObject(ctx, store, path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NewSectionReader(ra, 0, info.SizeBytes), nil
+}
+
+// -----------------------------------------------------------------------------
+// Health Checks
+// -----------------------------------------------------------------------------
+
+// healthCheckPrefix is the key prefix for health check probes. Probe keys
+// contain no '/', so they sit outside every layout's dataset paths: the
+// default and hive layouts keep datasets under "datasets/", and the flat
+// layout under "<dataset>/".
+const healthCheckPrefix = "_lode_health-"
+
+// HealthCheck verifies that store is reachable and writable: it puts a small
+// probe object at a root-level key outside every dataset, reads it back,
+// compares the bytes, and deletes it. Each call uses a unique key, so
+// concurrent checks do not collide. The returned error names the step that
+// failed; a probe left behind by a failed check is deleted on a best-effort
+// basis.
+func HealthCheck(ctx context.Context, store Store) error {
+	key := healthCheckPrefix + generateID()
+	probe := []byte("lode health check " + key)
+
+	if err := store.Put(ctx, key, bytes.NewReader(probe)); err != nil {
+		return fmt.Errorf("lode: health check put %s: %w", key, err)
+	}
+	if err := verifyProbe(ctx, store, key, probe); err != nil {
+		_ = store.Delete(context.WithoutCancel(ctx), key)
+		return err
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		return fmt.Errorf("lode: health check delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// verifyProbe reads the probe object at key back and compares it to want.
+func verifyProbe(ctx context.Context, store Store, key string, want []byte) error {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("lode: health check get %s: %w", key, err)
+	}
+	defer func() { _ = rc.Close() }()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("lode: health check read %s: %w", key, err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("lode: health check get %s: read back %d bytes that differ from the %d bytes written", key, len(got), len(want))
+	}
+	return nil
+}
+
+// HealthCheckReadOnly verifies that store is reachable for reads with a
+// single List of the health check prefix, which is normally empty.
+// It writes nothing, for read-only credentials.
+func HealthCheckReadOnly(ctx context.Context, store Store) error {
+	if _, err := store.List(ctx, healthCheckPrefix); err != nil {
+		return fmt.Errorf("lode: health check list: %w", err)
+	}
+	return nil
+}
+
 // -----------------------------------------------------------------------------
 // Memory Store
 // -----------------------------------------------------------------------------
 
 // memoryStore implements Store using an in-memory map.
 type memoryStore struct {
-	mu   sync.RWMutex
-	data map[string][]byte
+	mu       sync.RWMutex
+	data     map[string][]byte
+	modTimes map[string]time.Time // time each path was last written
 }
 
 // NewMemoryFactory returns a StoreFactory that creates an in-memory Store.
@@ -280,7 +644,8 @@ func NewMemoryFactory() StoreFactory {
 // Memory is safe for concurrent use.
 func NewMemory() Store {
 	return &memoryStore{
-		data: make(map[string][]byte),
+		data:     make(map[string][]byte),
+		modTimes: make(map[string]time.Time),
 	}
 }
 
@@ -303,6 +668,7 @@ func (m *memoryStore) Put(_ context.Context, path string, r io.Reader) error {
 	}
 
 	m.data[normalized] = data
+	m.modTimes[normalized] = time.Now()
 	return nil
 }
 
@@ -338,6 +704,50 @@ func (m *memoryStore) Exists(_ context.Context, path string) (bool, error) {
 	return exists, nil
 }
 
+func (m *memoryStore) Stat(_ context.Context, path string) (ObjectInfo, error) {
+	normalized, valid := normalizePathForFile(path)
+	if !valid {
+		return ObjectInfo{}, ErrInvalidPath
+	}
+
+	m.mu.RLock()
+	data, exists := m.data[normalized]
+	modTime := m.modTimes[normalized]
+	m.mu.RUnlock()
+
+	if !exists {
+		return ObjectInfo{}, ErrNotFound
+	}
+	return ObjectInfo{SizeBytes: int64(len(data)), ModTime: modTime}, nil
+}
+
+// Copy shares the stored bytes between src and dst; objects are immutable
+// and Get returns a copy, so neither path can observe changes to the other.
+func (m *memoryStore) Copy(_ context.Context, src, dst string) error {
+	srcNorm, valid := normalizePathForFile(src)
+	if !valid {
+		return ErrInvalidPath
+	}
+	dstNorm, valid := normalizePathForFile(dst)
+	if !valid {
+		return ErrInvalidPath
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, exists := m.data[srcNorm]
+	if !exists {
+		return ErrNotFound
+	}
+	if _, exists := m.data[dstNorm]; exists {
+		return ErrPathExists
+	}
+	m.data[dstNorm] = data
+	m.modTimes[dstNorm] = time.Now()
+	return nil
+}
+
 func (m *memoryStore) List(_ context.Context, prefix string) ([]string, error) {
 	normalized, valid := normalizePathForPrefix(prefix)
 	if !valid {
@@ -357,6 +767,26 @@ func (m *memoryStore) List(_ context.Context, prefix string) ([]string, error) {
 	return paths, nil
 }
 
+// ListSized returns the paths under prefix with their sizes and
+// modification times. Implements SizedLister.
+func (m *memoryStore) ListSized(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	normalized, valid := normalizePathForPrefix(prefix)
+	if !valid {
+		return nil, ErrInvalidPath
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var infos []ObjectInfo
+	for path, data := range m.data {
+		if strings.HasPrefix(path, normalized) {
+			infos = append(infos, ObjectInfo{Path: path, SizeBytes: int64(len(data)), ModTime: m.modTimes[path]})
+		}
+	}
+	return infos, nil
+}
+
 func (m *memoryStore) Delete(_ context.Context, path string) error {
 	normalized, valid := normalizePathForFile(path)
 	if !valid {
@@ -365,18 +795,39 @@ func (m *memoryStore) Delete(_ context.Context, path string) error {
 
 	m.mu.Lock()
 	delete(m.data, normalized)
+	delete(m.modTimes, normalized)
 	m.mu.Unlock()
 
 	return nil
 }
 
+// CompareAndSwap implements ConditionalWriter with a mutex-protected
+// read-compare-write.
+func (m *memoryStore) CompareAndSwap(_ context.Context, path, expected, replacement string) error {
+	normalized, valid := normalizePathForFile(path)
+	if !valid {
+		return ErrInvalidPath
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if string(m.data[normalized]) != expected {
+		return ErrSnapshotConflict
+	}
+
+	m.data[normalized] = []byte(replacement)
+	m.modTimes[normalized] = time.Now()
+	return nil
+}
+
 func (m *memoryStore) ReadRange(_ context.Context, path string, offset, length int64) ([]byte, error) {
 	if offset < 0 || length < 0 || length > maxReadRangeLength {
-		return nil, ErrInvalidPath
+		return nil, ErrInvalidRange
 	}
 	// Check for offset+length overflow
 	if offset > math.MaxInt64-length {
-		return nil, ErrInvalidPath
+		return nil, ErrInvalidRange
 	}
 
 	normalized, valid := normalizePathForFile(path)
@@ -460,5 +911,10 @@ func normalizePathForPrefix(path string) (string, bool) {
 		return "", false
 	}
 
+	// A trailing slash names a directory, as in the filesystem store:
+	// "a/b/" must not match "a/bc".
+	if strings.HasSuffix(path, "/") {
+		cleaned += "/"
+	}
 	return cleaned, true
 }
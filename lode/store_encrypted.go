@@ -0,0 +1,451 @@
+package lode
+
+import (
+	"bufio"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// -----------------------------------------------------------------------------
+// Encrypted Store
+// -----------------------------------------------------------------------------
+
+// encryptedChunkSize is the plaintext size of each sealed chunk. Range reads
+// decrypt whole chunks, so it bounds the read amplification of a range.
+const encryptedChunkSize = 64 << 10
+
+// encryptedStore implements Store by encrypting object bodies of an inner
+// store with an AEAD.
+//
+// Objects are stored as a random base nonce followed by the plaintext sealed
+// in chunks of encryptedChunkSize bytes. Chunk i is sealed with the base
+// nonce XOR i in its last 8 bytes, and with additional data marking whether
+// it is the final chunk, so reordered, dropped, or truncated chunks fail to
+// open. Every object has at least one (possibly empty) final chunk.
+type encryptedStore struct {
+	inner Store
+	aead  cipher.AEAD
+}
+
+// NewEncryptedStore returns a Store that encrypts object bodies written to
+// inner and decrypts them on read, using aead (for example AES-GCM from
+// crypto/cipher.NewGCM). Each object gets a fresh random nonce.
+//
+// Paths are not encrypted: List, Exists, and Delete pass through unchanged.
+// ReadRange and ReaderAt are supported when inner supports them; they read
+// and decrypt only the 64 KiB chunks covering the range, plus the object
+// header. Stat reports plaintext sizes.
+//
+// Ciphertexts are not bound to their paths, so CopyStore is forwarded when
+// inner implements it and a copied object decrypts at its new path.
+// ConditionalWriter is not forwarded: inner would compare ciphertext against
+// plaintext, so latest pointers fall back to Delete+Put.
+//
+// Objects that fail authentication, including those written under a
+// different key, return ErrDecryptionFailed.
+func NewEncryptedStore(inner Store, aead cipher.AEAD) (Store, error) {
+	if inner == nil {
+		return nil, errors.New("lode: encrypted store requires an inner store")
+	}
+	if aead == nil {
+		return nil, errors.New("lode: encrypted store requires an AEAD")
+	}
+	if aead.NonceSize() < 8 {
+		return nil, fmt.Errorf("lode: encrypted store requires a nonce of at least 8 bytes, got %d", aead.NonceSize())
+	}
+
+	s := &encryptedStore{inner: inner, aead: aead}
+	if _, ok := inner.(CopyStore); ok {
+		return &encryptedCopyStore{s}, nil
+	}
+	return s, nil
+}
+
+// sealedChunkSize is the stored size of a full chunk.
+func (s *encryptedStore) sealedChunkSize() int64 {
+	return encryptedChunkSize + int64(s.aead.Overhead())
+}
+
+// chunkNonce derives the nonce of chunk index from the object's base nonce.
+func chunkNonce(base []byte, index uint64) []byte {
+	nonce := slices.Clone(base)
+	tail := nonce[len(nonce)-8:]
+	binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)^index)
+	return nonce
+}
+
+// chunkAAD is the additional data sealed with a chunk.
+func chunkAAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func (s *encryptedStore) open(path string, base []byte, index uint64, sealed []byte, final bool) ([]byte, error) {
+	plain, err := s.aead.Open(nil, chunkNonce(base, index), sealed, chunkAAD(final))
+	if err != nil {
+		return nil, fmt.Errorf("lode: %s chunk %d: %w", path, index, ErrDecryptionFailed)
+	}
+	return plain, nil
+}
+
+// plaintextSize returns the plaintext size of a stored object of ctSize bytes.
+func (s *encryptedStore) plaintextSize(path string, ctSize int64) (int64, error) {
+	body := ctSize - int64(s.aead.NonceSize())
+	overhead := int64(s.aead.Overhead())
+	if body < overhead {
+		return 0, fmt.Errorf("lode: %s: truncated encrypted object: %w", path, ErrDecryptionFailed)
+	}
+	full, rem := body/s.sealedChunkSize(), body%s.sealedChunkSize()
+	if rem == 0 {
+		return full * encryptedChunkSize, nil
+	}
+	if rem < overhead {
+		return 0, fmt.Errorf("lode: %s: truncated encrypted object: %w", path, ErrDecryptionFailed)
+	}
+	return full*encryptedChunkSize + rem - overhead, nil
+}
+
+func (s *encryptedStore) Put(ctx context.Context, path string, r io.Reader) error {
+	base := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(base); err != nil {
+		return fmt.Errorf("lode: generate nonce: %w", err)
+	}
+	return s.inner.Put(ctx, path, &encryptingReader{store: s, src: r, base: base, out: base})
+}
+
+func (s *encryptedStore) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	rc, err := s.inner.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptingReader{
+		store: s,
+		path:  path,
+		src:   bufio.NewReaderSize(rc, int(s.sealedChunkSize())),
+		rc:    rc,
+	}, nil
+}
+
+func (s *encryptedStore) Exists(ctx context.Context, path string) (bool, error) {
+	return s.inner.Exists(ctx, path)
+}
+
+func (s *encryptedStore) List(ctx context.Context, prefix string) ([]string, error) {
+	return s.inner.List(ctx, prefix)
+}
+
+func (s *encryptedStore) Delete(ctx context.Context, path string) error {
+	return s.inner.Delete(ctx, path)
+}
+
+// ExistsMany implements BatchExistsStore, using inner's batch capability
+// when available.
+func (s *encryptedStore) ExistsMany(ctx context.Context, paths []string) (map[string]bool, error) {
+	return ExistsMany(ctx, s.inner, paths)
+}
+
+// ListPrefixes implements DelimitedLister, using inner's capability when
+// available.
+func (s *encryptedStore) ListPrefixes(ctx context.Context, prefix, delimiter string) ([]string, []string, error) {
+	return ListPrefixes(ctx, s.inner, prefix, delimiter)
+}
+
+// Stat implements StatStore, reporting the plaintext size.
+func (s *encryptedStore) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	info, err := StatObject(ctx, s.inner, path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	size, err := s.plaintextSize(path, info.SizeBytes)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{SizeBytes: size, ModTime: info.ModTime}, nil
+}
+
+// ListSized implements SizedLister, reporting plaintext sizes.
+func (s *encryptedStore) ListSized(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	infos, err := ListSized(ctx, s.inner, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range infos {
+		size, err := s.plaintextSize(info.Path, info.SizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		infos[i].SizeBytes = size
+	}
+	return infos, nil
+}
+
+func (s *encryptedStore) ReadRange(ctx context.Context, path string, offset, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 || length > maxReadRangeLength || offset > math.MaxInt64-length {
+		return nil, ErrInvalidRange
+	}
+	obj, err := s.openObject(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return obj.readRange(ctx, offset, length)
+}
+
+func (s *encryptedStore) ReaderAt(ctx context.Context, path string) (io.ReaderAt, error) {
+	obj, err := s.openObject(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedReaderAt{ctx: ctx, obj: obj}, nil
+}
+
+// openObject reads the header and size of a stored object for range reads.
+// The header is read with ReadRange first, so stores without range support
+// return ErrRangeReadNotSupported before the object is sized.
+func (s *encryptedStore) openObject(ctx context.Context, path string) (*encryptedObject, error) {
+	nonceSize := int64(s.aead.NonceSize())
+	base, err := s.inner.ReadRange(ctx, path, 0, nonceSize)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(base)) != nonceSize {
+		return nil, fmt.Errorf("lode: %s: truncated encrypted object: %w", path, ErrDecryptionFailed)
+	}
+	info, err := StatObject(ctx, s.inner, path)
+	if err != nil {
+		return nil, err
+	}
+	size, err := s.plaintextSize(path, info.SizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedObject{store: s, path: path, base: base, size: size}, nil
+}
+
+// encryptedObject locates the chunks of one stored object.
+type encryptedObject struct {
+	store *encryptedStore
+	path  string
+	base  []byte
+	size  int64 // plaintext size
+}
+
+// readRange decrypts the chunks covering [offset, offset+length), clamped
+// to the plaintext size.
+func (o *encryptedObject) readRange(ctx context.Context, offset, length int64) ([]byte, error) {
+	if length == 0 || offset >= o.size {
+		return []byte{}, nil
+	}
+	end := min(offset+length, o.size)
+	first, last := offset/encryptedChunkSize, (end-1)/encryptedChunkSize
+	lastChunk := (o.size - 1) / encryptedChunkSize
+
+	sealedSize := o.store.sealedChunkSize()
+	data, err := o.store.inner.ReadRange(ctx, o.path,
+		int64(len(o.base))+first*sealedSize, (last-first+1)*sealedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	plain := make([]byte, 0, (last-first+1)*encryptedChunkSize)
+	for i := first; i <= last; i++ {
+		start := (i - first) * sealedSize
+		if start >= int64(len(data)) {
+			return nil, fmt.Errorf("lode: %s: object changed during read: %w", o.path, ErrDecryptionFailed)
+		}
+		chunk, err := o.store.open(o.path, o.base, uint64(i), data[start:min(start+sealedSize, int64(len(data)))], i == lastChunk)
+		if err != nil {
+			return nil, err
+		}
+		plain = append(plain, chunk...)
+	}
+	from := offset - first*encryptedChunkSize
+	to := end - first*encryptedChunkSize
+	if to > int64(len(plain)) {
+		return nil, fmt.Errorf("lode: %s: object changed during read: %w", o.path, ErrDecryptionFailed)
+	}
+	return plain[from:to], nil
+}
+
+// encryptedReaderAt implements io.ReaderAt over an encrypted object.
+type encryptedReaderAt struct {
+	ctx context.Context
+	obj *encryptedObject
+}
+
+func (r *encryptedReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, ErrInvalidRange
+	}
+	data, err := r.obj.readRange(r.ctx, off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	n := copy(p, data)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// encryptingReader seals src chunk by chunk as it is read. One chunk of
+// lookahead identifies the final chunk.
+type encryptingReader struct {
+	store *encryptedStore
+	src   io.Reader
+	base  []byte
+	index uint64
+	out   []byte // sealed bytes not yet returned; starts as the header
+
+	started bool
+	cur     []byte // next plaintext chunk to seal
+	curEOF  bool   // src ended while reading cur
+	final   bool   // the final chunk has been sealed
+}
+
+func (e *encryptingReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.final {
+			return 0, io.EOF
+		}
+		if err := e.seal(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// seal seals the next chunk into out.
+func (e *encryptingReader) seal() error {
+	if !e.started {
+		e.started = true
+		var err error
+		if e.cur, e.curEOF, err = e.readChunk(); err != nil {
+			return err
+		}
+	}
+
+	final := e.curEOF
+	var next []byte
+	var nextEOF bool
+	if !final {
+		var err error
+		if next, nextEOF, err = e.readChunk(); err != nil {
+			return err
+		}
+		final = len(next) == 0 && nextEOF
+	}
+
+	e.out = e.store.aead.Seal(nil, chunkNonce(e.base, e.index), e.cur, chunkAAD(final))
+	e.index++
+	e.final = final
+	e.cur, e.curEOF = next, nextEOF
+	return nil
+}
+
+// readChunk reads up to one chunk of plaintext, reporting whether src ended.
+func (e *encryptingReader) readChunk() ([]byte, bool, error) {
+	buf := make([]byte, encryptedChunkSize)
+	n, err := io.ReadFull(e.src, buf)
+	switch {
+	case err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF):
+		return buf[:n], true, nil
+	case err != nil:
+		return nil, false, err
+	}
+	return buf, false, nil
+}
+
+// decryptingReader opens chunks of a stored object as it is read.
+type decryptingReader struct {
+	store *encryptedStore
+	path  string
+	src   *bufio.Reader
+	rc    io.Closer
+	base  []byte
+	index uint64
+	out   []byte // plaintext not yet returned
+	final bool   // the final chunk has been opened
+	err   error
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.final {
+			return 0, io.EOF
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// next opens the next chunk into out.
+func (d *decryptingReader) next() error {
+	if d.base == nil {
+		base := make([]byte, d.store.aead.NonceSize())
+		if _, err := io.ReadFull(d.src, base); err != nil {
+			return d.readErr(err)
+		}
+		d.base = base
+	}
+
+	sealed := make([]byte, d.store.sealedChunkSize())
+	n, err := io.ReadFull(d.src, sealed)
+	final := false
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		// io.EOF here means the final chunk is missing.
+		return d.readErr(err)
+	default:
+		if _, err := d.src.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := d.store.open(d.path, d.base, d.index, sealed[:n], final)
+	if err != nil {
+		return err
+	}
+	d.index++
+	d.out = plain
+	d.final = final
+	return nil
+}
+
+// readErr reports a premature end of the object as a decryption failure.
+func (d *decryptingReader) readErr(err error) error {
+	if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("lode: %s: truncated encrypted object: %w", d.path, ErrDecryptionFailed)
+	}
+	return err
+}
+
+func (d *decryptingReader) Close() error {
+	return d.rc.Close()
+}
+
+// encryptedCopyStore is an encryptedStore over an inner CopyStore.
+type encryptedCopyStore struct{ *encryptedStore }
+
+func (s *encryptedCopyStore) Copy(ctx context.Context, src, dst string) error {
+	return s.inner.(CopyStore).Copy(ctx, src, dst)
+}
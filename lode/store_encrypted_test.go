@@ -0,0 +1,274 @@
+package lode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"testing"
+)
+
+// testAEAD returns AES-256-GCM keyed by a single repeated byte.
+func testAEAD(t *testing.T, keyByte byte) cipher.AEAD {
+	t.Helper()
+	block, err := aes.NewCipher(bytes.Repeat([]byte{keyByte}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+// testPayload returns n pseudo-random bytes.
+func testPayload(n int) []byte {
+	rng := rand.New(rand.NewPCG(uint64(n), 1))
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(rng.Uint32())
+	}
+	return data
+}
+
+func readAllFrom(t *testing.T, store Store, path string) ([]byte, error) {
+	t.Helper()
+	rc, err := store.Get(t.Context(), path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(rc)
+}
+
+func TestEncryptedStore_RoundTrip(t *testing.T) {
+	ctx := t.Context()
+	inner := NewMemory()
+	store, err := NewEncryptedStore(inner, testAEAD(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, encryptedChunkSize - 1, encryptedChunkSize, encryptedChunkSize + 1, 3*encryptedChunkSize + 17} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			path := fmt.Sprintf("obj/%d", size)
+			payload := testPayload(size)
+			if err := store.Put(ctx, path, bytes.NewReader(payload)); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := readAllFrom(t, store, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Fatalf("round trip mismatch: got %d bytes, want %d", len(got), len(payload))
+			}
+
+			stored, err := readAllFrom(t, inner, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size >= 16 && bytes.Contains(stored, payload[:16]) {
+				t.Error("stored object contains plaintext")
+			}
+			if info, err := StatObject(ctx, store, path); err != nil || info.SizeBytes != int64(size) {
+				t.Errorf("Stat = %+v, %v; want %d bytes", info, err, size)
+			}
+		})
+	}
+
+	// A fresh nonce per object: equal plaintexts produce different objects.
+	for _, p := range []string{"same/a", "same/b"} {
+		if err := store.Put(ctx, p, bytes.NewReader([]byte("identical"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, _ := readAllFrom(t, inner, "same/a")
+	b, _ := readAllFrom(t, inner, "same/b")
+	if bytes.Equal(a, b) {
+		t.Error("equal plaintexts produced identical ciphertexts")
+	}
+}
+
+func TestEncryptedStore_RangeReads(t *testing.T) {
+	ctx := t.Context()
+	store, err := NewEncryptedStore(NewMemory(), testAEAD(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := testPayload(3*encryptedChunkSize + 100)
+	if err := store.Put(ctx, "obj", bytes.NewReader(payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	cs := int64(encryptedChunkSize)
+	size := int64(len(payload))
+	tests := []struct{ offset, length int64 }{
+		{0, 10},
+		{cs - 5, 10},      // spans a chunk boundary
+		{cs, cs},          // exactly one chunk
+		{10, 2*cs + 50},   // spans three chunks
+		{cs / 2, 2 * cs},  // unaligned, multiple chunks
+		{3*cs - 1, 2},     // into the final chunk
+		{3 * cs, 100},     // the whole final chunk
+		{size - 1, 1},     // last byte
+		{size - 20, 1000}, // clamped at EOF
+		{0, size},         // whole object
+		{0, size + 10*cs}, // whole object, clamped
+		{size, 10},        // at EOF
+		{size + 100, 10},  // beyond EOF
+		{5, 0},            // empty
+	}
+	for _, tt := range tests {
+		want := payload[min(tt.offset, size):min(tt.offset+tt.length, size)]
+		got, err := store.ReadRange(ctx, "obj", tt.offset, tt.length)
+		if err != nil {
+			t.Fatalf("ReadRange(%d, %d): %v", tt.offset, tt.length, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("ReadRange(%d, %d) returned %d bytes, want %d", tt.offset, tt.length, len(got), len(want))
+		}
+	}
+
+	if _, err := store.ReadRange(ctx, "obj", -1, 10); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange, got: %v", err)
+	}
+	if _, err := store.ReadRange(ctx, "missing", 0, 10); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+
+	section, err := OpenSection(ctx, store, "obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if section.Size() != size {
+		t.Errorf("section size = %d, want %d", section.Size(), size)
+	}
+	buf := make([]byte, 200)
+	n, err := section.ReadAt(buf, size-100)
+	if n != 100 || err != io.EOF || !bytes.Equal(buf[:n], payload[size-100:]) {
+		t.Errorf("ReadAt past EOF = %d, %v", n, err)
+	}
+
+	noRange, err := NewEncryptedStore(noRangeStore{NewMemory()}, testAEAD(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := noRange.ReadRange(ctx, "obj", 0, 1); !errors.Is(err, ErrRangeReadNotSupported) {
+		t.Errorf("expected ErrRangeReadNotSupported, got: %v", err)
+	}
+	if _, err := noRange.ReaderAt(ctx, "obj"); !errors.Is(err, ErrRangeReadNotSupported) {
+		t.Errorf("expected ErrRangeReadNotSupported, got: %v", err)
+	}
+}
+
+func TestEncryptedStore_RejectsWrongKeyAndTampering(t *testing.T) {
+	ctx := t.Context()
+	inner := NewMemory()
+	store, err := NewEncryptedStore(inner, testAEAD(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := testPayload(2*encryptedChunkSize + 10)
+	if err := store.Put(ctx, "obj", bytes.NewReader(payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongKey, err := NewEncryptedStore(inner, testAEAD(t, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readAllFrom(t, wrongKey, "obj"); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("Get with wrong key: expected ErrDecryptionFailed, got: %v", err)
+	}
+	if _, err := wrongKey.ReadRange(ctx, "obj", encryptedChunkSize, 5); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("ReadRange with wrong key: expected ErrDecryptionFailed, got: %v", err)
+	}
+
+	stored, err := readAllFrom(t, inner, "obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead := testAEAD(t, 1)
+	sealed, nonceSize := encryptedChunkSize+aead.Overhead(), aead.NonceSize()
+	corruptions := map[string][]byte{
+		"flipped byte":        flipByte(stored, len(stored)/2),
+		"final chunk dropped": stored[:nonceSize+2*sealed],
+		"truncated header":    stored[:nonceSize-1],
+		"chunks swapped": bytes.Join([][]byte{stored[:nonceSize], stored[nonceSize+sealed : nonceSize+2*sealed],
+			stored[nonceSize : nonceSize+sealed], stored[nonceSize+2*sealed:]}, nil),
+	}
+	for name, corrupt := range corruptions {
+		t.Run(name, func(t *testing.T) {
+			if err := inner.Delete(ctx, "corrupt"); err != nil {
+				t.Fatal(err)
+			}
+			if err := inner.Put(ctx, "corrupt", bytes.NewReader(corrupt)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := readAllFrom(t, store, "corrupt"); !errors.Is(err, ErrDecryptionFailed) {
+				t.Errorf("Get: expected ErrDecryptionFailed, got: %v", err)
+			}
+		})
+	}
+}
+
+func flipByte(data []byte, i int) []byte {
+	out := bytes.Clone(data)
+	out[i] ^= 0xff
+	return out
+}
+
+func TestEncryptedStore_Dataset(t *testing.T) {
+	ctx := t.Context()
+	inner := NewMemory()
+	store, err := NewEncryptedStore(inner, testAEAD(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(CopyStore); !ok {
+		t.Error("expected CopyStore to be forwarded from the memory store")
+	}
+	if _, ok := store.(ConditionalWriter); ok {
+		t.Error("ConditionalWriter must not be forwarded")
+	}
+
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithCommitter(NewStagedCommitter()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"id": "secret-value"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ds.Read(ctx, snap.ID)
+	if err != nil || len(records) != 1 || records[0].(map[string]any)["id"] != "secret-value" {
+		t.Fatalf("Read = %v, %v", records, err)
+	}
+
+	keys, err := inner.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		data, err := readAllFrom(t, inner, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("events")) || bytes.Contains(data, []byte("lode-manifest")) {
+			t.Errorf("object %s stores plaintext", k)
+		}
+	}
+
+	if _, err := NewEncryptedStore(NewMemory(), nil); err == nil {
+		t.Error("expected error for nil AEAD")
+	}
+}
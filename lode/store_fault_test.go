@@ -48,6 +48,7 @@ type faultStore struct {
 
 	// Pre-call hooks: called after recording but before blocking
 	beforePut func(path string)
+	beforeGet func(path string)
 
 	// Post-call hooks: called after operation completes (before returning)
 	afterPut    func(path string, err error)
@@ -97,6 +98,14 @@ func (f *faultStore) SetBeforePut(hook func(path string)) {
 	f.beforePut = hook
 }
 
+// SetBeforeGet sets a hook called after a Get is recorded but before it
+// reaches the inner store.
+func (f *faultStore) SetBeforeGet(hook func(path string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.beforeGet = hook
+}
+
 // SetAfterPut sets a hook called after each Put completes.
 func (f *faultStore) SetAfterPut(hook func(path string, err error)) {
 	f.mu.Lock()
@@ -198,8 +207,12 @@ func (f *faultStore) Get(ctx context.Context, path string) (io.ReadCloser, error
 	f.mu.Lock()
 	injectedErr := f.getErr
 	f.getCalls = append(f.getCalls, path)
+	beforeGet := f.beforeGet
 	f.mu.Unlock()
 
+	if beforeGet != nil {
+		beforeGet(path)
+	}
 	if injectedErr != nil {
 		return nil, injectedErr
 	}
@@ -0,0 +1,279 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Prefixed Store
+// -----------------------------------------------------------------------------
+
+// prefixedStore implements Store by scoping every key of an inner store
+// under a fixed prefix.
+type prefixedStore struct {
+	inner  Store
+	prefix string // clean, relative, ends with "/"
+}
+
+// NewPrefixedStore returns a Store that confines inner to the keys under
+// prefix, for example one tenant's namespace in a shared bucket.
+//
+// Every path is joined to prefix before it reaches inner, and List results
+// have prefix removed, so layouts see a clean namespace rooted at prefix.
+// Paths that are absolute or contain ".." segments return ErrInvalidPath,
+// so no key outside prefix can be read, written, or listed.
+//
+// Optional capabilities of inner are preserved: ConditionalWriter and
+// CopyStore are implemented only when inner implements them, while
+// BatchExistsStore, StatStore, DelimitedLister, and SizedLister dispatch
+// through ExistsMany, StatObject, ListPrefixes, and ListSized.
+//
+// Returns an error if prefix is empty, absolute, or not a clean path.
+func NewPrefixedStore(inner Store, prefix string) (Store, error) {
+	if inner == nil {
+		return nil, errors.New("lode: prefixed store requires an inner store")
+	}
+	trimmed := strings.TrimSuffix(prefix, "/")
+	if trimmed == "" || strings.HasPrefix(trimmed, "/") || path.Clean(trimmed) != trimmed ||
+		slices.Contains(strings.Split(trimmed, "/"), "..") {
+		return nil, fmt.Errorf("lode: invalid store prefix %q: must be a clean relative path", prefix)
+	}
+
+	base := &prefixedStore{inner: inner, prefix: trimmed + "/"}
+	_, canCopy := inner.(CopyStore)
+	_, canCAS := inner.(ConditionalWriter)
+	switch {
+	case canCopy && canCAS:
+		return &prefixedCopyCASStore{base}, nil
+	case canCopy:
+		return &prefixedCopyStore{base}, nil
+	case canCAS:
+		return &prefixedCASStore{base}, nil
+	default:
+		return base, nil
+	}
+}
+
+// key maps an object path to its inner key.
+func (p *prefixedStore) key(objPath string) (string, error) {
+	if objPath == "" {
+		return "", ErrInvalidPath
+	}
+	return p.listKey(objPath)
+}
+
+// listKey maps a List prefix, which may be empty, to its inner prefix.
+func (p *prefixedStore) listKey(prefix string) (string, error) {
+	if strings.HasPrefix(prefix, "/") || slices.Contains(strings.Split(prefix, "/"), "..") {
+		return "", ErrInvalidPath
+	}
+	return p.prefix + prefix, nil
+}
+
+func (p *prefixedStore) Put(ctx context.Context, path string, r io.Reader) error {
+	key, err := p.key(path)
+	if err != nil {
+		return err
+	}
+	return p.inner.Put(ctx, key, r)
+}
+
+func (p *prefixedStore) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	key, err := p.key(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.inner.Get(ctx, key)
+}
+
+func (p *prefixedStore) Exists(ctx context.Context, path string) (bool, error) {
+	key, err := p.key(path)
+	if err != nil {
+		return false, err
+	}
+	return p.inner.Exists(ctx, key)
+}
+
+func (p *prefixedStore) List(ctx context.Context, prefix string) ([]string, error) {
+	key, err := p.listKey(prefix)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := p.inner.List(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return dropDirectoryMarkers(p.trimKeys(paths)), nil
+}
+
+func (p *prefixedStore) Delete(ctx context.Context, path string) error {
+	key, err := p.key(path)
+	if err != nil {
+		return err
+	}
+	return p.inner.Delete(ctx, key)
+}
+
+func (p *prefixedStore) ReadRange(ctx context.Context, path string, offset, length int64) ([]byte, error) {
+	key, err := p.key(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.inner.ReadRange(ctx, key, offset, length)
+}
+
+func (p *prefixedStore) ReaderAt(ctx context.Context, path string) (io.ReaderAt, error) {
+	key, err := p.key(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.inner.ReaderAt(ctx, key)
+}
+
+// ExistsMany implements BatchExistsStore, using inner's batch capability
+// when available.
+func (p *prefixedStore) ExistsMany(ctx context.Context, paths []string) (map[string]bool, error) {
+	keys := make([]string, len(paths))
+	for i, objPath := range paths {
+		key, err := p.key(objPath)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+	found, err := ExistsMany(ctx, p.inner, keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(paths))
+	for i, objPath := range paths {
+		result[objPath] = found[keys[i]]
+	}
+	return result, nil
+}
+
+// Stat implements StatStore, using inner's capability when available.
+func (p *prefixedStore) Stat(ctx context.Context, path string) (ObjectInfo, error) {
+	key, err := p.key(path)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return StatObject(ctx, p.inner, key)
+}
+
+// ListPrefixes implements DelimitedLister, using inner's capability when
+// available. Keys and common prefixes have prefix removed.
+func (p *prefixedStore) ListPrefixes(ctx context.Context, prefix, delimiter string) ([]string, []string, error) {
+	key, err := p.listKey(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	keys, common, err := ListPrefixes(ctx, p.inner, key, delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dropDirectoryMarkers(p.trimKeys(keys)), p.trimKeys(common), nil
+}
+
+// ListSized implements SizedLister, using inner's capability when
+// available.
+func (p *prefixedStore) ListSized(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	key, err := p.listKey(prefix)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ListSized(ctx, p.inner, key)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ObjectInfo, 0, len(infos))
+	for _, info := range infos {
+		if rel, ok := p.trimKey(info.Path); ok && !strings.HasSuffix(rel, "/") {
+			info.Path = rel
+			result = append(result, info)
+		}
+	}
+	return result, nil
+}
+
+// dropDirectoryMarkers removes directory-marker keys (trailing slash),
+// which inner stores may list but which are not objects.
+func dropDirectoryMarkers(keys []string) []string {
+	return slices.DeleteFunc(keys, func(k string) bool {
+		return strings.HasSuffix(k, "/")
+	})
+}
+
+// trimKeys removes the prefix from inner keys, dropping any outside it.
+func (p *prefixedStore) trimKeys(keys []string) []string {
+	result := make([]string, 0, len(keys))
+	for _, full := range keys {
+		if rel, ok := p.trimKey(full); ok {
+			result = append(result, rel)
+		}
+	}
+	return result
+}
+
+// trimKey removes the prefix from one inner key. ok is false for keys
+// outside the prefix.
+func (p *prefixedStore) trimKey(full string) (rel string, ok bool) {
+	// Stores may report keys with a leading slash; anything that does
+	// not resolve under the prefix is not part of this namespace.
+	rel, ok = strings.CutPrefix(strings.TrimPrefix(full, "/"), p.prefix)
+	return rel, ok && rel != ""
+}
+
+// copy maps both ends of a copy and forwards it to inner's CopyStore.
+func (p *prefixedStore) copy(ctx context.Context, src, dst string) error {
+	srcKey, err := p.key(src)
+	if err != nil {
+		return err
+	}
+	dstKey, err := p.key(dst)
+	if err != nil {
+		return err
+	}
+	return p.inner.(CopyStore).Copy(ctx, srcKey, dstKey)
+}
+
+// compareAndSwap forwards to inner's ConditionalWriter.
+func (p *prefixedStore) compareAndSwap(ctx context.Context, path, expected, replacement string) error {
+	key, err := p.key(path)
+	if err != nil {
+		return err
+	}
+	return p.inner.(ConditionalWriter).CompareAndSwap(ctx, key, expected, replacement)
+}
+
+// prefixedCopyStore is a prefixedStore over an inner CopyStore.
+type prefixedCopyStore struct{ *prefixedStore }
+
+func (p *prefixedCopyStore) Copy(ctx context.Context, src, dst string) error {
+	return p.copy(ctx, src, dst)
+}
+
+// prefixedCASStore is a prefixedStore over an inner ConditionalWriter.
+type prefixedCASStore struct{ *prefixedStore }
+
+func (p *prefixedCASStore) CompareAndSwap(ctx context.Context, path, expected, replacement string) error {
+	return p.compareAndSwap(ctx, path, expected, replacement)
+}
+
+// prefixedCopyCASStore is a prefixedStore over an inner store with both
+// CopyStore and ConditionalWriter.
+type prefixedCopyCASStore struct{ *prefixedStore }
+
+func (p *prefixedCopyCASStore) Copy(ctx context.Context, src, dst string) error {
+	return p.copy(ctx, src, dst)
+}
+
+func (p *prefixedCopyCASStore) CompareAndSwap(ctx context.Context, path, expected, replacement string) error {
+	return p.compareAndSwap(ctx, path, expected, replacement)
+}
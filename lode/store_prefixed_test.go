@@ -0,0 +1,192 @@
+package lode
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPrefixedStore_IsolatesTenants(t *testing.T) {
+	ctx := t.Context()
+	shared := NewMemory()
+	if err := shared.Put(ctx, "tenant-b/secret", strings.NewReader("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := shared.Put(ctx, "tenant-a2/neighbor", strings.NewReader("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewPrefixedStore(shared, "tenant-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Put(ctx, "dir/obj", strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := shared.Exists(ctx, "tenant-a/dir/obj"); err != nil || !ok {
+		t.Errorf("expected key under the prefix in the inner store, got %v, %v", ok, err)
+	}
+
+	paths, err := a.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(paths, []string{"dir/obj"}) {
+		t.Errorf("List = %v, want [dir/obj]", paths)
+	}
+
+	if _, err := a.Get(ctx, "secret"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another tenant's key, got: %v", err)
+	}
+	for _, p := range []string{"../tenant-b/secret", "dir/../../tenant-b/secret", "/tenant-b/secret", ""} {
+		if _, err := a.Get(ctx, p); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Get(%q): expected ErrInvalidPath, got: %v", p, err)
+		}
+	}
+	if _, err := a.List(ctx, "../"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("List(../): expected ErrInvalidPath, got: %v", err)
+	}
+
+	data, err := a.ReadRange(ctx, "dir/obj", 1, 3)
+	if err != nil || string(data) != "ell" {
+		t.Errorf("ReadRange = %q, %v; want ell", data, err)
+	}
+	ra, err := a.ReaderAt(ctx, "dir/obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2)
+	if _, err := ra.ReadAt(buf, 3); err != nil || string(buf) != "lo" {
+		t.Errorf("ReadAt = %q, %v; want lo", buf, err)
+	}
+	if info, err := StatObject(ctx, a, "dir/obj"); err != nil || info.SizeBytes != 5 {
+		t.Errorf("Stat = %+v, %v; want 5 bytes", info, err)
+	}
+	found, err := ExistsMany(ctx, a, []string{"dir/obj", "secret"})
+	if err != nil || !found["dir/obj"] || found["secret"] {
+		t.Errorf("ExistsMany = %v, %v", found, err)
+	}
+
+	if err := a.Delete(ctx, "dir/obj"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := shared.Exists(ctx, "tenant-b/secret"); !ok {
+		t.Error("other tenant's key must be untouched")
+	}
+}
+
+func TestPrefixedStore_List_DropsDirectoryMarkers(t *testing.T) {
+	ctx := t.Context()
+	mem := NewMemory()
+	if err := mem.Put(ctx, "tenant/datasets/foo/obj", strings.NewReader("x")); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPrefixedStore(markerStore{mem}, "tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, err := p.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(paths, []string{"datasets/foo/obj"}) {
+		t.Errorf("List = %v, want [datasets/foo/obj]", paths)
+	}
+}
+
+func TestPrefixedStore_DatasetsAreScoped(t *testing.T) {
+	ctx := t.Context()
+	shared := NewMemory()
+	scoped := func(prefix string) Dataset {
+		t.Helper()
+		store, err := NewPrefixedStore(shared, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ds
+	}
+
+	a := scoped("tenants/a")
+	if _, err := a.Write(ctx, R(D{"id": 1}), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scoped("tenants/b").Latest(ctx); !errors.Is(err, ErrNoSnapshots) {
+		t.Errorf("expected ErrNoSnapshots in another tenant, got: %v", err)
+	}
+
+	snap, err := scoped("tenants/a/").Latest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := a.Read(ctx, snap.ID)
+	if err != nil || len(records) != 1 {
+		t.Errorf("Read = %v, %v", records, err)
+	}
+
+	keys, err := shared.List(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if !strings.HasPrefix(k, "tenants/a/datasets/events/") {
+			t.Errorf("inner key %q is outside the tenant prefix", k)
+		}
+	}
+}
+
+func TestPrefixedStore_Capabilities(t *testing.T) {
+	ctx := t.Context()
+	full, err := NewPrefixedStore(NewMemory(), "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := full.(CopyStore); !ok {
+		t.Error("expected CopyStore over the memory store")
+	}
+	cas, ok := full.(ConditionalWriter)
+	if !ok {
+		t.Fatal("expected ConditionalWriter over the memory store")
+	}
+	if err := cas.CompareAndSwap(ctx, "latest", "", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyObject(ctx, full, "latest", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := full.Get(ctx, "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if !bytes.Equal(data, []byte("v1")) {
+		t.Errorf("copied content = %q, want v1", data)
+	}
+
+	bare, err := NewPrefixedStore(struct{ Store }{NewMemory()}, "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bare.(CopyStore); ok {
+		t.Error("CopyStore must not be advertised when the inner store lacks it")
+	}
+	if _, ok := bare.(ConditionalWriter); ok {
+		t.Error("ConditionalWriter must not be advertised when the inner store lacks it")
+	}
+
+	for _, prefix := range []string{"", "/", "/abs", "a/../b", "a//b", "./a", ".."} {
+		if _, err := NewPrefixedStore(NewMemory(), prefix); err == nil {
+			t.Errorf("prefix %q: expected error", prefix)
+		}
+	}
+	if _, err := NewPrefixedStore(nil, "p"); err == nil {
+		t.Error("expected error for nil inner store")
+	}
+}
@@ -2,10 +2,18 @@ package lode
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"maps"
 	"math"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pithecene-io/lode/internal/testutil"
 )
@@ -181,8 +189,8 @@ func TestFSStore_ReadRange_NegativeOffset(t *testing.T) {
 	}
 
 	_, err = store.ReadRange(ctx, "test.txt", -1, 10)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for negative offset, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for negative offset, got: %v", err)
 	}
 }
 
@@ -200,8 +208,8 @@ func TestFSStore_ReadRange_NegativeLength(t *testing.T) {
 	}
 
 	_, err = store.ReadRange(ctx, "test.txt", 0, -1)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for negative length, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for negative length, got: %v", err)
 	}
 }
 
@@ -227,8 +235,8 @@ func TestFSStore_ReadRange_LengthOverflow(t *testing.T) {
 
 	// Length exceeding maxInt (only relevant on 32-bit)
 	_, err = store.ReadRange(ctx, "test.txt", 0, math.MaxInt64)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for length overflow, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for length overflow, got: %v", err)
 	}
 }
 
@@ -247,8 +255,8 @@ func TestFSStore_ReadRange_OffsetPlusLengthOverflow(t *testing.T) {
 
 	// offset + length would overflow int64
 	_, err = store.ReadRange(ctx, "test.txt", math.MaxInt64-10, 20)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for offset+length overflow, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for offset+length overflow, got: %v", err)
 	}
 }
 
@@ -383,8 +391,8 @@ func TestMemoryStore_ReadRange_NegativeOffset(t *testing.T) {
 	store := NewMemory()
 
 	_, err := store.ReadRange(ctx, "test.txt", -1, 10)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for negative offset, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for negative offset, got: %v", err)
 	}
 }
 
@@ -393,8 +401,8 @@ func TestMemoryStore_ReadRange_NegativeLength(t *testing.T) {
 	store := NewMemory()
 
 	_, err := store.ReadRange(ctx, "test.txt", 0, -1)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for negative length, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for negative length, got: %v", err)
 	}
 }
 
@@ -410,8 +418,8 @@ func TestMemoryStore_ReadRange_LengthOverflow(t *testing.T) {
 	store := NewMemory()
 
 	_, err := store.ReadRange(ctx, "test.txt", 0, math.MaxInt64)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for length overflow, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for length overflow, got: %v", err)
 	}
 }
 
@@ -420,8 +428,72 @@ func TestMemoryStore_ReadRange_OffsetPlusLengthOverflow(t *testing.T) {
 	store := NewMemory()
 
 	_, err := store.ReadRange(ctx, "test.txt", math.MaxInt64-10, 20)
-	if !errors.Is(err, ErrInvalidPath) {
-		t.Errorf("expected ErrInvalidPath for offset+length overflow, got: %v", err)
+	if !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expected ErrInvalidRange for offset+length overflow, got: %v", err)
+	}
+}
+
+func TestStore_ReadRange_BoundaryContract(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "lode-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testutil.RemoveAll(tmpDir)
+	fs, err := NewFS(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]Store{"memory": NewMemory(), "fs": fs}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := t.Context()
+			if err := store.Put(ctx, "test.txt", bytes.NewReader([]byte("hello"))); err != nil {
+				t.Fatal(err)
+			}
+
+			tests := []struct {
+				name           string
+				offset, length int64
+				want           string
+				wantErr        error
+			}{
+				{"within bounds", 1, 3, "ell", nil},
+				{"length clamped at EOF", 3, 10, "lo", nil},
+				{"huge length clamped", 0, math.MaxInt64, "hello", nil},
+				{"offset at EOF", 5, 10, "", nil},
+				{"offset past EOF", 6, 1, "", nil},
+				{"zero length", 2, 0, "", nil},
+				{"negative offset", -1, 1, "", ErrInvalidRange},
+				{"negative length", 0, -1, "", ErrInvalidRange},
+			}
+			for _, tt := range tests {
+				data, err := store.ReadRange(ctx, "test.txt", tt.offset, tt.length)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("%s: expected %v, got: %v", tt.name, tt.wantErr, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("%s: unexpected error: %v", tt.name, err)
+					continue
+				}
+				if data == nil || string(data) != tt.want {
+					t.Errorf("%s: got %q, want %q (non-nil)", tt.name, data, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestErrInvalidRange_MatchesErrInvalidPath(t *testing.T) {
+	// Stores returned ErrInvalidPath for bad ranges before ErrInvalidRange existed.
+	if !errors.Is(ErrInvalidRange, ErrInvalidPath) {
+		t.Error("expected ErrInvalidRange to match ErrInvalidPath")
+	}
+	if errors.Is(ErrInvalidPath, ErrInvalidRange) {
+		t.Error("ErrInvalidPath must not match ErrInvalidRange")
 	}
 }
 
@@ -514,6 +586,53 @@ func TestFSStore_List_EmptyPrefix_ReturnsEmpty(t *testing.T) {
 	}
 }
 
+func TestStore_List_PartialSegmentPrefix(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "lode-test-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testutil.RemoveAll(tmpDir)
+	fsStore, err := NewFS(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, store := range map[string]Store{"fs": fsStore, "memory": NewMemory()} {
+		t.Run(name, func(t *testing.T) {
+			for _, p := range []string{"datasets/tenant-a/x", "datasets/tenant-b/y", "datasets/other/z", "datasets/ten"} {
+				if err := store.Put(t.Context(), p, strings.NewReader("x")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			paths, err := store.List(t.Context(), "datasets/ten")
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(paths)
+			want := []string{"datasets/ten", "datasets/tenant-a/x", "datasets/tenant-b/y"}
+			if !slices.Equal(paths, want) {
+				t.Errorf("List(datasets/ten) = %v, want %v", paths, want)
+			}
+
+			// A trailing slash lists a directory, not a partial name.
+			paths, err = store.List(t.Context(), "datasets/tenant-a/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"datasets/tenant-a/x"}; !slices.Equal(paths, want) {
+				t.Errorf("List(datasets/tenant-a/) = %v, want %v", paths, want)
+			}
+			paths, err = store.List(t.Context(), "datasets/tenant/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(paths) != 0 {
+				t.Errorf("List(datasets/tenant/) = %v, want none", paths)
+			}
+		})
+	}
+}
+
 func TestMemoryStore_List_NonExistentPrefix_ReturnsEmpty(t *testing.T) {
 	ctx := t.Context()
 	store := NewMemory()
@@ -527,3 +646,501 @@ func TestMemoryStore_List_NonExistentPrefix_ReturnsEmpty(t *testing.T) {
 		t.Errorf("expected empty list, got: %v", paths)
 	}
 }
+
+// -----------------------------------------------------------------------------
+// ConditionalWriter: CompareAndSwap semantics
+// -----------------------------------------------------------------------------
+
+func TestMemoryStore_CompareAndSwap(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	cw, ok := store.(ConditionalWriter)
+	if !ok {
+		t.Fatal("memory store should implement ConditionalWriter")
+	}
+
+	// Missing path with non-empty expected: conflict.
+	if err := cw.CompareAndSwap(ctx, "ptr", "a", "b"); !errors.Is(err, ErrSnapshotConflict) {
+		t.Fatalf("expected ErrSnapshotConflict for missing path, got: %v", err)
+	}
+
+	// Missing path with empty expected: create.
+	if err := cw.CompareAndSwap(ctx, "ptr", "", "a"); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	// Matching expected: replace.
+	if err := cw.CompareAndSwap(ctx, "ptr", "a", "b"); err != nil {
+		t.Fatalf("swap failed: %v", err)
+	}
+
+	// Stale expected: conflict, content unchanged.
+	if err := cw.CompareAndSwap(ctx, "ptr", "a", "c"); !errors.Is(err, ErrSnapshotConflict) {
+		t.Fatalf("expected ErrSnapshotConflict for stale expected, got: %v", err)
+	}
+	data, err := store.ReadRange(ctx, "ptr", 0, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "b" {
+		t.Errorf("expected content %q, got %q", "b", string(data))
+	}
+}
+
+// -----------------------------------------------------------------------------
+// ExistsMany: batch capability dispatch and fallback
+// -----------------------------------------------------------------------------
+
+// batchExistsStore wraps a Store and records ExistsMany calls.
+type batchExistsStore struct {
+	Store
+	calls int
+}
+
+func (b *batchExistsStore) ExistsMany(ctx context.Context, paths []string) (map[string]bool, error) {
+	b.calls++
+	result := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		exists, err := b.Exists(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		result[p] = exists
+	}
+	return result, nil
+}
+
+func TestExistsMany_Fallback(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	if err := store.Put(ctx, "a", bytes.NewReader([]byte("x"))); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ExistsMany(ctx, store, []string{"a", "b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got["a"] || got["b"] {
+		t.Errorf("unexpected result: %v", got)
+	}
+}
+
+func TestExistsMany_UsesBatchCapability(t *testing.T) {
+	ctx := t.Context()
+	store := &batchExistsStore{Store: NewMemory()}
+	if err := store.Put(ctx, "a", bytes.NewReader([]byte("x"))); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ExistsMany(ctx, store, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.calls != 1 {
+		t.Errorf("expected 1 ExistsMany call, got %d", store.calls)
+	}
+	if !got["a"] || got["b"] {
+		t.Errorf("unexpected result: %v", got)
+	}
+}
+
+func TestExistsMany_Fallback_PropagatesError(t *testing.T) {
+	_, err := ExistsMany(t.Context(), NewMemory(), []string{"../escape"})
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath, got: %v", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// StatObject: stat capability dispatch and fallback
+// -----------------------------------------------------------------------------
+
+func TestStatObject_MemoryAndFS(t *testing.T) {
+	ctx := t.Context()
+	fsStore, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]Store{"memory": NewMemory(), "fs": fsStore}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			before := time.Now()
+			if err := store.Put(ctx, "dir/a", bytes.NewReader([]byte("hello"))); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := store.(StatStore); !ok {
+				t.Fatal("expected built-in store to implement StatStore")
+			}
+
+			// Built-in stores report when the object was written; allow for
+			// coarse filesystem timestamp granularity.
+			info, err := StatObject(ctx, store, "dir/a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.ModTime.Before(before.Add(-2*time.Second)) || info.ModTime.After(time.Now().Add(time.Second)) {
+				t.Errorf("ModTime = %v, want the time of Put (%v)", info.ModTime, before)
+			}
+
+			// The capability and the Get fallback must agree.
+			for _, s := range []Store{store, struct{ Store }{store}} {
+				info, err := StatObject(ctx, s, "dir/a")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.SizeBytes != 5 {
+					t.Errorf("SizeBytes = %d, want 5", info.SizeBytes)
+				}
+				if _, err := StatObject(ctx, s, "dir/missing"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("expected ErrNotFound, got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// noRangeStore wraps a Store without range read support.
+type noRangeStore struct {
+	Store
+}
+
+func (noRangeStore) ReadRange(context.Context, string, int64, int64) ([]byte, error) {
+	return nil, ErrRangeReadNotSupported
+}
+
+func (noRangeStore) ReaderAt(context.Context, string) (io.ReaderAt, error) {
+	return nil, ErrRangeReadNotSupported
+}
+
+func TestReadTail(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	if err := store.Put(ctx, "obj", bytes.NewReader([]byte("payload|FOOT"))); err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := ReadTail(ctx, store, "obj", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(tail) != "FOOT" {
+		t.Errorf("ReadTail = %q, want FOOT", tail)
+	}
+	if all, err := ReadTail(ctx, store, "obj", 12); err != nil || string(all) != "payload|FOOT" {
+		t.Errorf("ReadTail(whole object) = %q, %v", all, err)
+	}
+	if empty, err := ReadTail(ctx, store, "obj", 0); err != nil || len(empty) != 0 {
+		t.Errorf("ReadTail(0) = %q, %v", empty, err)
+	}
+
+	for _, n := range []int64{-1, 13} {
+		if _, err := ReadTail(ctx, store, "obj", n); !errors.Is(err, ErrInvalidRange) {
+			t.Errorf("ReadTail(%d): expected ErrInvalidRange, got: %v", n, err)
+		}
+	}
+	if _, err := ReadTail(ctx, store, "missing", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if _, err := ReadTail(ctx, noRangeStore{store}, "obj", 4); !errors.Is(err, ErrRangeReadNotSupported) {
+		t.Errorf("expected ErrRangeReadNotSupported, got: %v", err)
+	}
+}
+
+func TestListSized(t *testing.T) {
+	ctx := t.Context()
+	fsStore, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := NewEncryptedStore(NewMemory(), testAEAD(t, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefixed, err := NewPrefixedStore(NewMemory(), "tenant")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]Store{
+		"memory":    NewMemory(),
+		"fs":        fsStore,
+		"encrypted": encrypted,
+		"prefixed":  prefixed,
+		"fallback":  shuffledStore{NewMemory()},
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			for p, content := range map[string]string{"a/1": "1", "a/x/22": "22", "ab/333": "333"} {
+				if err := store.Put(ctx, p, strings.NewReader(content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			infos, err := ListSized(ctx, store, "a/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]int64)
+			for _, info := range infos {
+				got[info.Path] = info.SizeBytes
+				if name != "fallback" && info.ModTime.IsZero() {
+					t.Errorf("%s: ModTime not reported", info.Path)
+				}
+			}
+			if want := map[string]int64{"a/1": 1, "a/x/22": 2}; !maps.Equal(got, want) {
+				t.Errorf("ListSized = %v, want %v", got, want)
+			}
+			if _, err := ListSized(ctx, store, "../"); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("expected ErrInvalidPath, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestListPrefixes(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	for _, p := range []string{"a/1", "a/x/2", "a/x/3", "a/y/4", "ab/5", "b/6"} {
+		if err := store.Put(ctx, p, strings.NewReader(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		prefix, delimiter string
+		keys, common      []string
+	}{
+		{"a/", "/", []string{"a/1"}, []string{"a/x/", "a/y/"}},
+		{"", "/", nil, []string{"a/", "ab/", "b/"}},
+		{"a", "/", nil, []string{"a/", "ab/"}},
+		{"a/x/", "/", []string{"a/x/2", "a/x/3"}, nil},
+		{"a/", "", []string{"a/1", "a/x/2", "a/x/3", "a/y/4"}, nil},
+		{"missing/", "/", nil, nil},
+	}
+	for _, tt := range tests {
+		keys, common, err := ListPrefixes(ctx, store, tt.prefix, tt.delimiter)
+		if err != nil {
+			t.Fatalf("ListPrefixes(%q, %q): %v", tt.prefix, tt.delimiter, err)
+		}
+		if !slices.Equal(keys, tt.keys) || !slices.Equal(common, tt.common) {
+			t.Errorf("ListPrefixes(%q, %q) = %v, %v; want %v, %v", tt.prefix, tt.delimiter, keys, common, tt.keys, tt.common)
+		}
+	}
+
+	prefixed, err := NewPrefixedStore(store, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, common, err := ListPrefixes(ctx, prefixed, "", "/")
+	if err != nil || !slices.Equal(keys, []string{"1"}) || !slices.Equal(common, []string{"x/", "y/"}) {
+		t.Errorf("prefixed ListPrefixes = %v, %v, %v", keys, common, err)
+	}
+}
+
+func TestOpenSection(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	if err := store.Put(ctx, "obj", bytes.NewReader([]byte("payload|FOOT"))); err != nil {
+		t.Fatal(err)
+	}
+
+	section, err := OpenSection(ctx, store, "obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if section.Size() != 12 {
+		t.Errorf("Size() = %d, want 12", section.Size())
+	}
+	footer := make([]byte, 4)
+	if _, err := section.ReadAt(footer, section.Size()-4); err != nil {
+		t.Fatal(err)
+	}
+	if string(footer) != "FOOT" {
+		t.Errorf("footer = %q, want FOOT", footer)
+	}
+
+	if _, err := OpenSection(ctx, store, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+	if _, err := OpenSection(ctx, noRangeStore{store}, "obj"); !errors.Is(err, ErrRangeReadNotSupported) {
+		t.Errorf("expected ErrRangeReadNotSupported, got: %v", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// CopyStore capability tests
+// -----------------------------------------------------------------------------
+
+// copyRecordingStore wraps a Store and records Copy and Get calls.
+type copyRecordingStore struct {
+	Store
+	copies, gets int
+}
+
+func (c *copyRecordingStore) Copy(ctx context.Context, src, dst string) error {
+	c.copies++
+	return c.Store.(CopyStore).Copy(ctx, src, dst)
+}
+
+func (c *copyRecordingStore) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	c.gets++
+	return c.Store.Get(ctx, path)
+}
+
+func TestCopyObject_NativeAndFallback(t *testing.T) {
+	ctx := t.Context()
+	root := t.TempDir()
+	fsStore, err := NewFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]Store{"memory": NewMemory(), "fs": fsStore}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Put(ctx, "dir/a", bytes.NewReader([]byte("hello"))); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := store.(CopyStore); !ok {
+				t.Fatal("expected built-in store to implement CopyStore")
+			}
+
+			// The capability and the Get+Put fallback must agree.
+			for i, s := range []Store{store, struct{ Store }{store}} {
+				dst := fmt.Sprintf("copies/%d/a", i)
+				if err := CopyObject(ctx, s, "dir/a", dst); err != nil {
+					t.Fatalf("CopyObject failed: %v", err)
+				}
+				rc, err := store.Get(ctx, dst)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, _ := io.ReadAll(rc)
+				_ = rc.Close()
+				if string(data) != "hello" {
+					t.Errorf("copied content = %q, want hello", data)
+				}
+				if err := CopyObject(ctx, s, "dir/a", dst); !errors.Is(err, ErrPathExists) {
+					t.Errorf("expected ErrPathExists, got: %v", err)
+				}
+				if err := CopyObject(ctx, s, "dir/missing", "copies/missing"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("expected ErrNotFound, got: %v", err)
+				}
+			}
+		})
+	}
+
+	// The FS store hard-links rather than rewriting bytes.
+	src, err := os.Stat(filepath.Join(root, "dir", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := os.Stat(filepath.Join(root, "copies", "0", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(src, dst) {
+		t.Error("expected FS Copy to hard-link the source")
+	}
+
+	// Dispatch: native Copy when implemented, Get+Put otherwise.
+	native := &copyRecordingStore{Store: NewMemory()}
+	_ = native.Put(ctx, "a", bytes.NewReader([]byte("x")))
+	if err := CopyObject(ctx, native, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if native.copies != 1 || native.gets != 0 {
+		t.Errorf("native: copies=%d gets=%d, want 1 and 0", native.copies, native.gets)
+	}
+	fallback := &copyRecordingStore{Store: NewMemory()}
+	_ = fallback.Put(ctx, "a", bytes.NewReader([]byte("x")))
+	if err := CopyObject(ctx, struct{ Store }{fallback}, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if fallback.copies != 0 || fallback.gets != 1 {
+		t.Errorf("fallback: copies=%d gets=%d, want 0 and 1", fallback.copies, fallback.gets)
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	ctx := t.Context()
+	for name, store := range map[string]Store{
+		"memory": NewMemory(),
+		"fs":     mustFSStore(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := HealthCheck(ctx, store); err != nil {
+				t.Fatalf("HealthCheck: %v", err)
+			}
+			if err := HealthCheckReadOnly(ctx, store); err != nil {
+				t.Fatalf("HealthCheckReadOnly: %v", err)
+			}
+			if left, err := store.List(ctx, ""); err != nil || len(left) != 0 {
+				t.Errorf("probe objects left behind: %v, %v", left, err)
+			}
+		})
+	}
+
+	// Probes stay outside flat-layout datasets, whatever their ID.
+	fs := newFaultStore(NewMemory())
+	ds, err := NewDataset("_lode_health", newFaultStoreFactory(fs), WithCodec(NewJSONLCodec()), WithLayout(NewFlatLayout()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.Write(ctx, R(D{"n": 1}), Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	fs.Reset()
+	if err := HealthCheck(ctx, fs); err != nil {
+		t.Fatal(err)
+	}
+	if puts := fs.PutCalls(); len(puts) != 1 || strings.Contains(puts[0], "/") {
+		t.Errorf("probe Puts = %v, want one root-level key", puts)
+	}
+	if snaps, err := ds.Snapshots(ctx); err != nil || len(snaps) != 1 {
+		t.Errorf("dataset after health check: %d snapshots, %v", len(snaps), err)
+	}
+
+	boom := errors.New("boom")
+	tests := []struct {
+		name     string
+		inject   func(*faultStore)
+		readOnly bool
+		wantStep string
+	}{
+		{"put", func(f *faultStore) { f.SetPutError(boom) }, false, "put"},
+		{"get", func(f *faultStore) { f.getErr = boom }, false, "get"},
+		{"delete", func(f *faultStore) { f.SetDeleteError(boom) }, false, "delete"},
+		{"list", func(f *faultStore) { f.listErr = boom }, true, "list"},
+	}
+	for _, tt := range tests {
+		t.Run("failing "+tt.name, func(t *testing.T) {
+			mem := NewMemory()
+			fs := newFaultStore(mem)
+			tt.inject(fs)
+			check := HealthCheck
+			if tt.readOnly {
+				check = HealthCheckReadOnly
+			}
+			err := check(ctx, fs)
+			if !errors.Is(err, boom) || !strings.Contains(err.Error(), "health check "+tt.wantStep) {
+				t.Fatalf("expected %s step error wrapping boom, got: %v", tt.wantStep, err)
+			}
+			if tt.name == "get" {
+				if left, _ := mem.List(ctx, ""); len(left) != 0 {
+					t.Errorf("probe not cleaned up after failed read: %v", left)
+				}
+			}
+		})
+	}
+}
+
+func mustFSStore(t *testing.T) Store {
+	t.Helper()
+	store, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
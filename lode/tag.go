@@ -0,0 +1,99 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Snapshot Tags
+// -----------------------------------------------------------------------------
+
+// Tag writes the snapshot ID to the tag's pointer object. With a
+// ConditionalWriter the pointer is swapped against the value read just
+// before, so two writers moving the same tag cannot both succeed; other
+// stores fall back to Delete+Put, like the latest pointer.
+func (d *dataset) Tag(ctx context.Context, alias string, id DatasetSnapshotID) error {
+	key, err := tagKey(d.layout, d.id, alias)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return fmt.Errorf("lode: tag %q: empty snapshot ID: %w", alias, ErrInvalidPath)
+	}
+	if _, err := d.Snapshot(ctx, id); err != nil {
+		return err
+	}
+
+	cw, ok := d.store.(ConditionalWriter)
+	if !ok {
+		_ = d.store.Delete(ctx, key) // ignore error; tag may not exist
+		if err := d.store.Put(ctx, key, strings.NewReader(string(id))); err != nil {
+			return fmt.Errorf("lode: failed to write tag %q: %w", alias, err)
+		}
+		return nil
+	}
+	current, err := readTag(ctx, d.store, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("lode: failed to read tag %q: %w", alias, err)
+	}
+	if err := cw.CompareAndSwap(ctx, key, string(current), string(id)); err != nil {
+		return fmt.Errorf("lode: failed to move tag %q: %w", alias, err)
+	}
+	return nil
+}
+
+func (r *reader) ResolveTag(ctx context.Context, dataset DatasetID, alias string) (ManifestRef, error) {
+	if dataset == "" {
+		return ManifestRef{}, ErrInvalidPath
+	}
+	key, err := tagKey(r.layout, dataset, alias)
+	if err != nil {
+		return ManifestRef{}, err
+	}
+	id, err := readTag(ctx, r.store, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ManifestRef{}, fmt.Errorf("lode: tag %q: %w", alias, ErrNotFound)
+		}
+		return ManifestRef{}, fmt.Errorf("lode: failed to read tag %q: %w", alias, err)
+	}
+	return ManifestRef{ID: id}, nil
+}
+
+// tagKey validates alias and returns its pointer key. Aliases are single
+// path segments, and must not be parsed as a manifest by the layout (the
+// flat layout would read <dataset>/tags/manifest.json as one).
+func tagKey(l layout, dataset DatasetID, alias string) (string, error) {
+	if alias == "" || alias == "." || alias == ".." || strings.ContainsAny(alias, "/\\") {
+		return "", fmt.Errorf("lode: invalid tag %q: %w", alias, ErrInvalidPath)
+	}
+	key := tagPath(l, dataset, alias)
+	if l.isManifest(key) {
+		return "", fmt.Errorf("lode: invalid tag %q: collides with manifest naming: %w", alias, ErrInvalidPath)
+	}
+	return key, nil
+}
+
+// readTag reads the snapshot ID stored at a tag key. An empty object reads
+// as ErrNotFound, matching the latest pointer.
+func readTag(ctx context.Context, store Store, key string) (DatasetSnapshotID, error) {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	id := DatasetSnapshotID(strings.TrimSpace(string(data)))
+	if id == "" {
+		return "", ErrNotFound
+	}
+	return id, nil
+}
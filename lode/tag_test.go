@@ -0,0 +1,142 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDataset_Tag(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		wrap func(Store) Store
+	}{
+		{"conditional", func(s Store) Store { return s }},
+		{"delete and put", func(s Store) Store { return shuffledStore{s} }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			store := tt.wrap(NewMemory())
+			factory := func() (Store, error) { return store, nil }
+			ds, err := NewDataset("events", factory, WithCodec(NewJSONLCodec()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader, err := NewDatasetReader(factory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, err := ds.Write(ctx, R(D{"n": 1}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := ds.Write(ctx, R(D{"n": 2}), Metadata{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := reader.ResolveTag(ctx, "events", "prod"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound for missing tag, got: %v", err)
+			}
+
+			if err := ds.Tag(ctx, "prod", first.ID); err != nil {
+				t.Fatal(err)
+			}
+			ref, err := reader.ResolveTag(ctx, "events", "prod")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ref.ID != first.ID {
+				t.Errorf("prod = %s, want %s", ref.ID, first.ID)
+			}
+
+			// Re-tagging moves the alias.
+			if err := ds.Tag(ctx, "prod", second.ID); err != nil {
+				t.Fatal(err)
+			}
+			if ref, err = reader.ResolveTag(ctx, "events", "prod"); err != nil || ref.ID != second.ID {
+				t.Errorf("prod = %s, %v; want %s", ref.ID, err, second.ID)
+			}
+			if _, err := reader.GetManifest(ctx, "events", ref); err != nil {
+				t.Errorf("resolved ref does not load: %v", err)
+			}
+
+			// Tags are not snapshots.
+			snaps, err := ds.Snapshots(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs, err := reader.ListManifests(ctx, "events", "", ManifestListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(snaps) != 2 || len(refs) != 2 {
+				t.Errorf("found %d snapshots and %d manifests, want 2 each", len(snaps), len(refs))
+			}
+		})
+	}
+}
+
+func TestDataset_Tag_Errors(t *testing.T) {
+	ctx := t.Context()
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"n": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Tag(ctx, "prod", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing snapshot, got: %v", err)
+	}
+	for _, alias := range []string{"", ".", "..", "a/b"} {
+		if err := ds.Tag(ctx, alias, snap.ID); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Tag(%q): expected ErrInvalidPath, got: %v", alias, err)
+		}
+	}
+
+	// Under the flat layout, <dataset>/tags/manifest.json is a manifest path.
+	flat, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithLayout(NewFlatLayout()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := flat.Tag(ctx, "manifest.json", snap.ID); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("expected ErrInvalidPath for alias colliding with manifests, got: %v", err)
+	}
+}
+
+func TestDataset_Tag_ConcurrentMove(t *testing.T) {
+	ctx := t.Context()
+	mem := NewMemory()
+	store := &racingTagStore{Store: mem}
+	ds, err := NewDataset("events", func() (Store, error) { return store, nil }, WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(ctx, R(D{"n": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.race = true
+	if err := ds.Tag(ctx, "prod", snap.ID); !errors.Is(err, ErrSnapshotConflict) {
+		t.Errorf("expected ErrSnapshotConflict, got: %v", err)
+	}
+}
+
+// racingTagStore moves any pointer to "other" just before a swap, simulating
+// a concurrent writer.
+type racingTagStore struct {
+	Store
+	race bool
+}
+
+func (s *racingTagStore) CompareAndSwap(ctx context.Context, path, expected, replacement string) error {
+	cw := s.Store.(ConditionalWriter)
+	if s.race {
+		if err := cw.CompareAndSwap(ctx, path, expected, "other"); err != nil {
+			return err
+		}
+	}
+	return cw.CompareAndSwap(ctx, path, expected, replacement)
+}
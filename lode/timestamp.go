@@ -0,0 +1,376 @@
+package lode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Record Timestamps
+// -----------------------------------------------------------------------------
+
+// recordTimer derives a timestamp from a record. It is implemented by the
+// time-range partitioner and by WithTimestampField, and populates manifest
+// MinTimestamp/MaxTimestamp for records that do not implement Timestamped.
+type recordTimer interface {
+	recordTime(record any) (time.Time, bool)
+}
+
+// timestampField parses the named field of map records as a time.
+type timestampField string
+
+func (f timestampField) recordTime(record any) (time.Time, bool) {
+	m, ok := record.(map[string]any)
+	if !ok {
+		return time.Time{}, false
+	}
+	return parseRecordTime(m[string(f)])
+}
+
+// formattedTimestampField parses the named field of map records with
+// WithTimestampFormats, trying each format in order.
+type formattedTimestampField struct {
+	field   string
+	formats []string
+}
+
+func (f formattedTimestampField) recordTime(record any) (time.Time, bool) {
+	m, ok := record.(map[string]any)
+	if !ok {
+		return time.Time{}, false
+	}
+	v := m[f.field]
+	if t, ok := v.(time.Time); ok {
+		return t.UTC(), true
+	}
+	for _, format := range f.formats {
+		if t, ok := parseTimeFormat(v, format); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTimeFormat parses v with one WithTimestampFormats entry: an epoch
+// token for numbers and numeric strings, otherwise a time.Parse layout for
+// strings.
+func parseTimeFormat(v any, format string) (time.Time, bool) {
+	var unit time.Duration
+	switch format {
+	case TimestampEpochSeconds:
+		unit = time.Second
+	case TimestampEpochMillis:
+		unit = time.Millisecond
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return time.Time{}, false
+		}
+		t, err := time.Parse(format, s)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t.UTC(), true
+	}
+
+	// Integers are converted exactly; float64 loses sub-microsecond
+	// precision at current epoch magnitudes.
+	switch val := v.(type) {
+	case int:
+		return time.Unix(0, int64(val)*int64(unit)).UTC(), true
+	case int64:
+		return time.Unix(0, val*int64(unit)).UTC(), true
+	case float64:
+		return epochFloat(val, unit), true
+	case json.Number:
+		v = string(val)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, i*int64(unit)).UTC(), true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return epochFloat(f, unit), true
+}
+
+// epochFloat converts a fractional epoch count of unit to a UTC time,
+// rounding to the nearest microsecond.
+func epochFloat(n float64, unit time.Duration) time.Time {
+	whole := int64(n)
+	frac := time.Duration((n - float64(whole)) * float64(unit)).Round(time.Microsecond)
+	return time.Unix(0, whole*int64(unit)).Add(frac).UTC()
+}
+
+// recordTimestamp returns the timestamp of a record. Timestamped records
+// take precedence over timer. ok is false when the record has no timestamp;
+// skip reports that timer was consulted and could not parse one.
+func recordTimestamp(record any, timer recordTimer) (t time.Time, ok, skip bool) {
+	if ts, isTs := record.(Timestamped); isTs {
+		return ts.Timestamp(), true, false
+	}
+	if timer == nil {
+		return time.Time{}, false, false
+	}
+	t, ok = timer.recordTime(record)
+	return t, ok, !ok
+}
+
+// parseRecordTime converts a record field value to a UTC time.
+// Accepts time.Time, RFC3339 strings, and numeric Unix epoch seconds.
+func parseRecordTime(v any) (time.Time, bool) {
+	var seconds float64
+	switch val := v.(type) {
+	case time.Time:
+		return val.UTC(), true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, val)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t.UTC(), true
+	case json.Number:
+		f, err := val.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		seconds = f
+	case int:
+		seconds = float64(val)
+	case int64:
+		seconds = float64(val)
+	case float64:
+		seconds = val
+	default:
+		return time.Time{}, false
+	}
+	sec := int64(seconds)
+	nsec := int64((seconds - float64(sec)) * float64(time.Second))
+	return time.Unix(sec, nsec).UTC(), true
+}
+
+// -----------------------------------------------------------------------------
+// WithTimestampField Option
+// -----------------------------------------------------------------------------
+
+// timestampFieldOption implements Option for WithTimestampField (dataset-only).
+type timestampFieldOption struct {
+	field string
+}
+
+// WithTimestampField records manifest MinTimestamp/MaxTimestamp from a record field.
+// Default: none (only Timestamped records contribute timestamps).
+// This option is only valid for NewDataset and requires a codec.
+//
+// The field of each map[string]any record is parsed as time.Time, an RFC3339
+// string, or numeric Unix epoch seconds. Records implementing Timestamped use
+// Timestamp() instead. Missing or unparseable values do not fail the write;
+// they are counted in Manifest.TimestampsSkipped.
+func WithTimestampField(field string) Option {
+	return &timestampFieldOption{field: field}
+}
+
+func (o *timestampFieldOption) applyDataset(cfg *datasetConfig) error {
+	if o.field == "" {
+		return errors.New("WithTimestampField: field must not be empty")
+	}
+	cfg.tsField = o.field
+	return nil
+}
+
+func (o *timestampFieldOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithTimestampField: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// -----------------------------------------------------------------------------
+// WithTimestampFormats Option
+// -----------------------------------------------------------------------------
+
+// Epoch tokens for WithTimestampFormats. Other entries are time.Parse layouts.
+const (
+	// TimestampEpochSeconds parses numbers and numeric strings as Unix
+	// epoch seconds.
+	TimestampEpochSeconds = "epoch"
+
+	// TimestampEpochMillis parses numbers and numeric strings as Unix
+	// epoch milliseconds.
+	TimestampEpochMillis = "epoch_millis"
+)
+
+// timestampFormatsOption implements Option for WithTimestampFormats (dataset-only).
+type timestampFormatsOption struct {
+	formats []string
+}
+
+// WithTimestampFormats sets how WithTimestampField parses field values.
+// Each value is tried against formats in order and the first match wins.
+// An entry is TimestampEpochSeconds, TimestampEpochMillis, or a time.Parse
+// layout such as time.RFC3339. time.Time values are always accepted.
+// Default: time.Time, RFC3339 strings, and numeric Unix epoch seconds.
+// This option is only valid for NewDataset and requires WithTimestampField.
+//
+// Values matching no format are treated as missing and counted in
+// Manifest.TimestampsSkipped; they do not fail the write. Time-range
+// layouts keep their own parsing.
+func WithTimestampFormats(formats ...string) Option {
+	return &timestampFormatsOption{formats: formats}
+}
+
+func (o *timestampFormatsOption) applyDataset(cfg *datasetConfig) error {
+	if len(o.formats) == 0 {
+		return errors.New("WithTimestampFormats: at least one format is required")
+	}
+	if slices.Contains(o.formats, "") {
+		return errors.New("WithTimestampFormats: formats must not be empty")
+	}
+	cfg.tsFormats = slices.Clone(o.formats)
+	return nil
+}
+
+func (o *timestampFormatsOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithTimestampFormats: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// -----------------------------------------------------------------------------
+// WithVerifyTimeBounds Option
+// -----------------------------------------------------------------------------
+
+// verifyTimeBoundsOption implements Option for WithVerifyTimeBounds (dataset-only).
+type verifyTimeBoundsOption struct {
+	enabled bool
+}
+
+// WithVerifyTimeBounds makes reads check that every decoded record's
+// timestamp lies within the snapshot manifest's [MinTimestamp, MaxTimestamp].
+// Default: false.
+// This option is only valid for NewDataset and requires WithTimestampField
+// or a time-range layout, which supplies the timestamp of decoded records.
+//
+// Read, ReadPartitionsWhere, ReadFile, ReadFileRecords, and ReadSince return
+// a *TimeBoundsError at the first violation. Records without a parseable
+// timestamp, and snapshots that recorded no bounds, are not checked.
+// Verification needs every record decoded, so it is meant for validation
+// jobs rather than hot read paths.
+func WithVerifyTimeBounds(enabled bool) Option {
+	return &verifyTimeBoundsOption{enabled: enabled}
+}
+
+func (o *verifyTimeBoundsOption) applyDataset(cfg *datasetConfig) error {
+	cfg.verifyBounds = o.enabled
+	return nil
+}
+
+func (o *verifyTimeBoundsOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithVerifyTimeBounds: %w", ErrOptionNotValidForDatasetReader)
+}
+
+// TimeBoundsError reports a record whose timestamp lies outside the bounds
+// recorded in its snapshot manifest.
+type TimeBoundsError struct {
+	// SnapshotID is the snapshot whose manifest recorded the bounds.
+	SnapshotID DatasetSnapshotID
+	// Path is the data file containing the record.
+	Path string
+	// Index is the 0-based position of the record among those decoded
+	// from the file.
+	Index int
+	// Timestamp is the record's timestamp.
+	Timestamp time.Time
+	// Min and Max are the manifest's MinTimestamp and MaxTimestamp.
+	Min, Max time.Time
+}
+
+func (e *TimeBoundsError) Error() string {
+	return fmt.Sprintf("lode: record %d of %s has timestamp %s outside manifest bounds [%s, %s] of snapshot %s",
+		e.Index, e.Path, e.Timestamp.Format(time.RFC3339Nano),
+		e.Min.Format(time.RFC3339Nano), e.Max.Format(time.RFC3339Nano), e.SnapshotID)
+}
+
+// timeBounds checks decoded records against one manifest's timestamp
+// bounds. A nil *timeBounds checks nothing.
+type timeBounds struct {
+	snapshot DatasetSnapshotID
+	min, max time.Time
+	timer    recordTimer
+}
+
+// timeBounds returns the checker for m, or nil when verification is
+// disabled or m recorded no bounds.
+func (d *dataset) timeBounds(m *Manifest) *timeBounds {
+	if !d.verifyBounds || m.MinTimestamp == nil || m.MaxTimestamp == nil {
+		return nil
+	}
+	return &timeBounds{snapshot: m.SnapshotID, min: *m.MinTimestamp, max: *m.MaxTimestamp, timer: d.timer}
+}
+
+// check returns a *TimeBoundsError when record, the index-th of filePath,
+// has a timestamp outside the bounds.
+func (b *timeBounds) check(filePath string, index int, record any) error {
+	if b == nil {
+		return nil
+	}
+	t, ok, _ := recordTimestamp(record, b.timer)
+	if !ok || (!t.Before(b.min) && !t.After(b.max)) {
+		return nil
+	}
+	return &TimeBoundsError{SnapshotID: b.snapshot, Path: filePath, Index: index, Timestamp: t, Min: b.min, Max: b.max}
+}
+
+// checkAll checks the decoded records of filePath in order.
+func (b *timeBounds) checkAll(filePath string, records []any) error {
+	if b == nil {
+		return nil
+	}
+	for i, record := range records {
+		if err := b.check(filePath, i, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wrap returns it, checking each record as iteration reaches it. Iteration
+// stops at the first violation, which Err then returns.
+func (b *timeBounds) wrap(it FileRecordIterator, filePath string) FileRecordIterator {
+	if b == nil {
+		return it
+	}
+	return &timeBoundsIterator{FileRecordIterator: it, bounds: b, path: filePath}
+}
+
+// timeBoundsIterator implements FileRecordIterator for timeBounds.wrap.
+type timeBoundsIterator struct {
+	FileRecordIterator
+	bounds *timeBounds
+	path   string
+	index  int
+	err    error
+}
+
+func (it *timeBoundsIterator) Next() bool {
+	if it.err != nil || !it.FileRecordIterator.Next() {
+		return false
+	}
+	if err := it.bounds.check(it.path, it.index, it.FileRecordIterator.Record()); err != nil {
+		it.err = err
+		return false
+	}
+	it.index++
+	return true
+}
+
+func (it *timeBoundsIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.FileRecordIterator.Err()
+}
@@ -0,0 +1,244 @@
+package lode
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDataset_Write_WithTimestampField_RecordsMinMax(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithTimestampField("at"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	early := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	late := time.Date(2024, 1, 3, 20, 30, 0, 0, time.UTC)
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": "1", "at": "2024-01-02T12:00:00Z"},
+		D{"id": "2", "at": late},
+		D{"id": "3", "at": early.Unix()},
+		D{"id": "4", "at": "not a time"},
+		D{"id": "5"},
+	), Metadata{})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	m := snap.Manifest
+	if m.MinTimestamp == nil || !m.MinTimestamp.Equal(early) {
+		t.Errorf("MinTimestamp = %v, want %v", m.MinTimestamp, early)
+	}
+	if m.MaxTimestamp == nil || !m.MaxTimestamp.Equal(late) {
+		t.Errorf("MaxTimestamp = %v, want %v", m.MaxTimestamp, late)
+	}
+	if m.TimestampsSkipped != 2 {
+		t.Errorf("TimestampsSkipped = %d, want 2", m.TimestampsSkipped)
+	}
+}
+
+func TestDataset_Write_WithTimestampField_TimestampedTakesPrecedence(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithTimestampField("time"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	snap, err := ds.Write(t.Context(), []any{&timestampedRecord{ID: "a", Time: at}}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Manifest.MinTimestamp == nil || !snap.Manifest.MinTimestamp.Equal(at) {
+		t.Errorf("MinTimestamp = %v, want %v", snap.Manifest.MinTimestamp, at)
+	}
+	if snap.Manifest.TimestampsSkipped != 0 {
+		t.Errorf("Timestamped record must not be skipped, got %d", snap.Manifest.TimestampsSkipped)
+	}
+}
+
+func TestDataset_Write_WithoutTimestampField_LeavesTimestampsNil(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := ds.Write(t.Context(), R(D{"at": "2024-01-02T12:00:00Z"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := snap.Manifest
+	if m.MinTimestamp != nil || m.MaxTimestamp != nil || m.TimestampsSkipped != 0 {
+		t.Errorf("expected no timestamps, got min=%v max=%v skipped=%d", m.MinTimestamp, m.MaxTimestamp, m.TimestampsSkipped)
+	}
+}
+
+func TestDataset_StreamWriteRecords_WithTimestampField(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithTimestampField("at"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iter := &sliceIterator{records: R(
+		D{"at": "2024-01-02T00:00:00Z"},
+		D{"at": "2024-01-01T00:00:00Z"},
+		D{"at": 3.5},
+		D{"at": false},
+	)}
+	snap, err := ds.StreamWriteRecords(t.Context(), iter, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := snap.Manifest
+	wantMin := time.Unix(3, int64(500*time.Millisecond)).UTC()
+	wantMax := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	if m.MinTimestamp == nil || !m.MinTimestamp.Equal(wantMin) {
+		t.Errorf("MinTimestamp = %v, want %v", m.MinTimestamp, wantMin)
+	}
+	if m.MaxTimestamp == nil || !m.MaxTimestamp.Equal(wantMax) {
+		t.Errorf("MaxTimestamp = %v, want %v", m.MaxTimestamp, wantMax)
+	}
+	if m.TimestampsSkipped != 1 {
+		t.Errorf("TimestampsSkipped = %d, want 1", m.TimestampsSkipped)
+	}
+}
+
+func TestWithTimestampField_InvalidConfiguration(t *testing.T) {
+	if _, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithTimestampField("")); err == nil {
+		t.Error("expected error for empty field")
+	}
+	if _, err := NewDataset("events", NewMemoryFactory(), WithTimestampField("at")); err == nil {
+		t.Error("expected error for timestamp field without codec")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithTimestampField("at")); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_Write_WithTimestampFormats(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithTimestampField("at"),
+		WithTimestampFormats(time.RFC3339, TimestampEpochMillis, "2006-01-02"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2024, 1, 3, 20, 30, 0, 250*int(time.Millisecond), time.UTC)
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": "1", "at": "2024-01-02T12:00:00Z"},
+		D{"id": "2", "at": late.UnixMilli()},
+		D{"id": "3", "at": "2024-01-01"},
+		D{"id": "4", "at": "1704196800000"}, // epoch millis as a string
+		D{"id": "5", "at": "01/02/2024"},
+		D{"id": "6"},
+	), Metadata{})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	m := snap.Manifest
+	if m.MinTimestamp == nil || !m.MinTimestamp.Equal(early) {
+		t.Errorf("MinTimestamp = %v, want %v", m.MinTimestamp, early)
+	}
+	if m.MaxTimestamp == nil || !m.MaxTimestamp.Equal(late) {
+		t.Errorf("MaxTimestamp = %v, want %v", m.MaxTimestamp, late)
+	}
+	if m.TimestampsSkipped != 2 {
+		t.Errorf("TimestampsSkipped = %d, want 2", m.TimestampsSkipped)
+	}
+}
+
+func TestWithTimestampFormats_InvalidConfiguration(t *testing.T) {
+	if _, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithTimestampFormats(TimestampEpochSeconds)); err == nil {
+		t.Error("expected error for formats without a timestamp field")
+	}
+	if _, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithTimestampField("at"), WithTimestampFormats()); err == nil {
+		t.Error("expected error for no formats")
+	}
+	if _, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithTimestampField("at"), WithTimestampFormats("")); err == nil {
+		t.Error("expected error for an empty format")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithTimestampFormats(time.RFC3339)); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
+
+func TestDataset_VerifyTimeBounds_DetectsLyingManifest(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemory()
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store),
+		WithCodec(NewJSONLCodec()),
+		WithTimestampField("at"),
+		WithVerifyTimeBounds(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	honest, err := ds.Write(ctx, R(
+		D{"id": 1, "at": "2024-01-01T00:00:00Z"},
+		D{"id": 2, "at": "2024-01-03T00:00:00Z"},
+		D{"id": 3},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.Read(ctx, honest.ID); err != nil {
+		t.Fatalf("honest manifest failed verification: %v", err)
+	}
+
+	// Rewrite the manifest with a MaxTimestamp below the second record.
+	m := *honest.Manifest
+	lie := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	m.MaxTimestamp = &lie
+	manifestPath := "datasets/events/snapshots/" + string(m.SnapshotID) + "/manifest.json"
+	if err := store.Delete(ctx, manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	writeManifest(ctx, t, store, &m)
+
+	var boundsErr *TimeBoundsError
+	if _, err := ds.Read(ctx, m.SnapshotID); !errors.As(err, &boundsErr) {
+		t.Fatalf("Read: expected *TimeBoundsError, got %v", err)
+	}
+	if boundsErr.Index != 1 || !boundsErr.Max.Equal(lie) || boundsErr.Path != m.Files[0].Path {
+		t.Errorf("unexpected error details: %+v", boundsErr)
+	}
+
+	it, err := ds.ReadFileRecords(ctx, m.SnapshotID, m.Files[0].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = it.Close() }()
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 1 || !errors.As(it.Err(), &boundsErr) {
+		t.Errorf("iterator: got %d records and err %v, want 1 record then *TimeBoundsError", n, it.Err())
+	}
+
+	// Without the option the same snapshot reads normally.
+	plain, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records, err := plain.Read(ctx, m.SnapshotID); err != nil || len(records) != 3 {
+		t.Errorf("unverified read: got %d records, err %v", len(records), err)
+	}
+}
+
+func TestWithVerifyTimeBounds_InvalidConfiguration(t *testing.T) {
+	if _, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()), WithVerifyTimeBounds(true)); err == nil {
+		t.Error("expected error without a timestamp source")
+	}
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithVerifyTimeBounds(true)); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// -----------------------------------------------------------------------------
+// Typed Reads
+// -----------------------------------------------------------------------------
+
+// DecodeError reports a record that could not be decoded into the requested
+// type. It matches ErrInvalidFormat via errors.Is and wraps the decoder error.
+type DecodeError struct {
+	// Path is the data file containing the record.
+	Path string
+	// Line is the 1-based line number of the record within the file.
+	Line int
+	// Err is the underlying decode error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode %s: line %d: %v", e.Path, e.Line, e.Err)
+}
+
+func (e *DecodeError) Unwrap() []error {
+	return []error{ErrInvalidFormat, e.Err}
+}
+
+// ReadTyped reads all records of a JSONL snapshot, decoding each line
+// directly into T instead of map[string]any.
+//
+// ds must be created by NewDataset. The compressor is chosen from the
+// snapshot's manifest, as for Read: with WithCodecRegistry any built-in
+// compressor decodes, otherwise it must match the dataset's. Snapshots
+// written with another codec return an error. The first line that fails to
+// decode returns a *DecodeError naming the file and line, unless the
+// dataset's WithOnDecodeError mode drops it. Empty lines are skipped but
+// counted.
+func ReadTyped[T any](ctx context.Context, ds Dataset, id DatasetSnapshotID) ([]T, error) {
+	d, ok := ds.(*dataset)
+	if !ok {
+		return nil, errors.New("lode: ReadTyped requires a Dataset created by NewDataset")
+	}
+
+	snapshot, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	codec, compressor, err := d.resolveReadCodec(snapshot.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := codec.(*jsonlCodec); !ok {
+		return nil, fmt.Errorf("lode: ReadTyped requires a jsonl snapshot, got codec %q", snapshot.Manifest.Codec)
+	}
+
+	var records []T
+	for _, fileRef := range snapshot.Manifest.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if records, err = readTypedFile(ctx, d, compressor, codec.(*jsonlCodec), fileRef.Path, records); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// readTypedFile appends the decoded lines of one JSONL data file to records.
+func readTypedFile[T any](ctx context.Context, d *dataset, compressor Compressor, codec *jsonlCodec, filePath string, records []T) ([]T, error) {
+	rc, err := d.store.Get(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to read data file %s: %w", filePath, err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	decompReader, err := compressor.Decompress(rc)
+	if err != nil {
+		return nil, fmt.Errorf("lode: failed to read data file %s: %w", filePath, err)
+	}
+	defer func() { _ = decompReader.Close() }()
+
+	lines := codec.newLineReader(decompReader)
+	for {
+		data, err := lines.next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil && !lines.tooLong {
+			return nil, fmt.Errorf("lode: failed to read data file %s: %w", filePath, err)
+		}
+		if err == nil && len(data) == 0 {
+			continue
+		}
+		var record T
+		if err == nil {
+			err = jsonCodec.Unmarshal(data, &record)
+		}
+		if err != nil {
+			decodeErr := &DecodeError{Path: filePath, Line: lines.line, Err: err}
+			if !d.dropUndecodable(decodeErr) {
+				return nil, fmt.Errorf("lode: %w", decodeErr)
+			}
+			continue
+		}
+		records = append(records, record)
+	}
+}
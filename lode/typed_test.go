@@ -0,0 +1,107 @@
+package lode
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type typedEvent struct {
+	ID    string  `json:"id"`
+	Count int     `json:"count"`
+	Score float64 `json:"score"`
+}
+
+func TestReadTyped_DecodesIntoStruct(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithHiveLayout("id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(
+		D{"id": "a", "count": 1, "score": 0.5},
+		D{"id": "b", "count": 2, "extra": true},
+	), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadTyped[typedEvent](t.Context(), ds, snap.ID)
+	if err != nil {
+		t.Fatalf("ReadTyped failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(got))
+	}
+	byID := map[string]typedEvent{got[0].ID: got[0], got[1].ID: got[1]}
+	want := map[string]typedEvent{"a": {ID: "a", Count: 1, Score: 0.5}, "b": {ID: "b", Count: 2}}
+	if !reflect.DeepEqual(byID, want) {
+		t.Errorf("ReadTyped = %+v, want %+v", byID, want)
+	}
+
+	// The untyped API is unchanged.
+	untyped, err := ds.Read(t.Context(), snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := untyped[0].(map[string]any); !ok {
+		t.Errorf("Read must still return maps, got %T", untyped[0])
+	}
+}
+
+func TestReadTyped_DecodeErrorReportsLine(t *testing.T) {
+	store := NewMemory()
+	ds, err := NewDataset("events", NewMemoryFactoryFrom(store), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"id": "a", "count": 1}, D{"id": "b", "count": "two"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ReadTyped[typedEvent](t.Context(), ds, snap.ID)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected *DecodeError, got: %v", err)
+	}
+	if decodeErr.Line != 2 || decodeErr.Path != snap.Manifest.Files[0].Path {
+		t.Errorf("DecodeError = %s line %d, want %s line 2", decodeErr.Path, decodeErr.Line, snap.Manifest.Files[0].Path)
+	}
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("expected ErrInvalidFormat, got: %v", err)
+	}
+}
+
+func TestReadTyped_RequiresJSONL(t *testing.T) {
+	csv, err := NewDelimitedCodec(',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"id": "a"}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTyped[typedEvent](t.Context(), ds, snap.ID); err == nil || !strings.Contains(err.Error(), "jsonl") {
+		t.Errorf("expected jsonl codec error, got: %v", err)
+	}
+
+	blobs, err := NewDataset("blobs", NewMemoryFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobSnap, err := blobs.Write(t.Context(), []any{[]byte("x")}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTyped[typedEvent](t.Context(), blobs, blobSnap.ID); err == nil {
+		t.Error("expected error for raw blob snapshot")
+	}
+}
@@ -615,6 +615,9 @@ func validateVolumeManifest(m *VolumeManifest) error {
 	if m.FormatVersion == "" {
 		return &manifestValidationError{Field: "format_version", Message: "is required"}
 	}
+	if err := checkFormatVersion(m.FormatVersion, volumeManifestFormatVersion); err != nil {
+		return err
+	}
 	if m.VolumeID == "" {
 		return &manifestValidationError{Field: "volume_id", Message: "is required"}
 	}
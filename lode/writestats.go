@@ -0,0 +1,156 @@
+package lode
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// Write Statistics
+// -----------------------------------------------------------------------------
+
+// WriteStats describes the work done by one Write or Append.
+//
+// Byte counts cover every data file the write encoded, including files
+// replaced by a reference to an identical parent file under WithDedup.
+// Durations are cumulative: with WithWriteConcurrency above 1,
+// UploadDuration sums the time of overlapping uploads and can exceed the
+// wall-clock time of the write.
+type WriteStats struct {
+	// FileCount is the number of data files in the snapshot.
+	FileCount int
+
+	// UncompressedBytes is the size of the encoded data before compression.
+	UncompressedBytes int64
+
+	// CompressedBytes is the size of the data files as stored.
+	CompressedBytes int64
+
+	// EncodeDuration is the time spent encoding records with the codec.
+	// Zero in raw blob mode.
+	EncodeDuration time.Duration
+
+	// CompressDuration is the time spent in the compressor.
+	CompressDuration time.Duration
+
+	// UploadDuration is the time spent storing data files.
+	UploadDuration time.Duration
+
+	// DuplicatesDropped is the number of records removed by WithRecordDedup
+	// before encoding.
+	DuplicatesDropped int
+}
+
+// CompressionRatio returns UncompressedBytes divided by CompressedBytes.
+// Values above 1 mean compression reduced the stored size. Returns 0 when
+// no bytes were stored.
+func (s *WriteStats) CompressionRatio() float64 {
+	if s.CompressedBytes == 0 {
+		return 0
+	}
+	return float64(s.UncompressedBytes) / float64(s.CompressedBytes)
+}
+
+// writeStatsRecorder accumulates WriteStats for one write. A nil recorder
+// records nothing, so writes without WithWriteStats pay no timing cost.
+type writeStatsRecorder struct {
+	mu    sync.Mutex
+	stats WriteStats
+}
+
+// recordEncode adds one encoded data file.
+func (r *writeStatsRecorder) recordEncode(uncompressed, compressed int64, encode, compress time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.UncompressedBytes += uncompressed
+	r.stats.CompressedBytes += compressed
+	r.stats.EncodeDuration += encode
+	r.stats.CompressDuration += compress
+}
+
+// recordUpload adds the duration of one data file upload. Uploads may run
+// concurrently.
+func (r *writeStatsRecorder) recordUpload(d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.UploadDuration += d
+}
+
+// recordDuplicates adds n records dropped by WithRecordDedup.
+func (r *writeStatsRecorder) recordDuplicates(n int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.DuplicatesDropped += n
+}
+
+// result returns the accumulated stats for a snapshot of fileCount files.
+func (r *writeStatsRecorder) result(fileCount int) *WriteStats {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats
+	stats.FileCount = fileCount
+	return &stats
+}
+
+// timedWriter wraps a compressor writer, counting the uncompressed bytes
+// written through it and the time spent inside it.
+type timedWriter struct {
+	w       io.WriteCloser
+	n       int64
+	elapsed time.Duration
+}
+
+func (t *timedWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := t.w.Write(p)
+	t.elapsed += time.Since(start)
+	t.n += int64(n)
+	return n, err
+}
+
+func (t *timedWriter) Close() error {
+	start := time.Now()
+	err := t.w.Close()
+	t.elapsed += time.Since(start)
+	return err
+}
+
+// -----------------------------------------------------------------------------
+// WithWriteStats Option
+// -----------------------------------------------------------------------------
+
+// writeStatsOption implements Option for WithWriteStats (dataset-only).
+type writeStatsOption struct {
+	enabled bool
+}
+
+// WithWriteStats makes Write and Append report a WriteStats in
+// DatasetSnapshot.WriteStats.
+// Default: false, which leaves WriteStats nil and skips all accounting.
+// This option is only valid for NewDataset.
+func WithWriteStats(enabled bool) Option {
+	return &writeStatsOption{enabled: enabled}
+}
+
+func (o *writeStatsOption) applyDataset(cfg *datasetConfig) error {
+	cfg.writeStats = o.enabled
+	return nil
+}
+
+func (o *writeStatsOption) applyReader(*readerConfig) error {
+	return fmt.Errorf("WithWriteStats: %w", ErrOptionNotValidForDatasetReader)
+}
@@ -0,0 +1,114 @@
+package lode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDataset_Write_WriteStats(t *testing.T) {
+	layout, err := NewHiveLayout("day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds, err := NewDataset("events", NewMemoryFactory(),
+		WithLayout(layout),
+		WithCodec(NewJSONLCodec()),
+		WithCompressor(NewGzipCompressor()),
+		WithWriteStats(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []any
+	for i := range 200 {
+		records = append(records, D{"id": i, "day": fmt.Sprintf("2024-01-0%d", i%2+1), "msg": "the same repetitive payload"})
+	}
+	snap, err := ds.Write(t.Context(), records, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := snap.WriteStats
+	if stats == nil {
+		t.Fatal("WriteStats is nil with WithWriteStats(true)")
+	}
+	if stats.FileCount != 2 || stats.FileCount != snap.FileCount() {
+		t.Errorf("FileCount = %d, snapshot has %d files; want 2", stats.FileCount, snap.FileCount())
+	}
+	if stats.CompressedBytes != snap.TotalBytes() {
+		t.Errorf("CompressedBytes = %d, want stored size %d", stats.CompressedBytes, snap.TotalBytes())
+	}
+	if stats.UncompressedBytes == 0 || stats.CompressedBytes > stats.UncompressedBytes {
+		t.Errorf("compressed %d bytes, uncompressed %d bytes", stats.CompressedBytes, stats.UncompressedBytes)
+	}
+	if ratio := stats.CompressionRatio(); ratio < 1 {
+		t.Errorf("CompressionRatio() = %v, want >= 1", ratio)
+	}
+	if stats.EncodeDuration < 0 || stats.CompressDuration < 0 || stats.UploadDuration < 0 {
+		t.Errorf("negative duration: %+v", stats)
+	}
+
+	// Uncompressed bytes match what the codec produces for the same records.
+	var want int64
+	for _, part := range []string{"2024-01-01", "2024-01-02"} {
+		var partRecords []any
+		for _, r := range records {
+			if r.(D)["day"] == part {
+				partRecords = append(partRecords, r)
+			}
+		}
+		var buf bytes.Buffer
+		if err := NewJSONLCodec().Encode(&buf, partRecords); err != nil {
+			t.Fatal(err)
+		}
+		want += int64(buf.Len())
+	}
+	if stats.UncompressedBytes != want {
+		t.Errorf("UncompressedBytes = %d, want %d", stats.UncompressedBytes, want)
+	}
+}
+
+func TestDataset_Write_WriteStats_RawBlob(t *testing.T) {
+	ds, err := NewDataset("blobs", NewMemoryFactory(), WithWriteStats(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := []byte("raw bytes")
+	snap, err := ds.Write(t.Context(), []any{blob}, Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := snap.WriteStats
+	if stats == nil {
+		t.Fatal("WriteStats is nil with WithWriteStats(true)")
+	}
+	if stats.FileCount != 1 || stats.UncompressedBytes != int64(len(blob)) || stats.CompressedBytes != int64(len(blob)) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.EncodeDuration != 0 {
+		t.Errorf("EncodeDuration = %v, want 0 in raw blob mode", stats.EncodeDuration)
+	}
+	if ratio := stats.CompressionRatio(); ratio != 1 {
+		t.Errorf("CompressionRatio() = %v, want 1 with the no-op compressor", ratio)
+	}
+}
+
+func TestDataset_Write_WriteStatsDisabled(t *testing.T) {
+	ds, err := NewDataset("events", NewMemoryFactory(), WithCodec(NewJSONLCodec()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ds.Write(t.Context(), R(D{"id": 1}), Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.WriteStats != nil {
+		t.Errorf("WriteStats = %+v, want nil by default", snap.WriteStats)
+	}
+
+	if _, err := NewDatasetReader(NewMemoryFactory(), WithWriteStats(true)); !errors.Is(err, ErrOptionNotValidForDatasetReader) {
+		t.Errorf("expected ErrOptionNotValidForDatasetReader, got: %v", err)
+	}
+}